@@ -0,0 +1,628 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+
+	"pencatatan-data-mahasiswa/internal/config"
+	"pencatatan-data-mahasiswa/internal/db"
+	"pencatatan-data-mahasiswa/internal/testdb"
+)
+
+func TestMain(m *testing.M) {
+	testdb.Main(m, "http")
+}
+
+// apiClient menjalankan request ke router dan mencatat pola route yang tersentuh,
+// sehingga test bisa memastikan tidak ada route yang luput dari pengujian end-to-end
+type apiClient struct {
+	t      *testing.T
+	r      *gin.Engine
+	routes []gin.RouteInfo
+	seen   map[string]bool
+}
+
+type response struct {
+	Code int
+	Body map[string]any
+	Raw  string
+}
+
+// get menelusuri body JSON mengikuti key bertingkat, mis. get("data", "id_prodi")
+func (res response) get(keys ...string) any {
+	var cur any = res.Body
+	for _, k := range keys {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[k]
+	}
+	return cur
+}
+
+func (res response) str(keys ...string) string {
+	s, _ := res.get(keys...).(string)
+	return s
+}
+
+func (c *apiClient) do(method, path, token string, body any) response {
+	c.t.Helper()
+	var rd io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		rd = bytes.NewReader(b)
+	}
+	return c.send(method, path, token, "application/json", rd)
+}
+
+func (c *apiClient) upload(path, token, filename, content string) response {
+	c.t.Helper()
+	return c.multipart(path, token, nil, "file", filename, content)
+}
+
+// multipart mengirim field teks beserta satu berkas pada fileField
+func (c *apiClient) multipart(path, token string, fields map[string]string, fileField, filename, content string) response {
+	c.t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+	fw, err := mw.CreateFormFile(fileField, filename)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := io.WriteString(fw, content); err != nil {
+		c.t.Fatal(err)
+	}
+	if err := mw.Close(); err != nil {
+		c.t.Fatal(err)
+	}
+	return c.send(http.MethodPost, path, token, mw.FormDataContentType(), &buf)
+}
+
+func (c *apiClient) send(method, path, token, contentType string, body io.Reader) response {
+	c.t.Helper()
+	req := httptest.NewRequest(method, path, body)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	c.r.ServeHTTP(w, req)
+	c.mark(method, req.URL.Path)
+
+	res := response{Code: w.Code, Raw: w.Body.String()}
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		_ = json.Unmarshal(w.Body.Bytes(), &res.Body)
+	}
+	return res
+}
+
+// expect menjalankan request dan menggagalkan test bila status berbeda
+func (c *apiClient) expect(want int, method, path, token string, body any) response {
+	c.t.Helper()
+	res := c.do(method, path, token, body)
+	if res.Code != want {
+		c.t.Fatalf("%s %s: status %d, want %d; body %s", method, path, res.Code, want, res.Raw)
+	}
+	return res
+}
+
+// mark mencatat route terdaftar yang cocok dengan path, mengutamakan segmen statis
+// seperti yang dilakukan router gin (/semester/import di atas /semester/:id)
+func (c *apiClient) mark(method, path string) {
+	best, bestScore := "", -1
+	for _, rt := range c.routes {
+		if rt.Method != method {
+			continue
+		}
+		if score, ok := matchRoute(rt.Path, path); ok && score > bestScore {
+			best, bestScore = rt.Path, score
+		}
+	}
+	if best != "" {
+		c.seen[method+" "+best] = true
+	}
+}
+
+// matchRoute mencocokkan pola gin dengan path dan mengembalikan jumlah segmen statis yang cocok
+func matchRoute(pattern, path string) (int, bool) {
+	ps, xs := strings.Split(pattern, "/"), strings.Split(path, "/")
+	score := 0
+	for i, p := range ps {
+		if strings.HasPrefix(p, "*") {
+			return score, true
+		}
+		if i >= len(xs) {
+			return 0, false
+		}
+		switch {
+		case strings.HasPrefix(p, ":"):
+			if xs[i] == "" {
+				return 0, false
+			}
+		case p == xs[i]:
+			score++
+		default:
+			return 0, false
+		}
+	}
+	return score, len(ps) == len(xs)
+}
+
+// TestRoutesEndToEnd menjalankan setiap route NewRouterWithDeps terhadap PostgreSQL sungguhan
+// dengan JWT asli untuk keempat role, termasuk trigger set_angkatan di tabel mahasiswa
+func TestRoutesEndToEnd(t *testing.T) {
+	url := testdb.URL(t)
+	ctx := context.Background()
+	if err := db.Migrate(ctx, url); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	cfg := config.Defaults()
+	cfg.JWTSecret = "integration-secret"
+	cfg.Auth.BcryptCost = bcrypt.MinCost
+	cfg.RateLimit.Enabled = false
+	cfg.Features = config.FeatureConfig{Registration: true, SemesterImport: true, MahasiswaImport: true, Metrics: true}
+	pool, err := db.Connect(url, cfg.Database)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer pool.Close()
+
+	gin.SetMode(gin.TestMode)
+	r := NewRouterWithDeps(cfg, pool)
+	c := &apiClient{t: t, r: r, routes: r.Routes(), seen: map[string]bool{}}
+
+	const password = "rahasia123"
+	register := func(username, role string, refID *string) {
+		t.Helper()
+		body := map[string]any{"username": username, "password": password, "role": role, "ref_id": refID}
+		c.expect(http.StatusCreated, http.MethodPost, "/api/v1/auth/register", "", body)
+	}
+	login := func(username string) string {
+		t.Helper()
+		res := c.expect(http.StatusOK, http.MethodPost, "/api/v1/auth/login", "", map[string]any{"username": username, "password": password})
+		token := res.str("token")
+		if token == "" {
+			t.Fatalf("login %s: empty token; body %s", username, res.Raw)
+		}
+		return token
+	}
+
+	// sistem
+	c.expect(http.StatusOK, http.MethodGet, "/", "", nil)
+	c.expect(http.StatusOK, http.MethodGet, "/healthz", "", nil)
+	if res := c.expect(http.StatusOK, http.MethodGet, "/readyz", "", nil); res.str("status") != "ok" {
+		t.Errorf("readyz body = %s", res.Raw)
+	}
+	if res := c.expect(http.StatusOK, http.MethodGet, "/metrics", "", nil); !strings.Contains(res.Raw, "# HELP") {
+		t.Errorf("metrics body does not look like Prometheus exposition")
+	}
+	c.expect(http.StatusOK, http.MethodGet, "/openapi.json", "", nil)
+	if res := c.do(http.MethodGet, "/docs/index.html", "", nil); res.Code != http.StatusOK {
+		t.Errorf("GET /docs/index.html: status %d", res.Code)
+	}
+
+	// auth: admin dan operator tanpa ref_id
+	register("admin", "admin", nil)
+	register("operator", "operator", nil)
+	c.expect(http.StatusConflict, http.MethodPost, "/api/v1/auth/register", "", map[string]any{"username": "admin", "password": password, "role": "admin"})
+	c.expect(http.StatusUnauthorized, http.MethodPost, "/api/v1/auth/login", "", map[string]any{"username": "admin", "password": "salah-password"})
+	admin, operator := login("admin"), login("operator")
+
+	c.expect(http.StatusUnauthorized, http.MethodGet, "/api/v1/fakultas/", "", nil)
+	c.expect(http.StatusUnauthorized, http.MethodGet, "/api/v1/fakultas/", "bukan-jwt", nil)
+
+	// fakultas
+	res := c.expect(http.StatusCreated, http.MethodPost, "/api/v1/fakultas/", admin, map[string]any{"nama_fakultas": "Fakultas Teknik", "singkatan": "FT"})
+	fakultasID := res.str("data", "id_fakultas")
+	if fakultasID == "" {
+		t.Fatalf("create fakultas: no id; body %s", res.Raw)
+	}
+	c.expect(http.StatusOK, http.MethodGet, "/api/v1/fakultas/", operator, nil)
+	c.expect(http.StatusOK, http.MethodGet, "/api/v1/fakultas/"+fakultasID, operator, nil)
+	c.expect(http.StatusOK, http.MethodPut, "/api/v1/fakultas/"+fakultasID, admin, map[string]any{"nama_fakultas": "Fakultas Teknik dan Sains"})
+
+	// prodi
+	res = c.expect(http.StatusCreated, http.MethodPost, "/api/v1/prodi/", operator, map[string]any{
+		"id_fakultas": fakultasID, "nama_prodi": "Informatika", "jenjang": "S1", "kode_prodi": "IF",
+	})
+	prodiID := res.str("data", "id_prodi")
+	register("operator-if", "operator", &prodiID)
+	operatorIF := login("operator-if")
+	c.expect(http.StatusConflict, http.MethodPost, "/api/v1/prodi/", operator, map[string]any{
+		"id_fakultas": fakultasID, "nama_prodi": "Informatika 2", "jenjang": "S1", "kode_prodi": "IF",
+	})
+	c.expect(http.StatusUnprocessableEntity, http.MethodPost, "/api/v1/prodi/", operator, map[string]any{
+		"id_fakultas": "FAK99999", "nama_prodi": "Sistem Informasi", "jenjang": "S1", "kode_prodi": "SI",
+	})
+	c.expect(http.StatusOK, http.MethodGet, "/api/v1/prodi/?match=prefix&q=Inf", admin, nil)
+	c.expect(http.StatusOK, http.MethodGet, "/api/v1/prodi/"+prodiID, admin, nil)
+	c.expect(http.StatusOK, http.MethodPut, "/api/v1/prodi/"+prodiID, admin, map[string]any{
+		"id_fakultas": fakultasID, "nama_prodi": "Teknik Informatika", "jenjang": "S1", "kode_prodi": "IF", "akreditasi": "Unggul",
+	})
+	c.expect(http.StatusOK, http.MethodPatch, "/api/v1/prodi/"+prodiID, operator, map[string]any{"akreditasi": "Baik Sekali"})
+
+	// dosen
+	res = c.expect(http.StatusCreated, http.MethodPost, "/api/v1/dosen/", admin, map[string]any{
+		"nidn": "0011223344", "nama_dosen": "Dr. Rina Wulandari", "email": "rina@kampus.ac.id",
+	})
+	dosenID := res.str("data", "id_dosen")
+	c.expect(http.StatusOK, http.MethodGet, "/api/v1/dosen/?match=fuzzy&q=wulandri", operator, nil)
+	c.expect(http.StatusOK, http.MethodGet, "/api/v1/dosen/"+dosenID, operator, nil)
+	c.expect(http.StatusOK, http.MethodPut, "/api/v1/dosen/"+dosenID, admin, map[string]any{
+		"nidn": "0011223344", "nama_dosen": "Dr. Rina Wulandari, M.Kom", "jabatan_akademik": "Lektor",
+	})
+	c.expect(http.StatusOK, http.MethodPatch, "/api/v1/dosen/"+dosenID, operator, map[string]any{"no_hp": "+6281234567890"})
+
+	// mahasiswa: trigger set_angkatan harus menyamakan angkatan dengan tahun_masuk
+	const nim = "202400000001"
+	angkatan := func(res response) int {
+		f, _ := res.get("data", "angkatan").(float64)
+		return int(f)
+	}
+	res = c.expect(http.StatusCreated, http.MethodPost, "/api/v1/mahasiswa/", operator, map[string]any{
+		"id_mahasiswa": nim, "id_prodi": prodiID, "nik": "3201010101010001", "nama_lengkap": "Siti Aminah",
+		"jenis_kelamin": "P", "tanggal_lahir": "2006-05-17", "tahun_masuk": 2024,
+	})
+	if got := angkatan(res); got != 2024 {
+		t.Errorf("angkatan after create = %d, want 2024", got)
+	}
+	c.expect(http.StatusConflict, http.MethodPost, "/api/v1/mahasiswa/", operator, map[string]any{
+		"id_mahasiswa": nim, "id_prodi": prodiID, "nama_lengkap": "Siti Lain", "jenis_kelamin": "P", "tahun_masuk": 2024,
+	})
+	res = c.expect(http.StatusOK, http.MethodPut, "/api/v1/mahasiswa/"+nim, admin, map[string]any{
+		"id_prodi": prodiID, "nik": "3201010101010001", "nama_lengkap": "Siti Aminah", "jenis_kelamin": "P",
+		"tahun_masuk": 2023, "status": "Aktif",
+	})
+	if got := angkatan(res); got != 2023 {
+		t.Errorf("angkatan after PUT = %d, want 2023", got)
+	}
+	res = c.expect(http.StatusOK, http.MethodPatch, "/api/v1/mahasiswa/"+nim, operator, map[string]any{"tahun_masuk": 2022})
+	if got := angkatan(res); got != 2022 {
+		t.Errorf("angkatan after PATCH = %d, want 2022", got)
+	}
+	// update langsung ke kolom angkatan tetap ditimpa trigger
+	if _, err := pool.Exec(ctx, `UPDATE mahasiswa SET angkatan = 1990 WHERE id_mahasiswa = $1`, nim); err != nil {
+		t.Fatal(err)
+	}
+	if got := angkatan(c.expect(http.StatusOK, http.MethodGet, "/api/v1/mahasiswa/"+nim, admin, nil)); got != 2022 {
+		t.Errorf("angkatan after direct UPDATE = %d, want 2022", got)
+	}
+	res = c.expect(http.StatusOK, http.MethodGet, "/api/v1/mahasiswa/?angkatan=2022&id_fakultas="+fakultasID, operator, nil)
+	if data, _ := res.get("data").([]any); len(data) != 1 {
+		t.Errorf("list mahasiswa angkatan=2022: %d rows, want 1; body %s", len(data), res.Raw)
+	}
+
+	// status hanya berubah lewat endpoint transisi dan setiap transisi tercatat di riwayat
+	c.expect(http.StatusBadRequest, http.MethodPatch, "/api/v1/mahasiswa/"+nim, operator, map[string]any{"status": "Cuti"})
+	// operator hanya untuk prodi ref_id-nya
+	c.expect(http.StatusForbidden, http.MethodPost, "/api/v1/mahasiswa/"+nim+"/status", operator, map[string]any{
+		"status": "Cuti", "alasan": "Cuti sakit",
+	})
+	res = c.expect(http.StatusOK, http.MethodPost, "/api/v1/mahasiswa/"+nim+"/status", operatorIF, map[string]any{
+		"status": "Cuti", "alasan": "Cuti sakit",
+	})
+	if res.str("data", "mahasiswa", "status") != "Cuti" || res.str("data", "history", "status_lama") != "Aktif" {
+		t.Errorf("transition to Cuti: body %s", res.Raw)
+	}
+	c.expect(http.StatusUnprocessableEntity, http.MethodPost, "/api/v1/mahasiswa/"+nim+"/status", operatorIF, map[string]any{
+		"status": "Lulus", "nomor_sk": "SK/001", "alasan": "Yudisium",
+	})
+	c.expect(http.StatusForbidden, http.MethodPost, "/api/v1/mahasiswa/"+nim+"/status", operatorIF, map[string]any{
+		"status": "Lulus", "nomor_sk": "SK/001", "alasan": "Yudisium", "override": true,
+	})
+	c.expect(http.StatusOK, http.MethodPost, "/api/v1/mahasiswa/"+nim+"/status", admin, map[string]any{
+		"status": "Aktif", "alasan": "Kembali kuliah",
+	})
+	res = c.expect(http.StatusOK, http.MethodGet, "/api/v1/mahasiswa/"+nim+"/status-history", operator, nil)
+	if hist, _ := res.get("data").([]any); len(hist) != 2 {
+		t.Errorf("status history: %d entries, want 2; body %s", len(hist), res.Raw)
+	} else if last, _ := hist[0].(map[string]any); last["status_baru"] != "Aktif" || last["username"] != "admin" {
+		t.Errorf("latest history entry = %v, want Cuti -> Aktif by admin", last)
+	}
+
+	// dosen dan mahasiswa login dengan ref_id ke baris yang baru dibuat
+	nimRef := nim
+	register("dosen", "dosen", &dosenID)
+	register("mahasiswa", "mahasiswa", &nimRef)
+	dosen, mahasiswa := login("dosen"), login("mahasiswa")
+
+	for _, tc := range []struct{ path, token string }{
+		{"/api/v1/mahasiswa/", dosen},
+		{"/api/v1/mahasiswa/", mahasiswa},
+		{"/api/v1/fakultas/", dosen},
+		{"/api/v1/prodi/", mahasiswa},
+		{"/api/v1/dosen/", dosen},
+	} {
+		c.expect(http.StatusForbidden, http.MethodGet, tc.path, tc.token, nil)
+	}
+
+	// akun dibuat bersama datanya: mode aktivasi untuk dosen, password awal untuk import mahasiswa
+	res = c.expect(http.StatusCreated, http.MethodPost, "/api/v1/dosen/", admin, map[string]any{
+		"nidn": "0099887766", "nama_dosen": "Dr. Andi Pratama", "create_account": "activation",
+	})
+	dosen2ID, activation := res.str("data", "id_dosen"), res.str("account", "activation_token")
+	if res.str("account", "username") != dosen2ID || activation == "" {
+		t.Fatalf("create dosen with account: body %s", res.Raw)
+	}
+	c.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/auth/activate", "", map[string]any{"token": "token-salah", "password": password})
+	c.expect(http.StatusOK, http.MethodPost, "/api/v1/auth/activate", "", map[string]any{"token": activation, "password": password})
+	dosen2 := login(dosen2ID)
+
+	const nim2 = "202400000002"
+	mhsCSV := "id_mahasiswa,id_prodi,nik,nama_lengkap,jenis_kelamin,tempat_lahir,tanggal_lahir,alamat,email,no_hp,tahun_masuk,status\n" +
+		nim2 + "," + prodiID + ",,Budi Santoso,L,Bandung,2006-01-02,,,,2024,\n" +
+		nim2 + "," + prodiID + ",,Budi Lain,L,,,,,,2024,\n" +
+		"202400000099," + prodiID + ",,Citra Lulus,P,,,,,,2020,Lulus\n"
+	res = c.upload("/api/v1/mahasiswa/import?create_account=password", operator, "mahasiswa.csv", mhsCSV)
+	if res.Code != http.StatusOK || fmt.Sprint(res.get("valid_rows")) != "1" || fmt.Sprint(res.get("invalid_rows")) != "2" {
+		t.Fatalf("import mahasiswa dry run: status %d; body %s", res.Code, res.Raw)
+	}
+	c.expect(http.StatusNotFound, http.MethodGet, "/api/v1/mahasiswa/"+nim2, operator, nil)
+	res = c.upload("/api/v1/mahasiswa/import?dry_run=false&create_account=password", operator, "mahasiswa.csv", mhsCSV)
+	accounts, _ := res.get("accounts").([]any)
+	if res.Code != http.StatusOK || fmt.Sprint(res.get("imported")) != "1" || len(accounts) != 1 {
+		t.Fatalf("import mahasiswa: status %d; body %s", res.Code, res.Raw)
+	}
+	initial, _ := accounts[0].(map[string]any)["initial_password"].(string)
+	res = c.expect(http.StatusOK, http.MethodPost, "/api/v1/auth/login", "", map[string]any{"username": nim2, "password": initial})
+	pending := res.str("token")
+	if res.get("user", "must_change_password") != true {
+		t.Errorf("login with initial password: body %s", res.Raw)
+	}
+	c.expect(http.StatusForbidden, http.MethodGet, "/api/v1/semester/", pending, nil)
+	res = c.expect(http.StatusOK, http.MethodPost, "/api/v1/auth/change-password", pending, map[string]any{"old_password": initial, "new_password": password})
+	c.expect(http.StatusOK, http.MethodGet, "/api/v1/semester/", res.str("token"), nil)
+
+	// semester: baca untuk semua role, tulis hanya admin/operator
+	semester := map[string]any{"id_semester": "20241", "tahun_ajaran": "2024/2025", "term": "Ganjil", "tanggal_mulai": "2024-09-02", "tanggal_selesai": "2025-01-31"}
+	c.expect(http.StatusForbidden, http.MethodPost, "/api/v1/semester/", mahasiswa, semester)
+	c.expect(http.StatusCreated, http.MethodPost, "/api/v1/semester/", admin, semester)
+	c.expect(http.StatusOK, http.MethodGet, "/api/v1/semester/", mahasiswa, nil)
+	c.expect(http.StatusOK, http.MethodGet, "/api/v1/semester/20241", dosen, nil)
+	c.expect(http.StatusOK, http.MethodPut, "/api/v1/semester/20241", operator, map[string]any{
+		"tahun_ajaran": "2024/2025", "term": "Ganjil", "tanggal_mulai": "2024-09-09", "tanggal_selesai": "2025-01-31",
+	})
+	c.expect(http.StatusOK, http.MethodPatch, "/api/v1/semester/20241", operator, map[string]any{"tanggal_selesai": "2025-02-07"})
+	c.expect(http.StatusForbidden, http.MethodDelete, "/api/v1/semester/20241", dosen, nil)
+
+	csv := "id_semester,tahun_ajaran,term,tanggal_mulai,tanggal_selesai\n20242,2024/2025,Genap,2025-02-17,2025-06-30\n"
+	res = c.upload("/api/v1/semester/import?dry_run=false", operator, "semester.csv", csv)
+	if res.Code != http.StatusOK || fmt.Sprint(res.get("imported")) != "1" {
+		t.Fatalf("import semester: status %d; body %s", res.Code, res.Raw)
+	}
+	c.expect(http.StatusOK, http.MethodGet, "/api/v1/semester/20242", mahasiswa, nil)
+
+	// cuti: semester tujuan harus belum berakhir, jadi dibuat relatif terhadap tahun berjalan
+	year := time.Now().Year() + 1
+	cutiSem := fmt.Sprintf("%d1", year)
+	c.expect(http.StatusCreated, http.MethodPost, "/api/v1/semester/", admin, map[string]any{
+		"id_semester": cutiSem, "tahun_ajaran": fmt.Sprintf("%d/%d", year, year+1), "term": "Ganjil",
+		"tanggal_mulai": fmt.Sprintf("%d-09-01", year), "tanggal_selesai": fmt.Sprintf("%d-01-31", year+1),
+	})
+	if res := c.expect(http.StatusOK, http.MethodGet, "/api/v1/prodi/"+prodiID+"/cuti-policy", operator, nil); res.get("data", "default") != true {
+		t.Errorf("cuti policy before PUT: body %s", res.Raw)
+	}
+	c.expect(http.StatusOK, http.MethodPut, "/api/v1/prodi/"+prodiID+"/cuti-policy", operatorIF, map[string]any{"maks_semester": 1})
+
+	const pdf = "%PDF-1.4\n1 0 obj << >> endobj\ntrailer << >>\n%%EOF\n"
+	submitCuti := func(want int, alasan string) string {
+		t.Helper()
+		res := c.multipart("/api/v1/cuti/", mahasiswa, map[string]string{"id_semester": cutiSem, "alasan": alasan}, "lampiran", "surat.pdf", pdf)
+		if res.Code != want {
+			t.Fatalf("submit cuti: status %d, want %d; body %s", res.Code, want, res.Raw)
+		}
+		return fmt.Sprint(res.get("data", "id_cuti"))
+	}
+	cutiID := submitCuti(http.StatusCreated, "Merawat orang tua yang sakit")
+	submitCuti(http.StatusConflict, "Pengajuan kedua di semester yang sama")
+	res = c.expect(http.StatusOK, http.MethodGet, "/api/v1/cuti/", mahasiswa, nil)
+	if data, _ := res.get("data").([]any); len(data) != 1 {
+		t.Errorf("list cuti mahasiswa: %d rows, want 1; body %s", len(data), res.Raw)
+	}
+	c.expect(http.StatusForbidden, http.MethodGet, "/api/v1/cuti/", dosen, nil)
+	c.expect(http.StatusOK, http.MethodGet, "/api/v1/cuti/"+cutiID, operatorIF, nil)
+	if res := c.expect(http.StatusOK, http.MethodGet, "/api/v1/cuti/"+cutiID+"/lampiran", admin, nil); res.Raw != pdf {
+		t.Errorf("lampiran body = %q", res.Raw)
+	}
+	c.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/cuti/"+cutiID+"/reject", operatorIF, nil)
+	c.expect(http.StatusOK, http.MethodPost, "/api/v1/cuti/"+cutiID+"/cancel", mahasiswa, nil)
+	cutiID = submitCuti(http.StatusCreated, "Merawat orang tua yang sakit")
+	c.expect(http.StatusOK, http.MethodPost, "/api/v1/cuti/"+cutiID+"/reject", operatorIF, map[string]any{"catatan": "Lampiran tidak terbaca"})
+	cutiID = submitCuti(http.StatusCreated, "Merawat orang tua yang sakit, surat baru")
+	c.expect(http.StatusForbidden, http.MethodPost, "/api/v1/cuti/"+cutiID+"/approve", mahasiswa, nil)
+	if res := c.expect(http.StatusOK, http.MethodPost, "/api/v1/cuti/"+cutiID+"/approve", operatorIF, nil); res.str("data", "status") != "Disetujui" {
+		t.Errorf("approve cuti: body %s", res.Raw)
+	}
+	c.expect(http.StatusConflict, http.MethodDelete, "/api/v1/semester/"+cutiSem, admin, nil)
+
+	// pembimbing akademik: penetapan satu mahasiswa (dengan riwayat) dan massal per prodi/angkatan
+	c.expect(http.StatusOK, http.MethodPut, "/api/v1/mahasiswa/"+nim+"/pembimbing", operator, map[string]any{"id_dosen": dosen2ID})
+	c.expect(http.StatusOK, http.MethodPut, "/api/v1/mahasiswa/"+nim+"/pembimbing", operatorIF, map[string]any{"id_dosen": dosenID, "alasan": "Dosen sebelumnya tugas belajar"})
+	c.expect(http.StatusUnprocessableEntity, http.MethodPut, "/api/v1/mahasiswa/"+nim+"/pembimbing", operator, map[string]any{"id_dosen": dosenID})
+	c.expect(http.StatusUnprocessableEntity, http.MethodPut, "/api/v1/mahasiswa/"+nim+"/pembimbing", operator, map[string]any{"id_dosen": "DSN9999999"})
+	res = c.expect(http.StatusOK, http.MethodGet, "/api/v1/mahasiswa/"+nim+"/pembimbing-history", operator, nil)
+	if hist, _ := res.get("data").([]any); len(hist) != 2 {
+		t.Errorf("pembimbing history: %d entries, want 2; body %s", len(hist), res.Raw)
+	} else if cur, _ := hist[0].(map[string]any); cur["id_dosen"] != dosenID || cur["tanggal_selesai"] != nil {
+		t.Errorf("current pembimbing = %v, want %s without tanggal_selesai", cur, dosenID)
+	}
+	bulk := map[string]any{"id_prodi": prodiID, "angkatan": 2024}
+	res = c.expect(http.StatusOK, http.MethodPost, "/api/v1/dosen/"+dosenID+"/bimbingan", admin, bulk)
+	if fmt.Sprint(res.get("data", "assigned")) != "["+nim2+"]" {
+		t.Errorf("bulk pembimbing: body %s", res.Raw)
+	}
+	bulk["hanya_tanpa_pembimbing"] = true
+	if res := c.expect(http.StatusOK, http.MethodPost, "/api/v1/dosen/"+dosen2ID+"/bimbingan", admin, bulk); fmt.Sprint(res.get("data", "skipped")) != "["+nim2+"]" {
+		t.Errorf("bulk pembimbing hanya_tanpa_pembimbing: body %s", res.Raw)
+	}
+	res = c.expect(http.StatusOK, http.MethodGet, "/api/v1/bimbingan/?sort_by=nama_lengkap", dosen, nil)
+	if data, _ := res.get("data").([]any); len(data) != 2 {
+		t.Errorf("bimbingan dosen: %d rows, want 2; body %s", len(data), res.Raw)
+	}
+
+	// KRS: disusun dan diajukan mahasiswa, dikembalikan lalu disetujui PA
+	const mkID, kelasID = "MK00000001", "KLS000000001"
+	if _, err := pool.Exec(ctx, `INSERT INTO mata_kuliah (id_mk, kode_mk, nama_mk, sks, id_prodi) VALUES ($1, 'IF101', 'Algoritma', 3, $2)`, mkID, prodiID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, `INSERT INTO kelas_kuliah (id_kelas, id_mk, id_semester, nama_kelas, id_dosen_pengampu) VALUES ($1, $2, '20242', 'A', $3)`, kelasID, mkID, dosenID); err != nil {
+		t.Fatal(err)
+	}
+	krsPath := "/api/v1/krs/20242"
+	if res := c.expect(http.StatusOK, http.MethodGet, krsPath, mahasiswa, nil); res.str("data", "status") != "Draft" {
+		t.Errorf("krs before submit: body %s", res.Raw)
+	}
+	c.expect(http.StatusUnprocessableEntity, http.MethodPost, krsPath+"/submit", mahasiswa, nil)
+	c.expect(http.StatusUnprocessableEntity, http.MethodPut, krsPath, mahasiswa, map[string]any{"kelas": []string{"KLS999999999"}})
+	if res := c.expect(http.StatusOK, http.MethodPut, krsPath, mahasiswa, map[string]any{"kelas": []string{kelasID}}); fmt.Sprint(res.get("data", "total_sks")) != "3" {
+		t.Errorf("replace krs: body %s", res.Raw)
+	}
+	c.expect(http.StatusOK, http.MethodPost, krsPath+"/submit", mahasiswa, nil)
+	c.expect(http.StatusConflict, http.MethodPut, krsPath, mahasiswa, map[string]any{"kelas": []string{}})
+	if _, err := pool.Exec(ctx, `DELETE FROM krs WHERE id_mahasiswa = $1`, nim); err == nil {
+		t.Error("DELETE krs after submit: want krs_final violation from trigger lock_final")
+	}
+
+	res = c.expect(http.StatusOK, http.MethodGet, "/api/v1/bimbingan/krs?status=Diajukan", dosen, nil)
+	data, _ := res.get("data").([]any)
+	if len(data) != 1 {
+		t.Fatalf("pengajuan krs: %d rows, want 1; body %s", len(data), res.Raw)
+	}
+	pengajuanPath := fmt.Sprintf("/api/v1/bimbingan/krs/%v", data[0].(map[string]any)["id_pengajuan"])
+	c.expect(http.StatusOK, http.MethodGet, pengajuanPath, dosen, nil)
+	c.expect(http.StatusNotFound, http.MethodGet, pengajuanPath, dosen2, nil)
+	c.expect(http.StatusBadRequest, http.MethodPost, pengajuanPath+"/return", dosen, nil)
+	c.expect(http.StatusOK, http.MethodPost, pengajuanPath+"/return", dosen, map[string]any{"catatan": "Tambahkan mata kuliah wajib"})
+	c.expect(http.StatusOK, http.MethodPut, krsPath, mahasiswa, map[string]any{"kelas": []string{kelasID}})
+	c.expect(http.StatusOK, http.MethodPost, krsPath+"/submit", mahasiswa, nil)
+	c.expect(http.StatusNotFound, http.MethodPost, pengajuanPath+"/approve", dosen2, nil)
+	if res := c.expect(http.StatusOK, http.MethodPost, pengajuanPath+"/approve", dosen, nil); res.str("data", "status") != "Disetujui" {
+		t.Errorf("approve krs: body %s", res.Raw)
+	}
+	c.expect(http.StatusConflict, http.MethodPost, pengajuanPath+"/approve", dosen, nil)
+
+	// IPK hanya dari KRS yang disetujui
+	if _, err := pool.Exec(ctx, `INSERT INTO nilai (id_krs, nilai_huruf, bobot) SELECT id_krs, 'AB', 3.5 FROM krs WHERE id_mahasiswa = $1`, nim); err != nil {
+		t.Fatal(err)
+	}
+	res = c.expect(http.StatusOK, http.MethodGet, "/api/v1/dosen/"+dosenID+"/bimbingan?sort_by=ipk&id_mahasiswa="+nim, admin, nil)
+	if rows, _ := res.get("data").([]any); len(rows) != 1 || rows[0].(map[string]any)["ipk"] != 3.5 {
+		t.Errorf("bimbingan with ipk: body %s", res.Raw)
+	}
+	c.expect(http.StatusConflict, http.MethodDelete, "/api/v1/dosen/"+dosenID, admin, nil)
+
+	// kurikulum dan prasyarat: IF102 butuh IF101 yang lulus dengan AB pada KRS 20242
+	const mk2ID, kelas2ID = "MK00000002", "KLS000000002"
+	if _, err := pool.Exec(ctx, `INSERT INTO mata_kuliah (id_mk, kode_mk, nama_mk, sks, id_prodi) VALUES ($1, 'IF102', 'Struktur Data', 3, $2)`, mk2ID, prodiID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, `INSERT INTO kelas_kuliah (id_kelas, id_mk, id_semester, nama_kelas, id_dosen_pengampu) VALUES ($1, $2, '20241', 'A', $3)`, kelas2ID, mk2ID, dosenID); err != nil {
+		t.Fatal(err)
+	}
+	kurikulum := map[string]any{"id_prodi": prodiID, "tahun": 2024, "nama": "Kurikulum 2024", "aktif": true}
+	c.expect(http.StatusForbidden, http.MethodPost, "/api/v1/kurikulum/", dosen, kurikulum)
+	res = c.expect(http.StatusCreated, http.MethodPost, "/api/v1/kurikulum/", admin, kurikulum)
+	kurikulumPath := fmt.Sprintf("/api/v1/kurikulum/%v", res.get("data", "id_kurikulum"))
+	c.expect(http.StatusConflict, http.MethodPost, "/api/v1/kurikulum/", operator, kurikulum)
+	c.expect(http.StatusOK, http.MethodPut, kurikulumPath, operator, map[string]any{"tahun": 2024, "nama": "Kurikulum 2024 Revisi", "aktif": true})
+	c.expect(http.StatusUnprocessableEntity, http.MethodPut, kurikulumPath+"/mata-kuliah", admin, map[string]any{
+		"mata_kuliah": []map[string]any{{"id_mk": "MK99999999", "semester": 1}},
+	})
+	res = c.expect(http.StatusOK, http.MethodPut, kurikulumPath+"/mata-kuliah", admin, map[string]any{
+		"mata_kuliah": []map[string]any{{"id_mk": mkID, "semester": 1}, {"id_mk": mk2ID, "semester": 2, "jenis": "Pilihan"}},
+	})
+	if fmt.Sprint(res.get("data", "total_sks")) != "6" {
+		t.Errorf("kurikulum mata kuliah: body %s", res.Raw)
+	}
+	if res := c.expect(http.StatusOK, http.MethodGet, "/api/v1/kurikulum/?aktif=true&id_prodi="+prodiID, mahasiswa, nil); fmt.Sprint(res.get("meta", "total")) != "1" {
+		t.Errorf("kurikulum list: body %s", res.Raw)
+	}
+	if res := c.expect(http.StatusOK, http.MethodGet, kurikulumPath, mahasiswa, nil); res.str("data", "nama") != "Kurikulum 2024 Revisi" {
+		t.Errorf("kurikulum detail: body %s", res.Raw)
+	}
+	prasyaratPath := "/api/v1/mata-kuliah/" + mk2ID + "/prasyarat"
+	c.expect(http.StatusForbidden, http.MethodPut, prasyaratPath, dosen, map[string]any{"prasyarat": []any{}})
+	c.expect(http.StatusOK, http.MethodPut, prasyaratPath, admin, map[string]any{
+		"prasyarat": []map[string]any{{"id_mk_prasyarat": mkID, "nilai_minimal": "A"}},
+	})
+	c.expect(http.StatusUnprocessableEntity, http.MethodPut, "/api/v1/mata-kuliah/"+mkID+"/prasyarat", admin, map[string]any{
+		"prasyarat": []map[string]any{{"id_mk_prasyarat": mk2ID}},
+	})
+	if res := c.expect(http.StatusOK, http.MethodGet, prasyaratPath, mahasiswa, nil); fmt.Sprint(res.get("data")) == "[]" {
+		t.Errorf("prasyarat: body %s", res.Raw)
+	}
+	c.expect(http.StatusOK, http.MethodPut, "/api/v1/krs/20241", mahasiswa, map[string]any{"kelas": []string{kelas2ID}})
+	res = c.expect(http.StatusUnprocessableEntity, http.MethodPost, "/api/v1/krs/20241/submit", mahasiswa, nil)
+	if details, _ := res.get("details").([]any); len(details) != 1 || details[0].(map[string]any)["nilai_terbaik"] != "AB" {
+		t.Errorf("submit with unmet prasyarat: body %s", res.Raw)
+	}
+	c.expect(http.StatusOK, http.MethodPut, prasyaratPath, operator, map[string]any{
+		"prasyarat": []map[string]any{{"id_mk_prasyarat": mkID, "nilai_minimal": "B"}},
+	})
+	c.expect(http.StatusOK, http.MethodPost, "/api/v1/krs/20241/submit", mahasiswa, nil)
+	c.expect(http.StatusOK, http.MethodDelete, kurikulumPath, admin, nil)
+	c.expect(http.StatusNotFound, http.MethodGet, kurikulumPath, admin, nil)
+
+	for _, q := range []string{
+		`DELETE FROM krs_pengajuan WHERE id_mahasiswa = $1`,
+		`DELETE FROM krs WHERE id_mahasiswa = $1`,
+	} {
+		if _, err := pool.Exec(ctx, q, nim); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pool.Exec(ctx, `DELETE FROM prasyarat_mk WHERE id_mk = $1`, mk2ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, `DELETE FROM kelas_kuliah WHERE id_kelas IN ($1, $2)`, kelasID, kelas2ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, `DELETE FROM mata_kuliah WHERE id_mk IN ($1, $2)`, mkID, mk2ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// delete: yang masih dirujuk ditolak, lalu hapus berurutan dari anak ke induk
+	c.expect(http.StatusConflict, http.MethodDelete, "/api/v1/prodi/"+prodiID, admin, nil)
+	c.expect(http.StatusConflict, http.MethodDelete, "/api/v1/fakultas/"+fakultasID, admin, nil)
+	c.expect(http.StatusOK, http.MethodDelete, "/api/v1/semester/20241", admin, nil)
+	c.expect(http.StatusOK, http.MethodDelete, "/api/v1/semester/20242", operator, nil)
+	c.expect(http.StatusOK, http.MethodDelete, "/api/v1/mahasiswa/"+nim, operator, nil)
+	c.expect(http.StatusNotFound, http.MethodGet, "/api/v1/mahasiswa/"+nim, operator, nil)
+	c.expect(http.StatusOK, http.MethodDelete, "/api/v1/mahasiswa/"+nim2, operator, nil)
+	c.expect(http.StatusOK, http.MethodDelete, "/api/v1/semester/"+cutiSem, admin, nil)
+	c.expect(http.StatusOK, http.MethodDelete, "/api/v1/dosen/"+dosenID, admin, nil)
+	c.expect(http.StatusOK, http.MethodDelete, "/api/v1/dosen/"+dosen2ID, admin, nil)
+	c.expect(http.StatusOK, http.MethodDelete, "/api/v1/prodi/"+prodiID, admin, nil)
+	c.expect(http.StatusOK, http.MethodDelete, "/api/v1/fakultas/"+fakultasID, admin, nil)
+	c.expect(http.StatusNotFound, http.MethodDelete, "/api/v1/fakultas/"+fakultasID, admin, nil)
+
+	for _, rt := range c.routes {
+		if !c.seen[rt.Method+" "+rt.Path] {
+			t.Errorf("route %s %s is not exercised by TestRoutesEndToEnd", rt.Method, rt.Path)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swaggest/swgui/v5emb"
+
+	"pencatatan-data-mahasiswa/internal/openapi"
+	admin "pencatatan-data-mahasiswa/internal/todo/handler/admin"
+	auth "pencatatan-data-mahasiswa/internal/todo/handler/auth"
+	health "pencatatan-data-mahasiswa/internal/todo/handler/health"
+	adminsvc "pencatatan-data-mahasiswa/internal/todo/service/admin"
+	authsvc "pencatatan-data-mahasiswa/internal/todo/service/auth"
+)
+
+// docsPath adalah lokasi Swagger UI (aset tersemat di binary)
+const docsPath = "/docs/"
+
+// apiOperations mengumpulkan deskripsi semua route yang bisa didaftarkan NewRouterWithDeps
+func apiOperations() []openapi.Operation {
+	ops := []openapi.Operation{
+		{Method: http.MethodGet, Path: "/", Tag: "system", Summary: "Health check sederhana",
+			Response: map[string]string{}, Envelope: openapi.EnvelopeRaw},
+		{Method: http.MethodGet, Path: "/metrics", Tag: "system", Summary: "Metrik Prometheus",
+			ContentType: "text/plain", Response: "", Envelope: openapi.EnvelopeRaw},
+		{Method: http.MethodGet, Path: "/openapi.json", Tag: "system", Summary: "Dokumen OpenAPI ini",
+			Response: map[string]any{}, Envelope: openapi.EnvelopeRaw},
+		{Method: http.MethodGet, Path: docsPath + "*any", Tag: "system", Summary: "Swagger UI",
+			ContentType: "text/html", Response: "", Envelope: openapi.EnvelopeRaw},
+	}
+	ops = append(ops, health.Operations()...)
+	ops = append(ops, auth.Operations()...)
+	return append(ops, admin.Operations()...)
+}
+
+// apiEnums: nilai pilihan diambil dari validator service agar dokumen tidak menyimpang
+func apiEnums() map[string][]string {
+	enums := adminsvc.Choices()
+	enums["role"] = authsvc.Roles()
+	enums["create_account"] = authsvc.AccountModes()
+	enums["mode"] = authsvc.AccountModes()
+	return enums
+}
+
+// registerDocs memasang /openapi.json dan Swagger UI. Dokumen hanya memuat route yang benar-benar
+// terdaftar (mis. /auth/register hilang bila fitur registrasi dimatikan); dipanggil paling akhir.
+func registerDocs(r *gin.Engine) {
+	registered := map[string]bool{
+		http.MethodGet + " /openapi.json":        true,
+		http.MethodGet + " " + docsPath + "*any": true,
+	}
+	for _, rt := range r.Routes() {
+		registered[rt.Method+" "+rt.Path] = true
+	}
+	var ops []openapi.Operation
+	for _, op := range apiOperations() {
+		if registered[op.Key()] {
+			ops = append(ops, op)
+		}
+	}
+	doc := openapi.Build(openapi.Info{
+		Title:       "Pencatatan Data Mahasiswa API",
+		Version:     "1.0.0",
+		Description: "Error memakai bentuk {error, code, message, fields}; message mengikuti Accept-Language (id/en).",
+	}, ops, apiEnums())
+
+	r.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	})
+	ui := v5emb.New("Pencatatan Data Mahasiswa API", "/openapi.json", docsPath)
+	r.GET(docsPath+"*any", gin.WrapH(ui))
+}
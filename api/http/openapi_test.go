@@ -0,0 +1,68 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"pencatatan-data-mahasiswa/internal/config"
+)
+
+var ginParam = regexp.MustCompile(`[:*]([A-Za-z_][A-Za-z0-9_]*)`)
+
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := config.Defaults()
+	cfg.JWTSecret = "test-secret"
+	// pool nil cukup: route hanya didaftarkan, tidak ada handler yang dipanggil selain /openapi.json
+	return NewRouterWithDeps(cfg, nil)
+}
+
+// TestOpenAPIDocumentsEveryRoute gagal bila ada route di NewRouterWithDeps yang belum
+// dideskripsikan di Operations() paket handler (atau apiOperations untuk route sistem)
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	r := newTestRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json: status %d", w.Code)
+	}
+	var doc struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode /openapi.json: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Errorf("openapi version = %q, want 3.x", doc.OpenAPI)
+	}
+
+	for _, rt := range r.Routes() {
+		path := ginParam.ReplaceAllString(rt.Path, "{$1}")
+		if _, ok := doc.Paths[path][strings.ToLower(rt.Method)]; !ok {
+			t.Errorf("route %s %s is missing from /openapi.json", rt.Method, rt.Path)
+		}
+	}
+}
+
+// TestOpenAPIHasNoStaleOperations gagal bila dokumentasi menyebut route yang tidak lagi didaftarkan
+func TestOpenAPIHasNoStaleOperations(t *testing.T) {
+	r := newTestRouter(t)
+	registered := map[string]bool{}
+	for _, rt := range r.Routes() {
+		registered[rt.Method+" "+rt.Path] = true
+	}
+	for _, op := range apiOperations() {
+		if !registered[op.Key()] {
+			t.Errorf("documented operation %s is not registered by NewRouterWithDeps", op.Key())
+		}
+	}
+}
@@ -1,56 +1,120 @@
 package http
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	"pencatatan-data-mahasiswa/internal/config"
 	"pencatatan-data-mahasiswa/internal/db"
+	"pencatatan-data-mahasiswa/internal/logging"
+	"pencatatan-data-mahasiswa/internal/metrics"
+	"pencatatan-data-mahasiswa/internal/middleware"
+	"pencatatan-data-mahasiswa/internal/ratelimit"
 	admin "pencatatan-data-mahasiswa/internal/todo/handler/admin"
 	auth "pencatatan-data-mahasiswa/internal/todo/handler/auth"
+	health "pencatatan-data-mahasiswa/internal/todo/handler/health"
 )
 
 type Router struct{}
 
+// traced mengecualikan probe dan scrape metrics dari tracing agar tidak membanjiri backend
+func traced(c *gin.Context) bool {
+	switch c.Request.URL.Path {
+	case "/healthz", "/readyz", "/metrics":
+		return false
+	}
+	return true
+}
+
 func NewRouterWithDeps(cfg *config.Config, pool *db.Pool) *gin.Engine {
-	r := gin.Default()
+	// Logger bawaan gin diganti access log JSON; request id dipasang paling awal agar ikut ke semua log
+	// otelgin paling luar: membaca traceparent (W3C) lalu membuka span per route, sehingga
+	// log dan span service/pgx berada di trace yang sama
+	r := gin.New()
+	// X-Forwarded-For hanya dipercaya dari proxy yang dikonfigurasi; tanpa ini client bisa
+	// memalsukan IP dan lolos dari rate limit /auth. Daftar sudah divalidasi saat config dimuat.
+	if err := r.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		panic(fmt.Sprintf("http.trusted_proxies: %v", err))
+	}
+	r.Use(
+		otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithGinFilter(traced)),
+		logging.Middleware(),
+		// metrics sebelum Recovery: panic yang dipulihkan Recovery tetap tercatat sebagai 500
+		metrics.Middleware(),
+		gin.Recovery(),
+		middleware.SecurityHeaders(cfg.HTTP.HSTSMaxAge),
+		middleware.CORS(cfg.CORS),
+		middleware.BodyLimit(cfg.HTTP.MaxBodyBytes, map[string]int64{
+			"/api/v1/semester/import":  cfg.HTTP.MaxUploadBytes,
+			"/api/v1/mahasiswa/import": cfg.HTTP.MaxUploadBytes,
+			"/api/v1/cuti/":            cfg.HTTP.MaxUploadBytes,
+		}),
+	)
 
 	// Simple health check
 	r.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "OK"})
 	})
 
+	// Probe orchestrator: liveness tanpa dependensi, readiness memeriksa database dan migrasi
+	healthHandler := health.NewHandler(pool)
+	r.GET("/healthz", healthHandler.Liveness)
+	r.GET("/readyz", healthHandler.Readiness)
+	if cfg.Features.Metrics {
+		r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+
 	authHandler := auth.NewHandler(cfg, pool)
 	fakultasHandler := admin.NewHandler(cfg, pool)
 	prodiHandler := admin.NewProdiHandler(cfg, pool)
 	dosenHandler := admin.NewDosenHandler(cfg, pool)
 	mahasiswaHandler := admin.NewMahasiswaHandler(cfg, pool)
 	semesterHandler := admin.NewSemesterHandler(cfg, pool)
+	cutiHandler := admin.NewCutiHandler(cfg, pool)
+	pembimbingHandler := admin.NewPembimbingHandler(cfg, pool)
+	krsHandler := admin.NewKRSHandler(cfg, pool)
+	kurikulumHandler := admin.NewKurikulumHandler(cfg, pool)
+	// Rate limit: per IP untuk /auth, per user id (setelah RequireAuth) untuk route lain
+	var limitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Store == "postgres" {
+		limitStore = ratelimit.NewPostgresStore(pool)
+	}
+	limiter := ratelimit.New(limitStore, cfg.RateLimit)
+
 	v1 := r.Group("/api/v1")
 	{
-		authGroup := v1.Group("/auth")
+		authGroup := v1.Group("/auth", limiter.Auth())
 		{
 			authGroup.POST("/login", authHandler.Login)
-			authGroup.POST("/register", authHandler.Register)
+			authGroup.POST("/activate", authHandler.Activate)
+			// satu-satunya route yang menerima token dengan kewajiban ganti password
+			authGroup.POST("/change-password", auth.RequireLogin(cfg.JWTSecret), authHandler.ChangePassword)
+			if cfg.Features.Registration {
+				authGroup.POST("/register", authHandler.Register)
+			}
 		}
 
 		// Semester routes
-		semesterReadGroup := v1.Group("/semester", auth.RequireAuth(cfg.JWTSecret, "admin", "operator", "dosen", "mahasiswa"))
+		semesterReadGroup := v1.Group("/semester", auth.RequireAuth(cfg.JWTSecret, "admin", "operator", "dosen", "mahasiswa"), limiter.API())
 		{
 			semesterReadGroup.GET("/", semesterHandler.List)
 			semesterReadGroup.GET("/:id", semesterHandler.Get)
 		}
-		semesterWriteGroup := v1.Group("/semester", auth.RequireAuth(cfg.JWTSecret, "admin", "operator"))
+		semesterWriteGroup := v1.Group("/semester", auth.RequireAuth(cfg.JWTSecret, "admin", "operator"), limiter.API())
 		{
 			semesterWriteGroup.POST("/", semesterHandler.Create)
 			semesterWriteGroup.PUT("/:id", semesterHandler.UpdatePut)
 			semesterWriteGroup.PATCH("/:id", semesterHandler.UpdatePatch)
 			semesterWriteGroup.DELETE("/:id", semesterHandler.Delete)
-			semesterWriteGroup.POST("/import", semesterHandler.ImportCSV)
+			if cfg.Features.SemesterImport {
+				semesterWriteGroup.POST("/import", semesterHandler.ImportCSV)
+			}
 		}
 
-		mahasiswaGroup := v1.Group("/mahasiswa", auth.RequireAuth(cfg.JWTSecret, "admin", "operator"))
+		mahasiswaGroup := v1.Group("/mahasiswa", auth.RequireAuth(cfg.JWTSecret, "admin", "operator"), limiter.API())
 		{
 			mahasiswaGroup.GET("/", mahasiswaHandler.List)
 			mahasiswaGroup.GET("/:id", mahasiswaHandler.Get)
@@ -58,10 +122,74 @@ func NewRouterWithDeps(cfg *config.Config, pool *db.Pool) *gin.Engine {
 			mahasiswaGroup.PUT("/:id", mahasiswaHandler.UpdatePut)
 			mahasiswaGroup.PATCH("/:id", mahasiswaHandler.UpdatePatch)
 			mahasiswaGroup.DELETE("/:id", mahasiswaHandler.Delete)
+			mahasiswaGroup.POST("/:id/status", mahasiswaHandler.Transition)
+			mahasiswaGroup.GET("/:id/status-history", mahasiswaHandler.StatusHistory)
+			mahasiswaGroup.PUT("/:id/pembimbing", pembimbingHandler.Assign)
+			mahasiswaGroup.GET("/:id/pembimbing-history", pembimbingHandler.History)
+			if cfg.Features.MahasiswaImport {
+				mahasiswaGroup.POST("/import", mahasiswaHandler.ImportCSV)
+			}
+		}
+
+		// Cuti: mahasiswa mengajukan dan membatalkan, admin/operator meninjau; daftar dibatasi per role di service
+		cutiReadGroup := v1.Group("/cuti", auth.RequireAuth(cfg.JWTSecret, "admin", "operator", "mahasiswa"), limiter.API())
+		{
+			cutiReadGroup.GET("/", cutiHandler.List)
+			cutiReadGroup.GET("/:id", cutiHandler.Get)
+			cutiReadGroup.GET("/:id/lampiran", cutiHandler.Lampiran)
+		}
+		cutiMahasiswaGroup := v1.Group("/cuti", auth.RequireAuth(cfg.JWTSecret, "mahasiswa"), limiter.API())
+		{
+			cutiMahasiswaGroup.POST("/", cutiHandler.Submit)
+			cutiMahasiswaGroup.POST("/:id/cancel", cutiHandler.Cancel)
+		}
+		cutiReviewGroup := v1.Group("/cuti", auth.RequireAuth(cfg.JWTSecret, "admin", "operator"), limiter.API())
+		{
+			cutiReviewGroup.POST("/:id/approve", cutiHandler.Approve)
+			cutiReviewGroup.POST("/:id/reject", cutiHandler.Reject)
+		}
+
+		// KRS: mahasiswa menyusun dan mengajukan KRS-nya, dosen pembimbing akademik (PA) menyetujui
+		// atau mengembalikan pengajuan mahasiswa bimbingannya
+		krsGroup := v1.Group("/krs", auth.RequireAuth(cfg.JWTSecret, "mahasiswa"), limiter.API())
+		{
+			krsGroup.GET("/:semester", krsHandler.Get)
+			krsGroup.PUT("/:semester", krsHandler.Replace)
+			krsGroup.POST("/:semester/submit", krsHandler.Submit)
+		}
+		bimbinganGroup := v1.Group("/bimbingan", auth.RequireAuth(cfg.JWTSecret, "dosen"), limiter.API())
+		{
+			bimbinganGroup.GET("/", pembimbingHandler.ListBimbingan)
+			bimbinganGroup.GET("/krs", krsHandler.ListPengajuan)
+			bimbinganGroup.GET("/krs/:id", krsHandler.GetPengajuan)
+			bimbinganGroup.POST("/krs/:id/approve", krsHandler.Approve)
+			bimbinganGroup.POST("/krs/:id/return", krsHandler.Return)
+		}
+
+		// Kurikulum dan prasyarat mata kuliah: semua role boleh membaca, admin/operator mengubah
+		kurikulumReadGroup := v1.Group("/kurikulum", auth.RequireAuth(cfg.JWTSecret, "admin", "operator", "dosen", "mahasiswa"), limiter.API())
+		{
+			kurikulumReadGroup.GET("/", kurikulumHandler.List)
+			kurikulumReadGroup.GET("/:id", kurikulumHandler.Get)
+		}
+		kurikulumWriteGroup := v1.Group("/kurikulum", auth.RequireAuth(cfg.JWTSecret, "admin", "operator"), limiter.API())
+		{
+			kurikulumWriteGroup.POST("/", kurikulumHandler.Create)
+			kurikulumWriteGroup.PUT("/:id", kurikulumHandler.Update)
+			kurikulumWriteGroup.DELETE("/:id", kurikulumHandler.Delete)
+			kurikulumWriteGroup.PUT("/:id/mata-kuliah", kurikulumHandler.ReplaceMataKuliah)
+		}
+		mataKuliahReadGroup := v1.Group("/mata-kuliah", auth.RequireAuth(cfg.JWTSecret, "admin", "operator", "dosen", "mahasiswa"), limiter.API())
+		{
+			mataKuliahReadGroup.GET("/:id/prasyarat", kurikulumHandler.Prasyarat)
+		}
+		mataKuliahWriteGroup := v1.Group("/mata-kuliah", auth.RequireAuth(cfg.JWTSecret, "admin", "operator"), limiter.API())
+		{
+			mataKuliahWriteGroup.PUT("/:id/prasyarat", kurikulumHandler.ReplacePrasyarat)
 		}
 
 		// Fakultas routes (protected by RequireAuth for admin/operator)
-		fakultasGroup := v1.Group("/fakultas", auth.RequireAuth(cfg.JWTSecret, "admin", "operator"))
+		fakultasGroup := v1.Group("/fakultas", auth.RequireAuth(cfg.JWTSecret, "admin", "operator"), limiter.API())
 		{
 			fakultasGroup.GET("/", fakultasHandler.List)
 			fakultasGroup.GET("/:id", fakultasHandler.Get)
@@ -71,7 +199,7 @@ func NewRouterWithDeps(cfg *config.Config, pool *db.Pool) *gin.Engine {
 		}
 
 		// Prodi routes (protected by RequireAuth for admin/operator)
-		prodiGroup := v1.Group("/prodi", auth.RequireAuth(cfg.JWTSecret, "admin", "operator"))
+		prodiGroup := v1.Group("/prodi", auth.RequireAuth(cfg.JWTSecret, "admin", "operator"), limiter.API())
 		{
 			prodiGroup.GET("/", prodiHandler.List)
 			prodiGroup.GET("/:id", prodiHandler.Get)
@@ -79,10 +207,12 @@ func NewRouterWithDeps(cfg *config.Config, pool *db.Pool) *gin.Engine {
 			prodiGroup.PUT("/:id", prodiHandler.UpdatePut)
 			prodiGroup.PATCH("/:id", prodiHandler.UpdatePatch)
 			prodiGroup.DELETE("/:id", prodiHandler.Delete)
+			prodiGroup.GET("/:id/cuti-policy", cutiHandler.Policy)
+			prodiGroup.PUT("/:id/cuti-policy", cutiHandler.SetPolicy)
 		}
 
 		// Dosen routes (protected by RequireAuth for admin/operator)
-		dosenGroup := v1.Group("/dosen", auth.RequireAuth(cfg.JWTSecret, "admin", "operator"))
+		dosenGroup := v1.Group("/dosen", auth.RequireAuth(cfg.JWTSecret, "admin", "operator"), limiter.API())
 		{
 			dosenGroup.GET("/", dosenHandler.List)
 			dosenGroup.GET("/:id", dosenHandler.Get)
@@ -90,9 +220,12 @@ func NewRouterWithDeps(cfg *config.Config, pool *db.Pool) *gin.Engine {
 			dosenGroup.PUT("/:id", dosenHandler.UpdatePut)
 			dosenGroup.PATCH("/:id", dosenHandler.UpdatePatch)
 			dosenGroup.DELETE("/:id", dosenHandler.Delete)
+			dosenGroup.GET("/:id/bimbingan", pembimbingHandler.ListBimbingan)
+			dosenGroup.POST("/:id/bimbingan", pembimbingHandler.AssignBulk)
 		}
 	}
 
+	registerDocs(r)
 	return r
 }
 
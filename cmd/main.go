@@ -1,26 +1,82 @@
 package main
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	_ "time/tzdata" // APP_TIMEZONE tetap bisa dimuat di image tanpa /usr/share/zoneinfo
 
-	"pencatatan-data-mahasiswa/api/http"
 	"pencatatan-data-mahasiswa/internal/config"
-	"pencatatan-data-mahasiswa/internal/db"
+	"pencatatan-data-mahasiswa/internal/logging"
 )
 
-func main() {
-	cfg := config.Load()
+const usage = `usage:
+  app [serve] [-no-migrate]     jalankan HTTP server (default)
+  app migrate up                terapkan semua migrasi yang belum berjalan
+  app migrate down N            batalkan N migrasi terakhir
+  app migrate goto V            naik/turun sampai versi V
+  app migrate version           tampilkan versi saat ini
+  app migrate force V           set versi V tanpa menjalankan SQL (membersihkan dirty)
+  app config print              tampilkan konfigurasi efektif (secret disamarkan)`
 
-	pool, err := db.Connect(cfg.DatabaseURL)
-	if err != nil {
-		log.Fatalf("failed to connect db: %v", err)
+// errUsage menandakan argumen salah; usage dicetak dan proses keluar dengan kode 2
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	cmd, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
 	}
-	defer pool.Close()
 
-	// Jalankan migration setelah koneksi sukses
-	db.RunMigrations(cfg.DatabaseURL)
+	cfg, cfgErr := config.Load()
+	if cmd == "config" {
+		os.Exit(runConfig(cfg, cfgErr, args))
+	}
+	if cfgErr != nil {
+		fmt.Fprintln(os.Stderr, cfgErr)
+		os.Exit(1)
+	}
+	logging.Setup(cfg.LogLevel)
 
-	r := http.NewRouterWithDeps(cfg, pool)
+	var err error
+	switch cmd {
+	case "serve":
+		err = runServe(cfg, args)
+	case "migrate":
+		err = runMigrate(cfg, args)
+	case "help":
+		fmt.Println(usage)
+	default:
+		err = errUsage
+	}
+	if errors.Is(err, errUsage) {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		slog.Error(cmd+" failed", "error", err)
+		os.Exit(1)
+	}
+}
 
-	r.Run(":" + cfg.AppPort)
+// runConfig menjalankan "config print": konfigurasi efektif tetap dicetak walau tidak valid,
+// lalu seluruh masalah validasi ditulis ke stderr
+func runConfig(cfg *config.Config, cfgErr error, args []string) int {
+	if len(args) != 1 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+	out, err := cfg.YAML()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Print(string(out))
+	if cfgErr != nil {
+		fmt.Fprintln(os.Stderr, cfgErr)
+		return 1
+	}
+	return 0
 }
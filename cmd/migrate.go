@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"pencatatan-data-mahasiswa/internal/config"
+	"pencatatan-data-mahasiswa/internal/db"
+)
+
+// runMigrate menjalankan subcommand migrate up|down N|goto V|version|force V
+func runMigrate(cfg *config.Config, args []string) error {
+	op, err := parseMigrate(args)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	mg, err := db.NewMigrator(cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer mg.Close()
+
+	if err := op(ctx, mg); err != nil {
+		return err
+	}
+	return printVersion(mg)
+}
+
+// parseMigrate memvalidasi argumen sebelum menyentuh database
+func parseMigrate(args []string) (func(context.Context, *db.Migrator) error, error) {
+	if len(args) == 0 {
+		return nil, errUsage
+	}
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return nil, errUsage
+		}
+		return func(ctx context.Context, mg *db.Migrator) error { return mg.Up(ctx) }, nil
+	case "version":
+		if len(args) != 1 {
+			return nil, errUsage
+		}
+		return func(context.Context, *db.Migrator) error { return nil }, nil
+	case "down":
+		n, err := intArg(args)
+		if err != nil || n < 1 {
+			return nil, errUsage
+		}
+		return func(ctx context.Context, mg *db.Migrator) error { return mg.Down(ctx, n) }, nil
+	case "goto":
+		v, err := intArg(args)
+		if err != nil || v < 0 {
+			return nil, errUsage
+		}
+		return func(ctx context.Context, mg *db.Migrator) error { return mg.Goto(ctx, uint(v)) }, nil
+	case "force":
+		// -1 berarti belum ada versi (sesuai golang-migrate)
+		v, err := intArg(args)
+		if err != nil || v < -1 {
+			return nil, errUsage
+		}
+		return func(ctx context.Context, mg *db.Migrator) error { return mg.Force(ctx, v) }, nil
+	}
+	return nil, errUsage
+}
+
+// intArg membaca tepat satu argumen angka setelah nama aksi
+func intArg(args []string) (int, error) {
+	if len(args) != 2 {
+		return 0, errUsage
+	}
+	return strconv.Atoi(args[1])
+}
+
+func printVersion(mg *db.Migrator) error {
+	v, dirty, ok, err := mg.Version()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Println("no migration applied")
+		return nil
+	}
+	fmt.Printf("version %d (dirty=%t)\n", v, dirty)
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	nethttp "net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"pencatatan-data-mahasiswa/api/http"
+	"pencatatan-data-mahasiswa/internal/config"
+	"pencatatan-data-mahasiswa/internal/db"
+	"pencatatan-data-mahasiswa/internal/metrics"
+	admin "pencatatan-data-mahasiswa/internal/todo/handler/admin"
+	adminsvc "pencatatan-data-mahasiswa/internal/todo/service/admin"
+	"pencatatan-data-mahasiswa/internal/tracing"
+)
+
+// runServe menjalankan HTTP server sampai SIGINT/SIGTERM.
+// Flag -no-migrate (atau AUTO_MIGRATE=false) melewati migrasi otomatis saat start,
+// untuk deployment yang menjalankan "migrate up" sebagai langkah terpisah.
+func runServe(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	noMigrate := fs.Bool("no-migrate", false, "skip applying pending migrations on start")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	// SIGINT/SIGTERM (mis. saat deploy) memicu graceful shutdown, juga saat menunggu lock migrasi
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.ServiceName)
+	if err != nil {
+		return fmt.Errorf("set up tracing: %w", err)
+	}
+
+	pool, err := db.Connect(cfg.DatabaseURL, cfg.Database)
+	if err != nil {
+		return fmt.Errorf("connect db: %w", err)
+	}
+	defer pool.Close()
+
+	// Jalankan migration setelah koneksi sukses
+	if cfg.AutoMigrate && !*noMigrate {
+		if err := db.Migrate(ctx, cfg.DatabaseURL); err != nil {
+			return fmt.Errorf("migrate: %w", err)
+		}
+	} else {
+		slog.Info("migration: auto-migrate disabled, skipping")
+	}
+
+	if err := metrics.RegisterPool(pool); err != nil {
+		return fmt.Errorf("register pool metrics: %w", err)
+	}
+
+	r := http.NewRouterWithDeps(cfg, pool)
+	if cfg.Cuti.SyncInterval > 0 {
+		go syncCuti(ctx, admin.NewCutiService(cfg, pool), cfg.Cuti.SyncInterval)
+	}
+
+	srv := &nethttp.Server{
+		Addr:              ":" + cfg.AppPort,
+		Handler:           r,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+		MaxHeaderBytes:    cfg.HTTP.MaxHeaderBytes,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "addr", srv.Addr, "tls", cfg.HTTP.TLSEnabled())
+		if cfg.HTTP.TLSEnabled() {
+			serveErr <- srv.ListenAndServeTLS(cfg.HTTP.TLSCertFile, cfg.HTTP.TLSKeyFile)
+		} else {
+			serveErr <- srv.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		if err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+			return fmt.Errorf("server: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+	stop()
+
+	// Berhenti menerima koneksi baru dan tunggu request yang sedang berjalan (termasuk import CSV)
+	slog.Info("shutting down, draining in-flight requests", "timeout", cfg.HTTP.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("graceful shutdown did not complete", "error", err)
+		_ = srv.Close()
+	}
+	// kirim span yang masih tertahan di batcher sebelum proses berhenti
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("tracing shutdown failed", "error", err)
+	}
+	// pool ditutup oleh defer setelah semua handler selesai
+	return nil
+}
+
+// syncCuti menerapkan cuti yang disetujui saat semesternya dimulai dan mengembalikan status
+// ke Aktif setelah semester cuti lewat; sekali saat start lalu tiap interval sampai ctx selesai
+func syncCuti(ctx context.Context, svc *adminsvc.CutiService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		res, err := svc.Sync(ctx, time.Now())
+		if err != nil {
+			slog.Error("cuti sync failed", "error", err, "applied", res.Applied, "finished", res.Finished)
+		} else if res.Applied > 0 || res.Finished > 0 {
+			slog.Info("cuti sync", "applied", res.Applied, "finished", res.Finished)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Package apperror berisi error domain bertipe yang dipakai service dan handler,
+// beserta pemetaan terpusat ke status HTTP.
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"pencatatan-data-mahasiswa/internal/i18n"
+)
+
+// Sentinel jenis error domain; cek dengan errors.Is
+var (
+	ErrInvalidInput    = errors.New("invalid input")     // 400
+	ErrUnauthorized    = errors.New("unauthorized")      // 401
+	ErrForbidden       = errors.New("forbidden")         // 403
+	ErrNotFound        = errors.New("not found")         // 404
+	ErrConflict        = errors.New("conflict")          // 409
+	ErrUnprocessable   = errors.New("unprocessable")     // 422
+	ErrTooLarge        = errors.New("too large")         // 413
+	ErrTooManyRequests = errors.New("too many requests") // 429
+)
+
+// Error adalah error domain dengan jenis (salah satu sentinel di atas), kode pesan opsional
+// dari katalog i18n, detail per field (nama field -> kode yang bisa dibaca mesin) dan data
+// tambahan opsional untuk response
+type Error struct {
+	Kind    error
+	Code    string // kode pesan i18n, mis. i18n.MsgUsernameTaken
+	Fields  map[string]string
+	Details any   // dikirim apa adanya di field details, mis. daftar prasyarat yang belum terpenuhi
+	Err     error // penyebab asli, mis. *pgconn.PgError
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return i18n.T(i18n.EN, e.Code)
+	}
+	if len(e.Fields) > 0 {
+		keys := make([]string, 0, len(e.Fields))
+		for k := range e.Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = k + ": " + e.Fields[k]
+		}
+		return fmt.Sprintf("%s (%s)", e.Kind, strings.Join(parts, ", "))
+	}
+	return e.Kind.Error()
+}
+
+// Unwrap membuat errors.Is(err, ErrConflict) dan errors.As(err, &pgErr) bekerja
+func (e *Error) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// New membuat error domain dengan kode pesan dari katalog i18n
+func New(kind error, code string) *Error {
+	return &Error{Kind: kind, Code: code}
+}
+
+// Field membuat error domain untuk satu field, mis. Field(ErrConflict, "email", "taken")
+func Field(kind error, field, code string) *Error {
+	return &Error{Kind: kind, Fields: map[string]string{field: code}}
+}
+
+// Invalid membuat error validasi (400) dengan detail per field
+func Invalid(fields map[string]string) *Error {
+	return &Error{Kind: ErrInvalidInput, Fields: fields}
+}
+
+// FieldsOf mengembalikan detail field dari err bila ada
+func FieldsOf(err error) map[string]string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Fields
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package apperror
+
+// Kode umum untuk detail field; kode spesifik (mis. pattern_16_digits) ditulis langsung di validator
+const (
+	CodeRequired = "required"
+	CodeInvalid  = "invalid"
+	CodeTaken    = "taken"
+	CodeNotFound = "not_found"
+)
+
+// Fields mengumpulkan kegagalan validasi per field (nama field -> kode) sehingga
+// validator bisa melaporkan semua input yang salah sekaligus.
+type Fields map[string]string
+
+// Add mencatat kegagalan field; hanya kegagalan pertama per field yang disimpan
+func (f Fields) Add(field, code string) {
+	if _, ok := f[field]; !ok {
+		f[field] = code
+	}
+}
+
+// Has melaporkan apakah field sudah tercatat gagal
+func (f Fields) Has(field string) bool {
+	_, ok := f[field]
+	return ok
+}
+
+// Err mengembalikan error validasi (400) bila ada kegagalan, selain itu nil
+func (f Fields) Err() error {
+	return f.As(ErrInvalidInput)
+}
+
+// As mengembalikan error domain dengan jenis kind bila ada kegagalan, selain itu nil
+func (f Fields) As(kind error) error {
+	if len(f) == 0 {
+		return nil
+	}
+	return &Error{Kind: kind, Fields: f}
+}
@@ -0,0 +1,94 @@
+package apperror
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"pencatatan-data-mahasiswa/internal/i18n"
+)
+
+// Body adalah bentuk standar response error API. Error dan Code stabil untuk dipakai
+// program; Message diterjemahkan sesuai Accept-Language.
+type Body struct {
+	Error   string            `json:"error"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	Details any               `json:"details,omitempty"`
+}
+
+// Resolve memetakan err ke status HTTP dan body response dalam bahasa lang.
+// Error yang tidak dikenal menjadi 500 tanpa membocorkan detail internal.
+func Resolve(err error, lang string) (int, Body) {
+	err = FromDB(err)
+	if TooLarge(err) {
+		err = New(ErrTooLarge, i18n.MsgPayloadTooLarge)
+	}
+
+	var body Body
+	var e *Error
+	if errors.As(err, &e) {
+		body.Code = e.Code
+		body.Fields = e.Fields
+		body.Details = e.Details
+	}
+
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrInvalidInput):
+		status, body.Error = http.StatusBadRequest, i18n.MsgValidationError
+	case errors.Is(err, ErrUnauthorized):
+		status, body.Error = http.StatusUnauthorized, i18n.MsgUnauthorized
+	case errors.Is(err, ErrForbidden):
+		status, body.Error = http.StatusForbidden, i18n.MsgForbidden
+	case errors.Is(err, ErrNotFound):
+		status, body.Error = http.StatusNotFound, i18n.MsgNotFound
+	case errors.Is(err, ErrConflict):
+		status, body.Error = http.StatusConflict, i18n.MsgConflict
+	case errors.Is(err, ErrUnprocessable):
+		status, body.Error = http.StatusUnprocessableEntity, i18n.MsgUnprocessable
+	case errors.Is(err, ErrTooLarge):
+		status, body.Error = http.StatusRequestEntityTooLarge, i18n.MsgPayloadTooLarge
+	case errors.Is(err, ErrTooManyRequests):
+		status, body.Error = http.StatusTooManyRequests, i18n.MsgTooManyRequests
+	default:
+		body = Body{Error: i18n.MsgInternalError}
+	}
+	if body.Code == "" {
+		body.Code = body.Error
+	}
+	body.Message = i18n.T(lang, body.Code)
+	return status, body
+}
+
+// TooLarge melaporkan apakah err berasal dari body yang melewati http.MaxBytesReader
+func TooLarge(err error) bool {
+	var mbe *http.MaxBytesError
+	return errors.Is(err, ErrTooLarge) || errors.As(err, &mbe)
+}
+
+// Respond menulis response error standar untuk err
+func Respond(c *gin.Context, err error) {
+	status, body := resolve(c, err)
+	c.JSON(status, body)
+}
+
+// Abort sama dengan Respond namun menghentikan rantai handler (untuk middleware)
+func Abort(c *gin.Context, err error) {
+	status, body := resolve(c, err)
+	c.AbortWithStatusJSON(status, body)
+}
+
+func resolve(c *gin.Context, err error) (int, Body) {
+	lang := i18n.Lang(c)
+	status, body := Resolve(err, lang)
+	if status >= http.StatusInternalServerError {
+		slog.ErrorContext(c.Request.Context(), "request failed",
+			"method", c.Request.Method, "route", c.FullPath(), "error", err)
+	}
+	c.Header("Content-Language", lang)
+	return status, body
+}
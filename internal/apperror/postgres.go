@@ -0,0 +1,80 @@
+package apperror
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"pencatatan-data-mahasiswa/internal/i18n"
+)
+
+// Kode SQLSTATE PostgreSQL yang diterjemahkan menjadi error domain
+const (
+	pgNotNullViolation    = "23502"
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+	pgCheckViolation      = "23514"
+)
+
+// detailKeyPattern mengambil nama kolom dari Detail, mis. `Key (email)=(a@b.c) already exists.`
+var detailKeyPattern = regexp.MustCompile(`Key \(([^)]+)\)=`)
+
+// FromDB menerjemahkan error database menjadi error domain:
+// pgx.ErrNoRows -> ErrNotFound, 23505 -> ErrConflict, 23503 -> ErrUnprocessable/ErrConflict,
+// 23514/23502 -> ErrUnprocessable. Error lain dikembalikan apa adanya.
+func FromDB(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &Error{Kind: ErrNotFound, Err: err}
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		return &Error{Kind: ErrConflict, Fields: map[string]string{pgField(pgErr): CodeTaken}, Err: err}
+	case pgForeignKeyViolation:
+		if strings.Contains(pgErr.Detail, "still referenced") {
+			// DELETE/UPDATE baris yang masih dirujuk tabel lain
+			return &Error{Kind: ErrConflict, Code: i18n.MsgStillReferenced, Fields: map[string]string{pgField(pgErr): "still_referenced"}, Err: err}
+		}
+		return &Error{Kind: ErrUnprocessable, Fields: map[string]string{pgField(pgErr): CodeNotFound}, Err: err}
+	case pgCheckViolation:
+		return &Error{Kind: ErrUnprocessable, Fields: map[string]string{pgField(pgErr): "check_violation"}, Err: err}
+	case pgNotNullViolation:
+		return &Error{Kind: ErrUnprocessable, Fields: map[string]string{pgField(pgErr): CodeRequired}, Err: err}
+	}
+	return err
+}
+
+// pgField menebak nama field yang dilanggar dari kolom, detail, atau nama constraint
+func pgField(pgErr *pgconn.PgError) string {
+	if pgErr.ColumnName != "" {
+		return pgErr.ColumnName
+	}
+	if m := detailKeyPattern.FindStringSubmatch(pgErr.Detail); m != nil {
+		return strings.ReplaceAll(m[1], " ", "")
+	}
+	// nama default CHECK/UNIQUE PostgreSQL: <table>_<column>_check / <table>_<column>_key
+	name := pgErr.ConstraintName
+	if pgErr.TableName != "" {
+		name = strings.TrimPrefix(name, pgErr.TableName+"_")
+	}
+	for _, suffix := range []string{"_check", "_key", "_fkey"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	if name == "" {
+		return "unknown"
+	}
+	return name
+}
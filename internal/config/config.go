@@ -1,67 +1,177 @@
 package config
 
 import (
-	"log"
-	"net"
-	"net/url"
-	"os"
-
-	"github.com/joho/godotenv"
+	"time"
 )
 
+// Config adalah konfigurasi efektif aplikasi. Urutan lapisan (yang belakang menimpa):
+// nilai default -> file YAML (CONFIG_FILE, default ./config.yaml bila ada) -> environment variable.
 type Config struct {
-	AppPort     string
-	DatabaseURL string
-	JWTSecret   string
+	AppPort     string          `yaml:"app_port"`
+	DatabaseURL string          `yaml:"database_url"`
+	JWTSecret   string          `yaml:"jwt_secret"`
+	LogLevel    string          `yaml:"log_level"`    // debug | info | warn | error
+	AutoMigrate bool            `yaml:"auto_migrate"` // serve menerapkan migrasi saat start; AUTO_MIGRATE=false untuk mematikan
+	Timezone    string          `yaml:"timezone"`     // zona waktu kampus untuk "hari ini" (tanggal efektif, semester, cuti)
+	Database    DatabaseConfig  `yaml:"database"`
+	Auth        AuthConfig      `yaml:"auth"`
+	Pagination  PageConfig      `yaml:"pagination"`
+	HTTP        HTTPConfig      `yaml:"http"`
+	CORS        CORSConfig      `yaml:"cors"`
+	RateLimit   RateLimitConfig `yaml:"rate_limit"`
+	Tracing     TracingConfig   `yaml:"tracing"`
+	Features    FeatureConfig   `yaml:"features"`
+	Cuti        CutiConfig      `yaml:"cuti"`
 }
 
-// buildDatabaseURLFromEnv merakit connection string Postgres dari variabel env terpisah
-func buildDatabaseURLFromEnv() string {
-	host := os.Getenv("DATABASE_HOST")
-	port := os.Getenv("DATABASE_PORT")
-	user := os.Getenv("DATABASE_USER")
-	pass := os.Getenv("DATABASE_PASSWORD")
-	name := os.Getenv("DATABASE_NAME")
+// DatabaseConfig mengatur ukuran dan umur koneksi pgxpool
+type DatabaseConfig struct {
+	MaxConns          int32         `yaml:"max_conns"`
+	MinConns          int32         `yaml:"min_conns"`
+	MaxConnLifetime   time.Duration `yaml:"max_conn_lifetime"`
+	MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time"`
+	HealthCheckPeriod time.Duration `yaml:"health_check_period"`
+	ConnectTimeout    time.Duration `yaml:"connect_timeout"` // batas waktu ping awal saat start
+}
 
-	sslmode := os.Getenv("DATABASE_SSLMODE")
-	if sslmode == "" {
-		sslmode = "disable"
-	}
+// AuthConfig mengatur masa berlaku token dan biaya hashing password
+type AuthConfig struct {
+	TokenTTL      time.Duration `yaml:"token_ttl"`
+	BcryptCost    int           `yaml:"bcrypt_cost"`
+	ActivationTTL time.Duration `yaml:"activation_ttl"` // masa berlaku token aktivasi akun baru
+	// ActivationURL adalah URL halaman aktivasi di frontend; "{token}" diganti token aktivasi.
+	// Kosong berarti response hanya berisi token mentah.
+	ActivationURL string `yaml:"activation_url"`
+}
 
-	// Jika variabel utama ada yang kosong, kembalikan string kosong untuk memicu error handling di Load()
-	if host == "" || port == "" || user == "" || name == "" {
-		return ""
-	}
+// PageConfig mengatur ukuran halaman list (page/per_page)
+type PageConfig struct {
+	DefaultPageSize int `yaml:"default_page_size"`
+	MaxPageSize     int `yaml:"max_page_size"`
+}
 
-	u := &url.URL{
-		Scheme: "postgres",
-		User:   url.UserPassword(user, pass),
-		Host:   net.JoinHostPort(host, port),
-		Path:   "/" + name,
-	}
-	q := url.Values{}
-	q.Set("sslmode", sslmode)
-	u.RawQuery = q.Encode()
-	return u.String()
+// HTTPConfig mengatur http.Server: timeout, ukuran header, TLS dan batas waktu graceful shutdown
+type HTTPConfig struct {
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"` // cukup panjang untuk import CSV
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"` // batas waktu menunggu request yang sedang berjalan saat SIGTERM
+	MaxHeaderBytes    int           `yaml:"max_header_bytes"`
+	MaxBodyBytes      int64         `yaml:"max_body_bytes"`   // batas body JSON
+	MaxUploadBytes    int64         `yaml:"max_upload_bytes"` // batas upload multipart (import CSV)
+	HSTSMaxAge        time.Duration `yaml:"hsts_max_age"`     // 0 mematikan Strict-Transport-Security
+	TLSCertFile       string        `yaml:"tls_cert_file"`    // TLS aktif bila cert dan key diisi
+	TLSKeyFile        string        `yaml:"tls_key_file"`
+	// TrustedProxies: IP/CIDR reverse proxy yang X-Forwarded-For-nya dipercaya untuk IP client
+	// (rate limit /auth, access log). Kosong berarti IP koneksi langsung yang dipakai.
+	TrustedProxies []string `yaml:"trusted_proxies"`
 }
 
-func Load() *Config {
-	_ = godotenv.Load()
+// TLSEnabled melaporkan apakah server dijalankan dengan HTTPS
+func (h HTTPConfig) TLSEnabled() bool {
+	return h.TLSCertFile != "" && h.TLSKeyFile != ""
+}
 
-	port := os.Getenv("APP_PORT")
-	if port == "" {
-		port = "8080"
-	}
-	urlStr := os.Getenv("DATABASE_URL")
-	if urlStr == "" {
-		urlStr = buildDatabaseURLFromEnv()
-	}
-	if urlStr == "" {
-		log.Fatal("DATABASE_URL or DATABASE_* environment variables are required")
+// CORSConfig mengatur origin browser yang boleh memanggil API; AllowedOrigins kosong berarti CORS nonaktif
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins"`
+	AllowedMethods   []string      `yaml:"allowed_methods"`
+	AllowedHeaders   []string      `yaml:"allowed_headers"`
+	ExposedHeaders   []string      `yaml:"exposed_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"`
+}
+
+// RateLimitConfig mengatur token bucket per kelompok route
+type RateLimitConfig struct {
+	Enabled bool          `yaml:"enabled"`
+	Store   string        `yaml:"store"` // memory (per instance) | postgres (dibagi antar instance)
+	Auth    RateLimitRule `yaml:"auth"`  // login/register, dikunci per IP
+	Read    RateLimitRule `yaml:"read"`  // GET
+	Write   RateLimitRule `yaml:"write"` // POST/PUT/PATCH/DELETE
+}
+
+// RateLimitRule: Rate token per detik yang terisi ulang, Burst kapasitas bucket
+type RateLimitRule struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// TracingConfig memilih exporter OpenTelemetry. Endpoint/header OTLP dan sampler dibaca
+// langsung oleh SDK dari env standar (OTEL_EXPORTER_OTLP_ENDPOINT, OTEL_TRACES_SAMPLER, ...).
+type TracingConfig struct {
+	Exporter    string `yaml:"exporter"` // none (default) | stdout | otlp
+	ServiceName string `yaml:"service_name"`
+}
+
+// FeatureConfig menyalakan/mematikan fitur opsional
+type FeatureConfig struct {
+	Registration    bool `yaml:"registration"`     // POST /api/v1/auth/register
+	SemesterImport  bool `yaml:"semester_import"`  // POST /api/v1/semester/import
+	MahasiswaImport bool `yaml:"mahasiswa_import"` // POST /api/v1/mahasiswa/import
+	Metrics         bool `yaml:"metrics"`          // GET /metrics
+}
+
+// CutiConfig mengatur pengajuan cuti akademik. Batas semester cuti bisa ditimpa per prodi
+// lewat PUT /api/v1/prodi/{id}/cuti-policy; DefaultMaxSemesters berlaku untuk prodi tanpa aturan.
+type CutiConfig struct {
+	DefaultMaxSemesters int           `yaml:"default_max_semesters"`
+	MaxAttachmentBytes  int64         `yaml:"max_attachment_bytes"` // batas ukuran lampiran (pdf/jpeg/png)
+	SyncInterval        time.Duration `yaml:"sync_interval"`        // interval penerapan/pengembalian status Cuti; 0 mematikan
+}
+
+// Location mengembalikan zona waktu Timezone; UTC bila tidak dikenal (validate sudah melaporkannya)
+func (c *Config) Location() *time.Location {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
 	}
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		log.Fatal("JWT_SECRET environment variable is required")
+	return loc
+}
+
+// Defaults mengembalikan konfigurasi bawaan sebelum file dan env diterapkan
+func Defaults() *Config {
+	return &Config{
+		AppPort:     "8080",
+		LogLevel:    "info",
+		AutoMigrate: true,
+		Timezone:    "Asia/Jakarta",
+		Database: DatabaseConfig{
+			MaxConns:          10,
+			MinConns:          0,
+			MaxConnLifetime:   time.Hour,
+			MaxConnIdleTime:   30 * time.Minute,
+			HealthCheckPeriod: time.Minute,
+			ConnectTimeout:    5 * time.Second,
+		},
+		Auth:       AuthConfig{TokenTTL: 24 * time.Hour, BcryptCost: 10, ActivationTTL: 72 * time.Hour},
+		Pagination: PageConfig{DefaultPageSize: 20, MaxPageSize: 100},
+		HTTP: HTTPConfig{
+			ReadTimeout:       30 * time.Second,
+			ReadHeaderTimeout: 10 * time.Second,
+			WriteTimeout:      120 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			MaxBodyBytes:      1 << 20,
+			MaxUploadBytes:    10 << 20,
+			HSTSMaxAge:        180 * 24 * time.Hour,
+		},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Authorization", "Content-Type", "Accept-Language", "X-Request-ID"},
+			ExposedHeaders: []string{"X-Request-ID", "Link", "Content-Language"},
+			MaxAge:         12 * time.Hour,
+		},
+		RateLimit: RateLimitConfig{
+			Store: "memory",
+			Auth:  RateLimitRule{Rate: 0.2, Burst: 5},
+			Read:  RateLimitRule{Rate: 20, Burst: 60},
+			Write: RateLimitRule{Rate: 5, Burst: 20},
+		},
+		Tracing:  TracingConfig{Exporter: "none", ServiceName: "pencatatan-data-mahasiswa"},
+		Features: FeatureConfig{Registration: true, SemesterImport: true, MahasiswaImport: true, Metrics: true},
+		Cuti:     CutiConfig{DefaultMaxSemesters: 2, MaxAttachmentBytes: 2 << 20, SyncInterval: time.Hour},
 	}
-	return &Config{AppPort: port, DatabaseURL: urlStr, JWTSecret: jwtSecret}
 }
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMasked(t *testing.T) {
+	tests := []struct {
+		name, dsn, want string
+	}{
+		{"url with password", "postgres://app:s3cret@db:5432/akademik?sslmode=disable", "postgres://app:xxxxx@db:5432/akademik?sslmode=disable"},
+		{"url without password", "postgresql://app@db/akademik", "postgresql://app@db/akademik"},
+		{"password parameter", "postgres://db/akademik?password=s3cret&user=app", "postgres://db/akademik?password=xxxxx&user=app"},
+		{"keyword dsn", "host=db user=app password=s3cret dbname=akademik", mask},
+		{"quoted keyword dsn", "host=db password='s3 cret'", mask},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{DatabaseURL: tt.dsn, JWTSecret: "jwt-s3cret"}
+			m := c.Masked()
+			if m.DatabaseURL != tt.want {
+				t.Errorf("DatabaseURL = %q, want %q", m.DatabaseURL, tt.want)
+			}
+			if m.JWTSecret != mask {
+				t.Errorf("JWTSecret = %q, want masked", m.JWTSecret)
+			}
+			if c.DatabaseURL != tt.dsn || c.JWTSecret != "jwt-s3cret" {
+				t.Errorf("original config modified: %+v", c)
+			}
+		})
+	}
+
+	out, err := (&Config{DatabaseURL: "host=db password=s3cret", JWTSecret: "jwt-s3cret"}).YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "s3cret") {
+		t.Errorf("YAML leaks a secret:\n%s", out)
+	}
+}
+
+// TestLoadCollectsProblems: semua setting yang tidak valid dari file dan env dilaporkan sekaligus
+func TestLoadCollectsProblems(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir) // tanpa .env dan config.yaml dari working directory
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("pagination:\n  default_page_size: 0\nhttp:\n  trusted_proxies: [proxy.local]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("DATABASE_URL", "postgres://app@db/akademik")
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("APP_PORT", "99999")
+	t.Setenv("LOG_LEVEL", "loud")
+	t.Setenv("HTTP_READ_TIMEOUT", "soon")
+
+	cfg, err := Load()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Load error = %v, want *ValidationError", err)
+	}
+	if cfg == nil || cfg.DatabaseURL != "postgres://app@db/akademik" {
+		t.Errorf("cfg = %+v, want the loaded config alongside the error", cfg)
+	}
+	for _, prefix := range []string{
+		"HTTP_READ_TIMEOUT:",
+		"app_port (APP_PORT):",
+		"jwt_secret (JWT_SECRET):",
+		"log_level (LOG_LEVEL):",
+		"pagination.default_page_size (PAGE_SIZE_DEFAULT):",
+		"http.trusted_proxies (HTTP_TRUSTED_PROXIES):",
+	} {
+		found := false
+		for _, p := range verr.Problems {
+			found = found || strings.HasPrefix(p, prefix)
+		}
+		if !found {
+			t.Errorf("missing problem %q in %q", prefix, verr.Problems)
+		}
+	}
+}
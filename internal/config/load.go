@@ -0,0 +1,286 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultFile dibaca bila CONFIG_FILE tidak diisi dan file ini ada di working directory
+const defaultFile = "config.yaml"
+
+// ValidationError memuat semua setting yang tidak valid sekaligus, bukan hanya yang pertama
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Load membaca default, file YAML lalu env. Bila ada setting yang tidak valid, cfg tetap
+// dikembalikan (untuk config print) bersama *ValidationError yang berisi seluruh masalah.
+func Load() (*Config, error) {
+	_ = godotenv.Load()
+
+	cfg := Defaults()
+	l := &loader{}
+
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		if _, err := os.Stat(defaultFile); err == nil {
+			path = defaultFile
+		}
+	}
+	if path != "" {
+		l.file(path, cfg)
+	}
+	l.env(cfg)
+	if cfg.DatabaseURL == "" {
+		cfg.DatabaseURL = buildDatabaseURLFromEnv()
+	}
+	cfg.validate(l)
+
+	if len(l.problems) > 0 {
+		return cfg, &ValidationError{Problems: l.problems}
+	}
+	return cfg, nil
+}
+
+// loader mengumpulkan masalah konfigurasi agar dilaporkan sekaligus
+type loader struct {
+	problems []string
+}
+
+func (l *loader) addf(format string, args ...any) {
+	l.problems = append(l.problems, fmt.Sprintf(format, args...))
+}
+
+func (l *loader) file(path string, cfg *Config) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	default:
+		l.addf("CONFIG_FILE: %s must be a .yaml or .yml file", path)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			l.addf("CONFIG_FILE: %s does not exist", path)
+		} else {
+			l.addf("CONFIG_FILE: %v", err)
+		}
+		return
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true) // salah ketik nama key dilaporkan, bukan diabaikan
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		l.addf("%s: %v", path, err)
+	}
+}
+
+// env menimpa nilai dari file dengan environment variable yang diisi
+func (l *loader) env(c *Config) {
+	l.str("APP_PORT", &c.AppPort)
+	l.str("DATABASE_URL", &c.DatabaseURL)
+	l.str("JWT_SECRET", &c.JWTSecret)
+	l.str("LOG_LEVEL", &c.LogLevel)
+	l.boolean("AUTO_MIGRATE", &c.AutoMigrate)
+	l.str("APP_TIMEZONE", &c.Timezone)
+
+	l.int32("DB_MAX_CONNS", &c.Database.MaxConns)
+	l.int32("DB_MIN_CONNS", &c.Database.MinConns)
+	l.duration("DB_MAX_CONN_LIFETIME", &c.Database.MaxConnLifetime)
+	l.duration("DB_MAX_CONN_IDLE_TIME", &c.Database.MaxConnIdleTime)
+	l.duration("DB_HEALTH_CHECK_PERIOD", &c.Database.HealthCheckPeriod)
+	l.duration("DB_CONNECT_TIMEOUT", &c.Database.ConnectTimeout)
+
+	l.duration("AUTH_TOKEN_TTL", &c.Auth.TokenTTL)
+	l.integer("AUTH_BCRYPT_COST", &c.Auth.BcryptCost)
+	l.duration("AUTH_ACTIVATION_TTL", &c.Auth.ActivationTTL)
+	l.str("AUTH_ACTIVATION_URL", &c.Auth.ActivationURL)
+
+	l.integer("PAGE_SIZE_DEFAULT", &c.Pagination.DefaultPageSize)
+	l.integer("PAGE_SIZE_MAX", &c.Pagination.MaxPageSize)
+
+	l.duration("HTTP_READ_TIMEOUT", &c.HTTP.ReadTimeout)
+	l.duration("HTTP_READ_HEADER_TIMEOUT", &c.HTTP.ReadHeaderTimeout)
+	l.duration("HTTP_WRITE_TIMEOUT", &c.HTTP.WriteTimeout)
+	l.duration("HTTP_IDLE_TIMEOUT", &c.HTTP.IdleTimeout)
+	l.duration("HTTP_SHUTDOWN_TIMEOUT", &c.HTTP.ShutdownTimeout)
+	l.integer("HTTP_MAX_HEADER_BYTES", &c.HTTP.MaxHeaderBytes)
+	l.int64("HTTP_MAX_BODY_BYTES", &c.HTTP.MaxBodyBytes)
+	l.int64("HTTP_MAX_UPLOAD_BYTES", &c.HTTP.MaxUploadBytes)
+	l.duration("HTTP_HSTS_MAX_AGE", &c.HTTP.HSTSMaxAge)
+	l.str("TLS_CERT_FILE", &c.HTTP.TLSCertFile)
+	l.str("TLS_KEY_FILE", &c.HTTP.TLSKeyFile)
+	l.list("HTTP_TRUSTED_PROXIES", &c.HTTP.TrustedProxies)
+
+	l.list("CORS_ALLOWED_ORIGINS", &c.CORS.AllowedOrigins)
+	l.list("CORS_ALLOWED_METHODS", &c.CORS.AllowedMethods)
+	l.list("CORS_ALLOWED_HEADERS", &c.CORS.AllowedHeaders)
+	l.list("CORS_EXPOSED_HEADERS", &c.CORS.ExposedHeaders)
+	l.boolean("CORS_ALLOW_CREDENTIALS", &c.CORS.AllowCredentials)
+	l.duration("CORS_MAX_AGE", &c.CORS.MaxAge)
+
+	l.boolean("RATE_LIMIT_ENABLED", &c.RateLimit.Enabled)
+	l.str("RATE_LIMIT_STORE", &c.RateLimit.Store)
+	l.float("RATE_LIMIT_AUTH_RATE", &c.RateLimit.Auth.Rate)
+	l.integer("RATE_LIMIT_AUTH_BURST", &c.RateLimit.Auth.Burst)
+	l.float("RATE_LIMIT_READ_RATE", &c.RateLimit.Read.Rate)
+	l.integer("RATE_LIMIT_READ_BURST", &c.RateLimit.Read.Burst)
+	l.float("RATE_LIMIT_WRITE_RATE", &c.RateLimit.Write.Rate)
+	l.integer("RATE_LIMIT_WRITE_BURST", &c.RateLimit.Write.Burst)
+
+	l.str("OTEL_TRACES_EXPORTER", &c.Tracing.Exporter)
+	l.str("OTEL_SERVICE_NAME", &c.Tracing.ServiceName)
+
+	l.boolean("FEATURE_REGISTRATION", &c.Features.Registration)
+	l.boolean("FEATURE_SEMESTER_IMPORT", &c.Features.SemesterImport)
+	l.boolean("FEATURE_MAHASISWA_IMPORT", &c.Features.MahasiswaImport)
+	l.boolean("FEATURE_METRICS", &c.Features.Metrics)
+
+	l.integer("CUTI_DEFAULT_MAX_SEMESTERS", &c.Cuti.DefaultMaxSemesters)
+	l.int64("CUTI_MAX_ATTACHMENT_BYTES", &c.Cuti.MaxAttachmentBytes)
+	l.duration("CUTI_SYNC_INTERVAL", &c.Cuti.SyncInterval)
+}
+
+func (l *loader) str(name string, dst *string) {
+	if v := os.Getenv(name); v != "" {
+		*dst = v
+	}
+}
+
+func (l *loader) list(name string, dst *[]string) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+	out := []string{}
+	for _, p := range strings.Split(v, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	*dst = out
+}
+
+func (l *loader) duration(name string, dst *time.Duration) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		l.addf("%s: must be a duration (e.g. 15s, 24h), got %q", name, v)
+		return
+	}
+	*dst = d
+}
+
+func (l *loader) integer(name string, dst *int) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		l.addf("%s: must be an integer, got %q", name, v)
+		return
+	}
+	*dst = n
+}
+
+func (l *loader) int64(name string, dst *int64) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		l.addf("%s: must be an integer, got %q", name, v)
+		return
+	}
+	*dst = n
+}
+
+func (l *loader) int32(name string, dst *int32) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil {
+		l.addf("%s: must be an integer, got %q", name, v)
+		return
+	}
+	*dst = int32(n)
+}
+
+func (l *loader) float(name string, dst *float64) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		l.addf("%s: must be a number, got %q", name, v)
+		return
+	}
+	*dst = f
+}
+
+func (l *loader) boolean(name string, dst *bool) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		l.addf("%s: must be true or false, got %q", name, v)
+		return
+	}
+	*dst = b
+}
+
+// buildDatabaseURLFromEnv merakit connection string Postgres dari variabel env terpisah
+func buildDatabaseURLFromEnv() string {
+	host := os.Getenv("DATABASE_HOST")
+	port := os.Getenv("DATABASE_PORT")
+	user := os.Getenv("DATABASE_USER")
+	pass := os.Getenv("DATABASE_PASSWORD")
+	name := os.Getenv("DATABASE_NAME")
+
+	sslmode := os.Getenv("DATABASE_SSLMODE")
+	if sslmode == "" {
+		sslmode = "disable"
+	}
+
+	// Jika variabel utama ada yang kosong, kembalikan string kosong untuk memicu error handling di Load()
+	if host == "" || port == "" || user == "" || name == "" {
+		return ""
+	}
+
+	u := &url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(user, pass),
+		Host:   net.JoinHostPort(host, port),
+		Path:   "/" + name,
+	}
+	q := url.Values{}
+	q.Set("sslmode", sslmode)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
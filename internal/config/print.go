@@ -0,0 +1,41 @@
+package config
+
+import (
+	"net/url"
+
+	"gopkg.in/yaml.v3"
+)
+
+const mask = "********"
+
+// Masked mengembalikan salinan konfigurasi dengan secret disamarkan (JWT secret, password database)
+func (c *Config) Masked() *Config {
+	m := *c
+	if m.JWTSecret != "" {
+		m.JWTSecret = mask
+	}
+	m.DatabaseURL = maskDSN(m.DatabaseURL)
+	return &m
+}
+
+// maskDSN menyamarkan password pada DSN bentuk URL (userinfo dan parameter password). DSN
+// bentuk keyword (host=... password=...) atau yang tidak bisa di-parse disamarkan seluruhnya.
+func maskDSN(dsn string) string {
+	if dsn == "" {
+		return ""
+	}
+	u, err := url.Parse(dsn)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		return mask
+	}
+	if q := u.Query(); q.Has("password") {
+		q.Set("password", "xxxxx") // sama dengan hasil Redacted
+		u.RawQuery = q.Encode()
+	}
+	return u.Redacted()
+}
+
+// YAML menyajikan konfigurasi efektif (sudah disamarkan) dalam format file konfigurasi
+func (c *Config) YAML() ([]byte, error) {
+	return yaml.Marshal(c.Masked())
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// validate memeriksa nilai efektif; setiap masalah ditulis dengan key YAML dan env-nya
+func (c *Config) validate(l *loader) {
+	if p, err := strconv.Atoi(c.AppPort); err != nil || p < 1 || p > 65535 {
+		l.addf("app_port (APP_PORT): must be a port number 1-65535, got %q", c.AppPort)
+	}
+	if c.DatabaseURL == "" {
+		l.addf("database_url (DATABASE_URL or DATABASE_*): is required")
+	}
+	if c.JWTSecret == "" {
+		l.addf("jwt_secret (JWT_SECRET): is required")
+	}
+	if !slices.Contains([]string{"debug", "info", "warn", "warning", "error"}, strings.ToLower(c.LogLevel)) {
+		l.addf("log_level (LOG_LEVEL): must be one of debug, info, warn, error, got %q", c.LogLevel)
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil || c.Timezone == "" {
+		l.addf("timezone (APP_TIMEZONE): must be an IANA time zone (e.g. Asia/Jakarta), got %q", c.Timezone)
+	}
+
+	db := c.Database
+	if db.MaxConns < 1 {
+		l.addf("database.max_conns (DB_MAX_CONNS): must be at least 1")
+	}
+	if db.MinConns < 0 || db.MinConns > db.MaxConns {
+		l.addf("database.min_conns (DB_MIN_CONNS): must be between 0 and max_conns (%d)", db.MaxConns)
+	}
+	positive(l, "database.max_conn_lifetime (DB_MAX_CONN_LIFETIME)", int64(db.MaxConnLifetime))
+	positive(l, "database.max_conn_idle_time (DB_MAX_CONN_IDLE_TIME)", int64(db.MaxConnIdleTime))
+	positive(l, "database.health_check_period (DB_HEALTH_CHECK_PERIOD)", int64(db.HealthCheckPeriod))
+	positive(l, "database.connect_timeout (DB_CONNECT_TIMEOUT)", int64(db.ConnectTimeout))
+
+	positive(l, "auth.token_ttl (AUTH_TOKEN_TTL)", int64(c.Auth.TokenTTL))
+	if c.Auth.BcryptCost < bcrypt.MinCost || c.Auth.BcryptCost > bcrypt.MaxCost {
+		l.addf("auth.bcrypt_cost (AUTH_BCRYPT_COST): must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, c.Auth.BcryptCost)
+	}
+	positive(l, "auth.activation_ttl (AUTH_ACTIVATION_TTL)", int64(c.Auth.ActivationTTL))
+	if u := c.Auth.ActivationURL; u != "" && !strings.Contains(u, "{token}") {
+		l.addf("auth.activation_url (AUTH_ACTIVATION_URL): must contain the {token} placeholder, got %q", u)
+	}
+
+	positive(l, "pagination.max_page_size (PAGE_SIZE_MAX)", int64(c.Pagination.MaxPageSize))
+	if c.Pagination.DefaultPageSize < 1 || c.Pagination.DefaultPageSize > c.Pagination.MaxPageSize {
+		l.addf("pagination.default_page_size (PAGE_SIZE_DEFAULT): must be between 1 and max_page_size (%d)", c.Pagination.MaxPageSize)
+	}
+
+	h := c.HTTP
+	positive(l, "http.read_timeout (HTTP_READ_TIMEOUT)", int64(h.ReadTimeout))
+	positive(l, "http.read_header_timeout (HTTP_READ_HEADER_TIMEOUT)", int64(h.ReadHeaderTimeout))
+	positive(l, "http.write_timeout (HTTP_WRITE_TIMEOUT)", int64(h.WriteTimeout))
+	positive(l, "http.idle_timeout (HTTP_IDLE_TIMEOUT)", int64(h.IdleTimeout))
+	positive(l, "http.shutdown_timeout (HTTP_SHUTDOWN_TIMEOUT)", int64(h.ShutdownTimeout))
+	positive(l, "http.max_header_bytes (HTTP_MAX_HEADER_BYTES)", int64(h.MaxHeaderBytes))
+	positive(l, "http.max_body_bytes (HTTP_MAX_BODY_BYTES)", h.MaxBodyBytes)
+	positive(l, "http.max_upload_bytes (HTTP_MAX_UPLOAD_BYTES)", h.MaxUploadBytes)
+	if h.HSTSMaxAge < 0 {
+		l.addf("http.hsts_max_age (HTTP_HSTS_MAX_AGE): must not be negative")
+	}
+	if (h.TLSCertFile == "") != (h.TLSKeyFile == "") {
+		l.addf("http.tls_cert_file/tls_key_file (TLS_CERT_FILE/TLS_KEY_FILE): must be set together")
+	}
+
+	for _, p := range h.TrustedProxies {
+		if !validProxy(p) {
+			l.addf("http.trusted_proxies (HTTP_TRUSTED_PROXIES): must be an IP or CIDR, got %q", p)
+		}
+	}
+
+	if c.CORS.AllowCredentials && slices.Contains(c.CORS.AllowedOrigins, "*") {
+		l.addf("cors.allow_credentials (CORS_ALLOW_CREDENTIALS): cannot be combined with allowed_origins \"*\"")
+	}
+	if c.CORS.MaxAge < 0 {
+		l.addf("cors.max_age (CORS_MAX_AGE): must not be negative")
+	}
+
+	if c.RateLimit.Enabled {
+		if !slices.Contains([]string{"memory", "postgres"}, c.RateLimit.Store) {
+			l.addf("rate_limit.store (RATE_LIMIT_STORE): must be memory or postgres, got %q", c.RateLimit.Store)
+		}
+		rule(l, "auth", "AUTH", c.RateLimit.Auth)
+		rule(l, "read", "READ", c.RateLimit.Read)
+		rule(l, "write", "WRITE", c.RateLimit.Write)
+	}
+
+	if !slices.Contains([]string{"none", "stdout", "otlp"}, c.Tracing.Exporter) {
+		l.addf("tracing.exporter (OTEL_TRACES_EXPORTER): must be one of none, stdout, otlp, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.ServiceName == "" {
+		l.addf("tracing.service_name (OTEL_SERVICE_NAME): must not be empty")
+	}
+
+	if c.Cuti.DefaultMaxSemesters < 0 {
+		l.addf("cuti.default_max_semesters (CUTI_DEFAULT_MAX_SEMESTERS): must not be negative")
+	}
+	positive(l, "cuti.max_attachment_bytes (CUTI_MAX_ATTACHMENT_BYTES)", c.Cuti.MaxAttachmentBytes)
+	if c.Cuti.MaxAttachmentBytes > h.MaxUploadBytes {
+		l.addf("cuti.max_attachment_bytes (CUTI_MAX_ATTACHMENT_BYTES): must not exceed http.max_upload_bytes (%d)", h.MaxUploadBytes)
+	}
+	if c.Cuti.SyncInterval < 0 {
+		l.addf("cuti.sync_interval (CUTI_SYNC_INTERVAL): must not be negative")
+	}
+}
+
+func validProxy(s string) bool {
+	if strings.Contains(s, "/") {
+		_, err := netip.ParsePrefix(s)
+		return err == nil
+	}
+	_, err := netip.ParseAddr(s)
+	return err == nil
+}
+
+func positive(l *loader, name string, v int64) {
+	if v <= 0 {
+		l.addf("%s: must be positive", name)
+	}
+}
+
+func rule(l *loader, key, env string, r RateLimitRule) {
+	if r.Rate <= 0 {
+		l.addf("rate_limit.%s.rate (RATE_LIMIT_%s_RATE): must be positive", key, env)
+	}
+	if r.Burst < 1 {
+		l.addf("rate_limit.%s.burst (RATE_LIMIT_%s_BURST): must be at least 1", key, env)
+	}
+}
@@ -2,10 +2,12 @@ package db
 
 import (
 	"context"
-	"log"
-	"time"
+	"log/slog"
 
+	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pencatatan-data-mahasiswa/internal/config"
 )
 
 // Pool adalah alias untuk pgxpool.Pool agar tipe dependensi seragam di seluruh proyek
@@ -13,31 +15,38 @@ import (
 // sehingga fungsi yang menerima *db.Pool bisa menerima hasil dari Connect()
 type Pool = pgxpool.Pool
 
-func Connect(url string) (*pgxpool.Pool, error) {
+// Connect membuat pool sesuai pengaturan ukuran/umur koneksi di pc lalu memastikan database terjangkau
+func Connect(url string, pc config.DatabaseConfig) (*pgxpool.Pool, error) {
 	// Hindari mencetak URL database secara penuh karena berpotensi mengandung kredensial
 
 	cfg, err := pgxpool.ParseConfig(url)
 	if err != nil {
-		log.Printf("Error parsing database config: %v", err)
+		slog.Error("error parsing database config", "error", err)
 		return nil, err
 	}
 
-	cfg.MaxConns = 10
+	cfg.MaxConns = pc.MaxConns
+	cfg.MinConns = pc.MinConns
+	cfg.MaxConnLifetime = pc.MaxConnLifetime
+	cfg.MaxConnIdleTime = pc.MaxConnIdleTime
+	cfg.HealthCheckPeriod = pc.HealthCheckPeriod
+	// setiap query menjadi span anak dari span service/handler yang memanggilnya
+	cfg.ConnConfig.Tracer = otelpgx.NewTracer(otelpgx.WithTrimSQLInSpanName())
 	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
 	if err != nil {
-		log.Printf("Error creating connection pool: %v", err)
+		slog.Error("error creating connection pool", "error", err)
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), pc.ConnectTimeout)
 	defer cancel()
 
 	if err := pool.Ping(ctx); err != nil {
-		log.Printf("Error pinging database: %v", err)
+		slog.Error("error pinging database", "error", err)
 		pool.Close()
 		return nil, err
 	}
 
-	log.Printf("Successfully connected to database")
+	slog.Info("successfully connected to database", "max_conns", cfg.MaxConns)
 	return pool, nil
 }
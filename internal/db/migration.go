@@ -1,46 +1,137 @@
 package db
 
 import (
-	"log"
-	"os"
-	"path/filepath"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5"
+
+	"pencatatan-data-mahasiswa/migrations"
 )
 
-// RunMigrations membaca file migrasi dari folder "migrations" di working directory
-// dan menjalankannya terhadap database sesuai databaseURL.
-// Catatan: fungsi ini akan memanggil log.Fatalf pada error fatal.
-func RunMigrations(databaseURL string) {
-	wd, err := os.Getwd()
+// migrationLockKey adalah kunci pg_advisory_lock yang dipegang selama migrasi berjalan,
+// sehingga beberapa instance yang start bersamaan tidak saling balapan menjalankan migrasi
+const migrationLockKey int64 = 0x6d696772617465 // "migrate"
+
+// Migrator membungkus golang-migrate dengan sumber file SQL yang tersemat di binary
+// (package migrations), jadi tidak bergantung pada working directory.
+type Migrator struct {
+	databaseURL string
+	m           *migrate.Migrate
+}
+
+// NewMigrator menyiapkan migrator untuk databaseURL. Panggil Close setelah selesai.
+func NewMigrator(databaseURL string) (*Migrator, error) {
+	src, err := iofs.New(migrations.FS, ".")
 	if err != nil {
-		log.Fatalf("failed to get working dir: %v", err)
+		return nil, fmt.Errorf("init embedded migration source: %w", err)
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", src, databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("init migrate: %w", err)
+	}
+	m.Log = migrateLogger{}
+	return &Migrator{databaseURL: databaseURL, m: m}, nil
+}
+
+// Close menutup koneksi source dan database milik migrator
+func (mg *Migrator) Close() {
+	_, _ = mg.m.Close()
+}
+
+// Up menjalankan semua migrasi yang belum diterapkan; tidak ada perubahan bukan error
+func (mg *Migrator) Up(ctx context.Context) error {
+	return mg.locked(ctx, func() error { return ignoreNoChange(mg.m.Up()) })
+}
+
+// Down membatalkan n migrasi terakhir
+func (mg *Migrator) Down(ctx context.Context, n int) error {
+	if n < 1 {
+		return fmt.Errorf("down requires a positive number of steps, got %d", n)
 	}
-	migDir := filepath.Join(wd, "migrations")
+	return mg.locked(ctx, func() error { return ignoreNoChange(mg.m.Steps(-n)) })
+}
+
+// Goto bermigrasi naik atau turun sampai versi v
+func (mg *Migrator) Goto(ctx context.Context, v uint) error {
+	return mg.locked(ctx, func() error { return ignoreNoChange(mg.m.Migrate(v)) })
+}
+
+// Force menandai versi v sebagai versi saat ini tanpa menjalankan SQL dan menghapus flag dirty.
+// Dipakai setelah memperbaiki migrasi yang gagal di tengah jalan secara manual.
+func (mg *Migrator) Force(ctx context.Context, v int) error {
+	return mg.locked(ctx, func() error { return mg.m.Force(v) })
+}
 
-	// Gunakan source iofs agar kompatibel lintas OS (khususnya Windows)
-	d, err := iofs.New(os.DirFS(wd), "migrations")
+// Version mengembalikan versi saat ini; ok bernilai false bila belum ada migrasi yang diterapkan
+func (mg *Migrator) Version() (version uint, dirty bool, ok bool, err error) {
+	version, dirty, err = mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, false, nil
+	}
 	if err != nil {
-		log.Fatalf("failed to init iofs source for migrations at %s: %v", migDir, err)
+		return 0, false, false, err
 	}
+	return version, dirty, true, nil
+}
 
-	m, err := migrate.NewWithSourceInstance("iofs", d, databaseURL)
+// locked menjalankan fn sambil memegang advisory lock pada koneksi tersendiri.
+// Instance lain menunggu (sampai ctx berakhir) lalu mendapati migrasi sudah diterapkan.
+func (mg *Migrator) locked(ctx context.Context, fn func() error) error {
+	conn, err := pgx.Connect(ctx, mg.databaseURL)
 	if err != nil {
-		log.Fatalf("failed to init migrate: %v", err)
+		return fmt.Errorf("connect for migration lock: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	slog.Info("migration: waiting for advisory lock")
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
 	}
 	defer func() {
-		_, _ = m.Close()
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey); err != nil {
+			slog.Error("migration: release advisory lock", "error", err)
+		}
 	}()
+	return fn()
+}
 
-	if err := m.Up(); err != nil {
-		if err == migrate.ErrNoChange {
-			log.Println("migration: no changes")
-			return
-		}
-		log.Fatalf("migration failed: %v", err)
-		return
+// Migrate menerapkan semua migrasi yang belum berjalan; dipakai serve saat auto-migrate aktif
+func Migrate(ctx context.Context, databaseURL string) error {
+	mg, err := NewMigrator(databaseURL)
+	if err != nil {
+		return err
+	}
+	defer mg.Close()
+	if err := mg.Up(ctx); err != nil {
+		return err
 	}
-	log.Println("migration: applied successfully")
+	v, dirty, ok, err := mg.Version()
+	if err != nil {
+		return err
+	}
+	slog.Info("migration: up to date", "version", v, "dirty", dirty, "applied", ok)
+	return nil
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
 }
+
+// migrateLogger meneruskan log golang-migrate ke slog
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...any) {
+	slog.Info("migration: " + strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (migrateLogger) Verbose() bool { return false }
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pencatatan-data-mahasiswa/internal/testdb"
+	"pencatatan-data-mahasiswa/migrations"
+)
+
+func TestMain(m *testing.M) {
+	testdb.Main(m, "db")
+}
+
+// tables mengembalikan nama tabel di schema aktif (schema sekali pakai dari testdb)
+func tables(t *testing.T, pool *Pool) []string {
+	t.Helper()
+	rows, err := pool.Query(context.Background(),
+		`SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() ORDER BY table_name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	out := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// TestMigrationsUpDown menerapkan semua migrasi, membatalkan semuanya, lalu menerapkannya lagi;
+// memastikan setiap file down benar-benar kebalikan dari up-nya
+func TestMigrationsUpDown(t *testing.T) {
+	url := testdb.URL(t)
+	ctx := context.Background()
+	latest, err := migrations.Latest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mg, err := NewMigrator(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mg.Close()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	assertVersion := func(step string, want uint, wantOK bool) {
+		t.Helper()
+		v, dirty, ok, err := mg.Version()
+		if err != nil {
+			t.Fatalf("%s: version: %v", step, err)
+		}
+		if v != want || ok != wantOK || dirty {
+			t.Fatalf("%s: version = (%d, dirty=%v, ok=%v), want (%d, ok=%v)", step, v, dirty, ok, want, wantOK)
+		}
+	}
+
+	if err := mg.Up(ctx); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	assertVersion("up", latest, true)
+	if v, dirty, ok, err := MigrationVersion(ctx, pool); err != nil || v != latest || dirty || !ok {
+		t.Fatalf("MigrationVersion = (%d, %v, %v, %v), want %d", v, dirty, ok, err, latest)
+	}
+	applied := tables(t, pool)
+
+	if err := mg.Up(ctx); err != nil {
+		t.Fatalf("second up must be a no-op: %v", err)
+	}
+
+	if err := mg.Down(ctx, int(latest)); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	assertVersion("down", 0, false)
+	if got, want := tables(t, pool), []string{"schema_migrations"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("tables after down = %v, want %v", got, want)
+	}
+
+	if err := mg.Up(ctx); err != nil {
+		t.Fatalf("up after down: %v", err)
+	}
+	assertVersion("up after down", latest, true)
+	if got := tables(t, pool); !reflect.DeepEqual(got, applied) {
+		t.Errorf("tables after re-up = %v, want %v", got, applied)
+	}
+}
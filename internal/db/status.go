@@ -0,0 +1,22 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MigrationVersion membaca versi dan status dirty dari tabel schema_migrations milik golang-migrate.
+// ok bernilai false bila belum ada migrasi yang pernah dijalankan.
+func MigrationVersion(ctx context.Context, pool *Pool) (version uint, dirty bool, ok bool, err error) {
+	var v int64
+	err = pool.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&v, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, false, nil
+	}
+	if err != nil {
+		return 0, false, false, err
+	}
+	return uint(v), dirty, true, nil
+}
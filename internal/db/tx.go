@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DBTX adalah method query yang dimiliki *Pool maupun pgx.Tx, sehingga repository
+// bisa dibangun di atas salah satunya
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+var (
+	_ DBTX = (*Pool)(nil)
+	_ DBTX = (pgx.Tx)(nil)
+)
+
+// errNoTx: LockKey hanya bermakna di dalam transaksi (lock dilepas saat commit/rollback)
+var errNoTx = errors.New("db: advisory lock requires a transaction; call it inside TxManager.Do")
+
+type txKey struct{}
+
+// Conn mengembalikan transaksi yang sedang berjalan di ctx (dipasang TxManager.Do) atau fallback.
+// Repository memanggilnya di setiap query agar otomatis ikut transaksi milik service.
+func Conn(ctx context.Context, fallback DBTX) DBTX {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return fallback
+}
+
+// TxManager menjalankan beberapa panggilan repository dalam satu pgx.Tx (unit of work)
+type TxManager struct {
+	pool *Pool
+}
+
+func NewTxManager(pool *Pool) *TxManager {
+	return &TxManager{pool: pool}
+}
+
+// Do menjalankan fn dalam transaksi: commit bila fn mengembalikan nil, rollback bila error atau panic.
+// Panggilan bertingkat ikut transaksi terluar, jadi service boleh saling memanggil tanpa savepoint.
+func (m *TxManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+	return pgx.BeginFunc(ctx, m.pool, func(tx pgx.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// LockKey mengambil advisory lock transaksi untuk pasangan (scope, key), mis. ("fakultas.nama", "teknik").
+// Dipakai untuk aturan unik yang tidak punya constraint di database sehingga pengecekan
+// lalu penulisan oleh dua request bersamaan tidak saling mendahului.
+func (m *TxManager) LockKey(ctx context.Context, scope, key string) error {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	if !ok {
+		return errNoTx
+	}
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1), hashtext($2))`, scope, key)
+	return err
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pencatatan-data-mahasiswa/internal/testdb"
+)
+
+func TestTxManagerCommitAndRollback(t *testing.T) {
+	url := testdb.URL(t)
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	if _, err := pool.Exec(ctx, `CREATE TABLE tx_probe (id INT PRIMARY KEY)`); err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Exec(context.Background(), `DROP TABLE tx_probe`)
+
+	tm := NewTxManager(pool)
+	insert := func(ctx context.Context, id int) error {
+		_, err := Conn(ctx, pool).Exec(ctx, `INSERT INTO tx_probe (id) VALUES ($1)`, id)
+		return err
+	}
+	count := func() int {
+		var n int
+		if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM tx_probe`).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	boom := errors.New("boom")
+	err = tm.Do(ctx, func(ctx context.Context) error {
+		if err := insert(ctx, 1); err != nil {
+			return err
+		}
+		// Do bertingkat ikut transaksi luar, jadi ikut di-rollback
+		if err := tm.Do(ctx, func(ctx context.Context) error { return insert(ctx, 2) }); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("Do error = %v, want boom", err)
+	}
+	if n := count(); n != 0 {
+		t.Fatalf("rows after rollback = %d, want 0", n)
+	}
+
+	err = tm.Do(ctx, func(ctx context.Context) error {
+		if err := tm.LockKey(ctx, "tx_probe", "1"); err != nil {
+			return err
+		}
+		return insert(ctx, 1)
+	})
+	if err != nil {
+		t.Fatalf("Do commit: %v", err)
+	}
+	if n := count(); n != 1 {
+		t.Fatalf("rows after commit = %d, want 1", n)
+	}
+	if err := tm.LockKey(ctx, "tx_probe", "1"); err == nil {
+		t.Error("LockKey outside Do must fail")
+	}
+}
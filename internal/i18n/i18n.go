@@ -0,0 +1,91 @@
+// Package i18n berisi katalog pesan API dalam bahasa Indonesia dan Inggris.
+// Bahasa dipilih dari header Accept-Language; kode pesan tetap stabil untuk dipakai program.
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Bahasa yang didukung
+const (
+	ID = "id"
+	EN = "en"
+
+	// Default dipakai bila Accept-Language kosong atau tidak ada bahasa yang didukung
+	Default = ID
+)
+
+// Lang memilih bahasa response dari header Accept-Language (mendukung q-value),
+// mis. "en-US,en;q=0.9,id;q=0.8" -> en
+func Lang(c *gin.Context) string {
+	return ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+}
+
+// ParseAcceptLanguage mengembalikan bahasa yang didukung dengan q-value tertinggi
+func ParseAcceptLanguage(header string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var cands []candidate
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		tag, q := part, 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			tag = strings.TrimSpace(part[:i])
+			param := strings.TrimSpace(part[i+1:])
+			if v, ok := strings.CutPrefix(param, "q="); ok {
+				f, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					continue
+				}
+				q = f
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		primary := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		if primary == "in" { // kode lama untuk bahasa Indonesia
+			primary = ID
+		}
+		if primary == ID || primary == EN {
+			cands = append(cands, candidate{lang: primary, q: q})
+		}
+	}
+	if len(cands) == 0 {
+		return Default
+	}
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].q > cands[j].q })
+	return cands[0].lang
+}
+
+// T menerjemahkan kode pesan ke bahasa lang. Bila tidak ada terjemahan, dipakai
+// bahasa Inggris lalu kode itu sendiri.
+func T(lang, code string) string {
+	m, ok := messages[code]
+	if !ok {
+		return code
+	}
+	if s, ok := m[lang]; ok {
+		return s
+	}
+	if s, ok := m[EN]; ok {
+		return s
+	}
+	return code
+}
+
+// Message menerjemahkan kode pesan sesuai Accept-Language request dan menyetel Content-Language
+func Message(c *gin.Context, code string) string {
+	lang := Lang(c)
+	c.Header("Content-Language", lang)
+	return T(lang, code)
+}
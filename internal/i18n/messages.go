@@ -0,0 +1,142 @@
+package i18n
+
+// Kode pesan yang dipakai handler dan apperror
+const (
+	// sukses
+	MsgCreated = "created"
+	MsgUpdated = "updated"
+	MsgDeleted = "deleted"
+
+	MsgPasswordChanged  = "password_changed"
+	MsgAccountActivated = "account_activated"
+	MsgStatusChanged    = "status_changed"
+	MsgCutiApproved     = "cuti_approved"
+	MsgCutiRejected     = "cuti_rejected"
+	MsgCutiCancelled    = "cuti_cancelled"
+	MsgKRSSubmitted     = "krs_submitted"
+	MsgKRSApproved      = "krs_approved"
+	MsgKRSReturned      = "krs_returned"
+
+	// jenis error (nilai field "error" pada response)
+	MsgValidationError = "validation_error"
+	MsgUnauthorized    = "unauthorized"
+	MsgForbidden       = "forbidden"
+	MsgNotFound        = "not_found"
+	MsgConflict        = "conflict"
+	MsgUnprocessable   = "unprocessable"
+	MsgInternalError   = "internal_error"
+
+	// error spesifik
+	MsgInvalidRequestBody   = "invalid_request_body"
+	MsgPayloadTooLarge      = "payload_too_large"
+	MsgTooManyRequests      = "too_many_requests"
+	MsgInvalidFilter        = "invalid_filter"
+	MsgStillReferenced      = "still_referenced"
+	MsgFakultasInUse        = "fakultas_in_use"
+	MsgProdiInUse           = "prodi_in_use"
+	MsgDosenInUse           = "dosen_in_use"
+	MsgSemesterInUse        = "semester_in_use"
+	MsgMahasiswaInUse       = "mahasiswa_in_use"
+	MsgInvalidCredentials   = "invalid_credentials"
+	MsgUsernameTaken        = "username_taken"
+	MsgMissingAuthorization = "missing_authorization"
+	MsgInvalidToken         = "invalid_token"
+	MsgCSVFileRequired      = "csv_file_required"
+	MsgCSVFileUnreadable    = "csv_file_unreadable"
+	MsgCSVHeaderInvalid     = "csv_header_invalid"
+	MsgCSVRowInvalid        = "csv_row_invalid"
+	MsgCSVRowColumns        = "csv_row_columns"
+
+	MsgMahasiswaCSVHeaderInvalid = "mahasiswa_csv_header_invalid"
+	MsgCSVDuplicateRow           = "csv_duplicate_row"
+	MsgPasswordChangeRequired    = "password_change_required"
+	MsgActivationTokenInvalid    = "activation_token_invalid"
+
+	MsgStatusTransitionNotAllowed = "status_transition_not_allowed"
+	MsgStatusOverrideForbidden    = "status_override_forbidden"
+
+	MsgCutiLimitReached     = "cuti_limit_reached"
+	MsgCutiNotEligible      = "cuti_not_eligible"
+	MsgCutiAlreadyProcessed = "cuti_already_processed"
+	MsgCutiLampiranRequired = "cuti_lampiran_required"
+	MsgCutiKRSExists        = "cuti_krs_exists"
+
+	MsgKRSNotEligible      = "krs_not_eligible"
+	MsgKRSOnCuti           = "krs_on_cuti"
+	MsgKRSNoPembimbing     = "krs_no_pembimbing"
+	MsgKRSEmpty            = "krs_empty"
+	MsgKRSLocked           = "krs_locked"
+	MsgKRSAlreadyProcessed = "krs_already_processed"
+	MsgKRSPrasyarat        = "krs_prasyarat_unmet"
+
+	MsgPrasyaratCycle = "prasyarat_cycle"
+)
+
+// messages: kode -> bahasa -> teks
+var messages = map[string]map[string]string{
+	MsgCreated: {ID: "Data berhasil dibuat", EN: "Created"},
+	MsgUpdated: {ID: "Data berhasil diperbarui", EN: "Updated"},
+	MsgDeleted: {ID: "Data berhasil dihapus", EN: "Deleted"},
+
+	MsgPasswordChanged:  {ID: "Password berhasil diganti", EN: "Password changed"},
+	MsgAccountActivated: {ID: "Akun berhasil diaktifkan", EN: "Account activated"},
+	MsgCutiApproved:     {ID: "Pengajuan cuti disetujui", EN: "Leave request approved"},
+	MsgCutiRejected:     {ID: "Pengajuan cuti ditolak", EN: "Leave request rejected"},
+	MsgCutiCancelled:    {ID: "Pengajuan cuti dibatalkan", EN: "Leave request cancelled"},
+	MsgStatusChanged:    {ID: "Status mahasiswa berhasil diubah", EN: "Student status changed"},
+	MsgKRSSubmitted:     {ID: "KRS berhasil diajukan ke dosen pembimbing", EN: "Study plan submitted to the academic advisor"},
+	MsgKRSApproved:      {ID: "KRS disetujui", EN: "Study plan approved"},
+	MsgKRSReturned:      {ID: "KRS dikembalikan ke mahasiswa", EN: "Study plan returned to the student"},
+
+	MsgValidationError: {ID: "Validasi gagal", EN: "Validation failed"},
+	MsgUnauthorized:    {ID: "Tidak terautentikasi", EN: "Unauthorized"},
+	MsgForbidden:       {ID: "Akses ditolak", EN: "Forbidden"},
+	MsgNotFound:        {ID: "Data tidak ditemukan", EN: "Not found"},
+	MsgConflict:        {ID: "Data bentrok dengan data yang sudah ada", EN: "Conflict with existing data"},
+	MsgUnprocessable:   {ID: "Data rujukan tidak valid", EN: "Referenced data is invalid"},
+	MsgInternalError:   {ID: "Terjadi kesalahan pada server", EN: "Internal server error"},
+
+	MsgInvalidRequestBody:   {ID: "Body request tidak valid", EN: "Invalid request body"},
+	MsgPayloadTooLarge:      {ID: "Ukuran body request melebihi batas", EN: "Request body is too large"},
+	MsgTooManyRequests:      {ID: "Terlalu banyak request, coba lagi nanti", EN: "Too many requests, please retry later"},
+	MsgInvalidFilter:        {ID: "Filter tidak valid", EN: "Invalid filter"},
+	MsgStillReferenced:      {ID: "Data masih dirujuk oleh tabel lain", EN: "Still referenced by another table"},
+	MsgFakultasInUse:        {ID: "Tidak dapat dihapus: masih ada prodi terkait", EN: "Cannot delete: related prodi exists"},
+	MsgProdiInUse:           {ID: "Tidak dapat dihapus: masih ada mahasiswa atau mata kuliah terkait", EN: "Cannot delete: related mahasiswa or mata_kuliah exists"},
+	MsgDosenInUse:           {ID: "Tidak dapat dihapus: masih ada mata kuliah, kelas kuliah atau mahasiswa bimbingan terkait", EN: "Cannot delete: related mata_kuliah, kelas_kuliah or advisees exist"},
+	MsgSemesterInUse:        {ID: "Tidak dapat dihapus: masih ada kelas kuliah atau KRS terkait", EN: "Cannot delete: related kelas_kuliah or krs exists"},
+	MsgMahasiswaInUse:       {ID: "Tidak dapat dihapus: masih ada KRS terkait", EN: "Cannot delete: related krs exists"},
+	MsgInvalidCredentials:   {ID: "Username atau password salah", EN: "Invalid username or password"},
+	MsgUsernameTaken:        {ID: "Username sudah dipakai", EN: "Username already taken"},
+	MsgMissingAuthorization: {ID: "Header Authorization tidak ada atau tidak valid", EN: "Missing or invalid Authorization header"},
+	MsgInvalidToken:         {ID: "Token tidak valid atau kedaluwarsa", EN: "Invalid or expired token"},
+	MsgCSVFileRequired:      {ID: "Field file 'file' wajib diisi", EN: "Missing file field 'file'"},
+	MsgCSVFileUnreadable:    {ID: "File yang diunggah tidak dapat dibuka", EN: "Cannot open uploaded file"},
+	MsgCSVHeaderInvalid:     {ID: "Header CSV harus: id_semester,tahun_ajaran,term,tanggal_mulai,tanggal_selesai", EN: "Expected header: id_semester,tahun_ajaran,term,tanggal_mulai,tanggal_selesai"},
+	MsgCSVRowInvalid:        {ID: "Baris CSV tidak valid", EN: "Invalid CSV row"},
+	MsgCSVRowColumns:        {ID: "Jumlah kolom kurang", EN: "Not enough columns"},
+
+	MsgMahasiswaCSVHeaderInvalid: {ID: "Header CSV harus: id_mahasiswa,id_prodi,nik,nama_lengkap,jenis_kelamin,tempat_lahir,tanggal_lahir,alamat,email,no_hp,tahun_masuk,status", EN: "Expected header: id_mahasiswa,id_prodi,nik,nama_lengkap,jenis_kelamin,tempat_lahir,tanggal_lahir,alamat,email,no_hp,tahun_masuk,status"},
+	MsgCSVDuplicateRow:           {ID: "ID sudah muncul di baris sebelumnya pada file yang sama", EN: "ID already appears on an earlier row of the same file"},
+	MsgPasswordChangeRequired:    {ID: "Password awal harus diganti sebelum melanjutkan", EN: "The initial password must be changed before continuing"},
+	MsgActivationTokenInvalid:    {ID: "Token aktivasi tidak valid atau kedaluwarsa", EN: "Invalid or expired activation token"},
+
+	MsgStatusTransitionNotAllowed: {ID: "Perubahan status ini tidak diizinkan", EN: "This status transition is not allowed"},
+	MsgStatusOverrideForbidden:    {ID: "Override status hanya boleh dilakukan admin", EN: "Only admins may override status transitions"},
+
+	MsgCutiLimitReached:     {ID: "Batas semester cuti untuk prodi ini sudah tercapai", EN: "The leave semester limit for this study program has been reached"},
+	MsgCutiNotEligible:      {ID: "Cuti hanya dapat diajukan mahasiswa berstatus Aktif atau Cuti", EN: "Only students with status Aktif or Cuti may request leave"},
+	MsgCutiAlreadyProcessed: {ID: "Pengajuan cuti sudah diproses", EN: "The leave request has already been processed"},
+	MsgCutiKRSExists:        {ID: "Mahasiswa sudah memiliki KRS pada semester cuti; kosongkan KRS sebelum menyetujui cuti", EN: "The student already has a study plan for the leave semester; clear it before approving the leave"},
+	MsgCutiLampiranRequired: {ID: "Lampiran (PDF, JPEG atau PNG) wajib diunggah pada field lampiran", EN: "An attachment (PDF, JPEG or PNG) must be uploaded in the lampiran field"},
+
+	MsgKRSNotEligible:      {ID: "KRS hanya dapat diisi mahasiswa berstatus Aktif, atau Cuti untuk semester berikutnya", EN: "Only students with status Aktif, or Cuti for a later semester, may fill in and submit a study plan"},
+	MsgKRSOnCuti:           {ID: "Mahasiswa cuti pada semester ini sehingga KRS tidak dapat diisi", EN: "The student is on approved leave in this semester, so no study plan can be filled in"},
+	MsgKRSNoPembimbing:     {ID: "Mahasiswa belum memiliki dosen pembimbing akademik", EN: "The student has no academic advisor yet"},
+	MsgKRSEmpty:            {ID: "KRS belum berisi kelas", EN: "The study plan has no classes"},
+	MsgKRSLocked:           {ID: "KRS sudah diajukan atau disetujui dan tidak dapat diubah", EN: "The study plan has been submitted or approved and can no longer be changed"},
+	MsgKRSAlreadyProcessed: {ID: "Pengajuan KRS sudah diproses", EN: "The study plan submission has already been processed"},
+	MsgKRSPrasyarat:        {ID: "Prasyarat mata kuliah belum terpenuhi; lihat details", EN: "Course prerequisites are not met; see details"},
+
+	MsgPrasyaratCycle: {ID: "Prasyarat membentuk rantai melingkar", EN: "The prerequisites would form a cycle"},
+}
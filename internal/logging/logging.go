@@ -0,0 +1,100 @@
+// Package logging menyiapkan log/slog berformat JSON, menyisipkan request id, user id dan role
+// dari context ke setiap baris log, serta menyamarkan data pribadi (NIK, password, token, no HP).
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Setup memasang logger JSON sebagai slog default (log.Printf ikut diteruskan ke slog).
+// level: debug | info | warn | error (default info).
+func Setup(level string) *slog.Logger {
+	return setup(os.Stdout, level)
+}
+
+func setup(w io.Writer, level string) *slog.Logger {
+	var lv slog.Level
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		lv = slog.LevelDebug
+	case "warn", "warning":
+		lv = slog.LevelWarn
+	case "error":
+		lv = slog.LevelError
+	default:
+		lv = slog.LevelInfo
+	}
+	json := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: lv, ReplaceAttr: redactAttr})
+	logger := slog.New(&contextHandler{Handler: json})
+	slog.SetDefault(logger)
+	return logger
+}
+
+// requestInfo disimpan sebagai pointer di context agar middleware auth yang berjalan
+// setelah middleware request id bisa melengkapi user id dan role
+type requestInfo struct {
+	requestID string
+	userID    string
+	role      string
+}
+
+type ctxKey struct{}
+
+// WithRequestID mengembalikan context baru yang membawa request id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &requestInfo{requestID: id})
+}
+
+// RequestID mengembalikan request id dari context (kosong bila tidak ada)
+func RequestID(ctx context.Context) string {
+	if info, ok := ctx.Value(ctxKey{}).(*requestInfo); ok {
+		return info.requestID
+	}
+	return ""
+}
+
+// SetUser melengkapi informasi user (dari klaim JWT) untuk log berikutnya pada request yang sama
+func SetUser(ctx context.Context, userID, role string) {
+	if info, ok := ctx.Value(ctxKey{}).(*requestInfo); ok {
+		info.userID, info.role = userID, role
+	}
+}
+
+// contextHandler menambahkan atribut request dari context dan menyamarkan isi pesan log
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, redactString(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(a)
+		return true
+	})
+	if info, ok := ctx.Value(ctxKey{}).(*requestInfo); ok {
+		if info.requestID != "" {
+			nr.AddAttrs(slog.String("request_id", info.requestID))
+		}
+		if info.userID != "" {
+			nr.AddAttrs(slog.String("user_id", info.userID), slog.String("role", info.role))
+		}
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		nr.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, nr)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
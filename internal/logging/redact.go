@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys: nama atribut/field yang nilainya selalu disamarkan (dibandingkan lowercase)
+var sensitiveKeys = map[string]struct{}{
+	"nik": {}, "password": {}, "password_hash": {}, "new_password": {}, "old_password": {},
+	"token": {}, "access_token": {}, "refresh_token": {}, "authorization": {}, "jwt": {},
+	"secret": {}, "jwt_secret": {}, "no_hp": {}, "phone": {}, "telepon": {},
+}
+
+var (
+	// NIK: tepat 16 digit
+	nikPattern = regexp.MustCompile(`\b\d{16}\b`)
+	// JWT: tiga segmen base64url diawali eyJ
+	jwtPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	// Bearer token pada header yang ikut tercetak
+	bearerPattern = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._~+/=-]+`)
+	// Nomor HP Indonesia: +62/62/08 diikuti 8-13 digit (boleh dipisah spasi/strip, termasuk
+	// setelah kode negara: +62 812-...)
+	phonePattern = regexp.MustCompile(`(?:(?:\+62|\b62)[ -]?|\b0)8[0-9 -]{7,14}\d`)
+	// password=... pada query string atau pesan bebas
+	passwordPattern = regexp.MustCompile(`(?i)(password|passwd|pwd)=[^&\s]+`)
+)
+
+// redactString menyamarkan pola data pribadi di dalam teks bebas (pesan error, path, dll)
+func redactString(s string) string {
+	s = jwtPattern.ReplaceAllString(s, redacted)
+	s = bearerPattern.ReplaceAllString(s, "Bearer "+redacted)
+	s = passwordPattern.ReplaceAllString(s, "$1="+redacted)
+	s = nikPattern.ReplaceAllString(s, redacted)
+	s = phonePattern.ReplaceAllString(s, redacted)
+	return s
+}
+
+func isSensitive(key string) bool {
+	_, ok := sensitiveKeys[strings.ToLower(key)]
+	return ok
+}
+
+// redactAttr dipasang sebagai ReplaceAttr handler JSON
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if isSensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, redactString(v.String()))
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			return slog.String(a.Key, redactString(x.Error()))
+		case []byte:
+			return slog.Any(a.Key, redactPayload(x))
+		default:
+			b, err := json.Marshal(x)
+			if err != nil {
+				return a
+			}
+			return slog.Any(a.Key, redactPayload(b))
+		}
+	}
+	return a
+}
+
+// redactPayload menyamarkan field sensitif di dalam payload JSON (rekursif)
+func redactPayload(b []byte) any {
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return redactString(string(b))
+	}
+	return redactValue(v)
+}
+
+func redactValue(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, val := range x {
+			if isSensitive(k) {
+				x[k] = redacted
+			} else {
+				x[k] = redactValue(val)
+			}
+		}
+		return x
+	case []any:
+		for i := range x {
+			x[i] = redactValue(x[i])
+		}
+		return x
+	case string:
+		return redactString(x)
+	default:
+		return x
+	}
+}
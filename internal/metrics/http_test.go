@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestMiddlewareCountsRecoveredPanic: dengan urutan middleware seperti di router, handler yang
+// panic tercatat sebagai 500 pada template route-nya
+func TestMiddlewareCountsRecoveredPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Middleware(), gin.Recovery())
+	r.GET("/boom/:id", func(*gin.Context) { panic("boom") })
+	r.GET("/ok", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	panics := httpRequests.WithLabelValues(http.MethodGet, "/boom/:id", "500")
+	oks := httpRequests.WithLabelValues(http.MethodGet, "/ok", "204")
+	beforePanics, beforeOKs := testutil.ToFloat64(panics), testutil.ToFloat64(oks)
+
+	for _, path := range []string{"/boom/1", "/ok"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	if got := testutil.ToFloat64(panics) - beforePanics; got != 1 {
+		t.Errorf("500 count for /boom/:id increased by %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(oks) - beforeOKs; got != 1 {
+		t.Errorf("204 count for /ok increased by %v, want 1", got)
+	}
+}
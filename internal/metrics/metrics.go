@@ -0,0 +1,100 @@
+// Package metrics mengekspos metrik Prometheus: HTTP per template route, statistik pgxpool,
+// login, dan counter domain. Semua label dibatasi ke nilai yang jumlahnya tetap
+// (template route, bukan path mentah) agar kardinalitas tidak meledak.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pencatatan"
+
+// Registry menampung seluruh metrik aplikasi beserta metrik runtime Go dan proses
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Jumlah request HTTP per method, template route dan status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Durasi request HTTP per method dan template route.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"method", "route"})
+
+	loginAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_login_total",
+		Help:      "Jumlah percobaan login per hasil (success, failure).",
+	}, []string{"result"})
+
+	krsSubmissions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "krs_submissions_total",
+		Help:      "Jumlah pengajuan KRS per hasil (accepted, rejected).",
+	}, []string{"result"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Jumlah request yang ditolak 429 per kelas aturan (auth, read, write).",
+	}, []string{"class"})
+
+	csvImportRows = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "csv_import_rows_total",
+		Help:      "Jumlah baris import CSV per entitas dan hasil (imported, invalid, failed).",
+	}, []string{"entity", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, loginAttempts, krsSubmissions, rateLimited, csvImportRows,
+	)
+}
+
+// Handler melayani GET /metrics dalam format exposition Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Login mencatat hasil percobaan login
+func Login(success bool) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+	loginAttempts.WithLabelValues(result).Inc()
+}
+
+// KRSSubmission mencatat hasil pengajuan KRS
+func KRSSubmission(accepted bool) {
+	result := "rejected"
+	if accepted {
+		result = "accepted"
+	}
+	krsSubmissions.WithLabelValues(result).Inc()
+}
+
+// RateLimited mencatat request yang ditolak rate limiter
+func RateLimited(class string) {
+	rateLimited.WithLabelValues(class).Inc()
+}
+
+// CSVImportRows menambah jumlah baris import; entity mis. "semester", result imported|invalid|failed
+func CSVImportRows(entity, result string, n int) {
+	if n <= 0 {
+		return
+	}
+	csvImportRows.WithLabelValues(entity, result).Add(float64(n))
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"pencatatan-data-mahasiswa/internal/db"
+)
+
+// poolCollector membaca pgxpool.Stat() setiap kali /metrics di-scrape
+type poolCollector struct {
+	pool *db.Pool
+
+	totalConns, idleConns, acquiredConns, constructingConns, maxConns *prometheus.Desc
+	acquireCount, emptyAcquireCount, canceledAcquireCount             *prometheus.Desc
+	acquireDuration                                                   *prometheus.Desc
+}
+
+func newDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+}
+
+// RegisterPool mendaftarkan statistik pool ke Registry; cukup dipanggil sekali saat startup
+func RegisterPool(pool *db.Pool) error {
+	return Registry.Register(&poolCollector{
+		pool:                 pool,
+		totalConns:           newDesc("total_conns", "Jumlah koneksi di pool."),
+		idleConns:            newDesc("idle_conns", "Jumlah koneksi idle."),
+		acquiredConns:        newDesc("acquired_conns", "Jumlah koneksi yang sedang dipakai."),
+		constructingConns:    newDesc("constructing_conns", "Jumlah koneksi yang sedang dibuat."),
+		maxConns:             newDesc("max_conns", "Batas maksimum koneksi pool."),
+		acquireCount:         newDesc("acquire_total", "Jumlah acquire koneksi yang berhasil."),
+		emptyAcquireCount:    newDesc("empty_acquire_total", "Jumlah acquire yang harus menunggu karena pool kosong."),
+		canceledAcquireCount: newDesc("canceled_acquire_total", "Jumlah acquire yang dibatalkan context."),
+		acquireDuration:      newDesc("acquire_duration_seconds_total", "Total waktu menunggu acquire koneksi."),
+	})
+}
+
+func (p *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		p.totalConns, p.idleConns, p.acquiredConns, p.constructingConns, p.maxConns,
+		p.acquireCount, p.emptyAcquireCount, p.canceledAcquireCount, p.acquireDuration,
+	} {
+		ch <- d
+	}
+}
+
+func (p *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	st := p.pool.Stat()
+	gauge := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v)
+	}
+	counter := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v)
+	}
+	gauge(p.totalConns, float64(st.TotalConns()))
+	gauge(p.idleConns, float64(st.IdleConns()))
+	gauge(p.acquiredConns, float64(st.AcquiredConns()))
+	gauge(p.constructingConns, float64(st.ConstructingConns()))
+	gauge(p.maxConns, float64(st.MaxConns()))
+	counter(p.acquireCount, float64(st.AcquireCount()))
+	counter(p.emptyAcquireCount, float64(st.EmptyAcquireCount()))
+	counter(p.canceledAcquireCount, float64(st.CanceledAcquireCount()))
+	counter(p.acquireDuration, st.AcquireDuration().Seconds())
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/i18n"
+)
+
+var errTooLarge = apperror.New(apperror.ErrTooLarge, i18n.MsgPayloadTooLarge)
+
+// BodyLimit membatasi ukuran body request: def untuk semua route, kecuali route (pola gin,
+// mis. "/api/v1/semester/import") yang punya batas sendiri di perRoute.
+// Content-Length yang sudah melewati batas langsung ditolak 413; body chunked dipotong
+// http.MaxBytesReader dan handler memetakan error bacanya menjadi 413.
+func BodyLimit(def int64, perRoute map[string]int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		limit := def
+		if n, ok := perRoute[c.FullPath()]; ok {
+			limit = n
+		}
+		if c.Request.ContentLength > limit {
+			apperror.Abort(c, errTooLarge)
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
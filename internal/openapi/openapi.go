@@ -0,0 +1,244 @@
+// Package openapi menyusun dokumen OpenAPI 3 dari daftar Operation yang dideklarasikan
+// di samping handler. Skema request/response diturunkan dari struct Go lewat refleksi
+// (tag json, pointer = opsional, tag format), sehingga mengikuti perubahan struct.
+package openapi
+
+import (
+	"cmp"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+)
+
+// Version adalah versi spesifikasi OpenAPI yang dihasilkan
+const Version = "3.0.3"
+
+// Envelope menentukan bentuk body response sukses di sekitar Data
+type Envelope int
+
+const (
+	EnvelopeData    Envelope = iota // {"data": X}
+	EnvelopeList                    // {"data": [X], "meta": PageMeta}
+	EnvelopeKeyset                  // {"data": [X], "meta": {"per_page", "next_cursor"}}
+	EnvelopeMessage                 // {"code", "message", "data": X}
+	EnvelopeRaw                     // X apa adanya
+)
+
+// Param adalah parameter path/query
+type Param struct {
+	Name        string
+	Description string
+	Type        string // string (default) | integer | boolean
+	Enum        []string
+	Required    bool
+}
+
+// Operation mendeskripsikan satu route. Path memakai sintaks gin (":id"), sama seperti saat registrasi.
+type Operation struct {
+	Method      string
+	Path        string
+	Tag         string
+	Summary     string
+	Description string
+	Roles       []string // kosong berarti publik (tanpa bearer token)
+	PathParams  []Param
+	Query       []Param
+	Body        any     // nilai nol struct request JSON, nil bila tanpa body
+	FileField   string  // nama field multipart/form-data untuk upload file
+	Form        []Param // field teks tambahan pada multipart bersama FileField
+	Status      int     // status sukses, default 200
+	Response    any     // nilai nol tipe data response; nil berarti tanpa skema
+	Envelope    Envelope
+	ContentType string // default application/json
+}
+
+// Key mengembalikan "METHOD path" dalam sintaks gin; dipakai untuk mencocokkan dengan gin.Routes()
+func (o Operation) Key() string {
+	return o.Method + " " + o.Path
+}
+
+var ginParam = regexp.MustCompile(`[:*]([A-Za-z_][A-Za-z0-9_]*)`)
+
+// oasPath mengubah "/semester/:id" menjadi "/semester/{id}"
+func oasPath(p string) string {
+	return ginParam.ReplaceAllString(p, "{$1}")
+}
+
+// Info adalah metadata dokumen
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Build menyusun dokumen dari ops. enums memetakan nama field JSON/parameter ke daftar nilai
+// yang diizinkan (mis. "jenjang" -> D3, D4, S1, ...) dan diterapkan ke semua skema.
+func Build(info Info, ops []Operation, enums map[string][]string) *Document {
+	g := newGenerator(enums)
+	errRef := g.ref(apperror.Body{})
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	for _, o := range ops {
+		op := &OperationObject{
+			Tags:        nonEmpty(o.Tag),
+			Summary:     o.Summary,
+			Description: o.Description,
+			OperationID: operationID(o),
+			Responses:   map[string]Response{},
+		}
+		if len(o.Roles) > 0 {
+			op.Security = []map[string][]string{{"bearerAuth": {}}}
+			op.Description = strings.TrimSpace(op.Description + "\n\nRole: " + strings.Join(o.Roles, ", "))
+		}
+		for _, p := range o.PathParams {
+			p.Required = true
+			op.Parameters = append(op.Parameters, g.param("path", p))
+		}
+		for _, p := range o.Query {
+			op.Parameters = append(op.Parameters, g.param("query", p))
+		}
+		if o.Body != nil {
+			op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+				"application/json": {Schema: g.ref(o.Body)},
+			}}
+		}
+		if o.FileField != "" {
+			form := &Schema{
+				Type:       "object",
+				Required:   []string{o.FileField},
+				Properties: map[string]*Schema{o.FileField: {Type: "string", Format: "binary"}},
+			}
+			for _, p := range o.Form {
+				form.Properties[p.Name] = &Schema{Type: cmp.Or(p.Type, "string"), Enum: p.Enum, Description: p.Description}
+				if p.Required {
+					form.Required = append(form.Required, p.Name)
+				}
+			}
+			op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+				"multipart/form-data": {Schema: form},
+			}}
+		}
+
+		status := o.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		ct := o.ContentType
+		if ct == "" {
+			ct = "application/json"
+		}
+		success := Response{Description: http.StatusText(status)}
+		if s := g.envelope(o); s != nil {
+			success.Content = map[string]MediaType{ct: {Schema: s}}
+		}
+		op.Responses[itoa(status)] = success
+		for _, code := range errorStatuses(o) {
+			op.Responses[itoa(code)] = Response{
+				Description: http.StatusText(code),
+				Content:     map[string]MediaType{"application/json": {Schema: errRef}},
+			}
+		}
+
+		path := oasPath(o.Path)
+		item := doc.Paths[path]
+		if item == nil {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(o.Method)] = op
+	}
+	return doc
+}
+
+// errorStatuses memilih status error yang mungkin dari bentuk operasi
+func errorStatuses(o Operation) []int {
+	codes := []int{http.StatusInternalServerError}
+	if len(o.Query) > 0 || len(o.PathParams) > 0 || o.Body != nil || o.FileField != "" {
+		codes = append(codes, http.StatusBadRequest)
+	}
+	if len(o.Roles) > 0 {
+		codes = append(codes, http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests)
+	}
+	if len(o.PathParams) > 0 {
+		codes = append(codes, http.StatusNotFound)
+	}
+	switch o.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		codes = append(codes, http.StatusConflict, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity)
+	case http.MethodDelete:
+		codes = append(codes, http.StatusConflict)
+	}
+	sort.Ints(codes)
+	return codes
+}
+
+func (g *generator) envelope(o Operation) *Schema {
+	if o.Response == nil {
+		return nil
+	}
+	data := g.ref(o.Response)
+	switch o.Envelope {
+	case EnvelopeRaw:
+		return data
+	case EnvelopeList:
+		return object(map[string]*Schema{"data": {Type: "array", Items: data}, "meta": g.ref(PageMeta{})}, "data", "meta")
+	case EnvelopeKeyset:
+		return object(map[string]*Schema{"data": {Type: "array", Items: data}, "meta": g.ref(KeysetMeta{})}, "data", "meta")
+	case EnvelopeMessage:
+		return object(map[string]*Schema{
+			"code":    {Type: "string", Description: "kode pesan stabil, mis. created"},
+			"message": {Type: "string", Description: "pesan sesuai Accept-Language"},
+			"data":    data,
+		}, "code", "message", "data")
+	default:
+		return object(map[string]*Schema{"data": data}, "data")
+	}
+}
+
+// PageMeta mendokumentasikan meta pagination offset (lihat handler admin)
+type PageMeta struct {
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// KeysetMeta mendokumentasikan meta pagination keyset
+type KeysetMeta struct {
+	PerPage    int     `json:"per_page"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+func object(props map[string]*Schema, required ...string) *Schema {
+	return &Schema{Type: "object", Properties: props, Required: required}
+}
+
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+
+// operationID: "GET /api/v1/semester/:id" -> "getApiV1SemesterId"
+func operationID(o Operation) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(o.Method))
+	for _, part := range strings.FieldsFunc(o.Path, func(r rune) bool { return r == '/' || r == ':' || r == '*' || r == '_' || r == '.' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,200 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Document adalah dokumen OpenAPI 3.0 (hanya bagian yang dipakai API ini)
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// PathItem memetakan method (huruf kecil) ke operasi
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []ParameterObject     `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type ParameterObject struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// generator menurunkan skema dari tipe Go; struct bernama didaftarkan ke components.schemas
+type generator struct {
+	enums   map[string][]string
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newGenerator(enums map[string][]string) *generator {
+	return &generator{enums: enums, schemas: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ref mengembalikan $ref untuk struct bernama, atau skema inline untuk tipe lain
+func (g *generator) ref(v any) *Schema {
+	return g.schema(reflect.TypeOf(v), "")
+}
+
+func (g *generator) schema(t reflect.Type, field string) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t, nullable = t.Elem(), true
+	}
+	var s *Schema
+	switch {
+	case t == timeType:
+		s = &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		s = &Schema{Ref: "#/components/schemas/" + g.component(t)}
+		if nullable {
+			// $ref tidak boleh bersaudara dengan nullable di OAS 3.0; cukup tandai opsional di parent
+			nullable = false
+		}
+	case t.Kind() == reflect.Struct:
+		s = g.object(t)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		s = &Schema{Type: "array", Items: g.schema(t.Elem(), field)}
+	case t.Kind() == reflect.Map:
+		s = &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem(), "")}
+	case t.Kind() == reflect.String:
+		s = &Schema{Type: "string", Enum: g.enums[field]}
+	case t.Kind() == reflect.Bool:
+		s = &Schema{Type: "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		s = &Schema{Type: "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s = &Schema{Type: "number"}
+	default:
+		s = &Schema{}
+	}
+	s.Nullable = nullable
+	return s
+}
+
+// component mendaftarkan struct bernama; nama skema = nama tipe dengan huruf awal kapital
+func (g *generator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+	for i := 2; ; i++ {
+		if _, taken := g.schemas[name]; !taken {
+			break
+		}
+		name = strings.ToUpper(t.Name()[:1]) + t.Name()[1:] + strconv.Itoa(i)
+	}
+	g.names[t] = name
+	g.schemas[name] = &Schema{} // placeholder untuk tipe rekursif
+	*g.schemas[name] = *g.object(t)
+	return name
+}
+
+// object: field tanpa pointer dan tanpa omitempty dianggap wajib. Tag format:"date" menambah format;
+// tag enum:"A,B" mengganti enum global untuk field bernama sama.
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			// struct tersemat: field-nya dipromosikan ke level ini seperti encoding/json
+			embedded := g.object(f.Type)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		ps := g.schema(f.Type, name)
+		if format := f.Tag.Get("format"); format != "" {
+			ps.Format = format
+		}
+		if enum := f.Tag.Get("enum"); enum != "" {
+			ps.Enum = strings.Split(enum, ",")
+		}
+		s.Properties[name] = ps
+		if f.Type.Kind() != reflect.Pointer && !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+func (g *generator) param(in string, p Param) ParameterObject {
+	s := &Schema{Type: p.Type, Enum: p.Enum}
+	if s.Type == "" {
+		s.Type = "string"
+	}
+	return ParameterObject{Name: p.Name, In: in, Description: p.Description, Required: p.Required, Schema: s}
+}
+
+func itoa(n int) string {
+	return strconv.Itoa(n)
+}
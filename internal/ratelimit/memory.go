@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"pencatatan-data-mahasiswa/internal/config"
+)
+
+// sweepEvery: bucket yang sudah penuh kembali dibuang secara berkala agar map tidak tumbuh terus
+const sweepEvery = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	rule   config.RateLimitRule
+}
+
+// MemoryStore menyimpan bucket di memori proses; batas tidak dibagi antar instance
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, now: time.Now}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, rule config.RateLimitRule) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) > sweepEvery {
+		s.sweep(now)
+	}
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rule.Burst), last: now, rule: rule}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(rule.Burst), b.tokens+now.Sub(b.last).Seconds()*rule.Rate)
+	b.last, b.rule = now, rule
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return result(allowed, b.tokens, rule), nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	for k, b := range s.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rule.Rate >= float64(b.rule.Burst) {
+			delete(s.buckets, k)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/config"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	"pencatatan-data-mahasiswa/internal/metrics"
+)
+
+var errTooManyRequests = apperror.New(apperror.ErrTooManyRequests, i18n.MsgTooManyRequests)
+
+// Limiter memilih aturan per kelompok route dan menulis header RateLimit-*
+type Limiter struct {
+	store Store
+	cfg   config.RateLimitConfig
+}
+
+func New(store Store, cfg config.RateLimitConfig) *Limiter {
+	return &Limiter{store: store, cfg: cfg}
+}
+
+// Auth untuk route login/register: selalu dikunci per IP karena belum ada token
+func (l *Limiter) Auth() gin.HandlerFunc {
+	return l.handler(func(*gin.Context) (string, config.RateLimitRule) { return "auth", l.cfg.Auth })
+}
+
+// API untuk route terproteksi: dipasang setelah RequireAuth agar dikunci per user id.
+// GET/HEAD memakai aturan read, method lain aturan write.
+func (l *Limiter) API() gin.HandlerFunc {
+	return l.handler(func(c *gin.Context) (string, config.RateLimitRule) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			return "read", l.cfg.Read
+		}
+		return "write", l.cfg.Write
+	})
+}
+
+// handler: pick mengembalikan kelas aturan (auth|read|write) dan aturannya; kelas ikut menjadi
+// bagian key sehingga tiap kelas punya bucket sendiri
+func (l *Limiter) handler(pick func(*gin.Context) (string, config.RateLimitRule)) gin.HandlerFunc {
+	if !l.cfg.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return func(c *gin.Context) {
+		class, rule := pick(c)
+		key := class + ":" + clientKey(c)
+
+		res, err := l.store.Take(c.Request.Context(), key, rule)
+		if err != nil {
+			// store bermasalah (mis. database sibuk): lebih baik melayani daripada menolak semua request
+			slog.WarnContext(c.Request.Context(), "rate limit store failed, allowing request", "error", err)
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("RateLimit-Reset", ceilSeconds(res.Reset))
+		h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", rule.Burst, ceilSeconds(time.Duration(float64(rule.Burst)/rule.Rate*float64(time.Second)))))
+		if !res.Allowed {
+			h.Set("Retry-After", ceilSeconds(res.RetryIn))
+			metrics.RateLimited(class)
+			apperror.Abort(c, errTooManyRequests)
+			return
+		}
+		c.Next()
+	}
+}
+
+// clientKey: user id dari klaim JWT (diset RequireAuth) bila ada, selain itu IP client
+func clientKey(c *gin.Context) string {
+	if v, ok := c.Get("user"); ok {
+		if claims, ok := v.(jwt.MapClaims); ok {
+			if uid, ok := claims["user_id"]; ok {
+				return "user:" + fmt.Sprint(uid)
+			}
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
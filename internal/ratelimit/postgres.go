@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"pencatatan-data-mahasiswa/internal/config"
+	"pencatatan-data-mahasiswa/internal/db"
+)
+
+// PostgresStore menyimpan bucket di tabel rate_limit_buckets (UNLOGGED) sehingga semua
+// instance berbagi batas yang sama. Refill dan pengambilan token dilakukan atomik dalam
+// satu UPSERT memakai jam database.
+type PostgresStore struct {
+	pool      *db.Pool
+	lastSweep atomic.Int64
+}
+
+func NewPostgresStore(pool *db.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+// $1 key, $2 burst, $3 rate (token/detik)
+const takeSQL = `
+INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+VALUES ($1, $2::float8 - 1, true, now())
+ON CONFLICT (key) DO UPDATE SET
+    tokens = CASE
+        WHEN LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $3::float8) >= 1
+        THEN LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $3::float8) - 1
+        ELSE LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $3::float8)
+    END,
+    allowed = LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $3::float8) >= 1,
+    updated_at = now()
+RETURNING tokens, allowed`
+
+func (s *PostgresStore) Take(ctx context.Context, key string, rule config.RateLimitRule) (Result, error) {
+	s.maybeSweep()
+	var tokens float64
+	var allowed bool
+	if err := s.pool.QueryRow(ctx, takeSQL, key, rule.Burst, rule.Rate).Scan(&tokens, &allowed); err != nil {
+		return Result{}, err
+	}
+	return result(allowed, tokens, rule), nil
+}
+
+// maybeSweep menghapus bucket yang lama tidak dipakai, paling sering sekali per sweepEvery
+func (s *PostgresStore) maybeSweep() {
+	now := time.Now().UnixNano()
+	last := s.lastSweep.Load()
+	if now-last < int64(sweepEvery) || !s.lastSweep.CompareAndSwap(last, now) {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := s.pool.Exec(ctx, `DELETE FROM rate_limit_buckets WHERE updated_at < now() - interval '1 hour'`); err != nil {
+			slog.Warn("rate limit: sweep stale buckets", "error", err)
+		}
+	}()
+}
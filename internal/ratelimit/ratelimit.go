@@ -0,0 +1,47 @@
+// Package ratelimit membatasi laju request dengan token bucket per kunci (user id dari JWT,
+// atau IP client untuk route anonim). Bucket disimpan di memori atau di PostgreSQL agar
+// batas berlaku bersama untuk semua instance.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"pencatatan-data-mahasiswa/internal/config"
+)
+
+// Result adalah keputusan untuk satu request
+type Result struct {
+	Allowed   bool
+	Limit     int           // kapasitas bucket (burst)
+	Remaining int           // token tersisa setelah request ini
+	Reset     time.Duration // waktu sampai bucket penuh kembali
+	RetryIn   time.Duration // waktu sampai satu token tersedia (hanya bila ditolak)
+}
+
+// Store mengambil satu token dari bucket milik key
+type Store interface {
+	Take(ctx context.Context, key string, rule config.RateLimitRule) (Result, error)
+}
+
+// result menghitung Result dari sisa token setelah keputusan diambil
+func result(allowed bool, tokens float64, rule config.RateLimitRule) Result {
+	r := Result{
+		Allowed:   allowed,
+		Limit:     rule.Burst,
+		Remaining: int(math.Floor(math.Max(tokens, 0))),
+		Reset:     seconds((float64(rule.Burst) - tokens) / rule.Rate),
+	}
+	if !allowed {
+		r.RetryIn = seconds((1 - tokens) / rule.Rate)
+	}
+	return r
+}
+
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,133 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"pencatatan-data-mahasiswa/internal/config"
+)
+
+// fakeClock mengganti jam MemoryStore agar refill bisa diuji tanpa menunggu
+type fakeClock struct{ t time.Time }
+
+func (f *fakeClock) now() time.Time          { return f.t }
+func (f *fakeClock) advance(d time.Duration) { f.t = f.t.Add(d) }
+
+func newTestStore() (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 9, 2, 8, 0, 0, 0, time.UTC)}
+	s := NewMemoryStore()
+	s.now = clock.now
+	return s, clock
+}
+
+// TestMemoryStoreBurst: bucket baru penuh sebanyak Burst, lalu menolak sampai ada refill
+func TestMemoryStoreBurst(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestStore()
+	rule := config.RateLimitRule{Rate: 1, Burst: 3}
+
+	for i, want := range []int{2, 1, 0} {
+		res, err := s.Take(ctx, "auth:ip:10.0.0.1", rule)
+		if err != nil || !res.Allowed || res.Remaining != want || res.Limit != 3 {
+			t.Fatalf("take %d = %+v, %v; want allowed with %d remaining", i+1, res, err, want)
+		}
+	}
+	res, _ := s.Take(ctx, "auth:ip:10.0.0.1", rule)
+	if res.Allowed || res.Remaining != 0 {
+		t.Errorf("take over burst = %+v, want denied", res)
+	}
+	// key lain punya bucket sendiri
+	if res, _ := s.Take(ctx, "auth:ip:10.0.0.2", rule); !res.Allowed {
+		t.Errorf("other key = %+v, want allowed", res)
+	}
+}
+
+// TestMemoryStoreRefill: token terisi ulang sebesar Rate per detik dan tidak melebihi Burst
+func TestMemoryStoreRefill(t *testing.T) {
+	ctx := context.Background()
+	s, clock := newTestStore()
+	rule := config.RateLimitRule{Rate: 2, Burst: 2}
+	key := "write:user:1"
+
+	for range 2 {
+		s.Take(ctx, key, rule)
+	}
+	if res, _ := s.Take(ctx, key, rule); res.Allowed {
+		t.Fatalf("empty bucket = %+v, want denied", res)
+	}
+
+	clock.advance(500 * time.Millisecond) // +1 token
+	if res, _ := s.Take(ctx, key, rule); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("after 0.5s = %+v, want one token", res)
+	}
+	if res, _ := s.Take(ctx, key, rule); res.Allowed {
+		t.Errorf("second take after 0.5s = %+v, want denied", res)
+	}
+
+	clock.advance(time.Hour) // refill dibatasi Burst
+	res, _ := s.Take(ctx, key, rule)
+	if !res.Allowed || res.Remaining != 1 || res.Reset != 500*time.Millisecond {
+		t.Errorf("after 1h = %+v, want 1 remaining and reset 0.5s", res)
+	}
+}
+
+// TestResultRetryIn: waktu tunggu dihitung dari kekurangan token dibagi Rate
+func TestResultRetryIn(t *testing.T) {
+	tests := []struct {
+		name    string
+		tokens  float64
+		rule    config.RateLimitRule
+		allowed bool
+		retry   time.Duration
+		reset   time.Duration
+	}{
+		{"empty", 0, config.RateLimitRule{Rate: 0.5, Burst: 5}, false, 2 * time.Second, 10 * time.Second},
+		{"partial", 0.75, config.RateLimitRule{Rate: 1, Burst: 1}, false, 250 * time.Millisecond, 250 * time.Millisecond},
+		{"allowed", 1, config.RateLimitRule{Rate: 1, Burst: 2}, true, 0, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := result(tt.allowed, tt.tokens, tt.rule)
+			if r.RetryIn != tt.retry || r.Reset != tt.reset {
+				t.Errorf("result = %+v, want retry %v reset %v", r, tt.retry, tt.reset)
+			}
+		})
+	}
+}
+
+// TestAuthRetryAfter: request yang ditolak mendapat 429 dengan Retry-After dibulatkan ke atas,
+// dan X-Forwarded-For dari proxy yang tidak dipercaya tidak membuat bucket baru
+func TestAuthRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s, _ := newTestStore()
+	l := New(s, config.RateLimitConfig{Enabled: true, Auth: config.RateLimitRule{Rate: 0.4, Burst: 1}})
+	r := gin.New()
+	if err := r.SetTrustedProxies(nil); err != nil {
+		t.Fatal(err)
+	}
+	r.POST("/login", l.Auth(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	do := func(xff string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = "203.0.113.7:51000"
+		req.Header.Set("X-Forwarded-For", xff)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	if w := do("198.51.100.1"); w.Code != http.StatusNoContent || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("first = %d %v", w.Code, w.Header())
+	}
+	w := do("198.51.100.2")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("spoofed X-Forwarded-For = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "3" { // 1 / 0.4 = 2.5s
+		t.Errorf("Retry-After = %q, want 3", got)
+	}
+}
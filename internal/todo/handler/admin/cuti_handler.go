@@ -0,0 +1,258 @@
+package admin
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/config"
+	"pencatatan-data-mahasiswa/internal/db"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	authhandler "pencatatan-data-mahasiswa/internal/todo/handler/auth"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+	service "pencatatan-data-mahasiswa/internal/todo/service/admin"
+)
+
+type CutiHandler struct {
+	service     *service.CutiService
+	page        config.PageConfig
+	maxLampiran int64
+}
+
+// NewCutiService dipakai handler dan loop Sync di cmd serve
+func NewCutiService(cfg *config.Config, pool *db.Pool) *service.CutiService {
+	tx := db.NewTxManager(pool)
+	mhs := service.NewMahasiswaService(repo.NewMahasiswaRepository(pool), tx, authhandler.NewService(cfg, pool), clockOf(cfg))
+	return service.NewCutiService(repo.NewCutiRepository(pool), mhs, tx, service.CutiOptions{
+		DefaultMaxSemesters: cfg.Cuti.DefaultMaxSemesters,
+		MaxLampiranBytes:    cfg.Cuti.MaxAttachmentBytes,
+		Clock:               clockOf(cfg),
+	})
+}
+
+func NewCutiHandler(cfg *config.Config, pool *db.Pool) *CutiHandler {
+	return &CutiHandler{service: NewCutiService(cfg, pool), page: cfg.Pagination, maxLampiran: cfg.Cuti.MaxAttachmentBytes}
+}
+
+// Request payloads
+
+// cutiSubmitForm adalah field multipart POST /api/v1/cuti; berkas dikirim pada field "lampiran"
+type cutiSubmitForm struct {
+	IDSemester string `form:"id_semester" json:"id_semester"`
+	Alasan     string `form:"alasan" json:"alasan"`
+}
+
+type cutiReviewRequest struct {
+	Catatan *string `json:"catatan"`
+}
+
+type cutiPolicyRequest struct {
+	MaksSemester *int `json:"maks_semester"`
+}
+
+// numericID membaca parameter :id numerik
+func numericID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		apperror.Respond(c, invalidParam("id", codeInteger))
+		return 0, false
+	}
+	return id, true
+}
+
+// List: GET /api/v1/cuti
+// Mahasiswa hanya melihat pengajuannya, operator dengan ref_id hanya prodinya
+func (h *CutiHandler) List(c *gin.Context) {
+	filters := filterParams(c)
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		apperror.Respond(c, invalidParam("page", codeMin1))
+		return
+	}
+	perPage, err := strconv.Atoi(c.DefaultQuery("per_page", strconv.Itoa(h.page.DefaultPageSize)))
+	if err != nil || perPage < 1 {
+		apperror.Respond(c, invalidParam("per_page", codeMin1))
+		return
+	}
+	if perPage > h.page.MaxPageSize {
+		perPage = h.page.MaxPageSize
+	}
+
+	allowedCols := map[string]string{
+		"created_at":   "c.created_at",
+		"updated_at":   "c.updated_at",
+		"id_semester":  "c.id_semester",
+		"id_mahasiswa": "c.id_mahasiswa",
+		"status":       "c.status",
+	}
+	col, ok := allowedCols[strings.ToLower(strings.TrimSpace(c.DefaultQuery("sort_by", "created_at")))]
+	if !ok {
+		col = "c.created_at"
+	}
+	dir := "DESC"
+	if strings.EqualFold(strings.TrimSpace(c.Query("sort_dir")), "asc") {
+		dir = "ASC"
+	}
+
+	data, total, err := h.service.List(c.Request.Context(), filters, perPage, (page-1)*perPage, col+" "+dir, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	meta := newPageMeta(page, perPage, total)
+	setLinkHeader(c, meta, false)
+	c.JSON(http.StatusOK, gin.H{"data": data, "meta": meta})
+}
+
+// Get: GET /api/v1/cuti/:id
+func (h *CutiHandler) Get(c *gin.Context) {
+	id, ok := numericID(c)
+	if !ok {
+		return
+	}
+	out, err := h.service.Get(c.Request.Context(), id, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": out})
+}
+
+// Lampiran: GET /api/v1/cuti/:id/lampiran
+func (h *CutiHandler) Lampiran(c *gin.Context) {
+	id, ok := numericID(c)
+	if !ok {
+		return
+	}
+	out, err := h.service.Lampiran(c.Request.Context(), id, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": out.Nama}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, out.Tipe, out.Data)
+}
+
+// Submit: POST /api/v1/cuti (multipart: id_semester, alasan, lampiran)
+func (h *CutiHandler) Submit(c *gin.Context) {
+	var form cutiSubmitForm
+	if err := c.ShouldBind(&form); err != nil {
+		apperror.Respond(c, bindError(err))
+		return
+	}
+	file, err := c.FormFile("lampiran")
+	if apperror.TooLarge(err) {
+		apperror.Respond(c, err)
+		return
+	}
+	if err != nil {
+		apperror.Respond(c, &apperror.Error{Kind: apperror.ErrInvalidInput, Code: i18n.MsgCutiLampiranRequired,
+			Fields: map[string]string{"lampiran": apperror.CodeRequired}})
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		apperror.Respond(c, invalidParam("lampiran", "unreadable"))
+		return
+	}
+	defer f.Close()
+	// baca paling banyak satu byte di atas batas; service yang menolak lampiran terlalu besar
+	data, err := io.ReadAll(io.LimitReader(f, h.maxLampiran+1))
+	if err != nil {
+		apperror.Respond(c, invalidParam("lampiran", "unreadable"))
+		return
+	}
+
+	out, err := h.service.Submit(c.Request.Context(), service.CutiSubmission{
+		IDSemester:   form.IDSemester,
+		Alasan:       form.Alasan,
+		LampiranNama: file.Filename,
+		Lampiran:     data,
+	}, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, success(c, i18n.MsgCreated, out))
+}
+
+// Cancel: POST /api/v1/cuti/:id/cancel
+func (h *CutiHandler) Cancel(c *gin.Context) {
+	id, ok := numericID(c)
+	if !ok {
+		return
+	}
+	out, err := h.service.Cancel(c.Request.Context(), id, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, success(c, i18n.MsgCutiCancelled, out))
+}
+
+// Approve: POST /api/v1/cuti/:id/approve
+func (h *CutiHandler) Approve(c *gin.Context) {
+	h.review(c, true, i18n.MsgCutiApproved)
+}
+
+// Reject: POST /api/v1/cuti/:id/reject (catatan wajib)
+func (h *CutiHandler) Reject(c *gin.Context) {
+	h.review(c, false, i18n.MsgCutiRejected)
+}
+
+func (h *CutiHandler) review(c *gin.Context, approve bool, msg string) {
+	id, ok := numericID(c)
+	if !ok {
+		return
+	}
+	var req cutiReviewRequest
+	// body opsional untuk approve
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apperror.Respond(c, bindError(err))
+			return
+		}
+	}
+	out, err := h.service.Review(c.Request.Context(), id, approve, req.Catatan, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, success(c, msg, out))
+}
+
+// Policy: GET /api/v1/prodi/:id/cuti-policy
+func (h *CutiHandler) Policy(c *gin.Context) {
+	out, err := h.service.Policy(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": out})
+}
+
+// SetPolicy: PUT /api/v1/prodi/:id/cuti-policy
+func (h *CutiHandler) SetPolicy(c *gin.Context) {
+	var req cutiPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, bindError(err))
+		return
+	}
+	if req.MaksSemester == nil {
+		apperror.Respond(c, invalidParam("maks_semester", apperror.CodeRequired))
+		return
+	}
+	out, err := h.service.SetPolicy(c.Request.Context(), c.Param("id"), *req.MaksSemester, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
+}
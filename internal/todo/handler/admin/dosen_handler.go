@@ -1,19 +1,21 @@
 package admin
 
 import (
-    "errors"
     "net/http"
     "strconv"
     "strings"
 
     "github.com/gin-gonic/gin"
-    "github.com/jackc/pgx/v5"
 
+    "pencatatan-data-mahasiswa/internal/apperror"
     "pencatatan-data-mahasiswa/internal/config"
+    "pencatatan-data-mahasiswa/internal/i18n"
     "pencatatan-data-mahasiswa/internal/db"
+    authhandler "pencatatan-data-mahasiswa/internal/todo/handler/auth"
     model "pencatatan-data-mahasiswa/internal/todo/model/admin"
     repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
     service "pencatatan-data-mahasiswa/internal/todo/service/admin"
+    authsvc "pencatatan-data-mahasiswa/internal/todo/service/auth"
 )
 
 type DosenHandler struct {
@@ -22,7 +24,7 @@ type DosenHandler struct {
 
 func NewDosenHandler(cfg *config.Config, pool *db.Pool) *DosenHandler {
     r := repo.NewDosenRepository((*db.Pool)(pool))
-    s := service.NewDosenService(r)
+    s := service.NewDosenService(r, db.NewTxManager(pool), authhandler.NewService(cfg, pool))
     return &DosenHandler{service: s}
 }
 
@@ -35,6 +37,8 @@ type dosenCreateRequest struct {
     Email           *string `json:"email"`
     NoHP            *string `json:"no_hp"`
     JabatanAkademik *string `json:"jabatan_akademik"`
+    // CreateAccount membuat akun login (username = id_dosen) dalam transaksi yang sama: password atau activation
+    CreateAccount   string  `json:"create_account"`
 }
 
 type dosenPutRequest struct {
@@ -54,19 +58,23 @@ type dosenPatchRequest struct {
 }
 
 // List: GET /api/v1/dosen
+// Parameter match=exact|prefix|fuzzy menentukan mode pencarian nama (default exact)
+// Filter memakai grammar field=[op:]value, mis. nidn=is:null&created_at=gte:2024-01-01
 func (h *DosenHandler) List(c *gin.Context) {
     q := strings.TrimSpace(c.Query("q"))
+    match := strings.TrimSpace(c.Query("match"))
+    filters := filterParams(c)
 
     limitStr := c.DefaultQuery("limit", "20")
     offsetStr := c.DefaultQuery("offset", "0")
     limit, err := strconv.Atoi(limitStr)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+        apperror.Respond(c, invalidParam("limit", codeInteger))
         return
     }
     offset, err := strconv.Atoi(offsetStr)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+        apperror.Respond(c, invalidParam("offset", codeInteger))
         return
     }
 
@@ -90,16 +98,14 @@ func (h *DosenHandler) List(c *gin.Context) {
     }
     orderBy := col + " " + dir
 
-    data, err := h.service.List(c.Request.Context(), q, limit, offset, orderBy)
+    data, total, err := h.service.List(c.Request.Context(), q, match, filters, limit, offset, orderBy)
     if err != nil {
-        if err.Error() == "invalid input" {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+        apperror.Respond(c, err)
         return
     }
-    c.JSON(http.StatusOK, gin.H{"data": data})
+    meta := newPageMeta(pageFromOffset(limit, offset), limit, total)
+    setLinkHeader(c, meta, true)
+    c.JSON(http.StatusOK, gin.H{"data": data, "meta": meta})
 }
 
 // Get: GET /api/v1/dosen/:id
@@ -107,11 +113,7 @@ func (h *DosenHandler) Get(c *gin.Context) {
     id := c.Param("id")
     out, err := h.service.Get(c.Request.Context(), id)
     if err != nil {
-        if err.Error() == "invalid input" {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        }
-        c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+        apperror.Respond(c, err)
         return
     }
     c.JSON(http.StatusOK, gin.H{"data": out})
@@ -121,7 +123,7 @@ func (h *DosenHandler) Get(c *gin.Context) {
 func (h *DosenHandler) Create(c *gin.Context) {
     var req dosenCreateRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        apperror.Respond(c, bindError(err))
         return
     }
     d := &model.Dosen{
@@ -134,22 +136,18 @@ func (h *DosenHandler) Create(c *gin.Context) {
     if req.IDDosen != nil {
         d.IDDosen = *req.IDDosen
     }
+    mode, err := authsvc.ParseAccountMode(req.CreateAccount)
+    if err != nil {
+        apperror.Respond(c, err)
+        return
+    }
 
-    out, err := h.service.Create(c.Request.Context(), d)
+    out, acc, err := h.service.CreateWithAccount(c.Request.Context(), d, mode)
     if err != nil {
-        switch err.Error() {
-        case "invalid input":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        case "conflict":
-            c.JSON(http.StatusConflict, gin.H{"error": "duplicate id, nidn, or email"})
-            return
-        default:
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-            return
-        }
-    }
-    c.JSON(http.StatusCreated, gin.H{"message": "created", "data": out})
+        apperror.Respond(c, err)
+        return
+    }
+    c.JSON(http.StatusCreated, createdWithAccount(c, out, acc))
 }
 
 // UpdatePut: PUT /api/v1/dosen/:id
@@ -157,7 +155,7 @@ func (h *DosenHandler) UpdatePut(c *gin.Context) {
     id := c.Param("id")
     var req dosenPutRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        apperror.Respond(c, bindError(err))
         return
     }
     d := &model.Dosen{
@@ -170,23 +168,10 @@ func (h *DosenHandler) UpdatePut(c *gin.Context) {
 
     out, err := h.service.UpdatePut(c.Request.Context(), id, d)
     if err != nil {
-        switch err.Error() {
-        case "invalid input":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        case "conflict":
-            c.JSON(http.StatusConflict, gin.H{"error": "duplicate nidn or email"})
-            return
-        default:
-            if errors.Is(err, pgx.ErrNoRows) {
-                c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-                return
-            }
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-            return
-        }
-    }
-    c.JSON(http.StatusOK, gin.H{"message": "updated", "data": out})
+        apperror.Respond(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
 }
 
 // UpdatePatch: PATCH /api/v1/dosen/:id
@@ -194,50 +179,24 @@ func (h *DosenHandler) UpdatePatch(c *gin.Context) {
     id := c.Param("id")
     var req dosenPatchRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        apperror.Respond(c, bindError(err))
         return
     }
 
     out, err := h.service.UpdatePatch(c.Request.Context(), id, req.NIDN, req.NamaDosen, req.Email, req.NoHP, req.JabatanAkademik)
     if err != nil {
-        switch err.Error() {
-        case "invalid input":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        case "conflict":
-            c.JSON(http.StatusConflict, gin.H{"error": "duplicate nidn or email"})
-            return
-        default:
-            if errors.Is(err, pgx.ErrNoRows) {
-                c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-                return
-            }
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-            return
-        }
-    }
-    c.JSON(http.StatusOK, gin.H{"message": "updated", "data": out})
+        apperror.Respond(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
 }
 
 // Delete: DELETE /api/v1/dosen/:id
 func (h *DosenHandler) Delete(c *gin.Context) {
     id := c.Param("id")
     if err := h.service.Delete(c.Request.Context(), id); err != nil {
-        switch err.Error() {
-        case "invalid input":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        case "conflict":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "cannot delete: related mata_kuliah or kelas_kuliah exists"})
-            return
-        default:
-            if errors.Is(err, pgx.ErrNoRows) {
-                c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-                return
-            }
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-            return
-        }
-    }
-    c.JSON(http.StatusOK, gin.H{"message": "deleted", "data": gin.H{"id_dosen": id}})
+        apperror.Respond(c, err)
+        return
+    }
+    c.JSON(http.StatusOK, success(c, i18n.MsgDeleted, gin.H{"id_dosen": id}))
 }
\ No newline at end of file
@@ -1,15 +1,15 @@
 package admin
 
 import (
-    "errors"
     "net/http"
     "strconv"
     "strings"
 
     "github.com/gin-gonic/gin"
-    "github.com/jackc/pgx/v5"
 
+    "pencatatan-data-mahasiswa/internal/apperror"
     "pencatatan-data-mahasiswa/internal/config"
+    "pencatatan-data-mahasiswa/internal/i18n"
     "pencatatan-data-mahasiswa/internal/db"
     model "pencatatan-data-mahasiswa/internal/todo/model/admin"
     repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
@@ -22,7 +22,7 @@ type Handler struct {
 
 func NewHandler(cfg *config.Config, pool *db.Pool) *Handler {
     r := repo.NewFakultasRepository(pool)
-    s := service.NewService(r)
+    s := service.NewService(r, db.NewTxManager(pool))
     return &Handler{service: s}
 }
 
@@ -39,32 +39,32 @@ type updateRequest struct {
 }
 
 // List: GET /api/v1/fakultas?search=...&limit=..&offset=..
+// Filter memakai grammar field=[op:]value, mis. singkatan=is:notnull&created_at=gte:2024-01-01
 func (h *Handler) List(c *gin.Context) {
     search := strings.TrimSpace(c.Query("search"))
+    filters := filterParams(c)
     limitStr := c.DefaultQuery("limit", "20")
     offsetStr := c.DefaultQuery("offset", "0")
 
     limit, err := strconv.Atoi(limitStr)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+        apperror.Respond(c, invalidParam("limit", codeInteger))
         return
     }
     offset, err := strconv.Atoi(offsetStr)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+        apperror.Respond(c, invalidParam("offset", codeInteger))
         return
     }
 
-    data, err := h.service.List(c.Request.Context(), search, limit, offset)
+    data, total, err := h.service.List(c.Request.Context(), search, filters, limit, offset)
     if err != nil {
-        if err.Error() == "invalid input" {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+        apperror.Respond(c, err)
         return
     }
-    c.JSON(http.StatusOK, gin.H{"data": data})
+    meta := newPageMeta(pageFromOffset(limit, offset), limit, total)
+    setLinkHeader(c, meta, true)
+    c.JSON(http.StatusOK, gin.H{"data": data, "meta": meta})
 }
 
 // Get: GET /api/v1/fakultas/:id
@@ -72,12 +72,7 @@ func (h *Handler) Get(c *gin.Context) {
     id := c.Param("id")
     f, err := h.service.Get(c.Request.Context(), id)
     if err != nil {
-        if err.Error() == "invalid input" {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        }
-        // treat not found
-        c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+        apperror.Respond(c, err)
         return
     }
     c.JSON(http.StatusOK, gin.H{"data": f})
@@ -87,25 +82,16 @@ func (h *Handler) Get(c *gin.Context) {
 func (h *Handler) Create(c *gin.Context) {
     var req createRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        apperror.Respond(c, bindError(err))
         return
     }
     f := &model.Fakultas{NamaFakultas: req.NamaFakultas, Singkatan: req.Singkatan}
     out, err := h.service.Create(c.Request.Context(), f)
     if err != nil {
-        switch err.Error() {
-        case "invalid input":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        case "conflict":
-            c.JSON(http.StatusConflict, gin.H{"error": "duplicate id or name"})
-            return
-        default:
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-            return
-        }
+        apperror.Respond(c, err)
+        return
     }
-    c.JSON(http.StatusCreated, gin.H{"message": "created", "data": out})
+    c.JSON(http.StatusCreated, success(c, i18n.MsgCreated, out))
 }
 
 // Update: PUT /api/v1/fakultas/:id
@@ -113,49 +99,23 @@ func (h *Handler) Update(c *gin.Context) {
     id := c.Param("id")
     var req updateRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        apperror.Respond(c, bindError(err))
         return
     }
     out, err := h.service.Update(c.Request.Context(), id, req.NamaFakultas, req.Singkatan)
     if err != nil {
-        switch err.Error() {
-        case "invalid input":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        case "conflict":
-            c.JSON(http.StatusConflict, gin.H{"error": "duplicate name"})
-            return
-        default:
-            if errors.Is(err, pgx.ErrNoRows) {
-                c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-                return
-            }
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-            return
-        }
+        apperror.Respond(c, err)
+        return
     }
-    c.JSON(http.StatusOK, gin.H{"message": "updated", "data": out})
+    c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
 }
 
 // Delete: DELETE /api/v1/fakultas/:id
 func (h *Handler) Delete(c *gin.Context) {
     id := c.Param("id")
     if err := h.service.Delete(c.Request.Context(), id); err != nil {
-        switch err.Error() {
-        case "invalid input":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        case "conflict":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "cannot delete: related prodi exists"})
-            return
-        default:
-            if errors.Is(err, pgx.ErrNoRows) {
-                c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-                return
-            }
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-            return
-        }
+        apperror.Respond(c, err)
+        return
     }
-    c.JSON(http.StatusOK, gin.H{"message": "deleted", "data": gin.H{"id_fakultas": id}})
+    c.JSON(http.StatusOK, success(c, i18n.MsgDeleted, gin.H{"id_fakultas": id}))
 }
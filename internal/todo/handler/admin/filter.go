@@ -0,0 +1,24 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// listParams adalah parameter query list yang bukan filter (paginasi, urutan, pencarian)
+var listParams = map[string]bool{
+	"page": true, "per_page": true, "limit": true, "offset": true, "cursor": true,
+	"sort_by": true, "sort_dir": true, "q": true, "search": true, "match": true,
+}
+
+// filterParams mengambil semua parameter query selain listParams (boleh berulang). Nama yang
+// tidak ada di whitelist ikut diteruskan agar ParseFilters melaporkannya sebagai unknown_field,
+// bukan diabaikan diam-diam.
+func filterParams(c *gin.Context) map[string][]string {
+	out := map[string][]string{}
+	for name, vals := range c.Request.URL.Query() {
+		if !listParams[name] {
+			out[name] = vals
+		}
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/config"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+	"pencatatan-data-mahasiswa/internal/todo/repository/memory"
+	service "pencatatan-data-mahasiswa/internal/todo/service/admin"
+)
+
+// TestListUnknownFilter: filter yang salah ketik atau tidak didukung dilaporkan per field,
+// sedangkan parameter paginasi/urutan tidak dianggap filter
+func TestListUnknownFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := memory.NewStore()
+	h := &KurikulumHandler{
+		service: service.NewKurikulumService(memory.NewKurikulumRepository(s), s),
+		page:    config.Defaults().Pagination,
+	}
+	r := gin.New()
+	r.GET("/kurikulum", h.List)
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		fields map[string]string
+	}{
+		{"known filter with paging", "?aktif=true&page=1&per_page=5&sort_by=tahun&sort_dir=asc", http.StatusOK, nil},
+		{"misspelled", "?statsu=in:Aktif", http.StatusBadRequest, map[string]string{"statsu": repo.CodeUnknownField}},
+		{"mixed", "?tahun=dua&hobi=catur", http.StatusBadRequest, map[string]string{"tahun": repo.CodeInvalidInteger, "hobi": repo.CodeUnknownField}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/kurikulum"+tt.query, nil))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body %s", w.Code, tt.status, w.Body)
+			}
+			if tt.fields == nil {
+				return
+			}
+			var body apperror.Body
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range tt.fields {
+				if body.Fields[k] != v {
+					t.Errorf("fields = %v, want %s: %s", body.Fields, k, v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/config"
+	"pencatatan-data-mahasiswa/internal/db"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+	service "pencatatan-data-mahasiswa/internal/todo/service/admin"
+)
+
+type KRSHandler struct {
+	service *service.KRSService
+	page    config.PageConfig
+}
+
+func NewKRSHandler(cfg *config.Config, pool *db.Pool) *KRSHandler {
+	s := service.NewKRSService(repo.NewKRSRepository(pool), repo.NewKurikulumRepository(pool),
+		repo.NewPembimbingRepository(pool), repo.NewMahasiswaRepository(pool), repo.NewCutiRepository(pool), db.NewTxManager(pool), clockOf(cfg))
+	return &KRSHandler{service: s, page: cfg.Pagination}
+}
+
+// Request payloads
+
+type krsReplaceRequest struct {
+	// Kelas adalah id kelas_kuliah semester tersebut; daftar kosong mengosongkan KRS
+	Kelas []string `json:"kelas"`
+}
+
+type krsReviewRequest struct {
+	Catatan *string `json:"catatan"`
+}
+
+// Get: GET /api/v1/krs/:semester (KRS mahasiswa yang login)
+func (h *KRSHandler) Get(c *gin.Context) {
+	out, err := h.service.Get(c.Request.Context(), c.Param("semester"), currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": out})
+}
+
+// Replace: PUT /api/v1/krs/:semester
+func (h *KRSHandler) Replace(c *gin.Context) {
+	var req krsReplaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, bindError(err))
+		return
+	}
+	if req.Kelas == nil {
+		apperror.Respond(c, invalidParam("kelas", apperror.CodeRequired))
+		return
+	}
+	out, err := h.service.Replace(c.Request.Context(), c.Param("semester"), req.Kelas, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
+}
+
+// Submit: POST /api/v1/krs/:semester/submit
+func (h *KRSHandler) Submit(c *gin.Context) {
+	out, err := h.service.Submit(c.Request.Context(), c.Param("semester"), currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, success(c, i18n.MsgKRSSubmitted, out))
+}
+
+// ListPengajuan: GET /api/v1/bimbingan/krs (pengajuan KRS untuk PA yang login)
+func (h *KRSHandler) ListPengajuan(c *gin.Context) {
+	filters := filterParams(c)
+	page, perPage, ok := pageParams(c, h.page)
+	if !ok {
+		return
+	}
+	orderBy := sortParam(c, map[string]string{
+		"diajukan_at":  "p.diajukan_at",
+		"updated_at":   "p.updated_at",
+		"id_mahasiswa": "p.id_mahasiswa",
+		"id_semester":  "p.id_semester",
+		"status":       "p.status",
+	}, "diajukan_at", "DESC")
+
+	data, total, err := h.service.ListPengajuan(c.Request.Context(), filters, perPage, (page-1)*perPage, orderBy, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	meta := newPageMeta(page, perPage, total)
+	setLinkHeader(c, meta, false)
+	c.JSON(http.StatusOK, gin.H{"data": data, "meta": meta})
+}
+
+// GetPengajuan: GET /api/v1/bimbingan/krs/:id
+func (h *KRSHandler) GetPengajuan(c *gin.Context) {
+	id, ok := numericID(c)
+	if !ok {
+		return
+	}
+	out, err := h.service.GetPengajuan(c.Request.Context(), id, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": out})
+}
+
+// Approve: POST /api/v1/bimbingan/krs/:id/approve
+func (h *KRSHandler) Approve(c *gin.Context) {
+	h.review(c, true, i18n.MsgKRSApproved)
+}
+
+// Return: POST /api/v1/bimbingan/krs/:id/return (catatan wajib)
+func (h *KRSHandler) Return(c *gin.Context) {
+	h.review(c, false, i18n.MsgKRSReturned)
+}
+
+func (h *KRSHandler) review(c *gin.Context, approve bool, msg string) {
+	id, ok := numericID(c)
+	if !ok {
+		return
+	}
+	var req krsReviewRequest
+	// body opsional untuk approve
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			apperror.Respond(c, bindError(err))
+			return
+		}
+	}
+	out, err := h.service.Review(c.Request.Context(), id, approve, req.Catatan, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, success(c, msg, out))
+}
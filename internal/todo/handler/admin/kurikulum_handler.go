@@ -0,0 +1,209 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/config"
+	"pencatatan-data-mahasiswa/internal/db"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+	service "pencatatan-data-mahasiswa/internal/todo/service/admin"
+)
+
+type KurikulumHandler struct {
+	service *service.KurikulumService
+	page    config.PageConfig
+}
+
+func NewKurikulumHandler(cfg *config.Config, pool *db.Pool) *KurikulumHandler {
+	s := service.NewKurikulumService(repo.NewKurikulumRepository(pool), db.NewTxManager(pool))
+	return &KurikulumHandler{service: s, page: cfg.Pagination}
+}
+
+// Request payloads
+
+type kurikulumCreateRequest struct {
+	IDProdi string `json:"id_prodi"`
+	Tahun   int    `json:"tahun"`
+	Nama    string `json:"nama"`
+	// Aktif menjadikan kurikulum ini satu-satunya kurikulum aktif prodi
+	Aktif bool `json:"aktif"`
+}
+
+type kurikulumPutRequest struct {
+	Tahun int    `json:"tahun"`
+	Nama  string `json:"nama"`
+	Aktif bool   `json:"aktif"`
+}
+
+type kurikulumMKItem struct {
+	IDMK     string `json:"id_mk"`
+	Semester int    `json:"semester"`                   // semester anjuran 1-14
+	Jenis    string `json:"jenis" enum:"Wajib,Pilihan"` // default Wajib
+}
+
+type kurikulumMKRequest struct {
+	// MataKuliah mengganti seluruh daftar; daftar kosong mengosongkan kurikulum
+	MataKuliah []kurikulumMKItem `json:"mata_kuliah"`
+}
+
+type prasyaratItem struct {
+	IDMKPrasyarat string `json:"id_mk_prasyarat"`
+	NilaiMinimal  string `json:"nilai_minimal" enum:"A,AB,B,BC,C,D,E"` // default C
+}
+
+type prasyaratRequest struct {
+	// Prasyarat mengganti seluruh prasyarat; daftar kosong menghapus semuanya
+	Prasyarat []prasyaratItem `json:"prasyarat"`
+}
+
+// List: GET /api/v1/kurikulum
+func (h *KurikulumHandler) List(c *gin.Context) {
+	filters := filterParams(c)
+	page, perPage, ok := pageParams(c, h.page)
+	if !ok {
+		return
+	}
+	orderBy := sortParam(c, map[string]string{
+		"tahun":      "k.tahun",
+		"id_prodi":   "k.id_prodi",
+		"nama":       "k.nama",
+		"created_at": "k.created_at",
+	}, "tahun", "DESC")
+
+	data, total, err := h.service.List(c.Request.Context(), filters, perPage, (page-1)*perPage, orderBy)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	meta := newPageMeta(page, perPage, total)
+	setLinkHeader(c, meta, false)
+	c.JSON(http.StatusOK, gin.H{"data": data, "meta": meta})
+}
+
+// Get: GET /api/v1/kurikulum/:id (beserta mata kuliah dan prasyaratnya)
+func (h *KurikulumHandler) Get(c *gin.Context) {
+	id, ok := numericID(c)
+	if !ok {
+		return
+	}
+	out, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": out})
+}
+
+// Create: POST /api/v1/kurikulum
+func (h *KurikulumHandler) Create(c *gin.Context) {
+	var req kurikulumCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, bindError(err))
+		return
+	}
+	k := &model.Kurikulum{IDProdi: req.IDProdi, Tahun: req.Tahun, Nama: req.Nama, Aktif: req.Aktif}
+	out, err := h.service.Create(c.Request.Context(), k, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, success(c, i18n.MsgCreated, out))
+}
+
+// Update: PUT /api/v1/kurikulum/:id
+func (h *KurikulumHandler) Update(c *gin.Context) {
+	id, ok := numericID(c)
+	if !ok {
+		return
+	}
+	var req kurikulumPutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, bindError(err))
+		return
+	}
+	k := &model.Kurikulum{Tahun: req.Tahun, Nama: req.Nama, Aktif: req.Aktif}
+	out, err := h.service.Update(c.Request.Context(), id, k, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
+}
+
+// Delete: DELETE /api/v1/kurikulum/:id
+func (h *KurikulumHandler) Delete(c *gin.Context) {
+	id, ok := numericID(c)
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), id, currentActor(c)); err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, success(c, i18n.MsgDeleted, gin.H{"id_kurikulum": id}))
+}
+
+// ReplaceMataKuliah: PUT /api/v1/kurikulum/:id/mata-kuliah
+func (h *KurikulumHandler) ReplaceMataKuliah(c *gin.Context) {
+	id, ok := numericID(c)
+	if !ok {
+		return
+	}
+	var req kurikulumMKRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, bindError(err))
+		return
+	}
+	if req.MataKuliah == nil {
+		apperror.Respond(c, invalidParam("mata_kuliah", apperror.CodeRequired))
+		return
+	}
+	items := make([]model.KurikulumMK, len(req.MataKuliah))
+	for i, m := range req.MataKuliah {
+		items[i] = model.KurikulumMK{IDMK: m.IDMK, Semester: m.Semester, Jenis: m.Jenis}
+	}
+	out, err := h.service.ReplaceMataKuliah(c.Request.Context(), id, items, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
+}
+
+// Prasyarat: GET /api/v1/mata-kuliah/:id/prasyarat
+func (h *KurikulumHandler) Prasyarat(c *gin.Context) {
+	out, err := h.service.Prasyarat(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": out})
+}
+
+// ReplacePrasyarat: PUT /api/v1/mata-kuliah/:id/prasyarat
+func (h *KurikulumHandler) ReplacePrasyarat(c *gin.Context) {
+	var req prasyaratRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, bindError(err))
+		return
+	}
+	if req.Prasyarat == nil {
+		apperror.Respond(c, invalidParam("prasyarat", apperror.CodeRequired))
+		return
+	}
+	items := make([]model.Prasyarat, len(req.Prasyarat))
+	for i, p := range req.Prasyarat {
+		items[i] = model.Prasyarat{IDMKPrasyarat: p.IDMKPrasyarat, NilaiMinimal: p.NilaiMinimal}
+	}
+	out, err := h.service.ReplacePrasyarat(c.Request.Context(), c.Param("id"), items, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
+}
@@ -1,28 +1,36 @@
 package admin
 
 import (
+	"bufio"
+	"encoding/csv"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"pencatatan-data-mahasiswa/internal/apperror"
 	"pencatatan-data-mahasiswa/internal/config"
 	"pencatatan-data-mahasiswa/internal/db"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	"pencatatan-data-mahasiswa/internal/metrics"
+	authhandler "pencatatan-data-mahasiswa/internal/todo/handler/auth"
 	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
 	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
 	service "pencatatan-data-mahasiswa/internal/todo/service/admin"
+	authsvc "pencatatan-data-mahasiswa/internal/todo/service/auth"
 )
 
 type MahasiswaHandler struct {
 	service *service.MahasiswaService
+	page    config.PageConfig
 }
 
 func NewMahasiswaHandler(cfg *config.Config, pool *db.Pool) *MahasiswaHandler {
 	r := repo.NewMahasiswaRepository(pool)
-	s := service.NewMahasiswaService(r)
-	return &MahasiswaHandler{service: s}
+	s := service.NewMahasiswaService(r, db.NewTxManager(pool), authhandler.NewService(cfg, pool), clockOf(cfg))
+	return &MahasiswaHandler{service: s, page: cfg.Pagination}
 }
 
 // Request payloads
@@ -34,12 +42,14 @@ type mhsCreateRequest struct {
 	NamaLengkap  string  `json:"nama_lengkap"`
 	JenisKelamin string  `json:"jenis_kelamin"`
 	TempatLahir  *string `json:"tempat_lahir"`
-	TanggalLahir *string `json:"tanggal_lahir"` // format YYYY-MM-DD
+	TanggalLahir *string `json:"tanggal_lahir" format:"date"` // format YYYY-MM-DD
 	Alamat       *string `json:"alamat"`
 	Email        *string `json:"email"`
 	NoHP         *string `json:"no_hp"`
 	TahunMasuk   int     `json:"tahun_masuk"`
 	Status       *string `json:"status"`
+	// CreateAccount membuat akun login (username = NIM) dalam transaksi yang sama: password atau activation
+	CreateAccount string `json:"create_account"`
 }
 
 type mhsPutRequest struct {
@@ -48,12 +58,13 @@ type mhsPutRequest struct {
 	NamaLengkap  string  `json:"nama_lengkap"`
 	JenisKelamin string  `json:"jenis_kelamin"`
 	TempatLahir  *string `json:"tempat_lahir"`
-	TanggalLahir *string `json:"tanggal_lahir"`
+	TanggalLahir *string `json:"tanggal_lahir" format:"date"`
 	Alamat       *string `json:"alamat"`
 	Email        *string `json:"email"`
 	NoHP         *string `json:"no_hp"`
 	TahunMasuk   int     `json:"tahun_masuk"`
-	Status       *string `json:"status"`
+	// Status opsional; bila dikirim harus sama dengan status saat ini (ubah lewat POST /:id/status)
+	Status *string `json:"status"`
 }
 
 type mhsPatchRequest struct {
@@ -62,54 +73,46 @@ type mhsPatchRequest struct {
 	NamaLengkap  *string `json:"nama_lengkap"`
 	JenisKelamin *string `json:"jenis_kelamin"`
 	TempatLahir  *string `json:"tempat_lahir"`
-	TanggalLahir *string `json:"tanggal_lahir"`
+	TanggalLahir *string `json:"tanggal_lahir" format:"date"`
 	Alamat       *string `json:"alamat"`
 	Email        *string `json:"email"`
 	NoHP         *string `json:"no_hp"`
 	TahunMasuk   *int    `json:"tahun_masuk"`
-	Status       *string `json:"status"`
+	Status       *string `json:"status"` // hanya boleh sama dengan status saat ini
+}
+
+type mhsStatusRequest struct {
+	Status         string  `json:"status"`
+	TanggalEfektif *string `json:"tanggal_efektif" format:"date"` // YYYY-MM-DD, default hari ini
+	NomorSK        *string `json:"nomor_sk"`
+	Alasan         string  `json:"alasan"`
+	Override       bool    `json:"override"`
 }
 
 // List: GET /api/v1/mahasiswa
+// Mendukung pagination page/per_page (OFFSET) atau cursor (keyset) via ?cursor=...
+// Parameter match=exact|prefix|fuzzy menentukan mode pencarian nama (default exact)
+// Filter memakai grammar field=[op:]value, mis. status=in:Aktif,Cuti&angkatan=gte:2020&email=is:null
 func (h *MahasiswaHandler) List(c *gin.Context) {
 	q := strings.TrimSpace(c.Query("q"))
-	idProdi := strings.TrimSpace(c.Query("id_prodi"))
-	angkatanStr := strings.TrimSpace(c.Query("angkatan"))
-	status := strings.TrimSpace(c.Query("status"))
-
-	var idProdiPtr *string
-	if idProdi != "" {
-		idProdiPtr = &idProdi
-	}
-	var angkatanPtr *int
-	if angkatanStr != "" {
-		if v, err := strconv.Atoi(angkatanStr); err == nil {
-			angkatanPtr = &v
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "fields": gin.H{"angkatan": "must be integer"}})
-			return
-		}
-	}
-	var statusPtr *string
-	if status != "" {
-		statusPtr = &status
-	}
+	match := strings.TrimSpace(c.Query("match"))
+	filters := filterParams(c)
 
-	// pagination via page & per_page (cap 100)
+	// pagination via page & per_page (cap pagination.max_page_size)
 	pageStr := c.DefaultQuery("page", "1")
-	perPageStr := c.DefaultQuery("per_page", "20")
+	perPageStr := c.DefaultQuery("per_page", strconv.Itoa(h.page.DefaultPageSize))
 	page, err := strconv.Atoi(pageStr)
 	if err != nil || page < 1 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "fields": gin.H{"page": "must be >= 1"}})
+		apperror.Respond(c, invalidParam("page", codeMin1))
 		return
 	}
 	perPage, err := strconv.Atoi(perPageStr)
 	if err != nil || perPage < 1 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "fields": gin.H{"per_page": "must be >= 1"}})
+		apperror.Respond(c, invalidParam("per_page", codeMin1))
 		return
 	}
-	if perPage > 100 {
-		perPage = 100
+	if perPage > h.page.MaxPageSize {
+		perPage = h.page.MaxPageSize
 	}
 	limit := perPage
 	offset := (page - 1) * perPage
@@ -133,16 +136,35 @@ func (h *MahasiswaHandler) List(c *gin.Context) {
 	}
 	orderBy := col + " " + dir
 
-	data, err := h.service.List(c.Request.Context(), q, idProdiPtr, angkatanPtr, statusPtr, limit, offset, orderBy)
-	if err != nil {
-		if err.Error() == "invalid input" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
+	// keyset pagination: aktif bila parameter cursor dikirim (kosong = halaman pertama)
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		data, next, err := h.service.ListKeyset(c.Request.Context(), q, match, filters, perPage, col, dir == "DESC", cursor)
+		if err != nil {
+			apperror.Respond(c, err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error"})
+		var nextPtr *string
+		if next != "" {
+			nextPtr = &next
+			u := *c.Request.URL
+			qs := u.Query()
+			qs.Set("cursor", next)
+			qs.Del("page")
+			u.RawQuery = qs.Encode()
+			c.Header("Link", "<"+u.RequestURI()+">; rel=\"next\"")
+		}
+		c.JSON(http.StatusOK, gin.H{"data": data, "meta": gin.H{"per_page": perPage, "next_cursor": nextPtr}})
+		return
+	}
+
+	data, total, err := h.service.List(c.Request.Context(), q, match, filters, limit, offset, orderBy)
+	if err != nil {
+		apperror.Respond(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": data})
+	meta := newPageMeta(page, perPage, total)
+	setLinkHeader(c, meta, false)
+	c.JSON(http.StatusOK, gin.H{"data": data, "meta": meta})
 }
 
 // Get: GET /api/v1/mahasiswa/:id
@@ -150,11 +172,7 @@ func (h *MahasiswaHandler) Get(c *gin.Context) {
 	id := c.Param("id")
 	out, err := h.service.Get(c.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "invalid input" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
-			return
-		}
-		c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
+		apperror.Respond(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"data": out})
@@ -164,18 +182,20 @@ func (h *MahasiswaHandler) Get(c *gin.Context) {
 func (h *MahasiswaHandler) Create(c *gin.Context) {
 	var req mhsCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
+		apperror.Respond(c, bindError(err))
 		return
 	}
 
-	var tglPtr *time.Time
-	if req.TanggalLahir != nil && strings.TrimSpace(*req.TanggalLahir) != "" {
-		t, err := time.Parse("2006-01-02", strings.TrimSpace(*req.TanggalLahir))
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "fields": gin.H{"tanggal_lahir": "invalid date format (YYYY-MM-DD)"}})
-			return
-		}
-		tglPtr = &t
+	fe := apperror.Fields{}
+	tglPtr := parseDateField(fe, "tanggal_lahir", req.TanggalLahir)
+	if err := fe.Err(); err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	mode, err := authsvc.ParseAccountMode(req.CreateAccount)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
 	}
 
 	m := &model.Mahasiswa{
@@ -195,24 +215,12 @@ func (h *MahasiswaHandler) Create(c *gin.Context) {
 		m.Status = strings.TrimSpace(*req.Status)
 	}
 
-	out, err := h.service.Create(c.Request.Context(), m)
+	out, acc, err := h.service.CreateWithAccount(c.Request.Context(), m, mode)
 	if err != nil {
-		switch err.Error() {
-		case "invalid input":
-			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
-			return
-		case "unprocessable":
-			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "unprocessable", "fields": gin.H{"id_prodi": "not found"}})
-			return
-		case "conflict":
-			c.JSON(http.StatusConflict, gin.H{"error": "conflict"})
-			return
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error"})
-			return
-		}
+		apperror.Respond(c, err)
+		return
 	}
-	c.JSON(http.StatusCreated, gin.H{"message": "created", "data": out})
+	c.JSON(http.StatusCreated, createdWithAccount(c, out, acc))
 }
 
 // UpdatePut: PUT /api/v1/mahasiswa/:id
@@ -220,18 +228,15 @@ func (h *MahasiswaHandler) UpdatePut(c *gin.Context) {
 	id := c.Param("id")
 	var req mhsPutRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
+		apperror.Respond(c, bindError(err))
 		return
 	}
 
-	var tglPtr *time.Time
-	if req.TanggalLahir != nil && strings.TrimSpace(*req.TanggalLahir) != "" {
-		t, err := time.Parse("2006-01-02", strings.TrimSpace(*req.TanggalLahir))
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "fields": gin.H{"tanggal_lahir": "invalid date format (YYYY-MM-DD)"}})
-			return
-		}
-		tglPtr = &t
+	fe := apperror.Fields{}
+	tglPtr := parseDateField(fe, "tanggal_lahir", req.TanggalLahir)
+	if err := fe.Err(); err != nil {
+		apperror.Respond(c, err)
+		return
 	}
 
 	m := &model.Mahasiswa{
@@ -252,22 +257,10 @@ func (h *MahasiswaHandler) UpdatePut(c *gin.Context) {
 
 	out, err := h.service.UpdatePut(c.Request.Context(), id, m)
 	if err != nil {
-		switch err.Error() {
-		case "invalid input":
-			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
-			return
-		case "unprocessable":
-			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "unprocessable", "fields": gin.H{"id_prodi": "not found"}})
-			return
-		case "conflict":
-			c.JSON(http.StatusConflict, gin.H{"error": "conflict"})
-			return
-		default:
-			c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
-			return
-		}
+		apperror.Respond(c, err)
+		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "updated", "data": out})
+	c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
 }
 
 // UpdatePatch: PATCH /api/v1/mahasiswa/:id
@@ -275,55 +268,248 @@ func (h *MahasiswaHandler) UpdatePatch(c *gin.Context) {
 	id := c.Param("id")
 	var req mhsPatchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
+		apperror.Respond(c, bindError(err))
 		return
 	}
 
-	var tglPtr *time.Time
-	if req.TanggalLahir != nil && strings.TrimSpace(*req.TanggalLahir) != "" {
-		t, err := time.Parse("2006-01-02", strings.TrimSpace(*req.TanggalLahir))
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "fields": gin.H{"tanggal_lahir": "invalid date format (YYYY-MM-DD)"}})
-			return
-		}
-		tglPtr = &t
+	fe := apperror.Fields{}
+	tglPtr := parseDateField(fe, "tanggal_lahir", req.TanggalLahir)
+	if err := fe.Err(); err != nil {
+		apperror.Respond(c, err)
+		return
 	}
 
 	out, err := h.service.UpdatePatch(c.Request.Context(), id, req.IDProdi, req.NIK, req.NamaLengkap, req.JenisKelamin, req.TempatLahir, req.Alamat, req.Email, req.NoHP, req.Status, tglPtr, req.TahunMasuk)
 	if err != nil {
-		switch err.Error() {
-		case "invalid input":
-			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
-			return
-		case "unprocessable":
-			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "unprocessable", "fields": gin.H{"id_prodi": "not found"}})
-			return
-		case "conflict":
-			c.JSON(http.StatusConflict, gin.H{"error": "conflict"})
-			return
-		default:
-			c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
-			return
-		}
+		apperror.Respond(c, err)
+		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "updated", "data": out})
+	c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
 }
 
 // Delete: DELETE /api/v1/mahasiswa/:id
 func (h *MahasiswaHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 	if err := h.service.Delete(c.Request.Context(), id); err != nil {
-		switch err.Error() {
-		case "invalid input":
-			c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
-			return
-		case "conflict":
-			c.JSON(http.StatusConflict, gin.H{"error": "conflict"})
-			return
-		default:
-			c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, success(c, i18n.MsgDeleted, gin.H{"id_mahasiswa": id}))
+}
+
+// Transition: POST /api/v1/mahasiswa/:id/status
+// Satu-satunya cara mengubah status; transisi dan pelakunya dicatat ke riwayat
+func (h *MahasiswaHandler) Transition(c *gin.Context) {
+	id := c.Param("id")
+	var req mhsStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, bindError(err))
+		return
+	}
+
+	fe := apperror.Fields{}
+	tgl := parseDateField(fe, "tanggal_efektif", req.TanggalEfektif)
+	if err := fe.Err(); err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	in := service.StatusTransition{Status: req.Status, NomorSK: req.NomorSK, Alasan: req.Alasan, Override: req.Override}
+	if tgl != nil {
+		in.TanggalEfektif = *tgl
+	}
+	out, err := h.service.Transition(c.Request.Context(), id, in, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, success(c, i18n.MsgStatusChanged, out))
+}
+
+// StatusHistory: GET /api/v1/mahasiswa/:id/status-history
+func (h *MahasiswaHandler) StatusHistory(c *gin.Context) {
+	out, err := h.service.StatusHistory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": out})
+}
+
+// mhsCSVHeader adalah urutan kolom wajib CSV import mahasiswa
+var mhsCSVHeader = []string{"id_mahasiswa", "id_prodi", "nik", "nama_lengkap", "jenis_kelamin", "tempat_lahir",
+	"tanggal_lahir", "alamat", "email", "no_hp", "tahun_masuk", "status"}
+
+// errDuplicateNIM: NIM yang sama muncul lebih dari sekali dalam satu file
+var errDuplicateNIM = &apperror.Error{Kind: apperror.ErrInvalidInput, Code: i18n.MsgCSVDuplicateRow,
+	Fields: map[string]string{"id_mahasiswa": "duplicate"}}
+
+// codeImportAktifOnly: import hanya membuat mahasiswa Aktif; status lain lewat endpoint transisi
+// agar tercatat di riwayat beserta SK-nya
+const codeImportAktifOnly = "aktif_only"
+
+// mhsFromCSV membangun mahasiswa dari satu baris CSV; kolom kosong berarti NULL (status kosong = Aktif)
+func mhsFromCSV(rec []string) (*model.Mahasiswa, error) {
+	opt := func(i int) *string {
+		v := strings.TrimSpace(rec[i])
+		if v == "" {
+			return nil
+		}
+		return &v
+	}
+	fe := apperror.Fields{}
+	tgl := parseDateField(fe, "tanggal_lahir", opt(6))
+	tahun, err := strconv.Atoi(strings.TrimSpace(rec[10]))
+	if err != nil {
+		fe.Add("tahun_masuk", codeInteger)
+	}
+	status := strings.TrimSpace(rec[11])
+	if status != "" && status != "Aktif" {
+		fe.Add("status", codeImportAktifOnly)
+	}
+	if err := fe.Err(); err != nil {
+		return nil, err
+	}
+	return &model.Mahasiswa{
+		IDMahasiswa:  strings.TrimSpace(rec[0]),
+		IDProdi:      strings.TrimSpace(rec[1]),
+		NIK:          opt(2),
+		NamaLengkap:  strings.TrimSpace(rec[3]),
+		JenisKelamin: strings.TrimSpace(rec[4]),
+		TempatLahir:  opt(5),
+		TanggalLahir: tgl,
+		Alamat:       opt(7),
+		Email:        opt(8),
+		NoHP:         opt(9),
+		TahunMasuk:   tahun,
+		Status:       status,
+	}, nil
+}
+
+// ImportCSV: POST /api/v1/mahasiswa/import?dry_run=true&create_account=password|activation
+// Header CSV sesuai mhsCSVHeader. Setiap baris disimpan dalam transaksinya sendiri bersama akun
+// login-nya (bila create_account diisi), jadi baris yang gagal tidak membatalkan baris lain.
+func (h *MahasiswaHandler) ImportCSV(c *gin.Context) {
+	dryRun := strings.ToLower(c.DefaultQuery("dry_run", "true")) == "true"
+	mode, err := authsvc.ParseAccountMode(c.Query("create_account"))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if apperror.TooLarge(err) {
+		apperror.Respond(c, err)
+		return
+	}
+	if err != nil {
+		apperror.Respond(c, invalidFile(i18n.MsgCSVFileRequired, apperror.CodeRequired))
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		apperror.Respond(c, invalidFile(i18n.MsgCSVFileUnreadable, "unreadable"))
+		return
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(bufio.NewReader(f))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1 // jumlah kolom diperiksa per baris agar error-nya per baris
+
+	header, err := reader.Read()
+	if err != nil || len(header) < len(mhsCSVHeader) {
+		apperror.Respond(c, invalidFile(i18n.MsgMahasiswaCSVHeaderInvalid, "invalid_header"))
+		return
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff") // BOM dari Excel
+	for i, name := range mhsCSVHeader {
+		if !strings.EqualFold(strings.TrimSpace(header[i]), name) {
+			apperror.Respond(c, invalidFile(i18n.MsgMahasiswaCSVHeaderInvalid, "invalid_header"))
 			return
 		}
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "deleted", "data": gin.H{"id_mahasiswa": id}})
+
+	lang := i18n.Lang(c)
+	newRowError := func(line int, rec []string, err error) rowError {
+		_, body := apperror.Resolve(err, lang)
+		return rowError{Line: line, Record: rec, Code: body.Code, Error: body.Message, Fields: body.Fields}
+	}
+
+	var (
+		ctx        = c.Request.Context()
+		errorsList []rowError
+		accounts   []*authsvc.Account
+		seen       = map[string]bool{}
+		invalid    int
+		valid      int
+		line       = 1 // header = baris 1
+	)
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			invalid++
+			errorsList = append(errorsList, newRowError(line, rec, apperror.New(apperror.ErrInvalidInput, i18n.MsgCSVRowInvalid)))
+			continue
+		}
+		if len(rec) < len(mhsCSVHeader) {
+			invalid++
+			errorsList = append(errorsList, newRowError(line, rec, apperror.New(apperror.ErrInvalidInput, i18n.MsgCSVRowColumns)))
+			continue
+		}
+		m, err := mhsFromCSV(rec)
+		if err == nil && seen[strings.ToUpper(m.IDMahasiswa)] {
+			err = errDuplicateNIM
+		}
+		if err != nil {
+			invalid++
+			errorsList = append(errorsList, newRowError(line, rec, err))
+			continue
+		}
+		seen[strings.ToUpper(m.IDMahasiswa)] = true
+
+		if dryRun {
+			err = h.service.ValidateCreate(ctx, m, mode)
+		} else {
+			var acc *authsvc.Account
+			_, acc, err = h.service.CreateWithAccount(ctx, m, mode)
+			if acc != nil {
+				accounts = append(accounts, acc)
+			}
+		}
+		if err != nil {
+			errorsList = append(errorsList, newRowError(line, rec, err))
+			continue
+		}
+		valid++
+	}
+
+	c.Header("Content-Language", lang)
+	if dryRun {
+		c.JSON(http.StatusOK, gin.H{
+			"dry_run":      true,
+			"total_rows":   valid + len(errorsList),
+			"valid_rows":   valid,
+			"invalid_rows": len(errorsList),
+			"errors":       errorsList,
+		})
+		return
+	}
+
+	metrics.CSVImportRows("mahasiswa", "imported", valid)
+	metrics.CSVImportRows("mahasiswa", "invalid", invalid)
+	metrics.CSVImportRows("mahasiswa", "failed", len(errorsList)-invalid)
+	if accounts == nil {
+		accounts = []*authsvc.Account{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run":  false,
+		"imported": valid,
+		"failed":   len(errorsList),
+		"errors":   errorsList,
+		"accounts": accounts,
+	})
 }
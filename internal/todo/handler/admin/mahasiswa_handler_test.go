@@ -0,0 +1,26 @@
+package admin
+
+import (
+	"strings"
+	"testing"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+)
+
+// TestMhsFromCSVStatus: import hanya membuat mahasiswa Aktif; status lain ditolak per baris
+func TestMhsFromCSVStatus(t *testing.T) {
+	row := func(status string) []string {
+		return strings.Split("202400000002,PRD00001,,Budi Santoso,L,,,,,,2024,"+status, ",")
+	}
+	for _, status := range []string{"", "Aktif"} {
+		m, err := mhsFromCSV(row(status))
+		if err != nil || m.Status != status {
+			t.Errorf("status %q: %+v, %v", status, m, err)
+		}
+	}
+	for _, status := range []string{"Lulus", "Drop Out", "Cuti", "Non-Aktif"} {
+		if _, err := mhsFromCSV(row(status)); apperror.FieldsOf(err)["status"] != codeImportAktifOnly {
+			t.Errorf("status %q: error = %v, want status %s", status, err, codeImportAktifOnly)
+		}
+	}
+}
@@ -0,0 +1,440 @@
+package admin
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"pencatatan-data-mahasiswa/internal/openapi"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+	service "pencatatan-data-mahasiswa/internal/todo/service/admin"
+	authsvc "pencatatan-data-mahasiswa/internal/todo/service/auth"
+)
+
+// roleAdminOperator adalah role untuk seluruh route admin (lihat router); baca semester terbuka untuk semua role
+var (
+	roleAdminOperator = []string{"admin", "operator"}
+	roleAll           = []string{"admin", "operator", "dosen", "mahasiswa"}
+	roleCutiRead      = []string{"admin", "operator", "mahasiswa"}
+	roleMahasiswa     = []string{"mahasiswa"}
+	roleDosen         = []string{"dosen"}
+)
+
+// semesterImportResult mendokumentasikan response POST /semester/import (dry run atau tidak)
+type semesterImportResult struct {
+	DryRun      bool       `json:"dry_run"`
+	TotalRows   *int       `json:"total_rows"`   // hanya dry run
+	ValidRows   *int       `json:"valid_rows"`   // hanya dry run
+	InvalidRows *int       `json:"invalid_rows"` // hanya dry run
+	Imported    *int       `json:"imported"`     // hanya import sebenarnya
+	Failed      *int       `json:"failed"`       // hanya import sebenarnya
+	Errors      []rowError `json:"errors"`
+}
+
+// mahasiswaImportResult mendokumentasikan response POST /mahasiswa/import
+type mahasiswaImportResult struct {
+	semesterImportResult
+	Accounts []authsvc.Account `json:"accounts"` // hanya import sebenarnya dengan create_account
+}
+
+// mahasiswaCreated dan dosenCreated: response POST, account hanya ada bila create_account diisi
+type mahasiswaCreated struct {
+	Code    string           `json:"code"`
+	Message string           `json:"message"`
+	Data    model.Mahasiswa  `json:"data"`
+	Account *authsvc.Account `json:"account,omitempty"`
+}
+
+type dosenCreated struct {
+	Code    string           `json:"code"`
+	Message string           `json:"message"`
+	Data    model.Dosen      `json:"data"`
+	Account *authsvc.Account `json:"account,omitempty"`
+}
+
+// createAccountDoc menjelaskan field create_account pada POST mahasiswa/dosen
+const createAccountDoc = "create_account=password membuat akun dengan password awal acak, create_account=activation " +
+	"membuat akun dengan token aktivasi; keduanya wajib ganti password sebelum memakai route lain. " +
+	"Kredensial awal hanya dikembalikan sekali di field account."
+
+// Operations mendeskripsikan route admin untuk dokumen OpenAPI; Body/Response memakai
+// struct request dan model yang sama dengan handler
+func Operations() []openapi.Operation {
+	var ops []openapi.Operation
+	ops = append(ops, crud(crudDoc{
+		tag: "fakultas", base: "/api/v1/fakultas", idName: "id_fakultas", roles: roleAdminOperator,
+		model: model.Fakultas{}, create: createRequest{}, put: updateRequest{},
+		list: append(offsetQuery(), search("search")), filters: repo.FakultasFilterFields,
+	})...)
+	ops = append(ops, crud(crudDoc{
+		tag: "prodi", base: "/api/v1/prodi", idName: "id_prodi", roles: roleAdminOperator,
+		model: model.Prodi{}, create: prodiCreateRequest{}, put: prodiPutRequest{}, patch: prodiPatchRequest{},
+		list:    append(append(offsetQuery(), search("q")), sortQuery("nama_prodi", "kode_prodi", "jenjang", "akreditasi", "created_at", "updated_at")...),
+		filters: repo.ProdiFilterFields,
+	})...)
+	ops = append(ops, crud(crudDoc{
+		tag: "dosen", base: "/api/v1/dosen", idName: "id_dosen", roles: roleAdminOperator,
+		model: model.Dosen{}, create: dosenCreateRequest{}, put: dosenPutRequest{}, patch: dosenPatchRequest{},
+		created: dosenCreated{}, createDoc: "Username akun = id_dosen. " + createAccountDoc,
+		list:    append(append(offsetQuery(), search("q"), matchParam()), sortQuery("nama_dosen", "nidn", "email", "created_at", "updated_at")...),
+		filters: repo.DosenFilterFields,
+	})...)
+	ops = append(ops, crud(crudDoc{
+		tag: "mahasiswa", base: "/api/v1/mahasiswa", idName: "id_mahasiswa", roles: roleAdminOperator,
+		model: model.Mahasiswa{}, create: mhsCreateRequest{}, put: mhsPutRequest{}, patch: mhsPatchRequest{},
+		created: mahasiswaCreated{}, createDoc: "Username akun = NIM. " + createAccountDoc,
+		list: append(append(pageQuery(), search("q"), matchParam(),
+			openapi.Param{Name: "cursor", Description: "Aktifkan keyset pagination; kosong untuk halaman pertama, lalu pakai meta.next_cursor"}),
+			sortQuery("nama_lengkap", "tahun_masuk", "created_at", "updated_at")...),
+		filters: repo.MahasiswaFilterFields,
+		listDoc: "Tanpa parameter cursor memakai pagination page/per_page; dengan cursor response memakai meta keyset {per_page, next_cursor}.",
+	})...)
+
+	ops = append(ops, openapi.Operation{
+		Method: http.MethodPost, Path: "/api/v1/mahasiswa/import", Tag: "mahasiswa", Roles: roleAdminOperator,
+		Summary: "Import mahasiswa dari CSV",
+		Description: "Header CSV: " + strings.Join(mhsCSVHeader, ",") + ". dry_run=true (default) hanya memvalidasi. " +
+			"Setiap baris disimpan bersama akunnya dalam satu transaksi. status harus kosong atau Aktif " +
+			"(status lain lewat endpoint transisi; baris ditolak dengan status: aktif_only). " + createAccountDoc,
+		Query: []openapi.Param{
+			{Name: "dry_run", Type: "boolean", Description: "Default true"},
+			{Name: "create_account", Description: "Kosong berarti tanpa akun", Enum: authsvc.AccountModes()},
+		},
+		FileField: "file",
+		Response:  mahasiswaImportResult{},
+		Envelope:  openapi.EnvelopeRaw,
+	})
+
+	ops = append(ops,
+		openapi.Operation{
+			Method: http.MethodPost, Path: "/api/v1/mahasiswa/:id/status", Tag: "mahasiswa", Roles: roleAdminOperator,
+			Summary: "Ubah status mahasiswa",
+			Description: "Transisi yang diizinkan: " + statusTransitionDoc() + ". Lulus dan Drop Out hanya bisa diubah " +
+				"dengan override=true (khusus admin). nomor_sk wajib untuk Lulus, Drop Out dan override. " +
+				"PUT/PATCH tidak dapat mengubah status. Operator hanya untuk mahasiswa prodi ref_id-nya (tanpa ref_id: 403).",
+			PathParams: []openapi.Param{{Name: "id", Description: "id_mahasiswa"}},
+			Body:       mhsStatusRequest{}, Response: model.MahasiswaStatusChange{}, Envelope: openapi.EnvelopeMessage,
+		},
+		openapi.Operation{
+			Method: http.MethodGet, Path: "/api/v1/mahasiswa/:id/status-history", Tag: "mahasiswa", Roles: roleAdminOperator,
+			Summary: "Riwayat status mahasiswa", Description: "Terbaru lebih dulu (tanggal_efektif, lalu urutan pencatatan).",
+			PathParams: []openapi.Param{{Name: "id", Description: "id_mahasiswa"}},
+			Response:   []model.MahasiswaStatusHistory{}, Envelope: openapi.EnvelopeData,
+		},
+	)
+
+	cutiParam := []openapi.Param{{Name: "id", Type: "integer", Description: "id_cuti"}}
+	ops = append(ops,
+		openapi.Operation{
+			Method: http.MethodGet, Path: "/api/v1/cuti/", Tag: "cuti", Roles: roleCutiRead,
+			Summary: "Daftar pengajuan cuti",
+			Description: "Mahasiswa hanya melihat pengajuannya sendiri, operator hanya prodi ref_id-nya (operator tanpa ref_id: 403). " +
+				"Filter: <field>=[op:]value seperti route daftar lain.",
+			Query: append(append(pageQuery(), sortQuery("created_at", "updated_at", "id_semester", "id_mahasiswa", "status")...),
+				filterQuery(repo.CutiFilterFields)...),
+			Response: model.Cuti{}, Envelope: openapi.EnvelopeList,
+		},
+		openapi.Operation{
+			Method: http.MethodGet, Path: "/api/v1/cuti/:id", Tag: "cuti", Roles: roleCutiRead,
+			Summary: "Detail pengajuan cuti", PathParams: cutiParam, Response: model.Cuti{}, Envelope: openapi.EnvelopeData,
+		},
+		openapi.Operation{
+			Method: http.MethodGet, Path: "/api/v1/cuti/:id/lampiran", Tag: "cuti", Roles: roleCutiRead,
+			Summary: "Unduh lampiran cuti", Description: "Dikirim sebagai attachment dengan tipe asli (PDF, JPEG atau PNG).",
+			PathParams: cutiParam, ContentType: "application/octet-stream", Response: "", Envelope: openapi.EnvelopeRaw,
+		},
+		openapi.Operation{
+			Method: http.MethodPost, Path: "/api/v1/cuti/", Tag: "cuti", Roles: roleMahasiswa,
+			Summary: "Ajukan cuti",
+			Description: "Hanya untuk mahasiswa berstatus Aktif atau Cuti, semester reguler yang belum berakhir, " +
+				"satu pengajuan terbuka per semester dan dalam batas cuti prodi. Lampiran PDF/JPEG/PNG wajib.",
+			FileField: "lampiran",
+			Form: []openapi.Param{
+				{Name: "id_semester", Description: "Semester yang diajukan cuti", Required: true},
+				{Name: "alasan", Description: "10 sampai 1000 karakter", Required: true},
+			},
+			Status: http.StatusCreated, Response: model.Cuti{}, Envelope: openapi.EnvelopeMessage,
+		},
+		openapi.Operation{
+			Method: http.MethodPost, Path: "/api/v1/cuti/:id/cancel", Tag: "cuti", Roles: roleMahasiswa,
+			Summary: "Batalkan pengajuan cuti", Description: "Hanya pengajuan berstatus Diajukan.",
+			PathParams: cutiParam, Response: model.Cuti{}, Envelope: openapi.EnvelopeMessage,
+		},
+		openapi.Operation{
+			Method: http.MethodPost, Path: "/api/v1/cuti/:id/approve", Tag: "cuti", Roles: roleAdminOperator,
+			Summary: "Setujui pengajuan cuti",
+			Description: "Body opsional. Bila semester sudah dimulai status mahasiswa langsung menjadi Cuti; " +
+				"selain itu diterapkan oleh sinkronisasi berkala saat semester dimulai. Ditolak (409) selama mahasiswa masih " +
+				"punya KRS pada semester cuti.",
+			PathParams: cutiParam, Body: cutiReviewRequest{}, Response: model.Cuti{}, Envelope: openapi.EnvelopeMessage,
+		},
+		openapi.Operation{
+			Method: http.MethodPost, Path: "/api/v1/cuti/:id/reject", Tag: "cuti", Roles: roleAdminOperator,
+			Summary: "Tolak pengajuan cuti", Description: "catatan wajib.",
+			PathParams: cutiParam, Body: cutiReviewRequest{}, Response: model.Cuti{}, Envelope: openapi.EnvelopeMessage,
+		},
+		openapi.Operation{
+			Method: http.MethodGet, Path: "/api/v1/prodi/:id/cuti-policy", Tag: "prodi", Roles: roleAdminOperator,
+			Summary: "Batas cuti prodi", Description: "default=true berarti prodi memakai cuti.default_max_semesters dari konfigurasi.",
+			PathParams: []openapi.Param{{Name: "id", Description: "id_prodi"}}, Response: model.CutiPolicy{}, Envelope: openapi.EnvelopeData,
+		},
+		openapi.Operation{
+			Method: http.MethodPut, Path: "/api/v1/prodi/:id/cuti-policy", Tag: "prodi", Roles: roleAdminOperator,
+			Summary: "Ubah batas cuti prodi", Description: "maks_semester 0 sampai 14; operator hanya untuk prodi ref_id-nya (tanpa ref_id: 403).",
+			PathParams: []openapi.Param{{Name: "id", Description: "id_prodi"}},
+			Body:       cutiPolicyRequest{}, Response: model.CutiPolicy{}, Envelope: openapi.EnvelopeMessage,
+		},
+	)
+
+	nimParam := []openapi.Param{{Name: "id", Description: "id_mahasiswa"}}
+	dosenParam := []openapi.Param{{Name: "id", Description: "id_dosen"}}
+	bimbinganQuery := append(append(pageQuery(), sortQuery("id_mahasiswa", "nama_lengkap", "angkatan", "status", "ipk")...),
+		filterQuery(repo.BimbinganFilterFields)...)
+	ops = append(ops,
+		openapi.Operation{
+			Method: http.MethodPut, Path: "/api/v1/mahasiswa/:id/pembimbing", Tag: "pembimbing", Roles: roleAdminOperator,
+			Summary: "Tetapkan dosen pembimbing akademik",
+			Description: "PA lama diakhiri hari ini dan tetap tercatat di riwayat; KRS yang masih menunggu persetujuan " +
+				"dipindahkan ke PA baru. Tidak untuk mahasiswa Lulus/Drop Out.",
+			PathParams: nimParam, Body: pembimbingRequest{}, Response: model.PembimbingAkademik{}, Envelope: openapi.EnvelopeMessage,
+		},
+		openapi.Operation{
+			Method: http.MethodGet, Path: "/api/v1/mahasiswa/:id/pembimbing-history", Tag: "pembimbing", Roles: roleAdminOperator,
+			Summary: "Riwayat pembimbing akademik", Description: "Terbaru lebih dulu; tanggal_selesai kosong berarti PA saat ini.",
+			PathParams: nimParam, Response: []model.PembimbingAkademik{}, Envelope: openapi.EnvelopeData,
+		},
+		openapi.Operation{
+			Method: http.MethodPost, Path: "/api/v1/dosen/:id/bimbingan", Tag: "pembimbing", Roles: roleAdminOperator,
+			Summary: "Tetapkan PA massal per prodi/angkatan",
+			Description: "Semua mahasiswa prodi/angkatan yang belum Lulus atau Drop Out mendapat dosen ini sebagai PA dalam satu " +
+				"transaksi. hanya_tanpa_pembimbing=true melewati mahasiswa yang sudah punya PA.",
+			PathParams: dosenParam, Body: bimbinganBulkRequest{}, Response: model.BimbinganAssignment{}, Envelope: openapi.EnvelopeMessage,
+		},
+		openapi.Operation{
+			Method: http.MethodGet, Path: "/api/v1/dosen/:id/bimbingan", Tag: "pembimbing", Roles: roleAdminOperator,
+			Summary: "Daftar mahasiswa bimbingan dosen", Description: "IPK dihitung dari nilai terbaik per mata kuliah pada KRS yang disetujui.",
+			PathParams: dosenParam, Query: bimbinganQuery, Response: model.Bimbingan{}, Envelope: openapi.EnvelopeList,
+		},
+		openapi.Operation{
+			Method: http.MethodGet, Path: "/api/v1/bimbingan/", Tag: "pembimbing", Roles: roleDosen,
+			Summary: "Daftar mahasiswa bimbingan saya", Description: "Mahasiswa bimbingan aktif dosen yang login beserta IPK dan status.",
+			Query: bimbinganQuery, Response: model.Bimbingan{}, Envelope: openapi.EnvelopeList,
+		},
+	)
+
+	krsParam := []openapi.Param{{Name: "semester", Description: "id_semester"}}
+	pengajuanParam := []openapi.Param{{Name: "id", Type: "integer", Description: "id_pengajuan"}}
+	ops = append(ops,
+		openapi.Operation{
+			Method: http.MethodGet, Path: "/api/v1/krs/:semester", Tag: "krs", Roles: roleMahasiswa,
+			Summary: "KRS saya", Description: "status Draft berarti KRS belum pernah diajukan.",
+			PathParams: krsParam, Response: model.KRS{}, Envelope: openapi.EnvelopeData,
+		},
+		openapi.Operation{
+			Method: http.MethodPut, Path: "/api/v1/krs/:semester", Tag: "krs", Roles: roleMahasiswa,
+			Summary: "Ganti kelas KRS",
+			Description: "Hanya mahasiswa Aktif, atau Cuti untuk semester setelah semester berjalan, dan tanpa cuti yang disetujui " +
+				"pada semester tersebut; untuk KRS berstatus Draft atau Dikembalikan. Semua kelas harus kelas semester tersebut.",
+			PathParams: krsParam, Body: krsReplaceRequest{}, Response: model.KRS{}, Envelope: openapi.EnvelopeMessage,
+		},
+		openapi.Operation{
+			Method: http.MethodPost, Path: "/api/v1/krs/:semester/submit", Tag: "krs", Roles: roleMahasiswa,
+			Summary: "Ajukan KRS ke PA",
+			Description: "Syarat: sama dengan mengganti KRS, sudah punya PA, KRS berisi kelas, dan prasyarat setiap mata kuliah sudah " +
+				"lulus dengan nilai minimalnya pada KRS yang disetujui. Prasyarat yang belum terpenuhi dikembalikan (422) di " +
+				"field details. KRS yang diajukan tidak dapat diubah sampai dikembalikan PA; KRS yang disetujui bersifat final.",
+			PathParams: krsParam, Response: model.KRS{}, Envelope: openapi.EnvelopeMessage,
+		},
+		openapi.Operation{
+			Method: http.MethodGet, Path: "/api/v1/bimbingan/krs", Tag: "krs", Roles: roleDosen,
+			Summary: "Daftar pengajuan KRS mahasiswa bimbingan",
+			Query: append(append(pageQuery(), sortQuery("diajukan_at", "updated_at", "id_mahasiswa", "id_semester", "status")...),
+				filterQuery(repo.KRSPengajuanFilterFields)...),
+			Response: model.KRSPengajuan{}, Envelope: openapi.EnvelopeList,
+		},
+		openapi.Operation{
+			Method: http.MethodGet, Path: "/api/v1/bimbingan/krs/:id", Tag: "krs", Roles: roleDosen,
+			Summary: "Detail KRS yang diajukan", PathParams: pengajuanParam, Response: model.KRS{}, Envelope: openapi.EnvelopeData,
+		},
+		openapi.Operation{
+			Method: http.MethodPost, Path: "/api/v1/bimbingan/krs/:id/approve", Tag: "krs", Roles: roleDosen,
+			Summary: "Setujui KRS", Description: "Body opsional. Hanya pengajuan berstatus Diajukan.",
+			PathParams: pengajuanParam, Body: krsReviewRequest{}, Response: model.KRS{}, Envelope: openapi.EnvelopeMessage,
+		},
+		openapi.Operation{
+			Method: http.MethodPost, Path: "/api/v1/bimbingan/krs/:id/return", Tag: "krs", Roles: roleDosen,
+			Summary: "Kembalikan KRS ke mahasiswa", Description: "catatan wajib; mahasiswa dapat memperbaiki lalu mengajukan ulang.",
+			PathParams: pengajuanParam, Body: krsReviewRequest{}, Response: model.KRS{}, Envelope: openapi.EnvelopeMessage,
+		},
+	)
+
+	kurikulum := crud(crudDoc{
+		tag: "kurikulum", base: "/api/v1/kurikulum", idName: "id_kurikulum", roles: roleAdminOperator, readRoles: roleAll,
+		model: model.Kurikulum{}, create: kurikulumCreateRequest{}, put: kurikulumPutRequest{},
+		list:    append(pageQuery(), sortQuery("tahun", "id_prodi", "nama", "created_at")...),
+		filters: repo.KurikulumFilterFields,
+		listDoc: "Versi kurikulum per prodi; aktif=true untuk kurikulum yang berlaku.",
+	})
+	kurikulumParam := []openapi.Param{{Name: "id", Type: "integer", Description: "id_kurikulum"}}
+	for i := range kurikulum {
+		if len(kurikulum[i].PathParams) > 0 {
+			kurikulum[i].PathParams = kurikulumParam
+		}
+		switch kurikulum[i].Method {
+		case http.MethodGet:
+			if len(kurikulum[i].PathParams) > 0 {
+				kurikulum[i].Response = model.KurikulumDetail{}
+				kurikulum[i].Description = "Beserta mata kuliah (semester anjuran, jenis) dan prasyaratnya. " +
+					"Prasyarat melekat pada mata kuliah, sama di semua kurikulum."
+			}
+		case http.MethodPost, http.MethodPut:
+			kurikulum[i].Description = "Tahun unik per prodi. aktif=true menonaktifkan kurikulum aktif lain pada prodi yang sama; " +
+				"operator dengan ref_id hanya untuk prodinya."
+		}
+	}
+	mkParam := []openapi.Param{{Name: "id", Description: "id_mk"}}
+	kurikulum = append(kurikulum,
+		openapi.Operation{
+			Method: http.MethodPut, Path: "/api/v1/kurikulum/:id/mata-kuliah", Tag: "kurikulum", Roles: roleAdminOperator,
+			Summary: "Ganti mata kuliah kurikulum",
+			Description: "Mengganti seluruh daftar. semester anjuran 1 sampai 14; jenis Wajib (default) atau Pilihan. " +
+				"Mata kuliah prodi lain ditolak (422, id_mk: other_prodi).",
+			PathParams: kurikulumParam, Body: kurikulumMKRequest{}, Response: model.KurikulumDetail{}, Envelope: openapi.EnvelopeMessage,
+		},
+		openapi.Operation{
+			Method: http.MethodGet, Path: "/api/v1/mata-kuliah/:id/prasyarat", Tag: "kurikulum", Roles: roleAll,
+			Summary: "Prasyarat mata kuliah", PathParams: mkParam, Response: []model.Prasyarat{}, Envelope: openapi.EnvelopeData,
+		},
+		openapi.Operation{
+			Method: http.MethodPut, Path: "/api/v1/mata-kuliah/:id/prasyarat", Tag: "kurikulum", Roles: roleAdminOperator,
+			Summary: "Ganti prasyarat mata kuliah",
+			Description: "Mengganti seluruh prasyarat; nilai_minimal default C. Berlaku di semua kurikulum dan diperiksa saat KRS " +
+				"diajukan. Prasyarat melingkar ditolak.",
+			PathParams: mkParam, Body: prasyaratRequest{}, Response: []model.Prasyarat{}, Envelope: openapi.EnvelopeMessage,
+		},
+	)
+	ops = append(ops, kurikulum...)
+
+	semester := crud(crudDoc{
+		tag: "semester", base: "/api/v1/semester", idName: "id_semester", roles: roleAdminOperator, readRoles: roleAll,
+		model: model.Semester{}, create: semesterCreateRequest{}, put: semesterPutRequest{}, patch: semesterPatchRequest{},
+		list:    append(append(pageQuery(), search("q")), sortQuery("id_semester", "tahun_ajaran", "term", "tanggal_mulai", "tanggal_selesai", "created_at", "updated_at")...),
+		filters: repo.SemesterFilterFields,
+	})
+	semester = append(semester, openapi.Operation{
+		Method: http.MethodPost, Path: "/api/v1/semester/import", Tag: "semester", Roles: roleAdminOperator,
+		Summary:     "Import semester dari CSV",
+		Description: "Header CSV: id_semester,tahun_ajaran,term,tanggal_mulai,tanggal_selesai. dry_run=true (default) hanya memvalidasi.",
+		Query:       []openapi.Param{{Name: "dry_run", Type: "boolean", Description: "Default true"}},
+		FileField:   "file",
+		Response:    semesterImportResult{},
+		Envelope:    openapi.EnvelopeRaw,
+	})
+	return append(ops, semester...)
+}
+
+// crudDoc adalah bentuk umum route CRUD admin: GET list, GET :id, POST, PUT, PATCH (opsional), DELETE
+type crudDoc struct {
+	tag, base, idName  string
+	roles, readRoles   []string
+	model              any
+	create, put, patch any
+	list               []openapi.Param
+	filters            map[string]repo.FilterField
+	listDoc            string
+	// created dan createDoc mengganti response/deskripsi POST bila berbeda dari {code, message, data}
+	created   any
+	createDoc string
+}
+
+func crud(d crudDoc) []openapi.Operation {
+	readRoles := d.readRoles
+	if readRoles == nil {
+		readRoles = d.roles
+	}
+	id := []openapi.Param{{Name: "id", Description: d.idName}}
+	ops := []openapi.Operation{
+		{Method: http.MethodGet, Path: d.base + "/", Tag: d.tag, Roles: readRoles, Summary: "Daftar " + d.tag,
+			Description: strings.TrimSpace(d.listDoc + " Filter: <field>=[op:]value, op: eq, ne, gt, gte, lt, lte, between, in, nin, is:null|notnull; boleh diulang."),
+			Query:       append(d.list, filterQuery(d.filters)...), Response: d.model, Envelope: openapi.EnvelopeList},
+		{Method: http.MethodGet, Path: d.base + "/:id", Tag: d.tag, Roles: readRoles, Summary: "Detail " + d.tag,
+			PathParams: id, Response: d.model, Envelope: openapi.EnvelopeData},
+		{Method: http.MethodPost, Path: d.base + "/", Tag: d.tag, Roles: d.roles, Summary: "Tambah " + d.tag,
+			Body: d.create, Status: http.StatusCreated, Response: d.model, Envelope: openapi.EnvelopeMessage},
+		{Method: http.MethodPut, Path: d.base + "/:id", Tag: d.tag, Roles: d.roles, Summary: "Ganti " + d.tag,
+			PathParams: id, Body: d.put, Response: d.model, Envelope: openapi.EnvelopeMessage},
+	}
+	if d.created != nil {
+		ops[2].Response, ops[2].Envelope, ops[2].Description = d.created, openapi.EnvelopeRaw, d.createDoc
+	}
+	if d.patch != nil {
+		ops = append(ops, openapi.Operation{Method: http.MethodPatch, Path: d.base + "/:id", Tag: d.tag, Roles: d.roles,
+			Summary: "Ubah sebagian " + d.tag, Description: "Hanya field yang dikirim yang diubah.",
+			PathParams: id, Body: d.patch, Response: d.model, Envelope: openapi.EnvelopeMessage})
+	}
+	return append(ops, openapi.Operation{Method: http.MethodDelete, Path: d.base + "/:id", Tag: d.tag, Roles: d.roles,
+		Summary: "Hapus " + d.tag, PathParams: id, Response: map[string]string{}, Envelope: openapi.EnvelopeMessage})
+}
+
+func offsetQuery() []openapi.Param {
+	return []openapi.Param{
+		{Name: "limit", Type: "integer", Description: "Default 20"},
+		{Name: "offset", Type: "integer", Description: "Default 0"},
+	}
+}
+
+func pageQuery() []openapi.Param {
+	return []openapi.Param{
+		{Name: "page", Type: "integer", Description: "Mulai dari 1"},
+		{Name: "per_page", Type: "integer", Description: "Default dan batas maksimum mengikuti konfigurasi pagination"},
+	}
+}
+
+func search(name string) openapi.Param {
+	return openapi.Param{Name: name, Description: "Kata kunci pencarian"}
+}
+
+func matchParam() openapi.Param {
+	return openapi.Param{Name: "match", Description: "Mode pencarian nama (default exact)", Enum: []string{repo.MatchExact, repo.MatchPrefix, repo.MatchFuzzy}}
+}
+
+func sortQuery(cols ...string) []openapi.Param {
+	return []openapi.Param{
+		{Name: "sort_by", Enum: cols},
+		{Name: "sort_dir", Enum: []string{"asc", "desc"}},
+	}
+}
+
+// filterQuery menurunkan parameter filter dari whitelist repository
+func filterQuery(fields map[string]repo.FilterField) []openapi.Param {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := make([]openapi.Param, 0, len(names))
+	for _, name := range names {
+		desc := "Filter [op:]value"
+		if enum := fields[name].Enum; len(enum) > 0 {
+			desc += "; nilai: " + strings.Join(enum, ", ")
+		}
+		out = append(out, openapi.Param{Name: name, Description: desc})
+	}
+	return out
+}
+
+// statusTransitionDoc menuliskan aturan transisi status, mis. "Aktif -> Cuti|Lulus; Cuti -> Aktif"
+func statusTransitionDoc() string {
+	rules := service.StatusTransitions()
+	from := make([]string, 0, len(rules))
+	for s := range rules {
+		from = append(from, s)
+	}
+	sort.Strings(from)
+	parts := make([]string, 0, len(from))
+	for _, s := range from {
+		parts = append(parts, s+" -> "+strings.Join(rules[s], "|"))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,107 @@
+package admin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/config"
+)
+
+// pageMeta adalah metadata pagination yang dikirim bersama data list
+type pageMeta struct {
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+func newPageMeta(page, perPage int, total int64) pageMeta {
+	totalPages := 0
+	if perPage > 0 {
+		totalPages = int((total + int64(perPage) - 1) / int64(perPage))
+	} else if total > 0 {
+		// limit=0 berarti tanpa batas, seluruh data ada di satu halaman
+		totalPages = 1
+	}
+	return pageMeta{Page: page, PerPage: perPage, Total: total, TotalPages: totalPages}
+}
+
+// pageFromOffset mengonversi limit/offset menjadi nomor halaman (1-based)
+func pageFromOffset(limit, offset int) int {
+	if limit <= 0 {
+		return 1
+	}
+	return offset/limit + 1
+}
+
+// setLinkHeader menulis header Link (RFC 8288) untuk rel first/prev/next/last.
+// offsetStyle=true untuk endpoint yang memakai limit/offset, selain itu page/per_page.
+func setLinkHeader(c *gin.Context, m pageMeta, offsetStyle bool) {
+	if m.PerPage <= 0 || m.TotalPages == 0 {
+		return
+	}
+	link := func(page int, rel string) string {
+		u := *c.Request.URL
+		q := u.Query()
+		if offsetStyle {
+			q.Set("limit", strconv.Itoa(m.PerPage))
+			q.Set("offset", strconv.Itoa((page-1)*m.PerPage))
+		} else {
+			q.Set("page", strconv.Itoa(page))
+			q.Set("per_page", strconv.Itoa(m.PerPage))
+		}
+		u.RawQuery = q.Encode()
+		return fmt.Sprintf("<%s>; rel=\"%s\"", u.RequestURI(), rel)
+	}
+
+	links := []string{link(1, "first")}
+	if m.Page > 1 {
+		prev := m.Page - 1
+		if prev > m.TotalPages {
+			prev = m.TotalPages
+		}
+		links = append(links, link(prev, "prev"))
+	}
+	if m.Page < m.TotalPages {
+		links = append(links, link(m.Page+1, "next"))
+	}
+	links = append(links, link(m.TotalPages, "last"))
+	c.Header("Link", strings.Join(links, ", "))
+}
+
+// pageParams membaca page/per_page; per_page dibatasi MaxPageSize. ok false berarti response
+// error sudah ditulis.
+func pageParams(c *gin.Context, cfg config.PageConfig) (page, perPage int, ok bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		apperror.Respond(c, invalidParam("page", codeMin1))
+		return 0, 0, false
+	}
+	perPage, err = strconv.Atoi(c.DefaultQuery("per_page", strconv.Itoa(cfg.DefaultPageSize)))
+	if err != nil || perPage < 1 {
+		apperror.Respond(c, invalidParam("per_page", codeMin1))
+		return 0, 0, false
+	}
+	return page, min(perPage, cfg.MaxPageSize), true
+}
+
+// sortParam memetakan sort_by/sort_dir ke "kolom ARAH" dari allowed; sort_by yang tidak dikenal
+// memakai def
+func sortParam(c *gin.Context, allowed map[string]string, def, defDir string) string {
+	col, ok := allowed[strings.ToLower(strings.TrimSpace(c.DefaultQuery("sort_by", def)))]
+	if !ok {
+		col = allowed[def]
+	}
+	dir := defDir
+	switch strings.ToLower(strings.TrimSpace(c.Query("sort_dir"))) {
+	case "asc":
+		dir = "ASC"
+	case "desc":
+		dir = "DESC"
+	}
+	return col + " " + dir
+}
@@ -0,0 +1,115 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/config"
+	"pencatatan-data-mahasiswa/internal/db"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+	service "pencatatan-data-mahasiswa/internal/todo/service/admin"
+)
+
+type PembimbingHandler struct {
+	service *service.PembimbingService
+	page    config.PageConfig
+}
+
+func NewPembimbingHandler(cfg *config.Config, pool *db.Pool) *PembimbingHandler {
+	s := service.NewPembimbingService(repo.NewPembimbingRepository(pool), repo.NewMahasiswaRepository(pool), db.NewTxManager(pool), clockOf(cfg))
+	return &PembimbingHandler{service: s, page: cfg.Pagination}
+}
+
+// Request payloads
+
+type pembimbingRequest struct {
+	IDDosen string  `json:"id_dosen"`
+	Alasan  *string `json:"alasan"`
+}
+
+type bimbinganBulkRequest struct {
+	IDProdi  string `json:"id_prodi"`
+	Angkatan int    `json:"angkatan"`
+	// HanyaTanpaPembimbing melewati mahasiswa yang sudah punya PA
+	HanyaTanpaPembimbing bool    `json:"hanya_tanpa_pembimbing"`
+	Alasan               *string `json:"alasan"`
+}
+
+// Assign: PUT /api/v1/mahasiswa/:id/pembimbing
+// PA lama (bila ada) diakhiri hari ini dan tetap tercatat di riwayat
+func (h *PembimbingHandler) Assign(c *gin.Context) {
+	var req pembimbingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, bindError(err))
+		return
+	}
+	in := service.PembimbingAssignment{IDDosen: req.IDDosen, Alasan: req.Alasan}
+	out, err := h.service.Assign(c.Request.Context(), c.Param("id"), in, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
+}
+
+// History: GET /api/v1/mahasiswa/:id/pembimbing-history
+func (h *PembimbingHandler) History(c *gin.Context) {
+	out, err := h.service.History(c.Request.Context(), c.Param("id"), currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": out})
+}
+
+// AssignBulk: POST /api/v1/dosen/:id/bimbingan
+// Menetapkan dosen sebagai PA seluruh mahasiswa satu prodi/angkatan
+func (h *PembimbingHandler) AssignBulk(c *gin.Context) {
+	var req bimbinganBulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, bindError(err))
+		return
+	}
+	in := service.PembimbingAssignment{
+		IDDosen:              c.Param("id"),
+		IDProdi:              req.IDProdi,
+		Angkatan:             req.Angkatan,
+		HanyaTanpaPembimbing: req.HanyaTanpaPembimbing,
+		Alasan:               req.Alasan,
+	}
+	out, err := h.service.AssignBulk(c.Request.Context(), in, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
+}
+
+// ListBimbingan: GET /api/v1/dosen/:id/bimbingan (admin/operator) dan GET /api/v1/bimbingan
+// (dosen, bimbingannya sendiri)
+func (h *PembimbingHandler) ListBimbingan(c *gin.Context) {
+	filters := filterParams(c)
+	page, perPage, ok := pageParams(c, h.page)
+	if !ok {
+		return
+	}
+	orderBy := sortParam(c, map[string]string{
+		"id_mahasiswa": "m.id_mahasiswa",
+		"nama_lengkap": "m.nama_lengkap",
+		"angkatan":     "m.angkatan",
+		"status":       "m.status",
+		"ipk":          "g.ipk",
+	}, "id_mahasiswa", "ASC")
+
+	data, total, err := h.service.ListBimbingan(c.Request.Context(), c.Param("id"), filters, perPage, (page-1)*perPage, orderBy, currentActor(c))
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	meta := newPageMeta(page, perPage, total)
+	setLinkHeader(c, meta, false)
+	c.JSON(http.StatusOK, gin.H{"data": data, "meta": meta})
+}
@@ -1,15 +1,15 @@
 package admin
 
 import (
-    "errors"
     "net/http"
     "strconv"
     "strings"
 
     "github.com/gin-gonic/gin"
-    "github.com/jackc/pgx/v5"
 
+    "pencatatan-data-mahasiswa/internal/apperror"
     "pencatatan-data-mahasiswa/internal/config"
+    "pencatatan-data-mahasiswa/internal/i18n"
     "pencatatan-data-mahasiswa/internal/db"
     model "pencatatan-data-mahasiswa/internal/todo/model/admin"
     repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
@@ -22,7 +22,7 @@ type ProdiHandler struct {
 
 func NewProdiHandler(cfg *config.Config, pool *db.Pool) *ProdiHandler {
     r := repo.NewProdiRepository(pool)
-    s := service.NewProdiService(r)
+    s := service.NewProdiService(r, db.NewTxManager(pool))
     return &ProdiHandler{service: s}
 }
 
@@ -54,33 +54,21 @@ type prodiPatchRequest struct {
 }
 
 // List: GET /api/v1/prodi
+// Filter memakai grammar field=[op:]value, mis. jenjang=in:S1,S2&akreditasi=is:null
 func (h *ProdiHandler) List(c *gin.Context) {
     q := strings.TrimSpace(c.Query("q"))
-    idF := strings.TrimSpace(c.Query("id_fakultas"))
-    jen := strings.TrimSpace(c.Query("jenjang"))
-    akr := strings.TrimSpace(c.Query("akreditasi"))
-
-    var idFPtr, jenPtr, akrPtr *string
-    if idF != "" {
-        idFPtr = &idF
-    }
-    if jen != "" {
-        jenPtr = &jen
-    }
-    if akr != "" {
-        akrPtr = &akr
-    }
+    filters := filterParams(c)
 
     limitStr := c.DefaultQuery("limit", "20")
     offsetStr := c.DefaultQuery("offset", "0")
     limit, err := strconv.Atoi(limitStr)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+        apperror.Respond(c, invalidParam("limit", codeInteger))
         return
     }
     offset, err := strconv.Atoi(offsetStr)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+        apperror.Respond(c, invalidParam("offset", codeInteger))
         return
     }
 
@@ -105,16 +93,14 @@ func (h *ProdiHandler) List(c *gin.Context) {
     }
     orderBy := col + " " + dir
 
-    data, err := h.service.List(c.Request.Context(), q, idFPtr, jenPtr, akrPtr, limit, offset, orderBy)
+    data, total, err := h.service.List(c.Request.Context(), q, filters, limit, offset, orderBy)
     if err != nil {
-        if err.Error() == "invalid input" {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+        apperror.Respond(c, err)
         return
     }
-    c.JSON(http.StatusOK, gin.H{"data": data})
+    meta := newPageMeta(pageFromOffset(limit, offset), limit, total)
+    setLinkHeader(c, meta, true)
+    c.JSON(http.StatusOK, gin.H{"data": data, "meta": meta})
 }
 
 // Get: GET /api/v1/prodi/:id
@@ -122,11 +108,7 @@ func (h *ProdiHandler) Get(c *gin.Context) {
     id := c.Param("id")
     out, err := h.service.Get(c.Request.Context(), id)
     if err != nil {
-        if err.Error() == "invalid input" {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        }
-        c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+        apperror.Respond(c, err)
         return
     }
     c.JSON(http.StatusOK, gin.H{"data": out})
@@ -136,7 +118,7 @@ func (h *ProdiHandler) Get(c *gin.Context) {
 func (h *ProdiHandler) Create(c *gin.Context) {
     var req prodiCreateRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        apperror.Respond(c, bindError(err))
         return
     }
     p := &model.Prodi{
@@ -152,19 +134,10 @@ func (h *ProdiHandler) Create(c *gin.Context) {
 
     out, err := h.service.Create(c.Request.Context(), p)
     if err != nil {
-        switch err.Error() {
-        case "invalid input":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        case "conflict":
-            c.JSON(http.StatusConflict, gin.H{"error": "duplicate id, kode, or (nama+jenjang+fakultas)"})
-            return
-        default:
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-            return
-        }
+        apperror.Respond(c, err)
+        return
     }
-    c.JSON(http.StatusCreated, gin.H{"message": "created", "data": out})
+    c.JSON(http.StatusCreated, success(c, i18n.MsgCreated, out))
 }
 
 // UpdatePut: PUT /api/v1/prodi/:id
@@ -172,7 +145,7 @@ func (h *ProdiHandler) UpdatePut(c *gin.Context) {
     id := c.Param("id")
     var req prodiPutRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        apperror.Respond(c, bindError(err))
         return
     }
     p := &model.Prodi{
@@ -185,23 +158,10 @@ func (h *ProdiHandler) UpdatePut(c *gin.Context) {
 
     out, err := h.service.UpdatePut(c.Request.Context(), id, p)
     if err != nil {
-        switch err.Error() {
-        case "invalid input":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        case "conflict":
-            c.JSON(http.StatusConflict, gin.H{"error": "duplicate kode or (nama+jenjang+fakultas)"})
-            return
-        default:
-            if errors.Is(err, pgx.ErrNoRows) {
-                c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-                return
-            }
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-            return
-        }
+        apperror.Respond(c, err)
+        return
     }
-    c.JSON(http.StatusOK, gin.H{"message": "updated", "data": out})
+    c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
 }
 
 // UpdatePatch: PATCH /api/v1/prodi/:id
@@ -209,50 +169,24 @@ func (h *ProdiHandler) UpdatePatch(c *gin.Context) {
     id := c.Param("id")
     var req prodiPatchRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+        apperror.Respond(c, bindError(err))
         return
     }
 
     out, err := h.service.UpdatePatch(c.Request.Context(), id, req.IDFakultas, req.NamaProdi, req.Jenjang, req.KodeProdi, req.Akreditasi)
     if err != nil {
-        switch err.Error() {
-        case "invalid input":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        case "conflict":
-            c.JSON(http.StatusConflict, gin.H{"error": "duplicate kode or (nama+jenjang+fakultas)"})
-            return
-        default:
-            if errors.Is(err, pgx.ErrNoRows) {
-                c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-                return
-            }
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-            return
-        }
+        apperror.Respond(c, err)
+        return
     }
-    c.JSON(http.StatusOK, gin.H{"message": "updated", "data": out})
+    c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
 }
 
 // Delete: DELETE /api/v1/prodi/:id
 func (h *ProdiHandler) Delete(c *gin.Context) {
     id := c.Param("id")
     if err := h.service.Delete(c.Request.Context(), id); err != nil {
-        switch err.Error() {
-        case "invalid input":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-            return
-        case "conflict":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "cannot delete: related mahasiswa or mata_kuliah exists"})
-            return
-        default:
-            if errors.Is(err, pgx.ErrNoRows) {
-                c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
-                return
-            }
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-            return
-        }
+        apperror.Respond(c, err)
+        return
     }
-    c.JSON(http.StatusOK, gin.H{"message": "deleted", "data": gin.H{"id_prodi": id}})
+    c.JSON(http.StatusOK, success(c, i18n.MsgDeleted, gin.H{"id_prodi": id}))
 }
\ No newline at end of file
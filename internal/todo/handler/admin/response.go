@@ -0,0 +1,83 @@
+package admin
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/config"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	authhandler "pencatatan-data-mahasiswa/internal/todo/handler/auth"
+	service "pencatatan-data-mahasiswa/internal/todo/service/admin"
+	authsvc "pencatatan-data-mahasiswa/internal/todo/service/auth"
+)
+
+// Kode detail field untuk parameter yang divalidasi di handler
+const (
+	codeInteger     = "integer"
+	codeMin1        = "min_1"
+	codeInvalidDate = "invalid_date" // bukan YYYY-MM-DD
+)
+
+// errInvalidBody dipakai saat payload JSON gagal di-bind
+var errInvalidBody = apperror.New(apperror.ErrInvalidInput, i18n.MsgInvalidRequestBody)
+
+// bindError memetakan error bind: body yang melewati batas ukuran menjadi 413, sisanya errInvalidBody
+func bindError(err error) error {
+	if apperror.TooLarge(err) {
+		return err
+	}
+	return errInvalidBody
+}
+
+// success menyusun body response sukses: kode stabil, pesan sesuai Accept-Language, dan data
+func success(c *gin.Context, code string, data any) gin.H {
+	return gin.H{"code": code, "message": i18n.Message(c, code), "data": data}
+}
+
+// createdWithAccount menyusun response 201 untuk data yang bisa dibuat bersama akun login;
+// kredensial awal akun hanya muncul sekali di field account
+func createdWithAccount(c *gin.Context, data any, acc *authsvc.Account) gin.H {
+	body := success(c, i18n.MsgCreated, data)
+	if acc != nil {
+		body["account"] = acc
+	}
+	return body
+}
+
+// invalidParam membuat error validasi (400) untuk satu parameter
+func invalidParam(field, code string) error {
+	return apperror.Field(apperror.ErrInvalidInput, field, code)
+}
+
+// invalidFile membuat error validasi untuk field upload "file" dengan kode pesan msg
+func invalidFile(msg, code string) error {
+	return &apperror.Error{Kind: apperror.ErrInvalidInput, Code: msg, Fields: map[string]string{"file": code}}
+}
+
+// parseDateField mem-parse tanggal opsional berformat YYYY-MM-DD; string kosong berarti nil.
+// Format yang salah dicatat ke fe sehingga beberapa tanggal bisa dilaporkan sekaligus.
+func parseDateField(fe apperror.Fields, field string, raw *string) *time.Time {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", strings.TrimSpace(*raw))
+	if err != nil {
+		fe.Add(field, codeInvalidDate)
+		return nil
+	}
+	return &t
+}
+
+// currentActor membaca user yang login sebagai service.Actor (pelaku perubahan dan cakupan datanya)
+func currentActor(c *gin.Context) service.Actor {
+	u, _ := authhandler.CurrentUser(c)
+	return service.Actor{IDUser: u.ID, Username: u.Username, Role: u.Role, RefID: u.RefID}
+}
+
+// clockOf mengembalikan sumber "hari ini" pada zona waktu kampus dari konfigurasi
+func clockOf(cfg *config.Config) service.Clock {
+	return service.Clock{Location: cfg.Location()}
+}
@@ -11,7 +11,10 @@ import (
     "bufio"
     "encoding/csv"
     "io"
+    "pencatatan-data-mahasiswa/internal/apperror"
     "pencatatan-data-mahasiswa/internal/config"
+    "pencatatan-data-mahasiswa/internal/i18n"
+    "pencatatan-data-mahasiswa/internal/metrics"
     "pencatatan-data-mahasiswa/internal/db"
     model "pencatatan-data-mahasiswa/internal/todo/model/admin"
     repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
@@ -20,12 +23,13 @@ import (
 
 type SemesterHandler struct {
     service *service.SemesterService
+    page    config.PageConfig
 }
 
 func NewSemesterHandler(cfg *config.Config, pool *db.Pool) *SemesterHandler {
     r := repo.NewSemesterRepository(pool)
-    s := service.NewSemesterService(r)
-    return &SemesterHandler{service: s}
+    s := service.NewSemesterService(r, db.NewTxManager(pool))
+    return &SemesterHandler{service: s, page: cfg.Pagination}
 }
 
 // Request payloads
@@ -34,54 +38,45 @@ type semesterCreateRequest struct {
     IDSemester     string  `json:"id_semester"`
     TahunAjaran    string  `json:"tahun_ajaran"`
     Term           string  `json:"term"`
-    TanggalMulai   *string `json:"tanggal_mulai"`
-    TanggalSelesai *string `json:"tanggal_selesai"`
+    TanggalMulai   *string `json:"tanggal_mulai" format:"date"`
+    TanggalSelesai *string `json:"tanggal_selesai" format:"date"`
 }
 
 type semesterPutRequest struct {
     TahunAjaran    string  `json:"tahun_ajaran"`
     Term           string  `json:"term"`
-    TanggalMulai   *string `json:"tanggal_mulai"`
-    TanggalSelesai *string `json:"tanggal_selesai"`
+    TanggalMulai   *string `json:"tanggal_mulai" format:"date"`
+    TanggalSelesai *string `json:"tanggal_selesai" format:"date"`
 }
 
 type semesterPatchRequest struct {
     TahunAjaran    *string `json:"tahun_ajaran"`
     Term           *string `json:"term"`
-    TanggalMulai   *string `json:"tanggal_mulai"`
-    TanggalSelesai *string `json:"tanggal_selesai"`
+    TanggalMulai   *string `json:"tanggal_mulai" format:"date"`
+    TanggalSelesai *string `json:"tanggal_selesai" format:"date"`
 }
 
 // List: GET /api/v1/semester
+// Filter memakai grammar field=[op:]value, mis. term=in:Ganjil,Genap&tanggal_mulai=gte:2024-01-01
 func (h *SemesterHandler) List(c *gin.Context) {
     q := strings.TrimSpace(c.Query("q"))
-    tahunAjaran := strings.TrimSpace(c.Query("tahun_ajaran"))
-    term := strings.TrimSpace(c.Query("term"))
+    filters := filterParams(c)
 
-    var tahunAjaranPtr *string
-    if tahunAjaran != "" {
-        tahunAjaranPtr = &tahunAjaran
-    }
-    var termPtr *string
-    if term != "" {
-        termPtr = &term
-    }
-
-    // pagination via page & per_page (cap 100)
+    // pagination via page & per_page (cap pagination.max_page_size)
     pageStr := c.DefaultQuery("page", "1")
-    perPageStr := c.DefaultQuery("per_page", "20")
+    perPageStr := c.DefaultQuery("per_page", strconv.Itoa(h.page.DefaultPageSize))
     page, err := strconv.Atoi(pageStr)
     if err != nil || page < 1 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "fields": gin.H{"page": "must be >= 1"}})
+        apperror.Respond(c, invalidParam("page", codeMin1))
         return
     }
     perPage, err := strconv.Atoi(perPageStr)
     if err != nil || perPage < 1 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "fields": gin.H{"per_page": "must be >= 1"}})
+        apperror.Respond(c, invalidParam("per_page", codeMin1))
         return
     }
-    if perPage > 100 {
-        perPage = 100
+    if perPage > h.page.MaxPageSize {
+        perPage = h.page.MaxPageSize
     }
     limit := perPage
     offset := (page - 1) * perPage
@@ -108,16 +103,14 @@ func (h *SemesterHandler) List(c *gin.Context) {
     }
     orderBy := col + " " + dir
 
-    data, err := h.service.List(c.Request.Context(), q, tahunAjaranPtr, termPtr, limit, offset, orderBy)
+    data, total, err := h.service.List(c.Request.Context(), q, filters, limit, offset, orderBy)
     if err != nil {
-        if err.Error() == "invalid input" {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
-            return
-        }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error"})
+        apperror.Respond(c, err)
         return
     }
-    c.JSON(http.StatusOK, gin.H{"data": data})
+    meta := newPageMeta(page, perPage, total)
+    setLinkHeader(c, meta, false)
+    c.JSON(http.StatusOK, gin.H{"data": data, "meta": meta})
 }
 
 // Get: GET /api/v1/semester/:id
@@ -125,11 +118,7 @@ func (h *SemesterHandler) Get(c *gin.Context) {
     id := c.Param("id")
     out, err := h.service.Get(c.Request.Context(), id)
     if err != nil {
-        if err.Error() == "invalid input" {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
-            return
-        }
-        c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
+        apperror.Respond(c, err)
         return
     }
     c.JSON(http.StatusOK, gin.H{"data": out})
@@ -139,26 +128,16 @@ func (h *SemesterHandler) Get(c *gin.Context) {
 func (h *SemesterHandler) Create(c *gin.Context) {
     var req semesterCreateRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
+        apperror.Respond(c, bindError(err))
         return
     }
 
-    var tMulai, tSelesai *time.Time
-    if req.TanggalMulai != nil && strings.TrimSpace(*req.TanggalMulai) != "" {
-        t, err := time.Parse("2006-01-02", strings.TrimSpace(*req.TanggalMulai))
-        if err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "fields": gin.H{"tanggal_mulai": "invalid date format (YYYY-MM-DD)"}})
-            return
-        }
-        tMulai = &t
-    }
-    if req.TanggalSelesai != nil && strings.TrimSpace(*req.TanggalSelesai) != "" {
-        t, err := time.Parse("2006-01-02", strings.TrimSpace(*req.TanggalSelesai))
-        if err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "fields": gin.H{"tanggal_selesai": "invalid date format (YYYY-MM-DD)"}})
-            return
-        }
-        tSelesai = &t
+    fe := apperror.Fields{}
+    tMulai := parseDateField(fe, "tanggal_mulai", req.TanggalMulai)
+    tSelesai := parseDateField(fe, "tanggal_selesai", req.TanggalSelesai)
+    if err := fe.Err(); err != nil {
+        apperror.Respond(c, err)
+        return
     }
 
     s := &model.Semester{
@@ -171,19 +150,10 @@ func (h *SemesterHandler) Create(c *gin.Context) {
 
     out, err := h.service.Create(c.Request.Context(), s)
     if err != nil {
-        switch err.Error() {
-        case "invalid input":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
-            return
-        case "conflict":
-            c.JSON(http.StatusConflict, gin.H{"error": "conflict"})
-            return
-        default:
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error"})
-            return
-        }
+        apperror.Respond(c, err)
+        return
     }
-    c.JSON(http.StatusCreated, gin.H{"message": "created", "data": out})
+    c.JSON(http.StatusCreated, success(c, i18n.MsgCreated, out))
 }
 
 // UpdatePut: PUT /api/v1/semester/:id
@@ -191,26 +161,16 @@ func (h *SemesterHandler) UpdatePut(c *gin.Context) {
     id := c.Param("id")
     var req semesterPutRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
+        apperror.Respond(c, bindError(err))
         return
     }
 
-    var tMulai, tSelesai *time.Time
-    if req.TanggalMulai != nil && strings.TrimSpace(*req.TanggalMulai) != "" {
-        t, err := time.Parse("2006-01-02", strings.TrimSpace(*req.TanggalMulai))
-        if err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "fields": gin.H{"tanggal_mulai": "invalid date format (YYYY-MM-DD)"}})
-            return
-        }
-        tMulai = &t
-    }
-    if req.TanggalSelesai != nil && strings.TrimSpace(*req.TanggalSelesai) != "" {
-        t, err := time.Parse("2006-01-02", strings.TrimSpace(*req.TanggalSelesai))
-        if err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "fields": gin.H{"tanggal_selesai": "invalid date format (YYYY-MM-DD)"}})
-            return
-        }
-        tSelesai = &t
+    fe := apperror.Fields{}
+    tMulai := parseDateField(fe, "tanggal_mulai", req.TanggalMulai)
+    tSelesai := parseDateField(fe, "tanggal_selesai", req.TanggalSelesai)
+    if err := fe.Err(); err != nil {
+        apperror.Respond(c, err)
+        return
     }
 
     s := &model.Semester{
@@ -222,16 +182,10 @@ func (h *SemesterHandler) UpdatePut(c *gin.Context) {
 
     out, err := h.service.UpdatePut(c.Request.Context(), id, s)
     if err != nil {
-        switch err.Error() {
-        case "invalid input":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
-            return
-        default:
-            c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
-            return
-        }
+        apperror.Respond(c, err)
+        return
     }
-    c.JSON(http.StatusOK, gin.H{"message": "updated", "data": out})
+    c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
 }
 
 // UpdatePatch: PATCH /api/v1/semester/:id
@@ -239,59 +193,43 @@ func (h *SemesterHandler) UpdatePatch(c *gin.Context) {
     id := c.Param("id")
     var req semesterPatchRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
+        apperror.Respond(c, bindError(err))
         return
     }
 
-    var tMulai, tSelesai *time.Time
-    if req.TanggalMulai != nil && strings.TrimSpace(*req.TanggalMulai) != "" {
-        t, err := time.Parse("2006-01-02", strings.TrimSpace(*req.TanggalMulai))
-        if err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "fields": gin.H{"tanggal_mulai": "invalid date format (YYYY-MM-DD)"}})
-            return
-        }
-        tMulai = &t
-    }
-    if req.TanggalSelesai != nil && strings.TrimSpace(*req.TanggalSelesai) != "" {
-        t, err := time.Parse("2006-01-02", strings.TrimSpace(*req.TanggalSelesai))
-        if err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "fields": gin.H{"tanggal_selesai": "invalid date format (YYYY-MM-DD)"}})
-            return
-        }
-        tSelesai = &t
+    fe := apperror.Fields{}
+    tMulai := parseDateField(fe, "tanggal_mulai", req.TanggalMulai)
+    tSelesai := parseDateField(fe, "tanggal_selesai", req.TanggalSelesai)
+    if err := fe.Err(); err != nil {
+        apperror.Respond(c, err)
+        return
     }
 
     out, err := h.service.UpdatePatch(c.Request.Context(), id, req.TahunAjaran, req.Term, tMulai, tSelesai)
     if err != nil {
-        switch err.Error() {
-        case "invalid input":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
-            return
-        default:
-            c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
-            return
-        }
+        apperror.Respond(c, err)
+        return
     }
-    c.JSON(http.StatusOK, gin.H{"message": "updated", "data": out})
+    c.JSON(http.StatusOK, success(c, i18n.MsgUpdated, out))
 }
 
 // Delete: DELETE /api/v1/semester/:id
 func (h *SemesterHandler) Delete(c *gin.Context) {
     id := c.Param("id")
     if err := h.service.Delete(c.Request.Context(), id); err != nil {
-        switch err.Error() {
-        case "invalid input":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error"})
-            return
-        case "conflict":
-            c.JSON(http.StatusBadRequest, gin.H{"error": "cannot delete: related kelas_kuliah or krs exists"})
-            return
-        default:
-            c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
-            return
-        }
+        apperror.Respond(c, err)
+        return
     }
-    c.JSON(http.StatusOK, gin.H{"message": "deleted", "data": gin.H{"id_semester": id}})
+    c.JSON(http.StatusOK, success(c, i18n.MsgDeleted, gin.H{"id_semester": id}))
+}
+
+// rowError adalah satu baris CSV yang gagal beserta error-nya (bentuk sama dengan error API)
+type rowError struct {
+    Line   int               `json:"line"`
+    Record []string          `json:"record"`
+    Code   string            `json:"code"`
+    Error  string            `json:"error"`
+    Fields map[string]string `json:"fields,omitempty"`
 }
 
 // ImportCSV: POST /api/v1/semester/import?dry_run=true
@@ -300,13 +238,17 @@ func (h *SemesterHandler) ImportCSV(c *gin.Context) {
     dryRun := strings.ToLower(c.DefaultQuery("dry_run", "true")) == "true"
 
     file, err := c.FormFile("file")
+    if apperror.TooLarge(err) {
+        apperror.Respond(c, err)
+        return
+    }
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "missing file field 'file'"})
+        apperror.Respond(c, invalidFile(i18n.MsgCSVFileRequired, apperror.CodeRequired))
         return
     }
     f, err := file.Open()
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "cannot open uploaded file"})
+        apperror.Respond(c, invalidFile(i18n.MsgCSVFileUnreadable, "unreadable"))
         return
     }
     defer f.Close()
@@ -317,24 +259,31 @@ func (h *SemesterHandler) ImportCSV(c *gin.Context) {
     // read header
     header, err := reader.Read()
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "invalid CSV header"})
+        apperror.Respond(c, invalidFile(i18n.MsgCSVHeaderInvalid, "invalid_header"))
         return
     }
     if len(header) < 5 || strings.ToLower(header[0]) != "id_semester" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "validation_error", "message": "expected header: id_semester,tahun_ajaran,term,tanggal_mulai,tanggal_selesai"})
+        apperror.Respond(c, invalidFile(i18n.MsgCSVHeaderInvalid, "invalid_header"))
         return
     }
 
-    type rowError struct {
-        Line   int      `json:"line"`
-        Record []string `json:"record"`
-        Error  string   `json:"error"`
+    // newRowError menerjemahkan error baris dengan format yang sama seperti response error API
+    lang := i18n.Lang(c)
+    newRowError := func(line int, rec []string, err error) rowError {
+        _, body := apperror.Resolve(err, lang)
+        return rowError{Line: line, Record: rec, Code: body.Code, Error: body.Message, Fields: body.Fields}
     }
 
+    // semesterRow menyimpan nomor baris CSV asal agar error insert menunjuk baris yang benar
+    type semesterRow struct {
+        line int
+        rec  []string
+        sem  model.Semester
+    }
     var (
         imported   int
         errorsList []rowError
-        records    []model.Semester
+        records    []semesterRow
         line       = 1 // header counted as line 1; data starts at 2
     )
 
@@ -345,11 +294,11 @@ func (h *SemesterHandler) ImportCSV(c *gin.Context) {
         }
         line++
         if err != nil {
-            errorsList = append(errorsList, rowError{Line: line, Record: rec, Error: "invalid csv row"})
+            errorsList = append(errorsList, newRowError(line, rec, apperror.New(apperror.ErrInvalidInput, i18n.MsgCSVRowInvalid)))
             continue
         }
         if len(rec) < 5 {
-            errorsList = append(errorsList, rowError{Line: line, Record: rec, Error: "not enough columns"})
+            errorsList = append(errorsList, newRowError(line, rec, apperror.New(apperror.ErrInvalidInput, i18n.MsgCSVRowColumns)))
             continue
         }
 
@@ -360,7 +309,7 @@ func (h *SemesterHandler) ImportCSV(c *gin.Context) {
         if s := strings.TrimSpace(rec[3]); s != "" {
             tt, e := time.Parse("2006-01-02", s)
             if e != nil {
-                errorsList = append(errorsList, rowError{Line: line, Record: rec, Error: "tanggal_mulai invalid (YYYY-MM-DD)"})
+                errorsList = append(errorsList, newRowError(line, rec, invalidParam("tanggal_mulai", codeInvalidDate)))
                 continue
             }
             tMulai = &tt
@@ -368,7 +317,7 @@ func (h *SemesterHandler) ImportCSV(c *gin.Context) {
         if s := strings.TrimSpace(rec[4]); s != "" {
             tt, e := time.Parse("2006-01-02", s)
             if e != nil {
-                errorsList = append(errorsList, rowError{Line: line, Record: rec, Error: "tanggal_selesai invalid (YYYY-MM-DD)"})
+                errorsList = append(errorsList, newRowError(line, rec, invalidParam("tanggal_selesai", codeInvalidDate)))
                 continue
             }
             tSelesai = &tt
@@ -384,13 +333,14 @@ func (h *SemesterHandler) ImportCSV(c *gin.Context) {
 
         // validate only (and check conflict) when dry_run or before actual insert for early error reporting
         if err := h.service.ValidateForCreate(c.Request.Context(), sObj, true); err != nil {
-            errorsList = append(errorsList, rowError{Line: line, Record: rec, Error: err.Error()})
+            errorsList = append(errorsList, newRowError(line, rec, err))
             continue
         }
 
-        records = append(records, *sObj)
+        records = append(records, semesterRow{line: line, rec: rec, sem: *sObj})
     }
 
+    c.Header("Content-Language", lang)
     // If dry run, just return summary
     if dryRun {
         c.JSON(http.StatusOK, gin.H{
@@ -404,14 +354,17 @@ func (h *SemesterHandler) ImportCSV(c *gin.Context) {
     }
 
     // Insert valid records
+    invalid := len(errorsList)
     for i := range records {
-        if _, err := h.service.Create(c.Request.Context(), &records[i]); err != nil {
-            // on insert error, accumulate as error; add +2 to map record index to CSV line (skip header)
-            errorsList = append(errorsList, rowError{Line: i + 2, Error: err.Error()})
+        if _, err := h.service.Create(c.Request.Context(), &records[i].sem); err != nil {
+            errorsList = append(errorsList, newRowError(records[i].line, records[i].rec, err))
             continue
         }
         imported++
     }
+    metrics.CSVImportRows("semester", "imported", imported)
+    metrics.CSVImportRows("semester", "invalid", invalid)
+    metrics.CSVImportRows("semester", "failed", len(errorsList)-invalid)
 
     c.JSON(http.StatusOK, gin.H{
         "dry_run":  false,
@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"pencatatan-data-mahasiswa/internal/config"
+	"pencatatan-data-mahasiswa/internal/todo/repository/memory"
+	service "pencatatan-data-mahasiswa/internal/todo/service/admin"
+)
+
+// TestSemesterImportLines: error validasi maupun error insert menunjuk baris CSV asal,
+// walaupun baris sebelumnya sudah ditolak
+func TestSemesterImportLines(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := memory.NewStore()
+	h := &SemesterHandler{
+		service: service.NewSemesterService(memory.NewSemesterRepository(s), s),
+		page:    config.Defaults().Pagination,
+	}
+	r := gin.New()
+	r.POST("/semester/import", h.ImportCSV)
+
+	csv := "id_semester,tahun_ajaran,term,tanggal_mulai,tanggal_selesai\n" +
+		"20241,2024/2025,Ganjil,2024-09-01,2025-01-31\n" + // baris 2
+		"20242,2024/2025,Genap,besok,\n" + // baris 3: tanggal tidak valid
+		"20243,2024/2025,Antara,,\n" + // baris 4
+		"20241,2024/2025,Ganjil,,\n" // baris 5: duplikat baris 2, gagal saat insert
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, err := mw.CreateFormFile("file", "semester.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write([]byte(csv))
+	mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/semester/import?dry_run=false", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d; body %s", w.Code, w.Body)
+	}
+
+	var body struct {
+		Imported int        `json:"imported"`
+		Failed   int        `json:"failed"`
+		Errors   []rowError `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Imported != 2 || body.Failed != 2 || len(body.Errors) != 2 {
+		t.Fatalf("body = %+v", body)
+	}
+	for i, want := range []struct {
+		line int
+		id   string
+	}{{3, "20242"}, {5, "20241"}} {
+		e := body.Errors[i]
+		if e.Line != want.line || len(e.Record) == 0 || e.Record[0] != want.id {
+			t.Errorf("errors[%d] = %+v, want line %d for %s", i, e, want.line, want.id)
+		}
+	}
+}
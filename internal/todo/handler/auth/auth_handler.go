@@ -1,14 +1,18 @@
 package auth
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 
+	"pencatatan-data-mahasiswa/internal/apperror"
 	"pencatatan-data-mahasiswa/internal/config"
 	"pencatatan-data-mahasiswa/internal/db"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	"pencatatan-data-mahasiswa/internal/logging"
 	repo "pencatatan-data-mahasiswa/internal/todo/repository/auth"
 	service "pencatatan-data-mahasiswa/internal/todo/service/auth"
 )
@@ -19,9 +23,36 @@ type Handler struct {
 }
 
 func NewHandler(cfg *config.Config, pool *db.Pool) *Handler {
-	r := repo.NewRepository(pool)
-	s := service.NewService(r, cfg.JWTSecret)
-	return &Handler{service: s, jwtSecret: cfg.JWTSecret}
+	return &Handler{service: NewService(cfg, pool), jwtSecret: cfg.JWTSecret}
+}
+
+// NewService membangun service auth dari konfigurasi; dipakai juga handler admin untuk membuat
+// akun mahasiswa/dosen
+func NewService(cfg *config.Config, pool *db.Pool) *service.Service {
+	return service.NewService(repo.NewRepository(pool), db.NewTxManager(pool), service.Options{
+		JWTSecret:     cfg.JWTSecret,
+		TokenTTL:      cfg.Auth.TokenTTL,
+		BcryptCost:    cfg.Auth.BcryptCost,
+		ActivationTTL: cfg.Auth.ActivationTTL,
+		ActivationURL: cfg.Auth.ActivationURL,
+	})
+}
+
+var (
+	errInvalidBody  = apperror.New(apperror.ErrInvalidInput, i18n.MsgInvalidRequestBody)
+	errMissingAuth  = apperror.New(apperror.ErrUnauthorized, i18n.MsgMissingAuthorization)
+	errInvalidToken = apperror.New(apperror.ErrUnauthorized, i18n.MsgInvalidToken)
+	errForbidden    = apperror.New(apperror.ErrForbidden, i18n.MsgForbidden)
+	// errPasswordChange: token milik akun yang password awalnya belum diganti
+	errPasswordChange = apperror.New(apperror.ErrForbidden, i18n.MsgPasswordChangeRequired)
+)
+
+// bindError memetakan error bind: body yang melewati batas ukuran menjadi 413, sisanya errInvalidBody
+func bindError(err error) error {
+	if apperror.TooLarge(err) {
+		return err
+	}
+	return errInvalidBody
 }
 
 type loginRequest struct {
@@ -36,6 +67,16 @@ type registerRequest struct {
 	RefID    *string `json:"ref_id"`
 }
 
+type activateRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type changePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
 // Register godoc
 // @Summary Register
 // @Description Registrasi user baru
@@ -50,26 +91,18 @@ type registerRequest struct {
 func (h *Handler) Register(c *gin.Context) {
 	var req registerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		apperror.Respond(c, bindError(err))
 		return
 	}
 
 	created, err := h.service.Register(c.Request.Context(), req.Username, req.Password, req.Role, req.RefID)
 	if err != nil {
-		switch err.Error() {
-		case "invalid input":
-			c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed"})
-			return
-		case "username already taken":
-			c.JSON(http.StatusConflict, gin.H{"error": "username already taken"})
-			return
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-			return
-		}
+		apperror.Respond(c, err)
+		return
 	}
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "created",
+		"code":    i18n.MsgCreated,
+		"message": i18n.Message(c, i18n.MsgCreated),
 		"user": gin.H{
 			"id_user":    created.IDUser,
 			"username":   created.Username,
@@ -89,32 +122,96 @@ func (h *Handler) Register(c *gin.Context) {
 // @Success 200 {object} map[string]any
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
 // @Router /api/v1/auth/login [post]
 func (h *Handler) Login(c *gin.Context) {
 	var req loginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		apperror.Respond(c, bindError(err))
 		return
 	}
 	token, exp, user, err := h.service.Login(c.Request.Context(), req.Username, req.Password)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid username or password"})
+		apperror.Respond(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"token":      token,
 		"expires_in": exp,
 		"user": gin.H{
-			"id_user":  user.IDUser,
-			"username": user.Username,
-			"role":     user.Role,
-			"ref_id":   user.RefID,
+			"id_user":              user.IDUser,
+			"username":             user.Username,
+			"role":                 user.Role,
+			"ref_id":               user.RefID,
+			"must_change_password": user.MustChangePassword,
 		},
 	})
 }
 
-// RequireAuth memvalidasi Bearer JWT dan memastikan role user termasuk ke dalam allowedRoles
+// Activate: POST /api/v1/auth/activate
+// Memasang password untuk akun yang dibuat dengan mode aktivasi (token dari pembuat akun)
+func (h *Handler) Activate(c *gin.Context) {
+	var req activateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, bindError(err))
+		return
+	}
+	if err := h.service.Activate(c.Request.Context(), req.Token, req.Password); err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":    i18n.MsgAccountActivated,
+		"message": i18n.Message(c, i18n.MsgAccountActivated),
+	})
+}
+
+// ChangePassword: POST /api/v1/auth/change-password
+// Satu-satunya route yang menerima token dengan claim pwd_change; mengembalikan token baru
+func (h *Handler) ChangePassword(c *gin.Context) {
+	var req changePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperror.Respond(c, bindError(err))
+		return
+	}
+	cur, ok := CurrentUser(c)
+	if !ok {
+		apperror.Respond(c, errInvalidToken)
+		return
+	}
+	token, exp, user, err := h.service.ChangePassword(c.Request.Context(), cur.ID, req.OldPassword, req.NewPassword)
+	if err != nil {
+		apperror.Respond(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code":       i18n.MsgPasswordChanged,
+		"message":    i18n.Message(c, i18n.MsgPasswordChanged),
+		"token":      token,
+		"expires_in": exp,
+		"user": gin.H{
+			"id_user":              user.IDUser,
+			"username":             user.Username,
+			"role":                 user.Role,
+			"ref_id":               user.RefID,
+			"must_change_password": user.MustChangePassword,
+		},
+	})
+}
+
+// RequireAuth memvalidasi Bearer JWT dan memastikan role user termasuk ke dalam allowedRoles.
+// Token akun yang masih wajib mengganti password awal ditolak (403).
 func RequireAuth(jwtSecret string, allowedRoles ...string) gin.HandlerFunc {
+	return requireAuth(jwtSecret, false, allowedRoles)
+}
+
+// RequireLogin menerima token valid apa pun termasuk yang membawa claim pwd_change;
+// hanya untuk route ganti password
+func RequireLogin(jwtSecret string) gin.HandlerFunc {
+	return requireAuth(jwtSecret, true, nil)
+}
+
+func requireAuth(jwtSecret string, allowPasswordChange bool, allowedRoles []string) gin.HandlerFunc {
 	allowed := map[string]struct{}{}
 	for _, r := range allowedRoles {
 		allowed[r] = struct{}{}
@@ -122,7 +219,7 @@ func RequireAuth(jwtSecret string, allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if !strings.HasPrefix(strings.ToLower(authHeader), "bearer ") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid Authorization header"})
+			apperror.Abort(c, errMissingAuth)
 			return
 		}
 		tokenString := strings.TrimSpace(authHeader[len("Bearer "):])
@@ -134,14 +231,22 @@ func RequireAuth(jwtSecret string, allowedRoles ...string) gin.HandlerFunc {
 			return []byte(jwtSecret), nil
 		})
 		if err != nil || !tok.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+			apperror.Abort(c, errInvalidToken)
 			return
 		}
 		// cek role
 		role, _ := claims["role"].(string)
+		// user id dan role ikut tercatat di setiap baris log request ini (termasuk saat 403)
+		if uid, ok := claims["user_id"]; ok {
+			logging.SetUser(c.Request.Context(), fmt.Sprint(uid), role)
+		}
+		if pending, _ := claims[service.ClaimPasswordChange].(bool); pending && !allowPasswordChange {
+			apperror.Abort(c, errPasswordChange)
+			return
+		}
 		if len(allowed) > 0 {
 			if _, ok := allowed[role]; !ok {
-				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+				apperror.Abort(c, errForbidden)
 				return
 			}
 		}
@@ -149,3 +254,35 @@ func RequireAuth(jwtSecret string, allowedRoles ...string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// User adalah identitas user yang sedang login, dibaca dari claim JWT
+type User struct {
+	ID       int64
+	Username string
+	Role     string
+	RefID    *string
+}
+
+// CurrentUser membaca user dari claim yang diset RequireAuth/RequireLogin; false bila route tidak
+// memakai middleware tersebut atau claim user_id tidak ada
+func CurrentUser(c *gin.Context) (User, bool) {
+	v, ok := c.Get("user")
+	if !ok {
+		return User{}, false
+	}
+	claims, ok := v.(jwt.MapClaims)
+	if !ok {
+		return User{}, false
+	}
+	uid, ok := claims["user_id"].(float64) // angka JSON selalu float64
+	if !ok {
+		return User{}, false
+	}
+	u := User{ID: int64(uid)}
+	u.Username, _ = claims["username"].(string)
+	u.Role, _ = claims["role"].(string)
+	if ref, ok := claims["ref_id"].(string); ok {
+		u.RefID = &ref
+	}
+	return u, true
+}
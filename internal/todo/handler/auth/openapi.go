@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"net/http"
+
+	"pencatatan-data-mahasiswa/internal/openapi"
+	model "pencatatan-data-mahasiswa/internal/todo/model/auth"
+	service "pencatatan-data-mahasiswa/internal/todo/service/auth"
+)
+
+// userSummary adalah data user yang dikembalikan login
+type userSummary struct {
+	IDUser             int64   `json:"id_user"`
+	Username           string  `json:"username"`
+	Role               string  `json:"role"`
+	RefID              *string `json:"ref_id"`
+	MustChangePassword bool    `json:"must_change_password"` // true: token hanya berlaku untuk change-password
+}
+
+type loginResponse struct {
+	Token     string      `json:"token"`
+	ExpiresIn int64       `json:"expires_in"` // detik
+	User      userSummary `json:"user"`
+}
+
+type changePasswordResponse struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Token     string      `json:"token"`
+	ExpiresIn int64       `json:"expires_in"`
+	User      userSummary `json:"user"`
+}
+
+type messageResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type registerResponse struct {
+	Code    string     `json:"code"`
+	Message string     `json:"message"`
+	User    model.User `json:"user"`
+}
+
+// Operations mendeskripsikan route /api/v1/auth untuk dokumen OpenAPI
+func Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodPost, Path: "/api/v1/auth/login", Tag: "auth", Summary: "Login",
+			Description: "Autentikasi user dan menghasilkan JWT (Bearer) untuk route lain.",
+			Body:        loginRequest{}, Response: loginResponse{}, Envelope: openapi.EnvelopeRaw},
+		{Method: http.MethodPost, Path: "/api/v1/auth/register", Tag: "auth", Summary: "Registrasi user",
+			Description: "ref_id wajib untuk mahasiswa (NIM) dan dosen (id_dosen). Untuk operator, ref_id opsional berisi id_prodi: operator hanya memproses pengajuan cuti prodi tersebut; tanpa ref_id operator melihat semua prodi.",
+			Body:        registerRequest{}, Status: http.StatusCreated, Response: registerResponse{}, Envelope: openapi.EnvelopeRaw},
+		{Method: http.MethodPost, Path: "/api/v1/auth/activate", Tag: "auth", Summary: "Aktivasi akun",
+			Description: "Memasang password untuk akun mahasiswa/dosen yang dibuat dengan create_account=activation.",
+			Body:        activateRequest{}, Response: messageResponse{}, Envelope: openapi.EnvelopeRaw},
+		{Method: http.MethodPost, Path: "/api/v1/auth/change-password", Tag: "auth", Summary: "Ganti password",
+			Description: "Wajib dipanggil setelah login pertama bila must_change_password=true; route lain menolak token tersebut (403 password_change_required).",
+			Roles:       service.Roles(), Body: changePasswordRequest{}, Response: changePasswordResponse{}, Envelope: openapi.EnvelopeRaw},
+	}
+}
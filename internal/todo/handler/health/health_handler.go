@@ -0,0 +1,133 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"pencatatan-data-mahasiswa/internal/db"
+	"pencatatan-data-mahasiswa/migrations"
+)
+
+// pingTimeout membatasi lama pengecekan database agar probe orchestrator tidak menggantung
+const pingTimeout = 2 * time.Second
+
+type Handler struct {
+	pool *db.Pool
+}
+
+func NewHandler(pool *db.Pool) *Handler {
+	return &Handler{pool: pool}
+}
+
+// Kode kegagalan readiness. Endpoint tanpa autentikasi, jadi detail error (host, driver, SQL)
+// hanya ditulis ke log, bukan ke response.
+const (
+	codeDatabaseUnavailable = "database_unavailable"
+	codeMigrationCheck      = "migration_check_failed"
+	codeMigrationPending    = "migration_pending"
+	codeMigrationDirty      = "migration_dirty"
+	codeMigrationMismatch   = "migration_mismatch" // skema lebih baru dari binary
+)
+
+type checkResult struct {
+	Status string `json:"status"`         // ok | error
+	Code   string `json:"code,omitempty"` // hanya bila error
+}
+
+type migrationCheck struct {
+	checkResult
+	Current  uint `json:"current"`
+	Expected uint `json:"expected"`
+	Dirty    bool `json:"dirty"`
+}
+
+type poolStats struct {
+	TotalConns        int32 `json:"total_conns"`
+	IdleConns         int32 `json:"idle_conns"`
+	AcquiredConns     int32 `json:"acquired_conns"`
+	ConstructingConns int32 `json:"constructing_conns"`
+	MaxConns          int32 `json:"max_conns"`
+	AcquireCount      int64 `json:"acquire_count"`
+	EmptyAcquireCount int64 `json:"empty_acquire_count"`
+	CanceledAcquires  int64 `json:"canceled_acquire_count"`
+}
+
+// Liveness: GET /healthz — proses hidup, tanpa menyentuh dependensi
+func (h *Handler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness: GET /readyz — ping pool, versi migrasi harus sama dengan migrasi tersemat terbaru.
+// Mengembalikan 503 beserta detail bila salah satu pengecekan gagal.
+func (h *Handler) Readiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), pingTimeout)
+	defer cancel()
+
+	ready := true
+	database := checkResult{Status: "ok"}
+	if err := h.pool.Ping(ctx); err != nil {
+		ready = false
+		database = checkResult{Status: "error", Code: codeDatabaseUnavailable}
+		slog.WarnContext(ctx, "readiness: database ping failed", "error", err)
+	}
+
+	mig := h.checkMigrations(ctx)
+	if mig.Status != "ok" {
+		ready = false
+	}
+
+	st := h.pool.Stat()
+	stats := poolStats{
+		TotalConns:        st.TotalConns(),
+		IdleConns:         st.IdleConns(),
+		AcquiredConns:     st.AcquiredConns(),
+		ConstructingConns: st.ConstructingConns(),
+		MaxConns:          st.MaxConns(),
+		AcquireCount:      st.AcquireCount(),
+		EmptyAcquireCount: st.EmptyAcquireCount(),
+		CanceledAcquires:  st.CanceledAcquireCount(),
+	}
+
+	status, code := "ok", http.StatusOK
+	if !ready {
+		status, code = "unavailable", http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"status": status,
+		"checks": gin.H{"database": database, "migrations": mig},
+		"pool":   stats,
+	})
+}
+
+func (h *Handler) checkMigrations(ctx context.Context) migrationCheck {
+	out := migrationCheck{checkResult: checkResult{Status: "ok"}}
+	fail := func(code string) {
+		out.checkResult = checkResult{Status: "error", Code: code}
+	}
+	expected, err := migrations.Latest()
+	if err != nil {
+		slog.ErrorContext(ctx, "readiness: read embedded migrations", "error", err)
+		fail(codeMigrationCheck)
+		return out
+	}
+	out.Expected = expected
+
+	current, dirty, ok, err := db.MigrationVersion(ctx, h.pool)
+	switch {
+	case err != nil:
+		slog.WarnContext(ctx, "readiness: read schema version", "error", err)
+		fail(codeMigrationCheck)
+	case !ok || (!dirty && current < expected):
+		fail(codeMigrationPending)
+	case dirty:
+		fail(codeMigrationDirty)
+	case current != expected:
+		fail(codeMigrationMismatch)
+	}
+	out.Current, out.Dirty = current, dirty
+	return out
+}
@@ -0,0 +1,34 @@
+package health
+
+import (
+	"net/http"
+
+	"pencatatan-data-mahasiswa/internal/openapi"
+)
+
+type livenessResponse struct {
+	Status string `json:"status"`
+}
+
+type readinessChecks struct {
+	Database   checkResult    `json:"database"`
+	Migrations migrationCheck `json:"migrations"`
+}
+
+type readinessResponse struct {
+	Status string          `json:"status"` // ok | unavailable (503)
+	Checks readinessChecks `json:"checks"`
+	Pool   poolStats       `json:"pool"`
+}
+
+// Operations mendeskripsikan probe /healthz dan /readyz untuk dokumen OpenAPI
+func Operations() []openapi.Operation {
+	return []openapi.Operation{
+		{Method: http.MethodGet, Path: "/healthz", Tag: "system", Summary: "Liveness probe",
+			Response: livenessResponse{}, Envelope: openapi.EnvelopeRaw},
+		{Method: http.MethodGet, Path: "/readyz", Tag: "system", Summary: "Readiness probe",
+			Description: "503 bila database tidak terjangkau atau migrasi belum sesuai. checks.*.code: database_unavailable, " +
+				"migration_check_failed, migration_pending, migration_dirty, migration_mismatch; detail error hanya di log.",
+			Response: readinessResponse{}, Envelope: openapi.EnvelopeRaw},
+	}
+}
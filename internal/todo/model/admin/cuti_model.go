@@ -0,0 +1,43 @@
+package admin
+
+import "time"
+
+// Cuti merepresentasikan baris pada tabel cuti (pengajuan cuti akademik satu semester).
+// IDProdi diambil dari mahasiswa dan dipakai untuk menentukan operator yang memproses.
+// Isi lampiran tidak ikut di sini; unduh lewat GET /api/v1/cuti/{id}/lampiran.
+// Diterapkan = status mahasiswa sudah diubah ke Cuti untuk semester tersebut; Selesai = semester
+// berikutnya sudah dimulai dan status sudah dikembalikan ke Aktif.
+type Cuti struct {
+	IDCuti         int64      `db:"id_cuti" json:"id_cuti"`
+	IDMahasiswa    string     `db:"id_mahasiswa" json:"id_mahasiswa"`
+	IDProdi        string     `db:"id_prodi" json:"id_prodi"`
+	IDSemester     string     `db:"id_semester" json:"id_semester"`
+	Alasan         string     `db:"alasan" json:"alasan"`
+	LampiranNama   string     `db:"lampiran_nama" json:"lampiran_nama"`
+	LampiranTipe   string     `db:"lampiran_tipe" json:"lampiran_tipe"`
+	LampiranUkuran int        `db:"lampiran_ukuran" json:"lampiran_ukuran"`
+	Status         string     `db:"status" json:"status" enum:"Diajukan,Disetujui,Ditolak,Dibatalkan"`
+	Catatan        *string    `db:"catatan" json:"catatan,omitempty"`
+	IDReviewer     *int64     `db:"id_reviewer" json:"id_reviewer,omitempty"`
+	ReviewedAt     *time.Time `db:"reviewed_at" json:"reviewed_at,omitempty"`
+	Diterapkan     bool       `db:"diterapkan" json:"diterapkan"`
+	Selesai        bool       `db:"selesai" json:"selesai"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// CutiLampiran adalah berkas pendukung pengajuan cuti
+type CutiLampiran struct {
+	Nama string
+	Tipe string
+	Data []byte
+}
+
+// CutiPolicy adalah batas cuti suatu prodi. Default = prodi belum punya aturan sendiri
+// sehingga MaksSemester berasal dari konfigurasi (cuti.default_max_semesters).
+type CutiPolicy struct {
+	IDProdi      string     `db:"id_prodi" json:"id_prodi"`
+	MaksSemester int        `db:"maks_semester" json:"maks_semester"`
+	Default      bool       `json:"default"`
+	UpdatedAt    *time.Time `db:"updated_at" json:"updated_at,omitempty"`
+}
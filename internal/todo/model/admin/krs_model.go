@@ -0,0 +1,42 @@
+package admin
+
+import "time"
+
+// KRSPengajuan merepresentasikan baris pada tabel krs_pengajuan: status KRS satu mahasiswa pada
+// satu semester. IDDosen adalah PA yang memproses; Catatan diisi PA saat mengembalikan/menyetujui.
+type KRSPengajuan struct {
+	IDPengajuan int64      `db:"id_pengajuan" json:"id_pengajuan"`
+	IDMahasiswa string     `db:"id_mahasiswa" json:"id_mahasiswa"`
+	NamaLengkap string     `db:"nama_lengkap" json:"nama_lengkap"`
+	IDSemester  string     `db:"id_semester" json:"id_semester"`
+	Status      string     `db:"status" json:"status" enum:"Diajukan,Dikembalikan,Disetujui"`
+	IDDosen     *string    `db:"id_dosen" json:"id_dosen,omitempty"`
+	Catatan     *string    `db:"catatan" json:"catatan,omitempty"`
+	TotalSKS    int        `db:"total_sks" json:"total_sks"`
+	DiajukanAt  time.Time  `db:"diajukan_at" json:"diajukan_at"`
+	ReviewedAt  *time.Time `db:"reviewed_at" json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// KRSKelas adalah satu kelas yang diambil pada KRS beserta data mata kuliahnya
+type KRSKelas struct {
+	IDKRS     int64  `db:"id_krs" json:"id_krs"`
+	IDKelas   string `db:"id_kelas" json:"id_kelas"`
+	NamaKelas string `db:"nama_kelas" json:"nama_kelas"`
+	IDMK      string `db:"id_mk" json:"id_mk"`
+	KodeMK    string `db:"kode_mk" json:"kode_mk"`
+	NamaMK    string `db:"nama_mk" json:"nama_mk"`
+	SKS       int    `db:"sks" json:"sks"`
+}
+
+// KRS adalah KRS satu mahasiswa pada satu semester. Status "Draft" berarti belum pernah diajukan
+// (Pengajuan nil); selain itu sama dengan Pengajuan.Status.
+type KRS struct {
+	IDMahasiswa string        `json:"id_mahasiswa"`
+	IDSemester  string        `json:"id_semester"`
+	Status      string        `json:"status" enum:"Draft,Diajukan,Dikembalikan,Disetujui"`
+	TotalSKS    int           `json:"total_sks"`
+	Kelas       []KRSKelas    `json:"kelas"`
+	Pengajuan   *KRSPengajuan `json:"pengajuan,omitempty"`
+}
@@ -0,0 +1,53 @@
+package admin
+
+import "time"
+
+// Kurikulum merepresentasikan baris pada tabel kurikulum: satu versi kurikulum prodi per tahun.
+// JumlahMK dan TotalSKS dihitung dari kurikulum_mk.
+type Kurikulum struct {
+	IDKurikulum int64     `db:"id_kurikulum" json:"id_kurikulum"`
+	IDProdi     string    `db:"id_prodi" json:"id_prodi"`
+	Tahun       int       `db:"tahun" json:"tahun"`
+	Nama        string    `db:"nama" json:"nama"`
+	Aktif       bool      `db:"aktif" json:"aktif"`
+	JumlahMK    int       `db:"jumlah_mk" json:"jumlah_mk"`
+	TotalSKS    int       `db:"total_sks" json:"total_sks"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// KurikulumMK adalah mata kuliah pada kurikulum beserta semester anjuran, jenis dan prasyaratnya
+type KurikulumMK struct {
+	IDMK      string      `db:"id_mk" json:"id_mk"`
+	KodeMK    string      `db:"kode_mk" json:"kode_mk"`
+	NamaMK    string      `db:"nama_mk" json:"nama_mk"`
+	SKS       int         `db:"sks" json:"sks"`
+	Semester  int         `db:"semester" json:"semester"`
+	Jenis     string      `db:"jenis" json:"jenis" enum:"Wajib,Pilihan"`
+	Prasyarat []Prasyarat `json:"prasyarat"`
+}
+
+// KurikulumDetail adalah kurikulum lengkap dengan daftar mata kuliahnya, urut semester lalu kode
+type KurikulumDetail struct {
+	Kurikulum
+	MataKuliah []KurikulumMK `json:"mata_kuliah"`
+}
+
+// Prasyarat merepresentasikan baris pada tabel prasyarat_mk: IDMK hanya boleh diambil bila
+// IDMKPrasyarat sudah lulus dengan nilai minimal NilaiMinimal. Berlaku per mata kuliah, tidak per kurikulum.
+type Prasyarat struct {
+	IDMK            string `db:"id_mk" json:"id_mk"`
+	IDMKPrasyarat   string `db:"id_mk_prasyarat" json:"id_mk_prasyarat"`
+	KodeMKPrasyarat string `db:"kode_mk_prasyarat" json:"kode_mk_prasyarat"`
+	NamaMKPrasyarat string `db:"nama_mk_prasyarat" json:"nama_mk_prasyarat"`
+	NilaiMinimal    string `db:"nilai_minimal" json:"nilai_minimal" enum:"A,AB,B,BC,C,D,E"`
+}
+
+// PrasyaratBelumTerpenuhi adalah satu prasyarat yang belum dipenuhi mata kuliah pada KRS.
+// NilaiTerbaik nil bila prasyarat belum pernah dinilai pada KRS yang disetujui.
+type PrasyaratBelumTerpenuhi struct {
+	KodeMK string `json:"kode_mk"`
+	NamaMK string `json:"nama_mk"`
+	Prasyarat
+	NilaiTerbaik *string `json:"nilai_terbaik"`
+}
@@ -0,0 +1,26 @@
+package admin
+
+import "time"
+
+// MahasiswaStatusHistory merepresentasikan baris pada tabel mahasiswa_status_history.
+// Override = transisi di luar aturan yang dipaksakan admin. IDUser NULL bila akun pelaku sudah dihapus.
+type MahasiswaStatusHistory struct {
+	IDHistory      int64     `db:"id_history" json:"id_history"`
+	IDMahasiswa    string    `db:"id_mahasiswa" json:"id_mahasiswa"`
+	StatusLama     string    `db:"status_lama" json:"status_lama"`
+	StatusBaru     string    `db:"status_baru" json:"status_baru"`
+	TanggalEfektif time.Time `db:"tanggal_efektif" json:"tanggal_efektif"`
+	NomorSK        *string   `db:"nomor_sk" json:"nomor_sk,omitempty"`
+	Alasan         string    `db:"alasan" json:"alasan"`
+	Override       bool      `db:"override" json:"override"`
+	IDUser         *int64    `db:"id_user" json:"id_user,omitempty"`
+	Username       string    `db:"username" json:"username"`
+	Role           string    `db:"role" json:"role"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+}
+
+// MahasiswaStatusChange adalah hasil satu transisi status: data mahasiswa terbaru dan entri riwayatnya
+type MahasiswaStatusChange struct {
+	Mahasiswa Mahasiswa              `json:"mahasiswa"`
+	History   MahasiswaStatusHistory `json:"history"`
+}
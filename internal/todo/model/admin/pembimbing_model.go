@@ -0,0 +1,38 @@
+package admin
+
+import "time"
+
+// PembimbingAkademik merepresentasikan baris pada tabel pembimbing_akademik. TanggalSelesai nil
+// berarti dosen tersebut pembimbing saat ini. IDUser NULL bila akun pelaku sudah dihapus.
+type PembimbingAkademik struct {
+	IDPembimbing   int64      `db:"id_pembimbing" json:"id_pembimbing"`
+	IDMahasiswa    string     `db:"id_mahasiswa" json:"id_mahasiswa"`
+	IDDosen        string     `db:"id_dosen" json:"id_dosen"`
+	NamaDosen      string     `db:"nama_dosen" json:"nama_dosen"`
+	TanggalMulai   time.Time  `db:"tanggal_mulai" json:"tanggal_mulai"`
+	TanggalSelesai *time.Time `db:"tanggal_selesai" json:"tanggal_selesai,omitempty"`
+	Alasan         *string    `db:"alasan" json:"alasan,omitempty"`
+	IDUser         *int64     `db:"id_user" json:"id_user,omitempty"`
+	Username       string     `db:"username" json:"username"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+}
+
+// Bimbingan adalah mahasiswa bimbingan seorang dosen PA beserta IPK dari KRS yang sudah disetujui.
+// IPK nil bila belum ada nilai; TotalSKS adalah SKS mata kuliah yang sudah bernilai.
+type Bimbingan struct {
+	IDMahasiswa  string    `db:"id_mahasiswa" json:"id_mahasiswa"`
+	NamaLengkap  string    `db:"nama_lengkap" json:"nama_lengkap"`
+	IDProdi      string    `db:"id_prodi" json:"id_prodi"`
+	Angkatan     int       `db:"angkatan" json:"angkatan"`
+	Status       string    `db:"status" json:"status"`
+	IPK          *float64  `db:"ipk" json:"ipk"`
+	TotalSKS     int       `db:"total_sks" json:"total_sks"`
+	TanggalMulai time.Time `db:"tanggal_mulai" json:"tanggal_mulai"`
+}
+
+// BimbinganAssignment adalah hasil penetapan PA massal per prodi/angkatan
+type BimbinganAssignment struct {
+	IDDosen  string   `json:"id_dosen"`
+	Assigned []string `json:"assigned"` // NIM yang pembimbingnya berubah
+	Skipped  []string `json:"skipped"`  // NIM yang sudah dibimbing dosen ini atau dilewati karena sudah punya PA
+}
@@ -11,6 +11,11 @@ type User struct {
 	PasswordHash string     `db:"password_hash" json:"-"`
 	Role         string     `db:"role" json:"role"`
 	RefID        *string    `db:"ref_id" json:"ref_id"`
+	// MustChangePassword diset untuk akun yang dibuat otomatis; token login hanya boleh
+	// dipakai untuk mengganti password sampai flag ini dihapus
+	MustChangePassword  bool       `db:"must_change_password" json:"must_change_password"`
+	ActivationTokenHash *string    `db:"activation_token_hash" json:"-"` // sha256 hex token aktivasi
+	ActivationExpiresAt *time.Time `db:"activation_expires_at" json:"activation_expires_at,omitempty"`
 	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt    time.Time  `db:"updated_at" json:"updated_at"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,250 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"pencatatan-data-mahasiswa/internal/db"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+)
+
+type CutiRepository struct {
+	db db.DBTX
+}
+
+func NewCutiRepository(conn db.DBTX) *CutiRepository {
+	return &CutiRepository{db: conn}
+}
+
+func (r *CutiRepository) conn(ctx context.Context) db.DBTX {
+	return db.Conn(ctx, r.db)
+}
+
+// CutiFilterFields adalah field yang boleh dipakai pada grammar filter list cuti.
+// Query cuti selalu JOIN mahasiswa m, sehingga kolom diberi alias tabel.
+var CutiFilterFields = map[string]FilterField{
+	"status":       {Column: "c.status", Kind: FilterText, Enum: []string{"Diajukan", "Disetujui", "Ditolak", "Dibatalkan"}},
+	"id_semester":  {Column: "c.id_semester", Kind: FilterText},
+	"id_mahasiswa": {Column: "c.id_mahasiswa", Kind: FilterText},
+	"id_prodi":     {Column: "m.id_prodi", Kind: FilterText},
+	"created_at":   {Column: "c.created_at", Kind: FilterTime},
+}
+
+const cutiColumns = `c.id_cuti, c.id_mahasiswa, m.id_prodi, c.id_semester, c.alasan, c.lampiran_nama, c.lampiran_tipe,
+	c.lampiran_ukuran, c.status, c.catatan, c.id_reviewer, c.reviewed_at, c.diterapkan, c.selesai, c.created_at, c.updated_at`
+
+const cutiFrom = ` FROM cuti c JOIN mahasiswa m ON m.id_mahasiswa = c.id_mahasiswa`
+
+func scanCuti(row pgx.Row) (*model.Cuti, error) {
+	var c model.Cuti
+	if err := row.Scan(&c.IDCuti, &c.IDMahasiswa, &c.IDProdi, &c.IDSemester, &c.Alasan, &c.LampiranNama, &c.LampiranTipe,
+		&c.LampiranUkuran, &c.Status, &c.Catatan, &c.IDReviewer, &c.ReviewedAt, &c.Diterapkan, &c.Selesai, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *CutiRepository) query(ctx context.Context, q string, args ...any) ([]model.Cuti, error) {
+	rows, err := r.conn(ctx).Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.Cuti{}
+	for rows.Next() {
+		c, err := scanCuti(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *c)
+	}
+	return out, rows.Err()
+}
+
+func cutiWhere(filters []Filter) (string, []any) {
+	where, args := appendFilterSQL(nil, nil, filters)
+	if len(where) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(where, " AND "), args
+}
+
+// List mengembalikan pengajuan cuti; orderBy sudah disanitasi service (kolom dengan alias c.)
+func (r *CutiRepository) List(ctx context.Context, filters []Filter, limit, offset int, orderBy string) ([]model.Cuti, error) {
+	where, args := cutiWhere(filters)
+	if orderBy == "" {
+		orderBy = "c.created_at DESC"
+	}
+	q := "SELECT " + cutiColumns + cutiFrom + where + " ORDER BY " + orderBy + ", c.id_cuti DESC"
+	if limit > 0 {
+		args = append(args, limit)
+		q += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if offset > 0 {
+		args = append(args, offset)
+		q += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	return r.query(ctx, q, args...)
+}
+
+func (r *CutiRepository) Count(ctx context.Context, filters []Filter) (int64, error) {
+	where, args := cutiWhere(filters)
+	var total int64
+	err := r.conn(ctx).QueryRow(ctx, "SELECT COUNT(*)"+cutiFrom+where, args...).Scan(&total)
+	return total, err
+}
+
+func (r *CutiRepository) GetByID(ctx context.Context, id int64) (*model.Cuti, error) {
+	return scanCuti(r.conn(ctx).QueryRow(ctx, "SELECT "+cutiColumns+cutiFrom+" WHERE c.id_cuti = $1", id))
+}
+
+// LockByID mengunci baris cuti (FOR UPDATE) agar dua reviewer tidak memproses pengajuan yang sama
+func (r *CutiRepository) LockByID(ctx context.Context, id int64) error {
+	const q = `SELECT 1 FROM cuti WHERE id_cuti = $1 FOR UPDATE`
+	var x int
+	return r.conn(ctx).QueryRow(ctx, q, id).Scan(&x)
+}
+
+func (r *CutiRepository) GetLampiran(ctx context.Context, id int64) (*model.CutiLampiran, error) {
+	const q = `SELECT lampiran_nama, lampiran_tipe, lampiran FROM cuti WHERE id_cuti = $1`
+	var l model.CutiLampiran
+	if err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&l.Nama, &l.Tipe, &l.Data); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *CutiRepository) Create(ctx context.Context, c *model.Cuti, lampiran []byte) (*model.Cuti, error) {
+	const q = `INSERT INTO cuti (id_mahasiswa, id_semester, alasan, lampiran_nama, lampiran_tipe, lampiran_ukuran, lampiran)
+              VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id_cuti`
+	var id int64
+	if err := r.conn(ctx).QueryRow(ctx, q, c.IDMahasiswa, c.IDSemester, c.Alasan, c.LampiranNama, c.LampiranTipe, len(lampiran), lampiran).Scan(&id); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+// SetStatus mengubah status pengajuan; reviewer diisi saat disetujui/ditolak (reviewed_at = sekarang)
+func (r *CutiRepository) SetStatus(ctx context.Context, id int64, status string, catatan *string, reviewer *int64) (*model.Cuti, error) {
+	const q = `UPDATE cuti SET status = $1, catatan = $2, id_reviewer = $3,
+              reviewed_at = CASE WHEN $3::bigint IS NULL THEN reviewed_at ELSE CURRENT_TIMESTAMP END
+              WHERE id_cuti = $4`
+	ct, err := r.conn(ctx).Exec(ctx, q, status, catatan, reviewer, id)
+	if err != nil {
+		return nil, err
+	}
+	if ct.RowsAffected() == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	return r.GetByID(ctx, id)
+}
+
+// MarkApplied menandai status mahasiswa sudah diubah ke Cuti untuk pengajuan ini
+func (r *CutiRepository) MarkApplied(ctx context.Context, id int64) error {
+	return r.mark(ctx, `UPDATE cuti SET diterapkan = TRUE WHERE id_cuti = $1`, id)
+}
+
+// MarkFinished menandai masa cuti sudah berakhir dan tidak perlu diproses lagi
+func (r *CutiRepository) MarkFinished(ctx context.Context, id int64) error {
+	return r.mark(ctx, `UPDATE cuti SET selesai = TRUE WHERE id_cuti = $1`, id)
+}
+
+func (r *CutiRepository) mark(ctx context.Context, q string, id int64) error {
+	ct, err := r.conn(ctx).Exec(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// CountActive menghitung pengajuan Diajukan/Disetujui milik mahasiswa (satu per semester),
+// tanpa pengajuan excludeID; dipakai untuk batas semester cuti
+func (r *CutiRepository) CountActive(ctx context.Context, idMahasiswa string, excludeID int64) (int, error) {
+	const q = `SELECT COUNT(*) FROM cuti WHERE id_mahasiswa = $1 AND status IN ('Diajukan','Disetujui') AND id_cuti <> $2`
+	var n int
+	err := r.conn(ctx).QueryRow(ctx, q, idMahasiswa, excludeID).Scan(&n)
+	return n, err
+}
+
+// OpenStatus mengembalikan status pengajuan Diajukan/Disetujui untuk (mahasiswa, semester); "" bila tidak ada
+func (r *CutiRepository) OpenStatus(ctx context.Context, idMahasiswa, idSemester string) (string, error) {
+	const q = `SELECT status FROM cuti WHERE id_mahasiswa = $1 AND id_semester = $2 AND status IN ('Diajukan','Disetujui')`
+	var s string
+	err := r.conn(ctx).QueryRow(ctx, q, idMahasiswa, idSemester).Scan(&s)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return s, err
+}
+
+// HasKRS true bila mahasiswa sudah mengambil kelas atau mengajukan KRS pada semester tersebut
+func (r *CutiRepository) HasKRS(ctx context.Context, idMahasiswa, idSemester string) (bool, error) {
+	const q = `SELECT EXISTS (SELECT 1 FROM krs WHERE id_mahasiswa = $1 AND id_semester = $2 AND status_krs = 'Diambil')
+              OR EXISTS (SELECT 1 FROM krs_pengajuan WHERE id_mahasiswa = $1 AND id_semester = $2)`
+	var ok bool
+	err := r.conn(ctx).QueryRow(ctx, q, idMahasiswa, idSemester).Scan(&ok)
+	return ok, err
+}
+
+// ListToApply mengembalikan pengajuan yang disetujui untuk semester idSemester tetapi belum diterapkan
+func (r *CutiRepository) ListToApply(ctx context.Context, idSemester string) ([]model.Cuti, error) {
+	return r.query(ctx, "SELECT "+cutiColumns+cutiFrom+
+		" WHERE c.status = 'Disetujui' AND NOT c.diterapkan AND c.id_semester = $1 ORDER BY c.id_cuti", idSemester)
+}
+
+// ListToFinish mengembalikan cuti yang sudah diterapkan untuk semester selain idSemester dan belum selesai
+func (r *CutiRepository) ListToFinish(ctx context.Context, idSemester string) ([]model.Cuti, error) {
+	return r.query(ctx, "SELECT "+cutiColumns+cutiFrom+
+		" WHERE c.status = 'Disetujui' AND c.diterapkan AND NOT c.selesai AND c.id_semester <> $1 ORDER BY c.id_cuti", idSemester)
+}
+
+func (r *CutiRepository) GetSemester(ctx context.Context, id string) (*model.Semester, error) {
+	const q = `SELECT id_semester, tahun_ajaran, term, tanggal_mulai, tanggal_selesai, created_at, updated_at FROM semester WHERE id_semester = $1`
+	var s model.Semester
+	if err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&s.IDSemester, &s.TahunAjaran, &s.Term, &s.TanggalMulai, &s.TanggalSelesai, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// CurrentSemester mengembalikan semester reguler (Ganjil/Genap) terakhir yang sudah dimulai pada today
+func (r *CutiRepository) CurrentSemester(ctx context.Context, today time.Time) (*model.Semester, error) {
+	const q = `SELECT id_semester FROM semester
+              WHERE term <> 'Antara' AND tanggal_mulai IS NOT NULL AND tanggal_mulai <= $1
+              ORDER BY tanggal_mulai DESC, id_semester DESC LIMIT 1`
+	var id string
+	if err := r.conn(ctx).QueryRow(ctx, q, today).Scan(&id); err != nil {
+		return nil, err
+	}
+	return r.GetSemester(ctx, id)
+}
+
+// GetPolicy mengembalikan batas cuti prodi; pgx.ErrNoRows bila prodi belum punya aturan sendiri
+func (r *CutiRepository) GetPolicy(ctx context.Context, idProdi string) (*model.CutiPolicy, error) {
+	const q = `SELECT id_prodi, maks_semester, updated_at FROM prodi_cuti_policy WHERE id_prodi = $1`
+	var p model.CutiPolicy
+	if err := r.conn(ctx).QueryRow(ctx, q, idProdi).Scan(&p.IDProdi, &p.MaksSemester, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *CutiRepository) UpsertPolicy(ctx context.Context, idProdi string, maks int) (*model.CutiPolicy, error) {
+	const q = `INSERT INTO prodi_cuti_policy (id_prodi, maks_semester) VALUES ($1, $2)
+              ON CONFLICT (id_prodi) DO UPDATE SET maks_semester = EXCLUDED.maks_semester, updated_at = CURRENT_TIMESTAMP
+              RETURNING id_prodi, maks_semester, updated_at`
+	var p model.CutiPolicy
+	if err := r.conn(ctx).QueryRow(ctx, q, idProdi, maks).Scan(&p.IDProdi, &p.MaksSemester, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -7,43 +7,75 @@ import (
     "strings"
 
     "github.com/jackc/pgx/v5"
-    "github.com/jackc/pgx/v5/pgxpool"
 
+    "pencatatan-data-mahasiswa/internal/db"
     model "pencatatan-data-mahasiswa/internal/todo/model/admin"
 )
 
 type DosenRepository struct {
-    pool *pgxpool.Pool
+    db db.DBTX
 }
 
-func NewDosenRepository(pool *pgxpool.Pool) *DosenRepository {
-    return &DosenRepository{pool: pool}
+func NewDosenRepository(conn db.DBTX) *DosenRepository {
+    return &DosenRepository{db: conn}
 }
 
-// List dosen dengan optional q (search nama/nidn/email), pagination dan orderBy sudah disanitasi di service/handler
-func (r *DosenRepository) List(ctx context.Context, q string, limit, offset int, orderBy string) ([]model.Dosen, error) {
-    sb := strings.Builder{}
-    args := []any{}
-    sb.WriteString("SELECT id_dosen, nidn, nama_dosen, email, no_hp, jabatan_akademik, created_at, updated_at FROM dosen")
+func (r *DosenRepository) conn(ctx context.Context) db.DBTX {
+    return db.Conn(ctx, r.db)
+}
+
+// DosenFilterFields adalah field yang boleh dipakai pada grammar filter list dosen
+var DosenFilterFields = map[string]FilterField{
+    "nidn":             {Column: "nidn", Kind: FilterText, Nullable: true},
+    "email":            {Column: "email", Kind: FilterText, Nullable: true},
+    "no_hp":            {Column: "no_hp", Kind: FilterText, Nullable: true},
+    "jabatan_akademik": {Column: "jabatan_akademik", Kind: FilterText, Nullable: true},
+    "created_at":       {Column: "created_at", Kind: FilterTime},
+    "updated_at":       {Column: "updated_at", Kind: FilterTime},
+}
 
+// dosenWhere membangun klausa WHERE beserta argumennya; dipakai bersama oleh List dan Count.
+// rank berisi ekspresi relevansi bila match = fuzzy
+func dosenWhere(q, match string, filters []Filter) (string, string, []any) {
+    args := []any{}
+    where := []string{}
+    rank := ""
     if q != "" {
         // cari di nama_dosen, nidn, email (case-insensitive)
-        args = append(args, "%"+q+"%")
-        args = append(args, "%"+q+"%")
-        args = append(args, "%"+q+"%")
-        sb.WriteString(fmt.Sprintf(" WHERE (nama_dosen ILIKE $%d OR nidn ILIKE $%d OR email ILIKE $%d)", 1, 2, 3))
+        var cond string
+        cond, rank, args = nameSearchClause(match, q, "nama_dosen", []string{"nidn", "email"}, args)
+        where = append(where, cond)
     }
+    where, args = appendFilterSQL(where, args, filters)
+    if len(where) == 0 {
+        return "", rank, args
+    }
+    return " WHERE " + strings.Join(where, " AND "), rank, args
+}
+
+// List dosen dengan optional q (search nama/nidn/email), pagination dan orderBy sudah disanitasi di service/handler
+func (r *DosenRepository) List(ctx context.Context, q, match string, filters []Filter, limit, offset int, orderBy string) ([]model.Dosen, error) {
+    sb := strings.Builder{}
+    sb.WriteString("SELECT id_dosen, nidn, nama_dosen, email, no_hp, jabatan_akademik, created_at, updated_at FROM dosen")
+
+    where, rank, args := dosenWhere(q, match, filters)
+    sb.WriteString(where)
     if orderBy == "" {
         orderBy = "nama_dosen ASC"
     }
     sb.WriteString(" ORDER BY ")
+    if rank != "" {
+        // pencarian fuzzy: paling relevan terlebih dulu
+        sb.WriteString(rank)
+        sb.WriteString(" DESC, ")
+    }
     sb.WriteString(orderBy)
     sb.WriteString(" LIMIT ")
     sb.WriteString(fmt.Sprintf("%d", limit))
     sb.WriteString(" OFFSET ")
     sb.WriteString(fmt.Sprintf("%d", offset))
 
-    rows, err := r.pool.Query(ctx, sb.String(), args...)
+    rows, err := r.conn(ctx).Query(ctx, sb.String(), args...)
     if err != nil {
         return nil, err
     }
@@ -60,9 +92,19 @@ func (r *DosenRepository) List(ctx context.Context, q string, limit, offset int,
     return out, rows.Err()
 }
 
+// Count mengembalikan jumlah dosen yang cocok dengan pencarian yang sama seperti List
+func (r *DosenRepository) Count(ctx context.Context, q, match string, filters []Filter) (int64, error) {
+    where, _, args := dosenWhere(q, match, filters)
+    var total int64
+    if err := r.conn(ctx).QueryRow(ctx, "SELECT COUNT(*) FROM dosen"+where, args...).Scan(&total); err != nil {
+        return 0, err
+    }
+    return total, nil
+}
+
 func (r *DosenRepository) GetByID(ctx context.Context, id string) (*model.Dosen, error) {
     const q = `SELECT id_dosen, nidn, nama_dosen, email, no_hp, jabatan_akademik, created_at, updated_at FROM dosen WHERE id_dosen = $1`
-    row := r.pool.QueryRow(ctx, q, id)
+    row := r.conn(ctx).QueryRow(ctx, q, id)
     var d model.Dosen
     if err := row.Scan(&d.IDDosen, &d.NIDN, &d.NamaDosen, &d.Email, &d.NoHP, &d.JabatanAkademik, &d.CreatedAt, &d.UpdatedAt); err != nil {
         return nil, err
@@ -73,7 +115,7 @@ func (r *DosenRepository) GetByID(ctx context.Context, id string) (*model.Dosen,
 func (r *DosenRepository) ExistsID(ctx context.Context, id string) (bool, error) {
     const q = `SELECT 1 FROM dosen WHERE id_dosen = $1 LIMIT 1`
     var dummy int
-    err := r.pool.QueryRow(ctx, q, id).Scan(&dummy)
+    err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&dummy)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -87,7 +129,7 @@ func (r *DosenRepository) ExistsNIDN(ctx context.Context, nidn string, excludeID
     if excludeID != nil {
         const q = `SELECT 1 FROM dosen WHERE nidn = $1 AND id_dosen <> $2 LIMIT 1`
         var dummy int
-        err := r.pool.QueryRow(ctx, q, nidn, *excludeID).Scan(&dummy)
+        err := r.conn(ctx).QueryRow(ctx, q, nidn, *excludeID).Scan(&dummy)
         if errors.Is(err, pgx.ErrNoRows) {
             return false, nil
         }
@@ -98,7 +140,7 @@ func (r *DosenRepository) ExistsNIDN(ctx context.Context, nidn string, excludeID
     }
     const q = `SELECT 1 FROM dosen WHERE nidn = $1 LIMIT 1`
     var dummy int
-    err := r.pool.QueryRow(ctx, q, nidn).Scan(&dummy)
+    err := r.conn(ctx).QueryRow(ctx, q, nidn).Scan(&dummy)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -112,7 +154,7 @@ func (r *DosenRepository) ExistsEmail(ctx context.Context, email string, exclude
     if excludeID != nil {
         const q = `SELECT 1 FROM dosen WHERE email = $1 AND id_dosen <> $2 LIMIT 1`
         var dummy int
-        err := r.pool.QueryRow(ctx, q, email, *excludeID).Scan(&dummy)
+        err := r.conn(ctx).QueryRow(ctx, q, email, *excludeID).Scan(&dummy)
         if errors.Is(err, pgx.ErrNoRows) {
             return false, nil
         }
@@ -123,7 +165,7 @@ func (r *DosenRepository) ExistsEmail(ctx context.Context, email string, exclude
     }
     const q = `SELECT 1 FROM dosen WHERE email = $1 LIMIT 1`
     var dummy int
-    err := r.pool.QueryRow(ctx, q, email).Scan(&dummy)
+    err := r.conn(ctx).QueryRow(ctx, q, email).Scan(&dummy)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -137,7 +179,7 @@ func (r *DosenRepository) Create(ctx context.Context, d *model.Dosen) (*model.Do
     const q = `INSERT INTO dosen (id_dosen, nidn, nama_dosen, email, no_hp, jabatan_akademik)
                VALUES ($1,$2,$3,$4,$5,$6)
                RETURNING id_dosen, nidn, nama_dosen, email, no_hp, jabatan_akademik, created_at, updated_at`
-    row := r.pool.QueryRow(ctx, q, d.IDDosen, d.NIDN, d.NamaDosen, d.Email, d.NoHP, d.JabatanAkademik)
+    row := r.conn(ctx).QueryRow(ctx, q, d.IDDosen, d.NIDN, d.NamaDosen, d.Email, d.NoHP, d.JabatanAkademik)
     var out model.Dosen
     if err := row.Scan(&out.IDDosen, &out.NIDN, &out.NamaDosen, &out.Email, &out.NoHP, &out.JabatanAkademik, &out.CreatedAt, &out.UpdatedAt); err != nil {
         return nil, err
@@ -150,7 +192,7 @@ func (r *DosenRepository) UpdatePut(ctx context.Context, id string, d *model.Dos
                SET nidn=$1, nama_dosen=$2, email=$3, no_hp=$4, jabatan_akademik=$5
                WHERE id_dosen=$6
                RETURNING id_dosen, nidn, nama_dosen, email, no_hp, jabatan_akademik, created_at, updated_at`
-    row := r.pool.QueryRow(ctx, q, d.NIDN, d.NamaDosen, d.Email, d.NoHP, d.JabatanAkademik, id)
+    row := r.conn(ctx).QueryRow(ctx, q, d.NIDN, d.NamaDosen, d.Email, d.NoHP, d.JabatanAkademik, id)
     var out model.Dosen
     if err := row.Scan(&out.IDDosen, &out.NIDN, &out.NamaDosen, &out.Email, &out.NoHP, &out.JabatanAkademik, &out.CreatedAt, &out.UpdatedAt); err != nil {
         return nil, err
@@ -197,7 +239,7 @@ func (r *DosenRepository) UpdatePatch(ctx context.Context, id string, nidn, nama
         strings.Join(sets, ", "), idx)
     args = append(args, id)
 
-    row := r.pool.QueryRow(ctx, q, args...)
+    row := r.conn(ctx).QueryRow(ctx, q, args...)
     var out model.Dosen
     if err := row.Scan(&out.IDDosen, &out.NIDN, &out.NamaDosen, &out.Email, &out.NoHP, &out.JabatanAkademik, &out.CreatedAt, &out.UpdatedAt); err != nil {
         return nil, err
@@ -208,7 +250,7 @@ func (r *DosenRepository) UpdatePatch(ctx context.Context, id string, nidn, nama
 func (r *DosenRepository) HasMataKuliahPenanggungJawab(ctx context.Context, id string) (bool, error) {
     const q = `SELECT 1 FROM mata_kuliah WHERE id_dosen_pj = $1 LIMIT 1`
     var dummy int
-    err := r.pool.QueryRow(ctx, q, id).Scan(&dummy)
+    err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&dummy)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -221,7 +263,7 @@ func (r *DosenRepository) HasMataKuliahPenanggungJawab(ctx context.Context, id s
 func (r *DosenRepository) HasKelasKuliahPengampu(ctx context.Context, id string) (bool, error) {
     const q = `SELECT 1 FROM kelas_kuliah WHERE id_dosen_pengampu = $1 LIMIT 1`
     var dummy int
-    err := r.pool.QueryRow(ctx, q, id).Scan(&dummy)
+    err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&dummy)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -231,9 +273,30 @@ func (r *DosenRepository) HasKelasKuliahPengampu(ctx context.Context, id string)
     return true, nil
 }
 
+// HasPembimbingRelated true bila dosen pernah tercatat sebagai pembimbing akademik (termasuk riwayat)
+func (r *DosenRepository) HasPembimbingRelated(ctx context.Context, id string) (bool, error) {
+    const q = `SELECT 1 FROM pembimbing_akademik WHERE id_dosen = $1 LIMIT 1`
+    var dummy int
+    err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&dummy)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return false, nil
+    }
+    if err != nil {
+        return false, err
+    }
+    return true, nil
+}
+
+// LockByID mengunci baris dosen (FOR UPDATE) sampai transaksi selesai; pgx.ErrNoRows bila tidak ada
+func (r *DosenRepository) LockByID(ctx context.Context, id string) error {
+    const q = `SELECT 1 FROM dosen WHERE id_dosen = $1 FOR UPDATE`
+    var x int
+    return r.conn(ctx).QueryRow(ctx, q, id).Scan(&x)
+}
+
 func (r *DosenRepository) Delete(ctx context.Context, id string) error {
     const q = `DELETE FROM dosen WHERE id_dosen = $1`
-    ct, err := r.pool.Exec(ctx, q, id)
+    ct, err := r.conn(ctx).Exec(ctx, q, id)
     if err != nil {
         return err
     }
@@ -7,28 +7,51 @@ import (
     "strings"
 
     "github.com/jackc/pgx/v5"
-    "github.com/jackc/pgx/v5/pgxpool"
 
+    "pencatatan-data-mahasiswa/internal/db"
     model "pencatatan-data-mahasiswa/internal/todo/model/admin"
 )
 
 type FakultasRepository struct {
-    pool *pgxpool.Pool
+    db db.DBTX
 }
 
-func NewFakultasRepository(pool *pgxpool.Pool) *FakultasRepository {
-    return &FakultasRepository{pool: pool}
+func NewFakultasRepository(conn db.DBTX) *FakultasRepository {
+    return &FakultasRepository{db: conn}
 }
 
-// List mengembalikan daftar fakultas dengan filter pencarian nama (ILIKE) dan pagination
-func (r *FakultasRepository) List(ctx context.Context, search string, limit, offset int) ([]model.Fakultas, error) {
-    sb := strings.Builder{}
+func (r *FakultasRepository) conn(ctx context.Context) db.DBTX {
+    return db.Conn(ctx, r.db)
+}
+
+// FakultasFilterFields adalah field yang boleh dipakai pada grammar filter list fakultas
+var FakultasFilterFields = map[string]FilterField{
+    "singkatan":  {Column: "singkatan", Kind: FilterText, Nullable: true},
+    "created_at": {Column: "created_at", Kind: FilterTime},
+    "updated_at": {Column: "updated_at", Kind: FilterTime},
+}
+
+// fakultasWhere membangun klausa WHERE beserta argumennya; dipakai bersama oleh List dan Count
+func fakultasWhere(search string, filters []Filter) (string, []any) {
     args := []any{}
-    sb.WriteString("SELECT id_fakultas, nama_fakultas, singkatan, created_at, updated_at FROM fakultas")
+    where := []string{}
     if search != "" {
         args = append(args, "%"+search+"%")
-        sb.WriteString(fmt.Sprintf(" WHERE nama_fakultas ILIKE $%d", len(args)))
+        where = append(where, fmt.Sprintf("nama_fakultas ILIKE $%d", len(args)))
     }
+    where, args = appendFilterSQL(where, args, filters)
+    if len(where) == 0 {
+        return "", args
+    }
+    return " WHERE " + strings.Join(where, " AND "), args
+}
+
+// List mengembalikan daftar fakultas dengan filter pencarian nama (ILIKE) dan pagination
+func (r *FakultasRepository) List(ctx context.Context, search string, filters []Filter, limit, offset int) ([]model.Fakultas, error) {
+    sb := strings.Builder{}
+    sb.WriteString("SELECT id_fakultas, nama_fakultas, singkatan, created_at, updated_at FROM fakultas")
+    where, args := fakultasWhere(search, filters)
+    sb.WriteString(where)
     // default ordering by nama_fakultas asc untuk konsistensi
     sb.WriteString(" ORDER BY nama_fakultas ASC")
 
@@ -41,7 +64,7 @@ func (r *FakultasRepository) List(ctx context.Context, search string, limit, off
         sb.WriteString(fmt.Sprintf(" OFFSET $%d", len(args)))
     }
 
-    rows, err := r.pool.Query(ctx, sb.String(), args...)
+    rows, err := r.conn(ctx).Query(ctx, sb.String(), args...)
     if err != nil {
         return nil, err
     }
@@ -58,10 +81,20 @@ func (r *FakultasRepository) List(ctx context.Context, search string, limit, off
     return out, rows.Err()
 }
 
+// Count mengembalikan jumlah fakultas yang cocok dengan filter yang sama seperti List
+func (r *FakultasRepository) Count(ctx context.Context, search string, filters []Filter) (int64, error) {
+    where, args := fakultasWhere(search, filters)
+    var total int64
+    if err := r.conn(ctx).QueryRow(ctx, "SELECT COUNT(*) FROM fakultas"+where, args...).Scan(&total); err != nil {
+        return 0, err
+    }
+    return total, nil
+}
+
 // GetByID mengambil satu fakultas berdasarkan id
 func (r *FakultasRepository) GetByID(ctx context.Context, id string) (*model.Fakultas, error) {
     const q = `SELECT id_fakultas, nama_fakultas, singkatan, created_at, updated_at FROM fakultas WHERE id_fakultas = $1`
-    row := r.pool.QueryRow(ctx, q, id)
+    row := r.conn(ctx).QueryRow(ctx, q, id)
     var f model.Fakultas
     if err := row.Scan(&f.IDFakultas, &f.NamaFakultas, &f.Singkatan, &f.CreatedAt, &f.UpdatedAt); err != nil {
         return nil, err
@@ -73,7 +106,7 @@ func (r *FakultasRepository) GetByID(ctx context.Context, id string) (*model.Fak
 func (r *FakultasRepository) ExistsID(ctx context.Context, id string) (bool, error) {
     const q = `SELECT 1 FROM fakultas WHERE id_fakultas = $1 LIMIT 1`
     var dummy int
-    err := r.pool.QueryRow(ctx, q, id).Scan(&dummy)
+    err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&dummy)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -87,7 +120,7 @@ func (r *FakultasRepository) ExistsID(ctx context.Context, id string) (bool, err
 func (r *FakultasRepository) ExistsNamaCI(ctx context.Context, nama string) (bool, error) {
     const q = `SELECT 1 FROM fakultas WHERE LOWER(nama_fakultas) = LOWER($1) LIMIT 1`
     var dummy int
-    err := r.pool.QueryRow(ctx, q, nama).Scan(&dummy)
+    err := r.conn(ctx).QueryRow(ctx, q, nama).Scan(&dummy)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -101,7 +134,7 @@ func (r *FakultasRepository) ExistsNamaCI(ctx context.Context, nama string) (boo
 func (r *FakultasRepository) Create(ctx context.Context, f *model.Fakultas) (*model.Fakultas, error) {
     const q = `INSERT INTO fakultas (id_fakultas, nama_fakultas, singkatan) VALUES ($1, $2, $3)
                RETURNING id_fakultas, nama_fakultas, singkatan, created_at, updated_at`
-    row := r.pool.QueryRow(ctx, q, f.IDFakultas, f.NamaFakultas, f.Singkatan)
+    row := r.conn(ctx).QueryRow(ctx, q, f.IDFakultas, f.NamaFakultas, f.Singkatan)
     var out model.Fakultas
     if err := row.Scan(&out.IDFakultas, &out.NamaFakultas, &out.Singkatan, &out.CreatedAt, &out.UpdatedAt); err != nil {
         return nil, err
@@ -131,7 +164,7 @@ func (r *FakultasRepository) Update(ctx context.Context, id string, nama *string
     args = append(args, id)
     q := fmt.Sprintf("UPDATE fakultas SET %s WHERE id_fakultas = $%d RETURNING id_fakultas, nama_fakultas, singkatan, created_at, updated_at", strings.Join(sets, ", "), idx)
 
-    row := r.pool.QueryRow(ctx, q, args...)
+    row := r.conn(ctx).QueryRow(ctx, q, args...)
     var out model.Fakultas
     if err := row.Scan(&out.IDFakultas, &out.NamaFakultas, &out.Singkatan, &out.CreatedAt, &out.UpdatedAt); err != nil {
         return nil, err
@@ -143,7 +176,7 @@ func (r *FakultasRepository) Update(ctx context.Context, id string, nama *string
 func (r *FakultasRepository) HasProdiRelated(ctx context.Context, id string) (bool, error) {
     const q = `SELECT 1 FROM prodi WHERE id_fakultas = $1 LIMIT 1`
     var dummy int
-    err := r.pool.QueryRow(ctx, q, id).Scan(&dummy)
+    err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&dummy)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -154,9 +187,16 @@ func (r *FakultasRepository) HasProdiRelated(ctx context.Context, id string) (bo
 }
 
 // Delete menghapus baris fakultas
+// LockByID mengunci baris fakultas (FOR UPDATE) sampai transaksi selesai; pgx.ErrNoRows bila tidak ada
+func (r *FakultasRepository) LockByID(ctx context.Context, id string) error {
+    const q = `SELECT 1 FROM fakultas WHERE id_fakultas = $1 FOR UPDATE`
+    var x int
+    return r.conn(ctx).QueryRow(ctx, q, id).Scan(&x)
+}
+
 func (r *FakultasRepository) Delete(ctx context.Context, id string) error {
     const q = `DELETE FROM fakultas WHERE id_fakultas = $1`
-    ct, err := r.pool.Exec(ctx, q, id)
+    ct, err := r.conn(ctx).Exec(ctx, q, id)
     if err != nil {
         return err
     }
@@ -0,0 +1,277 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/i18n"
+)
+
+// Grammar filter list endpoint: <field>=[op:]value
+//
+//	status=Aktif                 (tanpa op berarti eq)
+//	status=in:Aktif,Cuti         angkatan=gte:2020
+//	angkatan=between:2019,2022   email=is:null
+//
+// Parameter yang sama boleh diulang (angkatan=gte:2019&angkatan=lte:2022) dan digabung dengan AND.
+// Nama field dan operator di-whitelist per tabel; nilai selalu dikirim sebagai parameter query.
+
+// FilterKind menentukan cara parsing nilai dan operator yang diizinkan
+type FilterKind int
+
+const (
+	FilterText FilterKind = iota
+	FilterInt
+	FilterDate // YYYY-MM-DD
+	FilterTime // RFC3339 atau YYYY-MM-DD
+)
+
+// FilterField mendeskripsikan field yang boleh difilter pada suatu tabel
+type FilterField struct {
+	Column   string // ekspresi SQL yang aman (bukan input user)
+	Kind     FilterKind
+	Enum     []string // nilai yang diizinkan (opsional)
+	Nullable bool     // mengizinkan is:null / is:notnull
+}
+
+// Filter adalah satu kondisi hasil parsing yang siap dijadikan SQL
+type Filter struct {
+	Column string
+	Op     string
+	Values []any
+}
+
+const maxFilterValues = 50
+
+// Kode alasan per field pada error filter (apperror.Error.Fields), stabil untuk klien
+const (
+	CodeInvalidInteger = "invalid_integer"
+	CodeInvalidDate    = "invalid_date"
+	CodeInvalidChoice  = "invalid_choice"
+	CodeOpNotSupported = "op_not_supported"
+	CodeUnknownField   = "unknown_field"
+	CodeTooManyValues  = "too_many_values"
+)
+
+var (
+	errInvalidInteger = errors.New(CodeInvalidInteger)
+	errInvalidDate    = errors.New(CodeInvalidDate)
+	errInvalidChoice  = errors.New(CodeInvalidChoice)
+	errOpNotSupported = errors.New(CodeOpNotSupported)
+	errTooManyValues  = errors.New(CodeTooManyValues)
+	errFilterEmpty    = errors.New(apperror.CodeRequired)
+	errFilterInvalid  = errors.New(apperror.CodeInvalid)
+)
+
+var filterOps = map[string]struct{}{
+	"eq": {}, "ne": {}, "in": {}, "nin": {},
+	"gt": {}, "gte": {}, "lt": {}, "lte": {}, "between": {}, "is": {},
+}
+
+// ParseFilters mem-parse parameter query mentah menjadi daftar Filter berdasarkan whitelist fields.
+// Nilai kosong diabaikan. Seluruh field yang tidak valid (termasuk yang tidak ada di whitelist)
+// dikumpulkan dalam satu apperror beserta kode alasannya, mis. invalid_integer atau unknown_field.
+func ParseFilters(raw map[string][]string, fields map[string]FilterField) ([]Filter, error) {
+	var (
+		out  []Filter
+		errs = map[string]string{}
+	)
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names) // urutan argumen SQL deterministik
+
+	for _, name := range names {
+		spec, ok := fields[name]
+		if !ok {
+			errs[name] = CodeUnknownField
+			continue
+		}
+		for _, expr := range raw[name] {
+			expr = strings.TrimSpace(expr)
+			if expr == "" {
+				continue
+			}
+			f, err := parseFilter(expr, spec)
+			if err != nil {
+				errs[name] = err.Error()
+				break
+			}
+			out = append(out, f)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, &apperror.Error{Kind: apperror.ErrInvalidInput, Code: i18n.MsgInvalidFilter, Fields: errs}
+	}
+	return out, nil
+}
+
+func parseFilter(expr string, spec FilterField) (Filter, error) {
+	op, arg := "eq", expr
+	if i := strings.Index(expr, ":"); i > 0 {
+		if _, ok := filterOps[strings.ToLower(expr[:i])]; ok {
+			op, arg = strings.ToLower(expr[:i]), expr[i+1:]
+		}
+	}
+	f := Filter{Column: spec.Column, Op: op}
+	ordered := spec.Kind != FilterText
+
+	switch op {
+	case "is":
+		if !spec.Nullable {
+			return f, errOpNotSupported
+		}
+		switch strings.ToLower(strings.TrimSpace(arg)) {
+		case "null":
+		case "notnull":
+			f.Op = "isnot"
+		default:
+			return f, errInvalidChoice
+		}
+		return f, nil
+	case "gt", "gte", "lt", "lte":
+		if !ordered {
+			return f, errOpNotSupported
+		}
+		v, err := parseFilterValue(arg, spec)
+		if err != nil {
+			return f, err
+		}
+		f.Values = []any{v}
+	case "between":
+		if !ordered {
+			return f, errOpNotSupported
+		}
+		parts := strings.Split(arg, ",")
+		if len(parts) != 2 {
+			return f, errFilterInvalid
+		}
+		for _, p := range parts {
+			v, err := parseFilterValue(p, spec)
+			if err != nil {
+				return f, err
+			}
+			f.Values = append(f.Values, v)
+		}
+	case "in", "nin":
+		parts := strings.Split(arg, ",")
+		if len(parts) > maxFilterValues {
+			return f, errTooManyValues
+		}
+		list, err := parseFilterList(parts, spec)
+		if err != nil {
+			return f, err
+		}
+		f.Values = []any{list}
+	default:
+		v, err := parseFilterValue(arg, spec)
+		if err != nil {
+			return f, err
+		}
+		f.Values = []any{v}
+	}
+	return f, nil
+}
+
+func parseFilterValue(s string, spec FilterField) (any, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errFilterEmpty
+	}
+	switch spec.Kind {
+	case FilterInt:
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, errInvalidInteger
+		}
+		return v, nil
+	case FilterDate:
+		v, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, errInvalidDate
+		}
+		return v, nil
+	case FilterTime:
+		if v, err := time.Parse(time.RFC3339, s); err == nil {
+			return v, nil
+		}
+		v, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, errInvalidDate
+		}
+		return v, nil
+	default:
+		if len(spec.Enum) > 0 {
+			for _, e := range spec.Enum {
+				if e == s {
+					return s, nil
+				}
+			}
+			return nil, errInvalidChoice
+		}
+		return s, nil
+	}
+}
+
+// parseFilterList menghasilkan slice bertipe agar bisa dikirim sebagai parameter = ANY($n)
+func parseFilterList(parts []string, spec FilterField) (any, error) {
+	var (
+		ints  []int64
+		times []time.Time
+		strs  []string
+	)
+	for _, p := range parts {
+		v, err := parseFilterValue(p, spec)
+		if err != nil {
+			return nil, err
+		}
+		switch x := v.(type) {
+		case int64:
+			ints = append(ints, x)
+		case time.Time:
+			times = append(times, x)
+		case string:
+			strs = append(strs, x)
+		}
+	}
+	switch spec.Kind {
+	case FilterInt:
+		return ints, nil
+	case FilterDate, FilterTime:
+		return times, nil
+	default:
+		return strs, nil
+	}
+}
+
+// appendFilterSQL menerjemahkan filters menjadi kondisi WHERE berparameter
+func appendFilterSQL(where []string, args []any, filters []Filter) ([]string, []any) {
+	for _, f := range filters {
+		switch f.Op {
+		case "is":
+			where = append(where, f.Column+" IS NULL")
+		case "isnot":
+			where = append(where, f.Column+" IS NOT NULL")
+		case "between":
+			args = append(args, f.Values[0], f.Values[1])
+			where = append(where, fmt.Sprintf("%s BETWEEN $%d AND $%d", f.Column, len(args)-1, len(args)))
+		case "in":
+			args = append(args, f.Values[0])
+			where = append(where, fmt.Sprintf("%s = ANY($%d)", f.Column, len(args)))
+		case "nin":
+			args = append(args, f.Values[0])
+			where = append(where, fmt.Sprintf("NOT (%s = ANY($%d))", f.Column, len(args)))
+		default:
+			sqlOp := map[string]string{"eq": "=", "ne": "<>", "gt": ">", "gte": ">=", "lt": "<", "lte": "<="}[f.Op]
+			args = append(args, f.Values[0])
+			where = append(where, fmt.Sprintf("%s %s $%d", f.Column, sqlOp, len(args)))
+		}
+	}
+	return where, args
+}
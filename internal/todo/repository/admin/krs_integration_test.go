@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pencatatan-data-mahasiswa/internal/db"
+	"pencatatan-data-mahasiswa/internal/testdb"
+)
+
+func TestMain(m *testing.M) {
+	testdb.Main(m, "repo_admin")
+}
+
+// TestKRSBackfillApproved: KRS bernilai yang ada sebelum migrasi 0017 tetap dihitung pada IPK
+// bimbingan dan nilai terbaik prasyarat setelah upgrade
+func TestKRSBackfillApproved(t *testing.T) {
+	url := testdb.URL(t)
+	ctx := context.Background()
+	mg, err := db.NewMigrator(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mg.Close()
+	if err := mg.Goto(ctx, 16); err != nil {
+		t.Fatalf("goto 16: %v", err)
+	}
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	for _, q := range []string{
+		`INSERT INTO fakultas (id_fakultas, nama_fakultas) VALUES ('FAK00001', 'Fakultas Teknik')`,
+		`INSERT INTO prodi (id_prodi, id_fakultas, nama_prodi, jenjang, kode_prodi) VALUES ('PRD00001', 'FAK00001', 'Informatika', 'S1', 'IF')`,
+		`INSERT INTO dosen (id_dosen, nama_dosen) VALUES ('DSN0000001', 'Dr. Budi')`,
+		`INSERT INTO mahasiswa (id_mahasiswa, id_prodi, nama_lengkap, jenis_kelamin, tahun_masuk, angkatan)
+		 VALUES ('202400000001', 'PRD00001', 'Siti Aminah', 'P', 2024, 2024)`,
+		`INSERT INTO semester (id_semester, tahun_ajaran, term) VALUES ('20241', '2024/2025', 'Ganjil')`,
+		`INSERT INTO mata_kuliah (id_mk, kode_mk, nama_mk, sks, id_prodi) VALUES
+		 ('MK00000001', 'IF101', 'Algoritma', 3, 'PRD00001'), ('MK00000002', 'IF102', 'Basis Data', 2, 'PRD00001')`,
+		`INSERT INTO kelas_kuliah (id_kelas, id_mk, id_semester, nama_kelas, id_dosen_pengampu) VALUES
+		 ('KLS000000001', 'MK00000001', '20241', 'A', 'DSN0000001'), ('KLS000000002', 'MK00000002', '20241', 'A', 'DSN0000001')`,
+		`INSERT INTO krs (id_mahasiswa, id_kelas, id_semester) VALUES
+		 ('202400000001', 'KLS000000001', '20241'), ('202400000001', 'KLS000000002', '20241')`,
+		`INSERT INTO nilai (id_krs, nilai_huruf, bobot) SELECT id_krs, CASE id_kelas WHEN 'KLS000000001' THEN 'A' ELSE 'C' END,
+		 CASE id_kelas WHEN 'KLS000000001' THEN 4 ELSE 2 END FROM krs`,
+	} {
+		if _, err := pool.Exec(ctx, q); err != nil {
+			t.Fatalf("seed: %v\n%s", err, q)
+		}
+	}
+
+	if err := mg.Up(ctx); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	var status string
+	if err := pool.QueryRow(ctx, `SELECT status FROM krs_pengajuan WHERE id_mahasiswa = '202400000001' AND id_semester = '20241'`).Scan(&status); err != nil || status != "Disetujui" {
+		t.Fatalf("backfilled pengajuan = %q, %v; want Disetujui", status, err)
+	}
+
+	best, err := NewKRSRepository(pool).BestBobot(ctx, "202400000001", []string{"MK00000001", "MK00000002"})
+	if err != nil || best["MK00000001"] != 4 || best["MK00000002"] != 2 {
+		t.Errorf("BestBobot = %v, %v", best, err)
+	}
+
+	if _, err := pool.Exec(ctx, `INSERT INTO pembimbing_akademik (id_mahasiswa, id_dosen, tanggal_mulai, username)
+		VALUES ('202400000001', 'DSN0000001', CURRENT_DATE, 'admin')`); err != nil {
+		t.Fatal(err)
+	}
+	list, err := NewPembimbingRepository(pool).ListBimbingan(ctx, "DSN0000001", nil, 10, 0, "")
+	// (4*3 + 2*2) / 5 = 3.2
+	if err != nil || len(list) != 1 || list[0].IPK == nil || *list[0].IPK != 3.2 || list[0].TotalSKS != 5 {
+		t.Errorf("ListBimbingan = %+v, %v", list, err)
+	}
+}
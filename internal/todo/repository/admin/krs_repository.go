@@ -0,0 +1,232 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"pencatatan-data-mahasiswa/internal/db"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+)
+
+type KRSRepository struct {
+	db db.DBTX
+}
+
+func NewKRSRepository(conn db.DBTX) *KRSRepository {
+	return &KRSRepository{db: conn}
+}
+
+func (r *KRSRepository) conn(ctx context.Context) db.DBTX {
+	return db.Conn(ctx, r.db)
+}
+
+// KRSPengajuanFilterFields adalah field yang boleh dipakai pada grammar filter daftar pengajuan KRS
+var KRSPengajuanFilterFields = map[string]FilterField{
+	"status":       {Column: "p.status", Kind: FilterText, Enum: []string{"Diajukan", "Dikembalikan", "Disetujui"}},
+	"id_semester":  {Column: "p.id_semester", Kind: FilterText},
+	"id_mahasiswa": {Column: "p.id_mahasiswa", Kind: FilterText},
+	"diajukan_at":  {Column: "p.diajukan_at", Kind: FilterTime},
+}
+
+const krsPengajuanColumns = `p.id_pengajuan, p.id_mahasiswa, m.nama_lengkap, p.id_semester, p.status, p.id_dosen, p.catatan,
+	COALESCE((SELECT SUM(mk.sks) FROM krs k
+		JOIN kelas_kuliah kk ON kk.id_kelas = k.id_kelas
+		JOIN mata_kuliah mk ON mk.id_mk = kk.id_mk
+		WHERE k.id_mahasiswa = p.id_mahasiswa AND k.id_semester = p.id_semester AND k.status_krs = 'Diambil'), 0),
+	p.diajukan_at, p.reviewed_at, p.created_at, p.updated_at`
+
+const krsPengajuanFrom = ` FROM krs_pengajuan p JOIN mahasiswa m ON m.id_mahasiswa = p.id_mahasiswa`
+
+func scanKRSPengajuan(row pgx.Row) (*model.KRSPengajuan, error) {
+	var p model.KRSPengajuan
+	if err := row.Scan(&p.IDPengajuan, &p.IDMahasiswa, &p.NamaLengkap, &p.IDSemester, &p.Status, &p.IDDosen, &p.Catatan,
+		&p.TotalSKS, &p.DiajukanAt, &p.ReviewedAt, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListKelas mengembalikan kelas yang diambil mahasiswa pada semester, urut kode mata kuliah
+func (r *KRSRepository) ListKelas(ctx context.Context, idMahasiswa, idSemester string) ([]model.KRSKelas, error) {
+	const q = `SELECT k.id_krs, k.id_kelas, kk.nama_kelas, mk.id_mk, mk.kode_mk, mk.nama_mk, mk.sks
+              FROM krs k
+              JOIN kelas_kuliah kk ON kk.id_kelas = k.id_kelas
+              JOIN mata_kuliah mk ON mk.id_mk = kk.id_mk
+              WHERE k.id_mahasiswa = $1 AND k.id_semester = $2 AND k.status_krs = 'Diambil'
+              ORDER BY mk.kode_mk, kk.nama_kelas`
+	rows, err := r.conn(ctx).Query(ctx, q, idMahasiswa, idSemester)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.KRSKelas{}
+	for rows.Next() {
+		var k model.KRSKelas
+		if err := rows.Scan(&k.IDKRS, &k.IDKelas, &k.NamaKelas, &k.IDMK, &k.KodeMK, &k.NamaMK, &k.SKS); err != nil {
+			return nil, err
+		}
+		out = append(out, k)
+	}
+	return out, rows.Err()
+}
+
+// KelasOutside mengembalikan id kelas yang tidak ada atau bukan kelas semester tersebut
+func (r *KRSRepository) KelasOutside(ctx context.Context, idSemester string, ids []string) ([]string, error) {
+	const q = `SELECT u.id FROM unnest($2::text[]) AS u(id)
+              LEFT JOIN kelas_kuliah kk ON kk.id_kelas = u.id AND kk.id_semester = $1
+              WHERE kk.id_kelas IS NULL ORDER BY u.id`
+	rows, err := r.conn(ctx).Query(ctx, q, idSemester, ids)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// ReplaceKelas menyamakan kelas KRS mahasiswa pada semester dengan ids: kelas lain dihapus,
+// kelas baru ditambah, dan kelas yang pernah Batal diambil kembali
+func (r *KRSRepository) ReplaceKelas(ctx context.Context, idMahasiswa, idSemester string, ids []string) error {
+	const del = `DELETE FROM krs WHERE id_mahasiswa = $1 AND id_semester = $2 AND NOT (id_kelas = ANY($3::text[]))`
+	if _, err := r.conn(ctx).Exec(ctx, del, idMahasiswa, idSemester, ids); err != nil {
+		return err
+	}
+	const ins = `INSERT INTO krs (id_mahasiswa, id_kelas, id_semester)
+              SELECT $1, u.id, $2 FROM unnest($3::text[]) AS u(id)
+              ON CONFLICT (id_mahasiswa, id_kelas) DO UPDATE SET status_krs = 'Diambil'
+              WHERE krs.status_krs IS DISTINCT FROM 'Diambil'`
+	_, err := r.conn(ctx).Exec(ctx, ins, idMahasiswa, idSemester, ids)
+	return err
+}
+
+// GetPengajuan mengembalikan pengajuan KRS mahasiswa pada semester; pgx.ErrNoRows bila belum pernah diajukan
+func (r *KRSRepository) GetPengajuan(ctx context.Context, idMahasiswa, idSemester string) (*model.KRSPengajuan, error) {
+	return scanKRSPengajuan(r.conn(ctx).QueryRow(ctx,
+		"SELECT "+krsPengajuanColumns+krsPengajuanFrom+" WHERE p.id_mahasiswa = $1 AND p.id_semester = $2", idMahasiswa, idSemester))
+}
+
+func (r *KRSRepository) GetPengajuanByID(ctx context.Context, id int64) (*model.KRSPengajuan, error) {
+	return scanKRSPengajuan(r.conn(ctx).QueryRow(ctx, "SELECT "+krsPengajuanColumns+krsPengajuanFrom+" WHERE p.id_pengajuan = $1", id))
+}
+
+// LockPengajuan mengunci pengajuan (FOR UPDATE) agar tidak diproses dua kali bersamaan
+func (r *KRSRepository) LockPengajuan(ctx context.Context, id int64) error {
+	const q = `SELECT 1 FROM krs_pengajuan WHERE id_pengajuan = $1 FOR UPDATE`
+	var x int
+	return r.conn(ctx).QueryRow(ctx, q, id).Scan(&x)
+}
+
+// Submit membuat pengajuan atau mengajukan ulang KRS yang dikembalikan; catatan PA sebelumnya dihapus
+func (r *KRSRepository) Submit(ctx context.Context, idMahasiswa, idSemester, idDosen string) (*model.KRSPengajuan, error) {
+	const q = `INSERT INTO krs_pengajuan (id_mahasiswa, id_semester, id_dosen) VALUES ($1,$2,$3)
+              ON CONFLICT (id_mahasiswa, id_semester) DO UPDATE
+              SET status = 'Diajukan', id_dosen = EXCLUDED.id_dosen, catatan = NULL,
+                  diajukan_at = CURRENT_TIMESTAMP, reviewed_at = NULL
+              RETURNING id_pengajuan`
+	var id int64
+	if err := r.conn(ctx).QueryRow(ctx, q, idMahasiswa, idSemester, idDosen).Scan(&id); err != nil {
+		return nil, err
+	}
+	return r.GetPengajuanByID(ctx, id)
+}
+
+// Review menyimpan keputusan PA (Disetujui atau Dikembalikan)
+func (r *KRSRepository) Review(ctx context.Context, id int64, status string, catatan *string) (*model.KRSPengajuan, error) {
+	const q = `UPDATE krs_pengajuan SET status = $1, catatan = $2, reviewed_at = CURRENT_TIMESTAMP WHERE id_pengajuan = $3`
+	ct, err := r.conn(ctx).Exec(ctx, q, status, catatan, id)
+	if err != nil {
+		return nil, err
+	}
+	if ct.RowsAffected() == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	return r.GetPengajuanByID(ctx, id)
+}
+
+func krsPengajuanWhere(idDosen string, filters []Filter) (string, []any) {
+	where, args := appendFilterSQL([]string{"p.id_dosen = $1"}, []any{idDosen}, filters)
+	return " WHERE " + strings.Join(where, " AND "), args
+}
+
+// ListPengajuan mengembalikan pengajuan KRS yang diproses dosen; orderBy sudah disanitasi service
+func (r *KRSRepository) ListPengajuan(ctx context.Context, idDosen string, filters []Filter, limit, offset int, orderBy string) ([]model.KRSPengajuan, error) {
+	where, args := krsPengajuanWhere(idDosen, filters)
+	if orderBy == "" {
+		orderBy = "p.diajukan_at DESC"
+	}
+	q := "SELECT " + krsPengajuanColumns + krsPengajuanFrom + where + " ORDER BY " + orderBy + ", p.id_pengajuan DESC"
+	if limit > 0 {
+		args = append(args, limit)
+		q += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if offset > 0 {
+		args = append(args, offset)
+		q += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	rows, err := r.conn(ctx).Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.KRSPengajuan{}
+	for rows.Next() {
+		p, err := scanKRSPengajuan(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, rows.Err()
+}
+
+func (r *KRSRepository) CountPengajuan(ctx context.Context, idDosen string, filters []Filter) (int64, error) {
+	where, args := krsPengajuanWhere(idDosen, filters)
+	var total int64
+	err := r.conn(ctx).QueryRow(ctx, "SELECT COUNT(*)"+krsPengajuanFrom+where, args...).Scan(&total)
+	return total, err
+}
+
+func (r *KRSRepository) ExistsSemester(ctx context.Context, id string) (bool, error) {
+	const q = `SELECT 1 FROM semester WHERE id_semester = $1`
+	var x int
+	err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&x)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// BestBobot mengembalikan bobot terbaik mahasiswa per mata kuliah ids dari KRS yang disetujui;
+// mata kuliah yang belum pernah dinilai tidak ada di hasil
+func (r *KRSRepository) BestBobot(ctx context.Context, idMahasiswa string, ids []string) (map[string]float64, error) {
+	const q = `SELECT kk.id_mk, MAX(n.bobot)::float8
+              FROM krs k
+              JOIN krs_pengajuan kp ON kp.id_mahasiswa = k.id_mahasiswa AND kp.id_semester = k.id_semester AND kp.status = 'Disetujui'
+              JOIN nilai n ON n.id_krs = k.id_krs
+              JOIN kelas_kuliah kk ON kk.id_kelas = k.id_kelas
+              WHERE k.id_mahasiswa = $1 AND k.status_krs = 'Diambil' AND n.bobot IS NOT NULL AND kk.id_mk = ANY($2::text[])
+              GROUP BY kk.id_mk`
+	rows, err := r.conn(ctx).Query(ctx, q, idMahasiswa, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]float64{}
+	for rows.Next() {
+		var id string
+		var bobot float64
+		if err := rows.Scan(&id, &bobot); err != nil {
+			return nil, err
+		}
+		out[id] = bobot
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,297 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"pencatatan-data-mahasiswa/internal/db"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+)
+
+type KurikulumRepository struct {
+	db db.DBTX
+}
+
+func NewKurikulumRepository(conn db.DBTX) *KurikulumRepository {
+	return &KurikulumRepository{db: conn}
+}
+
+func (r *KurikulumRepository) conn(ctx context.Context) db.DBTX {
+	return db.Conn(ctx, r.db)
+}
+
+// KurikulumFilterFields adalah field yang boleh dipakai pada grammar filter daftar kurikulum
+var KurikulumFilterFields = map[string]FilterField{
+	"id_prodi": {Column: "k.id_prodi", Kind: FilterText},
+	"tahun":    {Column: "k.tahun", Kind: FilterInt},
+	"aktif":    {Column: "k.aktif::text", Kind: FilterText, Enum: []string{"true", "false"}},
+}
+
+const kurikulumColumns = `k.id_kurikulum, k.id_prodi, k.tahun, k.nama, k.aktif,
+	(SELECT COUNT(*) FROM kurikulum_mk x WHERE x.id_kurikulum = k.id_kurikulum),
+	COALESCE((SELECT SUM(mk.sks) FROM kurikulum_mk x JOIN mata_kuliah mk ON mk.id_mk = x.id_mk
+		WHERE x.id_kurikulum = k.id_kurikulum), 0),
+	k.created_at, k.updated_at`
+
+func scanKurikulum(row pgx.Row) (*model.Kurikulum, error) {
+	var k model.Kurikulum
+	if err := row.Scan(&k.IDKurikulum, &k.IDProdi, &k.Tahun, &k.Nama, &k.Aktif, &k.JumlahMK, &k.TotalSKS,
+		&k.CreatedAt, &k.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func kurikulumWhere(filters []Filter) (string, []any) {
+	where, args := appendFilterSQL(nil, nil, filters)
+	if len(where) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(where, " AND "), args
+}
+
+// List mengembalikan kurikulum; orderBy sudah disanitasi handler
+func (r *KurikulumRepository) List(ctx context.Context, filters []Filter, limit, offset int, orderBy string) ([]model.Kurikulum, error) {
+	where, args := kurikulumWhere(filters)
+	if orderBy == "" {
+		orderBy = "k.tahun DESC"
+	}
+	q := "SELECT " + kurikulumColumns + " FROM kurikulum k" + where + " ORDER BY " + orderBy + ", k.id_kurikulum"
+	if limit > 0 {
+		args = append(args, limit)
+		q += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if offset > 0 {
+		args = append(args, offset)
+		q += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	rows, err := r.conn(ctx).Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.Kurikulum{}
+	for rows.Next() {
+		k, err := scanKurikulum(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *k)
+	}
+	return out, rows.Err()
+}
+
+func (r *KurikulumRepository) Count(ctx context.Context, filters []Filter) (int64, error) {
+	where, args := kurikulumWhere(filters)
+	var total int64
+	err := r.conn(ctx).QueryRow(ctx, "SELECT COUNT(*) FROM kurikulum k"+where, args...).Scan(&total)
+	return total, err
+}
+
+func (r *KurikulumRepository) GetByID(ctx context.Context, id int64) (*model.Kurikulum, error) {
+	return scanKurikulum(r.conn(ctx).QueryRow(ctx, "SELECT "+kurikulumColumns+" FROM kurikulum k WHERE k.id_kurikulum = $1", id))
+}
+
+// LockByID mengunci kurikulum (FOR UPDATE) selama perubahan
+func (r *KurikulumRepository) LockByID(ctx context.Context, id int64) error {
+	const q = `SELECT 1 FROM kurikulum WHERE id_kurikulum = $1 FOR UPDATE`
+	var x int
+	return r.conn(ctx).QueryRow(ctx, q, id).Scan(&x)
+}
+
+func (r *KurikulumRepository) ExistsProdi(ctx context.Context, idProdi string) (bool, error) {
+	const q = `SELECT 1 FROM prodi WHERE id_prodi = $1`
+	var x int
+	err := r.conn(ctx).QueryRow(ctx, q, idProdi).Scan(&x)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ExistsTahun memeriksa kurikulum lain dengan tahun yang sama pada prodi (uq_kurikulum_tahun)
+func (r *KurikulumRepository) ExistsTahun(ctx context.Context, idProdi string, tahun int, excludeID *int64) (bool, error) {
+	const q = `SELECT 1 FROM kurikulum WHERE id_prodi = $1 AND tahun = $2 AND id_kurikulum IS DISTINCT FROM $3`
+	var x int
+	err := r.conn(ctx).QueryRow(ctx, q, idProdi, tahun, excludeID).Scan(&x)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *KurikulumRepository) Create(ctx context.Context, k *model.Kurikulum) (*model.Kurikulum, error) {
+	const q = `INSERT INTO kurikulum (id_prodi, tahun, nama, aktif) VALUES ($1,$2,$3,$4) RETURNING id_kurikulum`
+	var id int64
+	if err := r.conn(ctx).QueryRow(ctx, q, k.IDProdi, k.Tahun, k.Nama, k.Aktif).Scan(&id); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *KurikulumRepository) Update(ctx context.Context, id int64, k *model.Kurikulum) (*model.Kurikulum, error) {
+	const q = `UPDATE kurikulum SET tahun = $1, nama = $2, aktif = $3 WHERE id_kurikulum = $4`
+	ct, err := r.conn(ctx).Exec(ctx, q, k.Tahun, k.Nama, k.Aktif, id)
+	if err != nil {
+		return nil, err
+	}
+	if ct.RowsAffected() == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Deactivate menonaktifkan kurikulum aktif prodi selain exceptID (uq_kurikulum_aktif)
+func (r *KurikulumRepository) Deactivate(ctx context.Context, idProdi string, exceptID int64) error {
+	const q = `UPDATE kurikulum SET aktif = FALSE WHERE id_prodi = $1 AND aktif AND id_kurikulum <> $2`
+	_, err := r.conn(ctx).Exec(ctx, q, idProdi, exceptID)
+	return err
+}
+
+// Delete menghapus kurikulum beserta daftar mata kuliahnya (ON DELETE CASCADE)
+func (r *KurikulumRepository) Delete(ctx context.Context, id int64) error {
+	ct, err := r.conn(ctx).Exec(ctx, `DELETE FROM kurikulum WHERE id_kurikulum = $1`, id)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// ListMataKuliah mengembalikan mata kuliah kurikulum urut semester lalu kode; Prasyarat belum diisi
+func (r *KurikulumRepository) ListMataKuliah(ctx context.Context, id int64) ([]model.KurikulumMK, error) {
+	const q = `SELECT mk.id_mk, mk.kode_mk, mk.nama_mk, mk.sks, x.semester, x.jenis
+              FROM kurikulum_mk x JOIN mata_kuliah mk ON mk.id_mk = x.id_mk
+              WHERE x.id_kurikulum = $1
+              ORDER BY x.semester, mk.kode_mk`
+	rows, err := r.conn(ctx).Query(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.KurikulumMK{}
+	for rows.Next() {
+		var m model.KurikulumMK
+		if err := rows.Scan(&m.IDMK, &m.KodeMK, &m.NamaMK, &m.SKS, &m.Semester, &m.Jenis); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// ReplaceMataKuliah menyamakan daftar mata kuliah kurikulum dengan items
+func (r *KurikulumRepository) ReplaceMataKuliah(ctx context.Context, id int64, items []model.KurikulumMK) error {
+	if _, err := r.conn(ctx).Exec(ctx, `DELETE FROM kurikulum_mk WHERE id_kurikulum = $1`, id); err != nil {
+		return err
+	}
+	ids := make([]string, len(items))
+	sems := make([]int32, len(items))
+	jenis := make([]string, len(items))
+	for i, m := range items {
+		ids[i], sems[i], jenis[i] = m.IDMK, int32(m.Semester), m.Jenis
+	}
+	const q = `INSERT INTO kurikulum_mk (id_kurikulum, id_mk, semester, jenis)
+              SELECT $1, u.id, u.semester, u.jenis FROM unnest($2::text[], $3::smallint[], $4::text[]) AS u(id, semester, jenis)`
+	_, err := r.conn(ctx).Exec(ctx, q, id, ids, sems, jenis)
+	return err
+}
+
+// MataKuliahMissing mengembalikan id mata kuliah yang tidak ada
+func (r *KurikulumRepository) MataKuliahMissing(ctx context.Context, ids []string) ([]string, error) {
+	const q = `SELECT u.id FROM unnest($1::text[]) AS u(id)
+              LEFT JOIN mata_kuliah mk ON mk.id_mk = u.id
+              WHERE mk.id_mk IS NULL ORDER BY u.id`
+	rows, err := r.conn(ctx).Query(ctx, q, ids)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// MataKuliahOutsideProdi mengembalikan id mata kuliah yang ada tetapi milik prodi selain idProdi
+func (r *KurikulumRepository) MataKuliahOutsideProdi(ctx context.Context, idProdi string, ids []string) ([]string, error) {
+	const q = `SELECT id_mk FROM mata_kuliah
+              WHERE id_mk = ANY($1::text[]) AND id_prodi <> $2 ORDER BY id_mk`
+	rows, err := r.conn(ctx).Query(ctx, q, ids, idProdi)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// MataKuliahProdi mengembalikan id_prodi pemilik mata kuliah; pgx.ErrNoRows bila tidak ada
+func (r *KurikulumRepository) MataKuliahProdi(ctx context.Context, idMK string) (string, error) {
+	var idProdi string
+	err := r.conn(ctx).QueryRow(ctx, `SELECT id_prodi FROM mata_kuliah WHERE id_mk = $1`, idMK).Scan(&idProdi)
+	return idProdi, err
+}
+
+// ListPrasyarat mengembalikan prasyarat mata kuliah ids, urut id_mk lalu kode prasyarat
+func (r *KurikulumRepository) ListPrasyarat(ctx context.Context, ids []string) ([]model.Prasyarat, error) {
+	const q = `SELECT p.id_mk, p.id_mk_prasyarat, mk.kode_mk, mk.nama_mk, p.nilai_minimal
+              FROM prasyarat_mk p JOIN mata_kuliah mk ON mk.id_mk = p.id_mk_prasyarat
+              WHERE p.id_mk = ANY($1::text[])
+              ORDER BY p.id_mk, mk.kode_mk`
+	rows, err := r.conn(ctx).Query(ctx, q, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.Prasyarat{}
+	for rows.Next() {
+		var p model.Prasyarat
+		if err := rows.Scan(&p.IDMK, &p.IDMKPrasyarat, &p.KodeMKPrasyarat, &p.NamaMKPrasyarat, &p.NilaiMinimal); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// ReplacePrasyarat menyamakan prasyarat mata kuliah idMK dengan items
+func (r *KurikulumRepository) ReplacePrasyarat(ctx context.Context, idMK string, items []model.Prasyarat) error {
+	if _, err := r.conn(ctx).Exec(ctx, `DELETE FROM prasyarat_mk WHERE id_mk = $1`, idMK); err != nil {
+		return err
+	}
+	ids := make([]string, len(items))
+	nilai := make([]string, len(items))
+	for i, p := range items {
+		ids[i], nilai[i] = p.IDMKPrasyarat, p.NilaiMinimal
+	}
+	const q = `INSERT INTO prasyarat_mk (id_mk, id_mk_prasyarat, nilai_minimal)
+              SELECT $1, u.id, u.nilai FROM unnest($2::text[], $3::text[]) AS u(id, nilai)`
+	_, err := r.conn(ctx).Exec(ctx, q, idMK, ids, nilai)
+	return err
+}
+
+// RequiredBy mengembalikan mata kuliah yang (langsung atau tidak) mensyaratkan idMK; dipakai
+// service untuk menolak prasyarat melingkar
+func (r *KurikulumRepository) RequiredBy(ctx context.Context, idMK string) ([]string, error) {
+	const q = `WITH RECURSIVE dep(id) AS (
+                SELECT id_mk FROM prasyarat_mk WHERE id_mk_prasyarat = $1
+                UNION
+                SELECT p.id_mk FROM prasyarat_mk p JOIN dep ON p.id_mk_prasyarat = dep.id
+              )
+              SELECT id FROM dep ORDER BY id`
+	rows, err := r.conn(ctx).Query(ctx, q, idMK)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
@@ -8,54 +8,76 @@ import (
     "time"
 
     "github.com/jackc/pgx/v5"
-    "github.com/jackc/pgx/v5/pgxpool"
 
+    "pencatatan-data-mahasiswa/internal/db"
     model "pencatatan-data-mahasiswa/internal/todo/model/admin"
 )
 
 type MahasiswaRepository struct {
-    pool *pgxpool.Pool
+    db db.DBTX
 }
 
-func NewMahasiswaRepository(pool *pgxpool.Pool) *MahasiswaRepository {
-    return &MahasiswaRepository{pool: pool}
+func NewMahasiswaRepository(conn db.DBTX) *MahasiswaRepository {
+    return &MahasiswaRepository{db: conn}
 }
 
-// List returns mahasiswa with optional filters and pagination; orderBy must be sanitized beforehand
-func (r *MahasiswaRepository) List(ctx context.Context, q string, idProdi *string, angkatan *int, status *string, limit, offset int, orderBy string) ([]model.Mahasiswa, error) {
-    sb := strings.Builder{}
-    args := []any{}
-    sb.WriteString("SELECT id_mahasiswa, id_prodi, nik, nama_lengkap, jenis_kelamin, tempat_lahir, tanggal_lahir, alamat, email, no_hp, tahun_masuk, status, angkatan, created_at, updated_at FROM mahasiswa")
+// conn mengembalikan transaksi milik ctx bila service menjalankan unit of work, selain itu r.db
+func (r *MahasiswaRepository) conn(ctx context.Context) db.DBTX {
+    return db.Conn(ctx, r.db)
+}
 
+// MahasiswaFilterFields adalah field yang boleh dipakai pada grammar filter list mahasiswa
+var MahasiswaFilterFields = map[string]FilterField{
+    "id_prodi":      {Column: "id_prodi", Kind: FilterText},
+    "id_fakultas":   {Column: "(SELECT p.id_fakultas FROM prodi p WHERE p.id_prodi = mahasiswa.id_prodi)", Kind: FilterText},
+    "status":        {Column: "status", Kind: FilterText, Enum: []string{"Aktif", "Cuti", "Lulus", "Drop Out", "Non-Aktif"}, Nullable: true},
+    "jenis_kelamin": {Column: "jenis_kelamin", Kind: FilterText, Enum: []string{"L", "P"}},
+    "angkatan":      {Column: "angkatan", Kind: FilterInt},
+    "tahun_masuk":   {Column: "tahun_masuk", Kind: FilterInt},
+    "tanggal_lahir": {Column: "tanggal_lahir", Kind: FilterDate, Nullable: true},
+    "tempat_lahir":  {Column: "tempat_lahir", Kind: FilterText, Nullable: true},
+    "email":         {Column: "email", Kind: FilterText, Nullable: true},
+    "nik":           {Column: "nik", Kind: FilterText, Nullable: true},
+    "no_hp":         {Column: "no_hp", Kind: FilterText, Nullable: true},
+    "created_at":    {Column: "created_at", Kind: FilterTime},
+    "updated_at":    {Column: "updated_at", Kind: FilterTime},
+}
+
+// mahasiswaWhere membangun klausa WHERE beserta argumennya; dipakai bersama oleh List dan Count.
+// rank berisi ekspresi relevansi bila match = fuzzy
+func mahasiswaWhere(q, match string, filters []Filter) (string, string, []any) {
+    args := []any{}
     where := []string{}
+    rank := ""
     if q != "" {
-        args = append(args, "%"+q+"%")
-        args = append(args, "%"+q+"%")
-        args = append(args, "%"+q+"%")
-        where = append(where, fmt.Sprintf("(nama_lengkap ILIKE $%d OR email ILIKE $%d OR id_mahasiswa ILIKE $%d)", len(args)-2, len(args)-1, len(args)))
-    }
-    if idProdi != nil && *idProdi != "" {
-        args = append(args, *idProdi)
-        where = append(where, fmt.Sprintf("id_prodi = $%d", len(args)))
+        var cond string
+        cond, rank, args = nameSearchClause(match, q, "nama_lengkap", []string{"email", "id_mahasiswa"}, args)
+        where = append(where, cond)
     }
-    if angkatan != nil && *angkatan > 0 {
-        args = append(args, *angkatan)
-        where = append(where, fmt.Sprintf("tahun_masuk = $%d", len(args)))
-    }
-    if status != nil && *status != "" {
-        args = append(args, *status)
-        where = append(where, fmt.Sprintf("status = $%d", len(args)))
+    where, args = appendFilterSQL(where, args, filters)
+    if len(where) == 0 {
+        return "", rank, args
     }
+    return " WHERE " + strings.Join(where, " AND "), rank, args
+}
 
-    if len(where) > 0 {
-        sb.WriteString(" WHERE ")
-        sb.WriteString(strings.Join(where, " AND "))
-    }
+// List returns mahasiswa with optional filters and pagination; orderBy must be sanitized beforehand
+func (r *MahasiswaRepository) List(ctx context.Context, q, match string, filters []Filter, limit, offset int, orderBy string) ([]model.Mahasiswa, error) {
+    sb := strings.Builder{}
+    sb.WriteString("SELECT id_mahasiswa, id_prodi, nik, nama_lengkap, jenis_kelamin, tempat_lahir, tanggal_lahir, alamat, email, no_hp, tahun_masuk, status, angkatan, created_at, updated_at FROM mahasiswa")
+
+    where, rank, args := mahasiswaWhere(q, match, filters)
+    sb.WriteString(where)
 
     if orderBy == "" {
         orderBy = "nama_lengkap ASC"
     }
     sb.WriteString(" ORDER BY ")
+    if rank != "" {
+        // pencarian fuzzy: paling relevan terlebih dulu, orderBy sebagai pemecah seri
+        sb.WriteString(rank)
+        sb.WriteString(" DESC, ")
+    }
     sb.WriteString(orderBy)
 
     if limit > 0 {
@@ -67,7 +89,7 @@ func (r *MahasiswaRepository) List(ctx context.Context, q string, idProdi *strin
         sb.WriteString(fmt.Sprintf(" OFFSET $%d", len(args)))
     }
 
-    rows, err := r.pool.Query(ctx, sb.String(), args...)
+    rows, err := r.conn(ctx).Query(ctx, sb.String(), args...)
     if err != nil {
         return nil, err
     }
@@ -100,9 +122,62 @@ func (r *MahasiswaRepository) List(ctx context.Context, q string, idProdi *strin
     return out, rows.Err()
 }
 
+// ListKeyset returns mahasiswa ordered by (sortCol, id_mahasiswa) starting strictly after the
+// given keyset position. sortCol must be sanitized beforehand; afterValue/afterID nil means first page.
+func (r *MahasiswaRepository) ListKeyset(ctx context.Context, q, match string, filters []Filter, limit int, sortCol string, desc bool, afterValue any, afterID *string) ([]model.Mahasiswa, error) {
+    sb := strings.Builder{}
+    sb.WriteString("SELECT id_mahasiswa, id_prodi, nik, nama_lengkap, jenis_kelamin, tempat_lahir, tanggal_lahir, alamat, email, no_hp, tahun_masuk, status, angkatan, created_at, updated_at FROM mahasiswa")
+
+    where, _, args := mahasiswaWhere(q, match, filters)
+    dir, cmp := "ASC", ">"
+    if desc {
+        dir, cmp = "DESC", "<"
+    }
+    if afterID != nil {
+        args = append(args, afterValue, *afterID)
+        cond := fmt.Sprintf("(%s, id_mahasiswa) %s ($%d, $%d)", sortCol, cmp, len(args)-1, len(args))
+        if where == "" {
+            where = " WHERE " + cond
+        } else {
+            where += " AND " + cond
+        }
+    }
+    sb.WriteString(where)
+    sb.WriteString(fmt.Sprintf(" ORDER BY %s %s, id_mahasiswa %s", sortCol, dir, dir))
+
+    args = append(args, limit)
+    sb.WriteString(fmt.Sprintf(" LIMIT $%d", len(args)))
+
+    rows, err := r.conn(ctx).Query(ctx, sb.String(), args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var out []model.Mahasiswa
+    for rows.Next() {
+        var m model.Mahasiswa
+        if err := rows.Scan(&m.IDMahasiswa, &m.IDProdi, &m.NIK, &m.NamaLengkap, &m.JenisKelamin, &m.TempatLahir, &m.TanggalLahir, &m.Alamat, &m.Email, &m.NoHP, &m.TahunMasuk, &m.Status, &m.Angkatan, &m.CreatedAt, &m.UpdatedAt); err != nil {
+            return nil, err
+        }
+        out = append(out, m)
+    }
+    return out, rows.Err()
+}
+
+// Count returns the number of mahasiswa matching the same filters as List
+func (r *MahasiswaRepository) Count(ctx context.Context, q, match string, filters []Filter) (int64, error) {
+    where, _, args := mahasiswaWhere(q, match, filters)
+    var total int64
+    if err := r.conn(ctx).QueryRow(ctx, "SELECT COUNT(*) FROM mahasiswa"+where, args...).Scan(&total); err != nil {
+        return 0, err
+    }
+    return total, nil
+}
+
 func (r *MahasiswaRepository) GetByID(ctx context.Context, id string) (*model.Mahasiswa, error) {
     const q = `SELECT id_mahasiswa, id_prodi, nik, nama_lengkap, jenis_kelamin, tempat_lahir, tanggal_lahir, alamat, email, no_hp, tahun_masuk, status, angkatan, created_at, updated_at FROM mahasiswa WHERE id_mahasiswa = $1`
-    row := r.pool.QueryRow(ctx, q, id)
+    row := r.conn(ctx).QueryRow(ctx, q, id)
     var m model.Mahasiswa
     if err := row.Scan(
         &m.IDMahasiswa,
@@ -129,7 +204,7 @@ func (r *MahasiswaRepository) GetByID(ctx context.Context, id string) (*model.Ma
 func (r *MahasiswaRepository) ExistsID(ctx context.Context, id string) (bool, error) {
     const q = `SELECT 1 FROM mahasiswa WHERE id_mahasiswa = $1 LIMIT 1`
     var x int
-    err := r.pool.QueryRow(ctx, q, id).Scan(&x)
+    err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&x)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -143,7 +218,7 @@ func (r *MahasiswaRepository) ExistsEmail(ctx context.Context, email string, exc
     if excludeID != nil {
         const q = `SELECT 1 FROM mahasiswa WHERE email = $1 AND id_mahasiswa <> $2 LIMIT 1`
         var x int
-        err := r.pool.QueryRow(ctx, q, email, *excludeID).Scan(&x)
+        err := r.conn(ctx).QueryRow(ctx, q, email, *excludeID).Scan(&x)
         if errors.Is(err, pgx.ErrNoRows) {
             return false, nil
         }
@@ -154,7 +229,7 @@ func (r *MahasiswaRepository) ExistsEmail(ctx context.Context, email string, exc
     }
     const q = `SELECT 1 FROM mahasiswa WHERE email = $1 LIMIT 1`
     var x int
-    err := r.pool.QueryRow(ctx, q, email).Scan(&x)
+    err := r.conn(ctx).QueryRow(ctx, q, email).Scan(&x)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -168,7 +243,7 @@ func (r *MahasiswaRepository) ExistsNIK(ctx context.Context, nik string, exclude
     if excludeID != nil {
         const q = `SELECT 1 FROM mahasiswa WHERE nik = $1 AND id_mahasiswa <> $2 LIMIT 1`
         var x int
-        err := r.pool.QueryRow(ctx, q, nik, *excludeID).Scan(&x)
+        err := r.conn(ctx).QueryRow(ctx, q, nik, *excludeID).Scan(&x)
         if errors.Is(err, pgx.ErrNoRows) {
             return false, nil
         }
@@ -179,7 +254,7 @@ func (r *MahasiswaRepository) ExistsNIK(ctx context.Context, nik string, exclude
     }
     const q = `SELECT 1 FROM mahasiswa WHERE nik = $1 LIMIT 1`
     var x int
-    err := r.pool.QueryRow(ctx, q, nik).Scan(&x)
+    err := r.conn(ctx).QueryRow(ctx, q, nik).Scan(&x)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -192,7 +267,7 @@ func (r *MahasiswaRepository) ExistsNIK(ctx context.Context, nik string, exclude
 func (r *MahasiswaRepository) ExistsProdi(ctx context.Context, idProdi string) (bool, error) {
     const q = `SELECT 1 FROM prodi WHERE id_prodi = $1 LIMIT 1`
     var x int
-    err := r.pool.QueryRow(ctx, q, idProdi).Scan(&x)
+    err := r.conn(ctx).QueryRow(ctx, q, idProdi).Scan(&x)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -206,7 +281,7 @@ func (r *MahasiswaRepository) Create(ctx context.Context, m *model.Mahasiswa) (*
     const q = `INSERT INTO mahasiswa (id_mahasiswa, id_prodi, nik, nama_lengkap, jenis_kelamin, tempat_lahir, tanggal_lahir, alamat, email, no_hp, tahun_masuk, status)
               VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
               RETURNING id_mahasiswa, id_prodi, nik, nama_lengkap, jenis_kelamin, tempat_lahir, tanggal_lahir, alamat, email, no_hp, tahun_masuk, status, angkatan, created_at, updated_at`
-    row := r.pool.QueryRow(ctx, q, m.IDMahasiswa, m.IDProdi, m.NIK, m.NamaLengkap, m.JenisKelamin, m.TempatLahir, m.TanggalLahir, m.Alamat, m.Email, m.NoHP, m.TahunMasuk, m.Status)
+    row := r.conn(ctx).QueryRow(ctx, q, m.IDMahasiswa, m.IDProdi, m.NIK, m.NamaLengkap, m.JenisKelamin, m.TempatLahir, m.TanggalLahir, m.Alamat, m.Email, m.NoHP, m.TahunMasuk, m.Status)
     var out model.Mahasiswa
     if err := row.Scan(&out.IDMahasiswa, &out.IDProdi, &out.NIK, &out.NamaLengkap, &out.JenisKelamin, &out.TempatLahir, &out.TanggalLahir, &out.Alamat, &out.Email, &out.NoHP, &out.TahunMasuk, &out.Status, &out.Angkatan, &out.CreatedAt, &out.UpdatedAt); err != nil {
         return nil, err
@@ -214,10 +289,11 @@ func (r *MahasiswaRepository) Create(ctx context.Context, m *model.Mahasiswa) (*
     return &out, nil
 }
 
+// UpdatePut mengganti seluruh kolom kecuali status; status hanya berubah lewat UpdateStatus
 func (r *MahasiswaRepository) UpdatePut(ctx context.Context, id string, m *model.Mahasiswa) (*model.Mahasiswa, error) {
-    const q = `UPDATE mahasiswa SET id_prodi=$1, nik=$2, nama_lengkap=$3, jenis_kelamin=$4, tempat_lahir=$5, tanggal_lahir=$6, alamat=$7, email=$8, no_hp=$9, tahun_masuk=$10, status=$11 WHERE id_mahasiswa=$12
+    const q = `UPDATE mahasiswa SET id_prodi=$1, nik=$2, nama_lengkap=$3, jenis_kelamin=$4, tempat_lahir=$5, tanggal_lahir=$6, alamat=$7, email=$8, no_hp=$9, tahun_masuk=$10 WHERE id_mahasiswa=$11
               RETURNING id_mahasiswa, id_prodi, nik, nama_lengkap, jenis_kelamin, tempat_lahir, tanggal_lahir, alamat, email, no_hp, tahun_masuk, status, angkatan, created_at, updated_at`
-    row := r.pool.QueryRow(ctx, q, m.IDProdi, m.NIK, m.NamaLengkap, m.JenisKelamin, m.TempatLahir, m.TanggalLahir, m.Alamat, m.Email, m.NoHP, m.TahunMasuk, m.Status, id)
+    row := r.conn(ctx).QueryRow(ctx, q, m.IDProdi, m.NIK, m.NamaLengkap, m.JenisKelamin, m.TempatLahir, m.TanggalLahir, m.Alamat, m.Email, m.NoHP, m.TahunMasuk, id)
     var out model.Mahasiswa
     if err := row.Scan(&out.IDMahasiswa, &out.IDProdi, &out.NIK, &out.NamaLengkap, &out.JenisKelamin, &out.TempatLahir, &out.TanggalLahir, &out.Alamat, &out.Email, &out.NoHP, &out.TahunMasuk, &out.Status, &out.Angkatan, &out.CreatedAt, &out.UpdatedAt); err != nil {
         return nil, err
@@ -225,7 +301,7 @@ func (r *MahasiswaRepository) UpdatePut(ctx context.Context, id string, m *model
     return &out, nil
 }
 
-func (r *MahasiswaRepository) UpdatePatch(ctx context.Context, id string, idProdi, nik, nama, jk, tempat, alamat, email, nohp, status *string, tgl *time.Time, tahunMasuk *int) (*model.Mahasiswa, error) {
+func (r *MahasiswaRepository) UpdatePatch(ctx context.Context, id string, idProdi, nik, nama, jk, tempat, alamat, email, nohp *string, tgl *time.Time, tahunMasuk *int) (*model.Mahasiswa, error) {
     sets := []string{}
     args := []any{}
     idx := 1
@@ -279,11 +355,6 @@ func (r *MahasiswaRepository) UpdatePatch(ctx context.Context, id string, idProd
         args = append(args, *tahunMasuk)
         idx++
     }
-    if status != nil {
-        sets = append(sets, fmt.Sprintf("status = $%d", idx))
-        args = append(args, *status)
-        idx++
-    }
 
     if len(sets) == 0 {
         return r.GetByID(ctx, id)
@@ -291,7 +362,19 @@ func (r *MahasiswaRepository) UpdatePatch(ctx context.Context, id string, idProd
 
     args = append(args, id)
     q := fmt.Sprintf("UPDATE mahasiswa SET %s WHERE id_mahasiswa = $%d RETURNING id_mahasiswa, id_prodi, nik, nama_lengkap, jenis_kelamin, tempat_lahir, tanggal_lahir, alamat, email, no_hp, tahun_masuk, status, angkatan, created_at, updated_at", strings.Join(sets, ", "), idx)
-    row := r.pool.QueryRow(ctx, q, args...)
+    row := r.conn(ctx).QueryRow(ctx, q, args...)
+    var out model.Mahasiswa
+    if err := row.Scan(&out.IDMahasiswa, &out.IDProdi, &out.NIK, &out.NamaLengkap, &out.JenisKelamin, &out.TempatLahir, &out.TanggalLahir, &out.Alamat, &out.Email, &out.NoHP, &out.TahunMasuk, &out.Status, &out.Angkatan, &out.CreatedAt, &out.UpdatedAt); err != nil {
+        return nil, err
+    }
+    return &out, nil
+}
+
+// UpdateStatus mengganti status mahasiswa; aturan transisi dan riwayatnya dijaga service
+func (r *MahasiswaRepository) UpdateStatus(ctx context.Context, id, status string) (*model.Mahasiswa, error) {
+    const q = `UPDATE mahasiswa SET status = $1 WHERE id_mahasiswa = $2
+              RETURNING id_mahasiswa, id_prodi, nik, nama_lengkap, jenis_kelamin, tempat_lahir, tanggal_lahir, alamat, email, no_hp, tahun_masuk, status, angkatan, created_at, updated_at`
+    row := r.conn(ctx).QueryRow(ctx, q, status, id)
     var out model.Mahasiswa
     if err := row.Scan(&out.IDMahasiswa, &out.IDProdi, &out.NIK, &out.NamaLengkap, &out.JenisKelamin, &out.TempatLahir, &out.TanggalLahir, &out.Alamat, &out.Email, &out.NoHP, &out.TahunMasuk, &out.Status, &out.Angkatan, &out.CreatedAt, &out.UpdatedAt); err != nil {
         return nil, err
@@ -299,10 +382,58 @@ func (r *MahasiswaRepository) UpdatePatch(ctx context.Context, id string, idProd
     return &out, nil
 }
 
+const statusHistoryColumns = `id_history, id_mahasiswa, status_lama, status_baru, tanggal_efektif, nomor_sk, alasan, override, id_user, username, role, created_at`
+
+func scanStatusHistory(row pgx.Row) (*model.MahasiswaStatusHistory, error) {
+    var h model.MahasiswaStatusHistory
+    if err := row.Scan(&h.IDHistory, &h.IDMahasiswa, &h.StatusLama, &h.StatusBaru, &h.TanggalEfektif, &h.NomorSK, &h.Alasan, &h.Override, &h.IDUser, &h.Username, &h.Role, &h.CreatedAt); err != nil {
+        return nil, err
+    }
+    return &h, nil
+}
+
+func (r *MahasiswaRepository) CreateStatusHistory(ctx context.Context, h *model.MahasiswaStatusHistory) (*model.MahasiswaStatusHistory, error) {
+    const q = `INSERT INTO mahasiswa_status_history (id_mahasiswa, status_lama, status_baru, tanggal_efektif, nomor_sk, alasan, override, id_user, username, role)
+              VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+              RETURNING ` + statusHistoryColumns
+    return scanStatusHistory(r.conn(ctx).QueryRow(ctx, q, h.IDMahasiswa, h.StatusLama, h.StatusBaru, h.TanggalEfektif, h.NomorSK, h.Alasan, h.Override, h.IDUser, h.Username, h.Role))
+}
+
+// ListStatusHistory mengembalikan riwayat status mahasiswa, terbaru lebih dulu
+func (r *MahasiswaRepository) ListStatusHistory(ctx context.Context, id string) ([]model.MahasiswaStatusHistory, error) {
+    const q = `SELECT ` + statusHistoryColumns + ` FROM mahasiswa_status_history WHERE id_mahasiswa = $1
+              ORDER BY tanggal_efektif DESC, id_history DESC`
+    rows, err := r.conn(ctx).Query(ctx, q, id)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    out := []model.MahasiswaStatusHistory{}
+    for rows.Next() {
+        h, err := scanStatusHistory(rows)
+        if err != nil {
+            return nil, err
+        }
+        out = append(out, *h)
+    }
+    return out, rows.Err()
+}
+
+// LatestStatusDate mengembalikan tanggal efektif transisi terakhir; nil bila belum ada riwayat
+func (r *MahasiswaRepository) LatestStatusDate(ctx context.Context, id string) (*time.Time, error) {
+    const q = `SELECT MAX(tanggal_efektif) FROM mahasiswa_status_history WHERE id_mahasiswa = $1`
+    var t *time.Time
+    if err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&t); err != nil {
+        return nil, err
+    }
+    return t, nil
+}
+
 func (r *MahasiswaRepository) HasKRSRelated(ctx context.Context, id string) (bool, error) {
     const q = `SELECT 1 FROM krs WHERE id_mahasiswa = $1 LIMIT 1`
     var x int
-    err := r.pool.QueryRow(ctx, q, id).Scan(&x)
+    err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&x)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -312,9 +443,16 @@ func (r *MahasiswaRepository) HasKRSRelated(ctx context.Context, id string) (boo
     return true, nil
 }
 
+// LockByID mengunci baris mahasiswa (FOR UPDATE) sampai transaksi selesai; pgx.ErrNoRows bila tidak ada
+func (r *MahasiswaRepository) LockByID(ctx context.Context, id string) error {
+    const q = `SELECT 1 FROM mahasiswa WHERE id_mahasiswa = $1 FOR UPDATE`
+    var x int
+    return r.conn(ctx).QueryRow(ctx, q, id).Scan(&x)
+}
+
 func (r *MahasiswaRepository) Delete(ctx context.Context, id string) error {
     const q = `DELETE FROM mahasiswa WHERE id_mahasiswa = $1`
-    ct, err := r.pool.Exec(ctx, q, id)
+    ct, err := r.conn(ctx).Exec(ctx, q, id)
     if err != nil {
         return err
     }
@@ -0,0 +1,201 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"pencatatan-data-mahasiswa/internal/db"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+)
+
+type PembimbingRepository struct {
+	db db.DBTX
+}
+
+func NewPembimbingRepository(conn db.DBTX) *PembimbingRepository {
+	return &PembimbingRepository{db: conn}
+}
+
+func (r *PembimbingRepository) conn(ctx context.Context) db.DBTX {
+	return db.Conn(ctx, r.db)
+}
+
+// BimbinganFilterFields adalah field yang boleh dipakai pada grammar filter daftar mahasiswa bimbingan
+var BimbinganFilterFields = map[string]FilterField{
+	"id_mahasiswa": {Column: "m.id_mahasiswa", Kind: FilterText},
+	"status":       {Column: "m.status", Kind: FilterText},
+	"id_prodi":     {Column: "m.id_prodi", Kind: FilterText},
+	"angkatan":     {Column: "m.angkatan", Kind: FilterInt},
+}
+
+const pembimbingColumns = `p.id_pembimbing, p.id_mahasiswa, p.id_dosen, d.nama_dosen, p.tanggal_mulai, p.tanggal_selesai,
+	p.alasan, p.id_user, p.username, p.created_at`
+
+const pembimbingFrom = ` FROM pembimbing_akademik p JOIN dosen d ON d.id_dosen = p.id_dosen`
+
+func scanPembimbing(row pgx.Row) (*model.PembimbingAkademik, error) {
+	var p model.PembimbingAkademik
+	if err := row.Scan(&p.IDPembimbing, &p.IDMahasiswa, &p.IDDosen, &p.NamaDosen, &p.TanggalMulai, &p.TanggalSelesai,
+		&p.Alasan, &p.IDUser, &p.Username, &p.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Current mengembalikan pembimbing aktif mahasiswa; pgx.ErrNoRows bila belum punya PA
+func (r *PembimbingRepository) Current(ctx context.Context, idMahasiswa string) (*model.PembimbingAkademik, error) {
+	const q = `SELECT ` + pembimbingColumns + pembimbingFrom + ` WHERE p.id_mahasiswa = $1 AND p.tanggal_selesai IS NULL`
+	return scanPembimbing(r.conn(ctx).QueryRow(ctx, q, idMahasiswa))
+}
+
+// History mengembalikan riwayat pembimbing mahasiswa, terbaru lebih dulu
+func (r *PembimbingRepository) History(ctx context.Context, idMahasiswa string) ([]model.PembimbingAkademik, error) {
+	const q = `SELECT ` + pembimbingColumns + pembimbingFrom + ` WHERE p.id_mahasiswa = $1
+              ORDER BY p.tanggal_mulai DESC, p.id_pembimbing DESC`
+	rows, err := r.conn(ctx).Query(ctx, q, idMahasiswa)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.PembimbingAkademik{}
+	for rows.Next() {
+		p, err := scanPembimbing(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, rows.Err()
+}
+
+// End menutup masa bimbingan yang masih aktif
+func (r *PembimbingRepository) End(ctx context.Context, id int64, tanggal time.Time) error {
+	const q = `UPDATE pembimbing_akademik SET tanggal_selesai = $1 WHERE id_pembimbing = $2 AND tanggal_selesai IS NULL`
+	ct, err := r.conn(ctx).Exec(ctx, q, tanggal, id)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *PembimbingRepository) Create(ctx context.Context, p *model.PembimbingAkademik) (*model.PembimbingAkademik, error) {
+	const q = `INSERT INTO pembimbing_akademik (id_mahasiswa, id_dosen, tanggal_mulai, alasan, id_user, username)
+              VALUES ($1,$2,$3,$4,$5,$6) RETURNING id_pembimbing`
+	var id int64
+	if err := r.conn(ctx).QueryRow(ctx, q, p.IDMahasiswa, p.IDDosen, p.TanggalMulai, p.Alasan, p.IDUser, p.Username).Scan(&id); err != nil {
+		return nil, err
+	}
+	return scanPembimbing(r.conn(ctx).QueryRow(ctx, `SELECT `+pembimbingColumns+pembimbingFrom+` WHERE p.id_pembimbing = $1`, id))
+}
+
+// ReassignPending memindahkan KRS yang masih menunggu persetujuan ke PA baru
+func (r *PembimbingRepository) ReassignPending(ctx context.Context, idMahasiswa, idDosen string) error {
+	const q = `UPDATE krs_pengajuan SET id_dosen = $1 WHERE id_mahasiswa = $2 AND status = 'Diajukan'`
+	_, err := r.conn(ctx).Exec(ctx, q, idDosen, idMahasiswa)
+	return err
+}
+
+// LockDosen mengunci baris dosen (FOR KEY SHARE) agar tidak terhapus selama penetapan PA
+func (r *PembimbingRepository) LockDosen(ctx context.Context, id string) error {
+	const q = `SELECT 1 FROM dosen WHERE id_dosen = $1 FOR KEY SHARE`
+	var x int
+	return r.conn(ctx).QueryRow(ctx, q, id).Scan(&x)
+}
+
+// LockMahasiswaAngkatan mengunci dan mengembalikan NIM mahasiswa prodi/angkatan yang belum Lulus
+// atau Drop Out, urut NIM agar urutan lock konsisten antar transaksi
+func (r *PembimbingRepository) LockMahasiswaAngkatan(ctx context.Context, idProdi string, angkatan int) ([]string, error) {
+	const q = `SELECT id_mahasiswa FROM mahasiswa
+              WHERE id_prodi = $1 AND angkatan = $2 AND status NOT IN ('Lulus','Drop Out')
+              ORDER BY id_mahasiswa FOR UPDATE`
+	rows, err := r.conn(ctx).Query(ctx, q, idProdi, angkatan)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// ipkLateral menghitung IPK per mahasiswa m: nilai terbaik per mata kuliah dari KRS yang disetujui
+const ipkLateral = ` LEFT JOIN LATERAL (
+	SELECT ROUND(SUM(b.bobot * b.sks) / NULLIF(SUM(b.sks), 0), 2)::float8 AS ipk, COALESCE(SUM(b.sks), 0) AS total_sks
+	FROM (
+		SELECT DISTINCT ON (mk.id_mk) n.bobot, mk.sks
+		FROM krs k
+		JOIN krs_pengajuan kp ON kp.id_mahasiswa = k.id_mahasiswa AND kp.id_semester = k.id_semester AND kp.status = 'Disetujui'
+		JOIN nilai n ON n.id_krs = k.id_krs
+		JOIN kelas_kuliah kk ON kk.id_kelas = k.id_kelas
+		JOIN mata_kuliah mk ON mk.id_mk = kk.id_mk
+		WHERE k.id_mahasiswa = m.id_mahasiswa AND k.status_krs = 'Diambil' AND n.bobot IS NOT NULL
+		ORDER BY mk.id_mk, n.bobot DESC
+	) b
+) g ON TRUE`
+
+const bimbinganFrom = ` FROM pembimbing_akademik p JOIN mahasiswa m ON m.id_mahasiswa = p.id_mahasiswa`
+
+func bimbinganWhere(idDosen string, filters []Filter) (string, []any) {
+	where, args := appendFilterSQL([]string{"p.id_dosen = $1", "p.tanggal_selesai IS NULL"}, []any{idDosen}, filters)
+	return " WHERE " + strings.Join(where, " AND "), args
+}
+
+// ListBimbingan mengembalikan mahasiswa bimbingan aktif dosen; orderBy sudah disanitasi service
+func (r *PembimbingRepository) ListBimbingan(ctx context.Context, idDosen string, filters []Filter, limit, offset int, orderBy string) ([]model.Bimbingan, error) {
+	where, args := bimbinganWhere(idDosen, filters)
+	if orderBy == "" {
+		orderBy = "m.id_mahasiswa ASC"
+	}
+	q := `SELECT m.id_mahasiswa, m.nama_lengkap, m.id_prodi, m.angkatan, m.status, g.ipk, g.total_sks, p.tanggal_mulai` +
+		bimbinganFrom + ipkLateral + where + " ORDER BY " + orderBy + ", m.id_mahasiswa ASC"
+	if limit > 0 {
+		args = append(args, limit)
+		q += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if offset > 0 {
+		args = append(args, offset)
+		q += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	rows, err := r.conn(ctx).Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.Bimbingan{}
+	for rows.Next() {
+		var b model.Bimbingan
+		if err := rows.Scan(&b.IDMahasiswa, &b.NamaLengkap, &b.IDProdi, &b.Angkatan, &b.Status, &b.IPK, &b.TotalSKS, &b.TanggalMulai); err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+func (r *PembimbingRepository) CountBimbingan(ctx context.Context, idDosen string, filters []Filter) (int64, error) {
+	where, args := bimbinganWhere(idDosen, filters)
+	var total int64
+	err := r.conn(ctx).QueryRow(ctx, "SELECT COUNT(*)"+bimbinganFrom+where, args...).Scan(&total)
+	return total, err
+}
+
+// IsAdvisee true bila mahasiswa sedang dibimbing dosen tersebut
+func (r *PembimbingRepository) IsAdvisee(ctx context.Context, idDosen, idMahasiswa string) (bool, error) {
+	const q = `SELECT 1 FROM pembimbing_akademik WHERE id_dosen = $1 AND id_mahasiswa = $2 AND tanggal_selesai IS NULL`
+	var x int
+	err := r.conn(ctx).QueryRow(ctx, q, idDosen, idMahasiswa).Scan(&x)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
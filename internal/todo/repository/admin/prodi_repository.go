@@ -7,47 +7,56 @@ import (
     "strings"
 
     "github.com/jackc/pgx/v5"
-    "github.com/jackc/pgx/v5/pgxpool"
 
+    "pencatatan-data-mahasiswa/internal/db"
     model "pencatatan-data-mahasiswa/internal/todo/model/admin"
 )
 
 type ProdiRepository struct {
-    pool *pgxpool.Pool
+    db db.DBTX
 }
 
-func NewProdiRepository(pool *pgxpool.Pool) *ProdiRepository {
-    return &ProdiRepository{pool: pool}
+func NewProdiRepository(conn db.DBTX) *ProdiRepository {
+    return &ProdiRepository{db: conn}
 }
 
-// List returns prodi with optional filters and pagination and orderBy (pre-sanitized)
-func (r *ProdiRepository) List(ctx context.Context, q string, idFakultas, jenjang, akreditasi *string, limit, offset int, orderBy string) ([]model.Prodi, error) {
-    sb := strings.Builder{}
-    args := []any{}
-    sb.WriteString("SELECT id_prodi, id_fakultas, nama_prodi, jenjang, kode_prodi, akreditasi, created_at, updated_at FROM prodi")
+func (r *ProdiRepository) conn(ctx context.Context) db.DBTX {
+    return db.Conn(ctx, r.db)
+}
+
+// ProdiFilterFields adalah field yang boleh dipakai pada grammar filter list prodi
+var ProdiFilterFields = map[string]FilterField{
+    "id_fakultas": {Column: "id_fakultas", Kind: FilterText},
+    "jenjang":     {Column: "jenjang", Kind: FilterText, Enum: []string{"D3", "D4", "S1", "S2", "S3"}},
+    "akreditasi":  {Column: "akreditasi", Kind: FilterText, Enum: []string{"A", "B", "C", "Baik", "Baik Sekali", "Unggul"}, Nullable: true},
+    "kode_prodi":  {Column: "kode_prodi", Kind: FilterText},
+    "created_at":  {Column: "created_at", Kind: FilterTime},
+    "updated_at":  {Column: "updated_at", Kind: FilterTime},
+}
 
+// prodiWhere membangun klausa WHERE beserta argumennya; dipakai bersama oleh List dan Count
+func prodiWhere(q string, filters []Filter) (string, []any) {
+    args := []any{}
     where := []string{}
     if q != "" {
         args = append(args, "%"+q+"%")
         args = append(args, "%"+q+"%")
         where = append(where, fmt.Sprintf("(nama_prodi ILIKE $%d OR kode_prodi ILIKE $%d)", len(args)-1, len(args)))
     }
-    if idFakultas != nil && *idFakultas != "" {
-        args = append(args, *idFakultas)
-        where = append(where, fmt.Sprintf("id_fakultas = $%d", len(args)))
-    }
-    if jenjang != nil && *jenjang != "" {
-        args = append(args, *jenjang)
-        where = append(where, fmt.Sprintf("jenjang = $%d", len(args)))
-    }
-    if akreditasi != nil && *akreditasi != "" {
-        args = append(args, *akreditasi)
-        where = append(where, fmt.Sprintf("akreditasi = $%d", len(args)))
-    }
-    if len(where) > 0 {
-        sb.WriteString(" WHERE ")
-        sb.WriteString(strings.Join(where, " AND "))
+    where, args = appendFilterSQL(where, args, filters)
+    if len(where) == 0 {
+        return "", args
     }
+    return " WHERE " + strings.Join(where, " AND "), args
+}
+
+// List returns prodi with optional filters and pagination and orderBy (pre-sanitized)
+func (r *ProdiRepository) List(ctx context.Context, q string, filters []Filter, limit, offset int, orderBy string) ([]model.Prodi, error) {
+    sb := strings.Builder{}
+    sb.WriteString("SELECT id_prodi, id_fakultas, nama_prodi, jenjang, kode_prodi, akreditasi, created_at, updated_at FROM prodi")
+
+    where, args := prodiWhere(q, filters)
+    sb.WriteString(where)
 
     // orderBy passed in as safe string
     if orderBy == "" {
@@ -65,7 +74,7 @@ func (r *ProdiRepository) List(ctx context.Context, q string, idFakultas, jenjan
         sb.WriteString(fmt.Sprintf(" OFFSET $%d", len(args)))
     }
 
-    rows, err := r.pool.Query(ctx, sb.String(), args...)
+    rows, err := r.conn(ctx).Query(ctx, sb.String(), args...)
     if err != nil {
         return nil, err
     }
@@ -82,9 +91,19 @@ func (r *ProdiRepository) List(ctx context.Context, q string, idFakultas, jenjan
     return out, rows.Err()
 }
 
+// Count returns the number of prodi matching the same filters as List
+func (r *ProdiRepository) Count(ctx context.Context, q string, filters []Filter) (int64, error) {
+    where, args := prodiWhere(q, filters)
+    var total int64
+    if err := r.conn(ctx).QueryRow(ctx, "SELECT COUNT(*) FROM prodi"+where, args...).Scan(&total); err != nil {
+        return 0, err
+    }
+    return total, nil
+}
+
 func (r *ProdiRepository) GetByID(ctx context.Context, id string) (*model.Prodi, error) {
     const q = `SELECT id_prodi, id_fakultas, nama_prodi, jenjang, kode_prodi, akreditasi, created_at, updated_at FROM prodi WHERE id_prodi = $1`
-    row := r.pool.QueryRow(ctx, q, id)
+    row := r.conn(ctx).QueryRow(ctx, q, id)
     var p model.Prodi
     if err := row.Scan(&p.IDProdi, &p.IDFakultas, &p.NamaProdi, &p.Jenjang, &p.KodeProdi, &p.Akreditasi, &p.CreatedAt, &p.UpdatedAt); err != nil {
         return nil, err
@@ -95,7 +114,7 @@ func (r *ProdiRepository) GetByID(ctx context.Context, id string) (*model.Prodi,
 func (r *ProdiRepository) ExistsID(ctx context.Context, id string) (bool, error) {
     const q = `SELECT 1 FROM prodi WHERE id_prodi = $1 LIMIT 1`
     var dummy int
-    err := r.pool.QueryRow(ctx, q, id).Scan(&dummy)
+    err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&dummy)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -108,7 +127,7 @@ func (r *ProdiRepository) ExistsID(ctx context.Context, id string) (bool, error)
 func (r *ProdiRepository) ExistsFakultas(ctx context.Context, idFak string) (bool, error) {
     const q = `SELECT 1 FROM fakultas WHERE id_fakultas = $1 LIMIT 1`
     var dummy int
-    err := r.pool.QueryRow(ctx, q, idFak).Scan(&dummy)
+    err := r.conn(ctx).QueryRow(ctx, q, idFak).Scan(&dummy)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -122,7 +141,7 @@ func (r *ProdiRepository) ExistsKode(ctx context.Context, kode string, excludeID
     if excludeID != nil {
         const q = `SELECT 1 FROM prodi WHERE kode_prodi = $1 AND id_prodi <> $2 LIMIT 1`
         var dummy int
-        err := r.pool.QueryRow(ctx, q, kode, *excludeID).Scan(&dummy)
+        err := r.conn(ctx).QueryRow(ctx, q, kode, *excludeID).Scan(&dummy)
         if errors.Is(err, pgx.ErrNoRows) {
             return false, nil
         }
@@ -133,7 +152,7 @@ func (r *ProdiRepository) ExistsKode(ctx context.Context, kode string, excludeID
     }
     const q = `SELECT 1 FROM prodi WHERE kode_prodi = $1 LIMIT 1`
     var dummy int
-    err := r.pool.QueryRow(ctx, q, kode).Scan(&dummy)
+    err := r.conn(ctx).QueryRow(ctx, q, kode).Scan(&dummy)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -147,7 +166,7 @@ func (r *ProdiRepository) ExistsNamaPerFakultasJenjangCI(ctx context.Context, id
     if excludeID != nil {
         const q = `SELECT 1 FROM prodi WHERE id_fakultas = $1 AND jenjang = $2 AND LOWER(nama_prodi) = LOWER($3) AND id_prodi <> $4 LIMIT 1`
         var dummy int
-        err := r.pool.QueryRow(ctx, q, idFakultas, jenjang, nama, *excludeID).Scan(&dummy)
+        err := r.conn(ctx).QueryRow(ctx, q, idFakultas, jenjang, nama, *excludeID).Scan(&dummy)
         if errors.Is(err, pgx.ErrNoRows) {
             return false, nil
         }
@@ -158,7 +177,7 @@ func (r *ProdiRepository) ExistsNamaPerFakultasJenjangCI(ctx context.Context, id
     }
     const q = `SELECT 1 FROM prodi WHERE id_fakultas = $1 AND jenjang = $2 AND LOWER(nama_prodi) = LOWER($3) LIMIT 1`
     var dummy int
-    err := r.pool.QueryRow(ctx, q, idFakultas, jenjang, nama).Scan(&dummy)
+    err := r.conn(ctx).QueryRow(ctx, q, idFakultas, jenjang, nama).Scan(&dummy)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -171,7 +190,7 @@ func (r *ProdiRepository) ExistsNamaPerFakultasJenjangCI(ctx context.Context, id
 func (r *ProdiRepository) Create(ctx context.Context, p *model.Prodi) (*model.Prodi, error) {
     const q = `INSERT INTO prodi (id_prodi, id_fakultas, nama_prodi, jenjang, kode_prodi, akreditasi) VALUES ($1,$2,$3,$4,$5,$6)
                RETURNING id_prodi, id_fakultas, nama_prodi, jenjang, kode_prodi, akreditasi, created_at, updated_at`
-    row := r.pool.QueryRow(ctx, q, p.IDProdi, p.IDFakultas, p.NamaProdi, p.Jenjang, p.KodeProdi, p.Akreditasi)
+    row := r.conn(ctx).QueryRow(ctx, q, p.IDProdi, p.IDFakultas, p.NamaProdi, p.Jenjang, p.KodeProdi, p.Akreditasi)
     var out model.Prodi
     if err := row.Scan(&out.IDProdi, &out.IDFakultas, &out.NamaProdi, &out.Jenjang, &out.KodeProdi, &out.Akreditasi, &out.CreatedAt, &out.UpdatedAt); err != nil {
         return nil, err
@@ -185,7 +204,7 @@ func (r *ProdiRepository) UpdatePut(ctx context.Context, id string, p *model.Pro
                SET id_fakultas=$1, nama_prodi=$2, jenjang=$3, kode_prodi=$4, akreditasi=$5
                WHERE id_prodi=$6
                RETURNING id_prodi, id_fakultas, nama_prodi, jenjang, kode_prodi, akreditasi, created_at, updated_at`
-    row := r.pool.QueryRow(ctx, q, p.IDFakultas, p.NamaProdi, p.Jenjang, p.KodeProdi, p.Akreditasi, id)
+    row := r.conn(ctx).QueryRow(ctx, q, p.IDFakultas, p.NamaProdi, p.Jenjang, p.KodeProdi, p.Akreditasi, id)
     var out model.Prodi
     if err := row.Scan(&out.IDProdi, &out.IDFakultas, &out.NamaProdi, &out.Jenjang, &out.KodeProdi, &out.Akreditasi, &out.CreatedAt, &out.UpdatedAt); err != nil {
         return nil, err
@@ -228,7 +247,7 @@ func (r *ProdiRepository) UpdatePatch(ctx context.Context, id string, idFakultas
     }
     args = append(args, id)
     q := fmt.Sprintf("UPDATE prodi SET %s WHERE id_prodi = $%d RETURNING id_prodi, id_fakultas, nama_prodi, jenjang, kode_prodi, akreditasi, created_at, updated_at", strings.Join(sets, ", "), idx)
-    row := r.pool.QueryRow(ctx, q, args...)
+    row := r.conn(ctx).QueryRow(ctx, q, args...)
     var out model.Prodi
     if err := row.Scan(&out.IDProdi, &out.IDFakultas, &out.NamaProdi, &out.Jenjang, &out.KodeProdi, &out.Akreditasi, &out.CreatedAt, &out.UpdatedAt); err != nil {
         return nil, err
@@ -239,7 +258,7 @@ func (r *ProdiRepository) UpdatePatch(ctx context.Context, id string, idFakultas
 func (r *ProdiRepository) HasMahasiswaRelated(ctx context.Context, id string) (bool, error) {
     const q = `SELECT 1 FROM mahasiswa WHERE id_prodi = $1 LIMIT 1`
     var dummy int
-    err := r.pool.QueryRow(ctx, q, id).Scan(&dummy)
+    err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&dummy)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -252,7 +271,7 @@ func (r *ProdiRepository) HasMahasiswaRelated(ctx context.Context, id string) (b
 func (r *ProdiRepository) HasMataKuliahRelated(ctx context.Context, id string) (bool, error) {
     const q = `SELECT 1 FROM mata_kuliah WHERE id_prodi = $1 LIMIT 1`
     var dummy int
-    err := r.pool.QueryRow(ctx, q, id).Scan(&dummy)
+    err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&dummy)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -262,9 +281,16 @@ func (r *ProdiRepository) HasMataKuliahRelated(ctx context.Context, id string) (
     return true, nil
 }
 
+// LockByID mengunci baris prodi (FOR UPDATE) sampai transaksi selesai; pgx.ErrNoRows bila tidak ada
+func (r *ProdiRepository) LockByID(ctx context.Context, id string) error {
+    const q = `SELECT 1 FROM prodi WHERE id_prodi = $1 FOR UPDATE`
+    var x int
+    return r.conn(ctx).QueryRow(ctx, q, id).Scan(&x)
+}
+
 func (r *ProdiRepository) Delete(ctx context.Context, id string) error {
     const q = `DELETE FROM prodi WHERE id_prodi = $1`
-    ct, err := r.pool.Exec(ctx, q, id)
+    ct, err := r.conn(ctx).Exec(ctx, q, id)
     if err != nil {
         return err
     }
@@ -0,0 +1,50 @@
+package admin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mode pencarian nama untuk parameter match pada list mahasiswa dan dosen
+const (
+	MatchExact  = "exact"  // substring ILIKE, perilaku lama
+	MatchPrefix = "prefix" // diawali q, tanpa memperhatikan diakritik
+	MatchFuzzy  = "fuzzy"  // trigram word_similarity (pg_trgm), diurutkan berdasarkan relevansi
+)
+
+// likeEscaper meloloskan karakter khusus LIKE agar q dicocokkan apa adanya (dipakai dengan ESCAPE '\')
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// nameSearchClause membangun kondisi pencarian q pada kolom nama (memakai index trigram dari
+// migrasi 0012) dan kolom tambahan (id/email/nidn). rank hanya terisi untuk mode fuzzy dan
+// dipakai sebagai urutan relevansi.
+func nameSearchClause(match, q, nameCol string, otherCols []string, args []any) (cond string, rank string, out []any) {
+	parts := []string{}
+	lit := likeEscaper.Replace(q)
+	switch match {
+	case MatchFuzzy:
+		args = append(args, q)
+		n := len(args)
+		parts = append(parts, fmt.Sprintf("immutable_unaccent($%d) <%% immutable_unaccent(%s)", n, nameCol))
+		rank = fmt.Sprintf("word_similarity(immutable_unaccent($%d), immutable_unaccent(%s))", n, nameCol)
+		for _, col := range otherCols {
+			args = append(args, "%"+lit+"%")
+			parts = append(parts, fmt.Sprintf(`%s ILIKE $%d ESCAPE '\'`, col, len(args)))
+		}
+	case MatchPrefix:
+		args = append(args, lit+"%")
+		n := len(args)
+		parts = append(parts, fmt.Sprintf(`immutable_unaccent(%s) ILIKE immutable_unaccent($%d) ESCAPE '\'`, nameCol, n))
+		for _, col := range otherCols {
+			parts = append(parts, fmt.Sprintf(`%s ILIKE $%d ESCAPE '\'`, col, n))
+		}
+	default:
+		args = append(args, "%"+lit+"%")
+		parts = append(parts, fmt.Sprintf(`%s ILIKE $%d ESCAPE '\'`, nameCol, len(args)))
+		for _, col := range otherCols {
+			args = append(args, "%"+lit+"%")
+			parts = append(parts, fmt.Sprintf(`%s ILIKE $%d ESCAPE '\'`, col, len(args)))
+		}
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", rank, args
+}
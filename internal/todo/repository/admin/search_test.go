@@ -0,0 +1,34 @@
+package admin
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestNameSearchClauseEscapesLike: %, _ dan \ pada q dicocokkan apa adanya, bukan sebagai wildcard
+func TestNameSearchClauseEscapesLike(t *testing.T) {
+	tests := []struct {
+		match, q string
+		want     []any
+	}{
+		{MatchPrefix, `50%_a\b`, []any{`50\%\_a\\b%`}},
+		{MatchExact, `a_b`, []any{`%a\_b%`, `%a\_b%`}},
+		{MatchFuzzy, `a%`, []any{`a%`, `%a\%%`}}, // argumen trigram tidak memakai LIKE
+	}
+	for _, tt := range tests {
+		t.Run(tt.match, func(t *testing.T) {
+			cond, _, args := nameSearchClause(tt.match, tt.q, "nama", []string{"email"}, nil)
+			if len(args) != len(tt.want) {
+				t.Fatalf("args = %q, want %q", args, tt.want)
+			}
+			for i := range args {
+				if args[i] != tt.want[i] {
+					t.Errorf("args[%d] = %q, want %q", i, args[i], tt.want[i])
+				}
+			}
+			if strings.Count(cond, "ILIKE") != strings.Count(cond, `ESCAPE '\'`) {
+				t.Errorf("every ILIKE needs ESCAPE: %s", cond)
+			}
+		})
+	}
+}
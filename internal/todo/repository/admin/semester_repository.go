@@ -8,25 +8,36 @@ import (
     "time"
 
     "github.com/jackc/pgx/v5"
-    "github.com/jackc/pgx/v5/pgxpool"
 
+    "pencatatan-data-mahasiswa/internal/db"
     model "pencatatan-data-mahasiswa/internal/todo/model/admin"
 )
 
 type SemesterRepository struct {
-    pool *pgxpool.Pool
+    db db.DBTX
 }
 
-func NewSemesterRepository(pool *pgxpool.Pool) *SemesterRepository {
-    return &SemesterRepository{pool: pool}
+func NewSemesterRepository(conn db.DBTX) *SemesterRepository {
+    return &SemesterRepository{db: conn}
 }
 
-// List returns semesters with optional filters and pagination; orderBy must be sanitized beforehand
-func (r *SemesterRepository) List(ctx context.Context, q string, tahunAjaran, term *string, limit, offset int, orderBy string) ([]model.Semester, error) {
-    sb := strings.Builder{}
-    args := []any{}
-    sb.WriteString("SELECT id_semester, tahun_ajaran, term, tanggal_mulai, tanggal_selesai, created_at, updated_at FROM semester")
+func (r *SemesterRepository) conn(ctx context.Context) db.DBTX {
+    return db.Conn(ctx, r.db)
+}
+
+// SemesterFilterFields adalah field yang boleh dipakai pada grammar filter list semester
+var SemesterFilterFields = map[string]FilterField{
+    "tahun_ajaran":    {Column: "tahun_ajaran", Kind: FilterText},
+    "term":            {Column: "term", Kind: FilterText, Enum: []string{"Ganjil", "Genap", "Antara"}},
+    "tanggal_mulai":   {Column: "tanggal_mulai", Kind: FilterDate, Nullable: true},
+    "tanggal_selesai": {Column: "tanggal_selesai", Kind: FilterDate, Nullable: true},
+    "created_at":      {Column: "created_at", Kind: FilterTime},
+    "updated_at":      {Column: "updated_at", Kind: FilterTime},
+}
 
+// semesterWhere membangun klausa WHERE beserta argumennya; dipakai bersama oleh List dan Count
+func semesterWhere(q string, filters []Filter) (string, []any) {
+    args := []any{}
     where := []string{}
     if q != "" {
         args = append(args, "%"+q+"%")
@@ -34,19 +45,20 @@ func (r *SemesterRepository) List(ctx context.Context, q string, tahunAjaran, te
         args = append(args, "%"+q+"%")
         where = append(where, fmt.Sprintf("(id_semester ILIKE $%d OR tahun_ajaran ILIKE $%d OR term ILIKE $%d)", len(args)-2, len(args)-1, len(args)))
     }
-    if tahunAjaran != nil && *tahunAjaran != "" {
-        args = append(args, *tahunAjaran)
-        where = append(where, fmt.Sprintf("tahun_ajaran = $%d", len(args)))
-    }
-    if term != nil && *term != "" {
-        args = append(args, *term)
-        where = append(where, fmt.Sprintf("term = $%d", len(args)))
+    where, args = appendFilterSQL(where, args, filters)
+    if len(where) == 0 {
+        return "", args
     }
+    return " WHERE " + strings.Join(where, " AND "), args
+}
 
-    if len(where) > 0 {
-        sb.WriteString(" WHERE ")
-        sb.WriteString(strings.Join(where, " AND "))
-    }
+// List returns semesters with optional filters and pagination; orderBy must be sanitized beforehand
+func (r *SemesterRepository) List(ctx context.Context, q string, filters []Filter, limit, offset int, orderBy string) ([]model.Semester, error) {
+    sb := strings.Builder{}
+    sb.WriteString("SELECT id_semester, tahun_ajaran, term, tanggal_mulai, tanggal_selesai, created_at, updated_at FROM semester")
+
+    where, args := semesterWhere(q, filters)
+    sb.WriteString(where)
 
     if orderBy == "" {
         orderBy = "id_semester DESC"
@@ -63,7 +75,7 @@ func (r *SemesterRepository) List(ctx context.Context, q string, tahunAjaran, te
         sb.WriteString(fmt.Sprintf(" OFFSET $%d", len(args)))
     }
 
-    rows, err := r.pool.Query(ctx, sb.String(), args...)
+    rows, err := r.conn(ctx).Query(ctx, sb.String(), args...)
     if err != nil {
         return nil, err
     }
@@ -80,9 +92,19 @@ func (r *SemesterRepository) List(ctx context.Context, q string, tahunAjaran, te
     return out, rows.Err()
 }
 
+// Count returns the number of semesters matching the same filters as List
+func (r *SemesterRepository) Count(ctx context.Context, q string, filters []Filter) (int64, error) {
+    where, args := semesterWhere(q, filters)
+    var total int64
+    if err := r.conn(ctx).QueryRow(ctx, "SELECT COUNT(*) FROM semester"+where, args...).Scan(&total); err != nil {
+        return 0, err
+    }
+    return total, nil
+}
+
 func (r *SemesterRepository) GetByID(ctx context.Context, id string) (*model.Semester, error) {
     const q = `SELECT id_semester, tahun_ajaran, term, tanggal_mulai, tanggal_selesai, created_at, updated_at FROM semester WHERE id_semester = $1`
-    row := r.pool.QueryRow(ctx, q, id)
+    row := r.conn(ctx).QueryRow(ctx, q, id)
     var s model.Semester
     if err := row.Scan(&s.IDSemester, &s.TahunAjaran, &s.Term, &s.TanggalMulai, &s.TanggalSelesai, &s.CreatedAt, &s.UpdatedAt); err != nil {
         return nil, err
@@ -93,7 +115,7 @@ func (r *SemesterRepository) GetByID(ctx context.Context, id string) (*model.Sem
 func (r *SemesterRepository) ExistsID(ctx context.Context, id string) (bool, error) {
     const q = `SELECT 1 FROM semester WHERE id_semester = $1 LIMIT 1`
     var x int
-    err := r.pool.QueryRow(ctx, q, id).Scan(&x)
+    err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&x)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -107,7 +129,7 @@ func (r *SemesterRepository) Create(ctx context.Context, s *model.Semester) (*mo
     const q = `INSERT INTO semester (id_semester, tahun_ajaran, term, tanggal_mulai, tanggal_selesai)
               VALUES ($1,$2,$3,$4,$5)
               RETURNING id_semester, tahun_ajaran, term, tanggal_mulai, tanggal_selesai, created_at, updated_at`
-    row := r.pool.QueryRow(ctx, q, s.IDSemester, s.TahunAjaran, s.Term, s.TanggalMulai, s.TanggalSelesai)
+    row := r.conn(ctx).QueryRow(ctx, q, s.IDSemester, s.TahunAjaran, s.Term, s.TanggalMulai, s.TanggalSelesai)
     var out model.Semester
     if err := row.Scan(&out.IDSemester, &out.TahunAjaran, &out.Term, &out.TanggalMulai, &out.TanggalSelesai, &out.CreatedAt, &out.UpdatedAt); err != nil {
         return nil, err
@@ -118,7 +140,7 @@ func (r *SemesterRepository) Create(ctx context.Context, s *model.Semester) (*mo
 func (r *SemesterRepository) UpdatePut(ctx context.Context, id string, s *model.Semester) (*model.Semester, error) {
     const q = `UPDATE semester SET tahun_ajaran=$1, term=$2, tanggal_mulai=$3, tanggal_selesai=$4 WHERE id_semester=$5
               RETURNING id_semester, tahun_ajaran, term, tanggal_mulai, tanggal_selesai, created_at, updated_at`
-    row := r.pool.QueryRow(ctx, q, s.TahunAjaran, s.Term, s.TanggalMulai, s.TanggalSelesai, id)
+    row := r.conn(ctx).QueryRow(ctx, q, s.TahunAjaran, s.Term, s.TanggalMulai, s.TanggalSelesai, id)
     var out model.Semester
     if err := row.Scan(&out.IDSemester, &out.TahunAjaran, &out.Term, &out.TanggalMulai, &out.TanggalSelesai, &out.CreatedAt, &out.UpdatedAt); err != nil {
         return nil, err
@@ -157,7 +179,7 @@ func (r *SemesterRepository) UpdatePatch(ctx context.Context, id string, tahunAj
 
     args = append(args, id)
     q := fmt.Sprintf("UPDATE semester SET %s WHERE id_semester = $%d RETURNING id_semester, tahun_ajaran, term, tanggal_mulai, tanggal_selesai, created_at, updated_at", strings.Join(sets, ", "), idx)
-    row := r.pool.QueryRow(ctx, q, args...)
+    row := r.conn(ctx).QueryRow(ctx, q, args...)
     var out model.Semester
     if err := row.Scan(&out.IDSemester, &out.TahunAjaran, &out.Term, &out.TanggalMulai, &out.TanggalSelesai, &out.CreatedAt, &out.UpdatedAt); err != nil {
         return nil, err
@@ -168,7 +190,7 @@ func (r *SemesterRepository) UpdatePatch(ctx context.Context, id string, tahunAj
 func (r *SemesterRepository) HasKelasRelated(ctx context.Context, id string) (bool, error) {
     const q = `SELECT 1 FROM kelas_kuliah WHERE id_semester = $1 LIMIT 1`
     var x int
-    err := r.pool.QueryRow(ctx, q, id).Scan(&x)
+    err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&x)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -181,7 +203,7 @@ func (r *SemesterRepository) HasKelasRelated(ctx context.Context, id string) (bo
 func (r *SemesterRepository) HasKRSRelated(ctx context.Context, id string) (bool, error) {
     const q = `SELECT 1 FROM krs WHERE id_semester = $1 LIMIT 1`
     var x int
-    err := r.pool.QueryRow(ctx, q, id).Scan(&x)
+    err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&x)
     if errors.Is(err, pgx.ErrNoRows) {
         return false, nil
     }
@@ -191,9 +213,16 @@ func (r *SemesterRepository) HasKRSRelated(ctx context.Context, id string) (bool
     return true, nil
 }
 
+// LockByID mengunci baris semester (FOR UPDATE) sampai transaksi selesai; pgx.ErrNoRows bila tidak ada
+func (r *SemesterRepository) LockByID(ctx context.Context, id string) error {
+    const q = `SELECT 1 FROM semester WHERE id_semester = $1 FOR UPDATE`
+    var x int
+    return r.conn(ctx).QueryRow(ctx, q, id).Scan(&x)
+}
+
 func (r *SemesterRepository) Delete(ctx context.Context, id string) error {
     const q = `DELETE FROM semester WHERE id_semester = $1`
-    ct, err := r.pool.Exec(ctx, q, id)
+    ct, err := r.conn(ctx).Exec(ctx, q, id)
     if err != nil {
         return err
     }
@@ -6,8 +6,8 @@ import (
 	"errors"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 
+	"pencatatan-data-mahasiswa/internal/db"
 	model "pencatatan-data-mahasiswa/internal/todo/model/auth"
 )
 
@@ -15,42 +15,72 @@ import (
 // Hanya expose method yang dibutuhkan oleh service
 
 type Repository struct {
-	pool *pgxpool.Pool
+	db db.DBTX
 }
 
-func NewRepository(pool *pgxpool.Pool) *Repository {
-	return &Repository{pool: pool}
+func NewRepository(conn db.DBTX) *Repository {
+	return &Repository{db: conn}
 }
 
-// GetByUsername mengambil user berdasarkan username, atau mengembalikan (nil, sql.ErrNoRows)
-func (r *Repository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
-	const q = `
-		SELECT id_user, username, password_hash, role, ref_id, created_at, updated_at
-		FROM users
-		WHERE username = $1
-		LIMIT 1
-	`
-	row := r.pool.QueryRow(ctx, q, username)
+func (r *Repository) conn(ctx context.Context) db.DBTX {
+	return db.Conn(ctx, r.db)
+}
+
+// userColumns adalah kolom yang dibaca scanUser, dipakai SELECT maupun RETURNING
+const userColumns = `id_user, username, password_hash, role, ref_id, must_change_password,
+		activation_token_hash, activation_expires_at, created_at, updated_at`
 
+// scanUser membaca satu baris users dengan urutan userColumns
+func scanUser(row pgx.Row) (*model.User, error) {
 	var (
-		u   model.User
-		ref sql.NullString
+		u       model.User
+		ref     sql.NullString
+		actHash sql.NullString
+		actExp  sql.NullTime
 	)
-	if err := row.Scan(&u.IDUser, &u.Username, &u.PasswordHash, &u.Role, &ref, &u.CreatedAt, &u.UpdatedAt); err != nil {
+	if err := row.Scan(&u.IDUser, &u.Username, &u.PasswordHash, &u.Role, &ref, &u.MustChangePassword,
+		&actHash, &actExp, &u.CreatedAt, &u.UpdatedAt); err != nil {
 		return nil, err
 	}
 	if ref.Valid {
 		v := ref.String
 		u.RefID = &v
 	}
+	if actHash.Valid {
+		v := actHash.String
+		u.ActivationTokenHash = &v
+	}
+	if actExp.Valid {
+		v := actExp.Time
+		u.ActivationExpiresAt = &v
+	}
 	return &u, nil
 }
 
+// GetByUsername mengambil user berdasarkan username, atau mengembalikan (nil, pgx.ErrNoRows)
+func (r *Repository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	q := `SELECT ` + userColumns + ` FROM users WHERE username = $1 LIMIT 1`
+	return scanUser(r.conn(ctx).QueryRow(ctx, q, username))
+}
+
+// GetByID mengambil user berdasarkan id_user
+func (r *Repository) GetByID(ctx context.Context, id int64) (*model.User, error) {
+	q := `SELECT ` + userColumns + ` FROM users WHERE id_user = $1`
+	return scanUser(r.conn(ctx).QueryRow(ctx, q, id))
+}
+
+// GetByActivationHash mengambil user pemilik token aktivasi (hash sha256) dan menguncinya
+// sampai transaksi selesai, sehingga satu token tidak bisa dipakai dua kali bersamaan
+func (r *Repository) GetByActivationHash(ctx context.Context, hash string) (*model.User, error) {
+	q := `SELECT ` + userColumns + ` FROM users WHERE activation_token_hash = $1 FOR UPDATE`
+	return scanUser(r.conn(ctx).QueryRow(ctx, q, hash))
+}
+
 // UsernameExists mengembalikan true jika username sudah ada
 func (r *Repository) UsernameExists(ctx context.Context, username string) (bool, error) {
 	const q = `SELECT 1 FROM users WHERE username = $1 LIMIT 1`
 	var dummy int
-	err := r.pool.QueryRow(ctx, q, username).Scan(&dummy)
+	err := r.conn(ctx).QueryRow(ctx, q, username).Scan(&dummy)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return false, nil
 	}
@@ -60,11 +90,12 @@ func (r *Repository) UsernameExists(ctx context.Context, username string) (bool,
 	return true, nil
 }
 
-// ExistsMahasiswaByID validasi keberadaan id_mahasiswa
+// ExistsMahasiswaByID validasi keberadaan id_mahasiswa. FOR KEY SHARE: di dalam transaksi baris
+// tidak bisa dihapus sampai user yang merujuknya tersimpan (users.ref_id tidak punya FK)
 func (r *Repository) ExistsMahasiswaByID(ctx context.Context, id string) (bool, error) {
-	const q = `SELECT 1 FROM mahasiswa WHERE id_mahasiswa = $1 LIMIT 1`
+	const q = `SELECT 1 FROM mahasiswa WHERE id_mahasiswa = $1 FOR KEY SHARE`
 	var dummy int
-	err := r.pool.QueryRow(ctx, q, id).Scan(&dummy)
+	err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&dummy)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return false, nil
 	}
@@ -74,11 +105,25 @@ func (r *Repository) ExistsMahasiswaByID(ctx context.Context, id string) (bool,
 	return true, nil
 }
 
-// ExistsDosenByID validasi keberadaan id_dosen
+// ExistsDosenByID validasi keberadaan id_dosen (dikunci seperti ExistsMahasiswaByID)
 func (r *Repository) ExistsDosenByID(ctx context.Context, id string) (bool, error) {
-	const q = `SELECT 1 FROM dosen WHERE id_dosen = $1 LIMIT 1`
+	const q = `SELECT 1 FROM dosen WHERE id_dosen = $1 FOR KEY SHARE`
+	var dummy int
+	err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&dummy)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ExistsProdiByID validasi keberadaan id_prodi untuk ref_id operator (dikunci seperti ExistsMahasiswaByID)
+func (r *Repository) ExistsProdiByID(ctx context.Context, id string) (bool, error) {
+	const q = `SELECT 1 FROM prodi WHERE id_prodi = $1 FOR KEY SHARE`
 	var dummy int
-	err := r.pool.QueryRow(ctx, q, id).Scan(&dummy)
+	err := r.conn(ctx).QueryRow(ctx, q, id).Scan(&dummy)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return false, nil
 	}
@@ -90,11 +135,11 @@ func (r *Repository) ExistsDosenByID(ctx context.Context, id string) (bool, erro
 
 // Create menyimpan user baru, mengembalikan user yang dibuat (termasuk id dan timestamp)
 func (r *Repository) Create(ctx context.Context, u *model.User) (*model.User, error) {
-	const q = `
-		INSERT INTO users (username, password_hash, role, ref_id)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id_user, username, password_hash, role, ref_id, created_at, updated_at
-	`
+	q := `
+		INSERT INTO users (username, password_hash, role, ref_id, must_change_password,
+			activation_token_hash, activation_expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + userColumns
 	// Jangan kirim *string langsung; gunakan nilai atau NULL
 	var refParam interface{}
 	if u.RefID != nil && *u.RefID != "" {
@@ -103,15 +148,25 @@ func (r *Repository) Create(ctx context.Context, u *model.User) (*model.User, er
 		refParam = nil
 	}
 
-	row := r.pool.QueryRow(ctx, q, u.Username, u.PasswordHash, u.Role, refParam)
-	var out model.User
-	var ref sql.NullString
-	if err := row.Scan(&out.IDUser, &out.Username, &out.PasswordHash, &out.Role, &ref, &out.CreatedAt, &out.UpdatedAt); err != nil {
-		return nil, err
+	return scanUser(r.conn(ctx).QueryRow(ctx, q, u.Username, u.PasswordHash, u.Role, refParam,
+		u.MustChangePassword, u.ActivationTokenHash, u.ActivationExpiresAt))
+}
+
+// SetPassword mengganti password_hash dan sekaligus menghapus token aktivasi;
+// mustChange menentukan apakah user masih wajib mengganti password setelah ini
+func (r *Repository) SetPassword(ctx context.Context, id int64, hash string, mustChange bool) error {
+	const q = `
+		UPDATE users
+		SET password_hash = $2, must_change_password = $3,
+			activation_token_hash = NULL, activation_expires_at = NULL
+		WHERE id_user = $1
+	`
+	tag, err := r.conn(ctx).Exec(ctx, q, id, hash, mustChange)
+	if err != nil {
+		return err
 	}
-	if ref.Valid {
-		v := ref.String
-		out.RefID = &v
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
 	}
-	return &out, nil
+	return nil
 }
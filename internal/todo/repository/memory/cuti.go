@@ -0,0 +1,338 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+)
+
+// cutiRow adalah baris tabel cuti beserta isi lampiran
+type cutiRow struct {
+	model.Cuti
+	lampiran []byte
+}
+
+type CutiRepository struct {
+	s *Store
+}
+
+func NewCutiRepository(s *Store) *CutiRepository {
+	return &CutiRepository{s: s}
+}
+
+// cutiColumns memakai nama kolom beralias seperti repo.CutiFilterFields
+var cutiColumns = columns[model.Cuti]{
+	"c.id_cuti":      func(c *model.Cuti) any { return c.IDCuti },
+	"c.status":       func(c *model.Cuti) any { return c.Status },
+	"c.id_semester":  func(c *model.Cuti) any { return c.IDSemester },
+	"c.id_mahasiswa": func(c *model.Cuti) any { return c.IDMahasiswa },
+	"m.id_prodi":     func(c *model.Cuti) any { return c.IDProdi },
+	"c.created_at":   func(c *model.Cuti) any { return c.CreatedAt },
+	"c.updated_at":   func(c *model.Cuti) any { return c.UpdatedAt },
+}
+
+func cutiPK(c *model.Cuti) string { return fmt.Sprintf("%020d", c.IDCuti) }
+
+// row menyalin baris dan mengisi id_prodi dari mahasiswa (JOIN pada implementasi PostgreSQL)
+func (r *CutiRepository) row(c *cutiRow) model.Cuti {
+	out := c.Cuti
+	out.Catatan = cloneStr(c.Catatan)
+	out.ReviewedAt = cloneTime(c.ReviewedAt)
+	if c.IDReviewer != nil {
+		id := *c.IDReviewer
+		out.IDReviewer = &id
+	}
+	out.IDProdi = r.s.mahasiswa[c.IDMahasiswa].IDProdi
+	return out
+}
+
+func (r *CutiRepository) rows(match func(*cutiRow) bool) []model.Cuti {
+	out := []model.Cuti{}
+	for i := range r.s.cuti {
+		if match == nil || match(&r.s.cuti[i]) {
+			out = append(out, r.row(&r.s.cuti[i]))
+		}
+	}
+	return out
+}
+
+func (r *CutiRepository) find(id int64) *cutiRow {
+	for i := range r.s.cuti {
+		if r.s.cuti[i].IDCuti == id {
+			return &r.s.cuti[i]
+		}
+	}
+	return nil
+}
+
+func (r *CutiRepository) List(ctx context.Context, filters []repo.Filter, limit, offset int, orderBy string) ([]model.Cuti, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := filterRows(r.rows(nil), cutiColumns, filters, nil)
+	if err != nil {
+		return nil, err
+	}
+	if orderBy == "" {
+		orderBy = "c.created_at DESC"
+	}
+	keys, err := parseOrder(orderBy+", c.id_cuti DESC", cutiColumns)
+	if err != nil {
+		return nil, err
+	}
+	sortRows(rows, cutiColumns, keys, cutiPK)
+	return page(rows, limit, offset), nil
+}
+
+func (r *CutiRepository) Count(ctx context.Context, filters []repo.Filter) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := filterRows(r.rows(nil), cutiColumns, filters, nil)
+	return int64(len(rows)), err
+}
+
+func (r *CutiRepository) GetByID(ctx context.Context, id int64) (*model.Cuti, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	c := r.find(id)
+	if c == nil {
+		return nil, pgx.ErrNoRows
+	}
+	out := r.row(c)
+	return &out, nil
+}
+
+func (r *CutiRepository) LockByID(ctx context.Context, id int64) error {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	if r.find(id) == nil {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *CutiRepository) GetLampiran(ctx context.Context, id int64) (*model.CutiLampiran, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	c := r.find(id)
+	if c == nil {
+		return nil, pgx.ErrNoRows
+	}
+	return &model.CutiLampiran{Nama: c.LampiranNama, Tipe: c.LampiranTipe, Data: slices.Clone(c.lampiran)}, nil
+}
+
+// Create menerapkan FK mahasiswa/semester dan indeks unik uq_cuti_open
+func (r *CutiRepository) Create(ctx context.Context, c *model.Cuti, lampiran []byte) (*model.Cuti, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.mahasiswa[c.IDMahasiswa]; !ok {
+		return nil, fkMissing("cuti", "fk_cuti_mhs", "id_mahasiswa", c.IDMahasiswa, "mahasiswa")
+	}
+	if _, ok := r.s.semester[c.IDSemester]; !ok {
+		return nil, fkMissing("cuti", "fk_cuti_semester", "id_semester", c.IDSemester, "semester")
+	}
+	if r.s.openCuti(c.IDMahasiswa, c.IDSemester) != "" {
+		return nil, uniqueViolation("cuti", "uq_cuti_open", "id_mahasiswa, id_semester", c.IDMahasiswa+", "+c.IDSemester)
+	}
+	r.s.cutiSeq++
+	now := r.s.Now()
+	row := cutiRow{
+		Cuti: model.Cuti{
+			IDCuti:         r.s.cutiSeq,
+			IDMahasiswa:    c.IDMahasiswa,
+			IDSemester:     c.IDSemester,
+			Alasan:         c.Alasan,
+			LampiranNama:   c.LampiranNama,
+			LampiranTipe:   c.LampiranTipe,
+			LampiranUkuran: len(lampiran),
+			Status:         "Diajukan",
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		},
+		lampiran: slices.Clone(lampiran),
+	}
+	r.s.cuti = append(r.s.cuti, row)
+	out := r.row(&row)
+	return &out, nil
+}
+
+func (r *CutiRepository) SetStatus(ctx context.Context, id int64, status string, catatan *string, reviewer *int64) (*model.Cuti, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	c := r.find(id)
+	if c == nil {
+		return nil, pgx.ErrNoRows
+	}
+	now := r.s.Now()
+	c.Status = status
+	c.Catatan = cloneStr(catatan)
+	c.IDReviewer = nil
+	if reviewer != nil {
+		uid := *reviewer
+		c.IDReviewer = &uid
+		c.ReviewedAt = &now
+	}
+	c.UpdatedAt = now
+	out := r.row(c)
+	return &out, nil
+}
+
+func (r *CutiRepository) MarkApplied(ctx context.Context, id int64) error {
+	return r.mark(id, func(c *cutiRow) { c.Diterapkan = true })
+}
+
+func (r *CutiRepository) MarkFinished(ctx context.Context, id int64) error {
+	return r.mark(id, func(c *cutiRow) { c.Selesai = true })
+}
+
+func (r *CutiRepository) mark(id int64, set func(*cutiRow)) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	c := r.find(id)
+	if c == nil {
+		return pgx.ErrNoRows
+	}
+	set(c)
+	c.UpdatedAt = r.s.Now()
+	return nil
+}
+
+func (r *CutiRepository) CountActive(ctx context.Context, idMahasiswa string, excludeID int64) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	n := 0
+	for _, c := range r.s.cuti {
+		if c.IDMahasiswa == idMahasiswa && c.IDCuti != excludeID && cutiOpen(c.Status) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *CutiRepository) OpenStatus(ctx context.Context, idMahasiswa, idSemester string) (string, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.s.openCuti(idMahasiswa, idSemester), nil
+}
+
+func (r *CutiRepository) HasKRS(ctx context.Context, idMahasiswa, idSemester string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	if r.s.krsStatus(idMahasiswa, idSemester) != "" {
+		return true, nil
+	}
+	return slices.ContainsFunc(r.s.krs, func(k KRS) bool {
+		return k.IDMahasiswa == idMahasiswa && k.IDSemester == idSemester && k.Status == "Diambil"
+	}), nil
+}
+
+func (r *CutiRepository) ListToApply(ctx context.Context, idSemester string) ([]model.Cuti, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.rows(func(c *cutiRow) bool {
+		return c.Status == "Disetujui" && !c.Diterapkan && c.IDSemester == idSemester
+	}), nil
+}
+
+func (r *CutiRepository) ListToFinish(ctx context.Context, idSemester string) ([]model.Cuti, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.rows(func(c *cutiRow) bool {
+		return c.Status == "Disetujui" && c.Diterapkan && !c.Selesai && c.IDSemester != idSemester
+	}), nil
+}
+
+func (r *CutiRepository) GetSemester(ctx context.Context, id string) (*model.Semester, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	s, ok := r.s.semester[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &s, nil
+}
+
+func (r *CutiRepository) CurrentSemester(ctx context.Context, today time.Time) (*model.Semester, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var started []model.Semester
+	for _, s := range r.s.semester {
+		if s.Term != "Antara" && s.TanggalMulai != nil && !s.TanggalMulai.After(today) {
+			started = append(started, s)
+		}
+	}
+	if len(started) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	sort.Slice(started, func(i, j int) bool {
+		if !started[i].TanggalMulai.Equal(*started[j].TanggalMulai) {
+			return started[i].TanggalMulai.After(*started[j].TanggalMulai)
+		}
+		return started[i].IDSemester > started[j].IDSemester
+	})
+	return &started[0], nil
+}
+
+func (r *CutiRepository) GetPolicy(ctx context.Context, idProdi string) (*model.CutiPolicy, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	p, ok := r.s.cutiPolicy[idProdi]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &p, nil
+}
+
+func (r *CutiRepository) UpsertPolicy(ctx context.Context, idProdi string, maks int) (*model.CutiPolicy, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.prodi[idProdi]; !ok {
+		return nil, fkMissing("prodi_cuti_policy", "fk_cuti_policy_prodi", "id_prodi", idProdi, "prodi")
+	}
+	now := r.s.Now()
+	p := model.CutiPolicy{IDProdi: idProdi, MaksSemester: maks, UpdatedAt: &now}
+	r.s.cutiPolicy[idProdi] = p
+	return &p, nil
+}
+
+func cutiOpen(status string) bool {
+	return status == "Diajukan" || status == "Disetujui"
+}
+
+// openCuti mengembalikan status pengajuan Diajukan/Disetujui untuk (mahasiswa, semester); "" bila tidak ada
+func (s *Store) openCuti(idMahasiswa, idSemester string) string {
+	for _, c := range s.cuti {
+		if c.IDMahasiswa == idMahasiswa && c.IDSemester == idSemester && cutiOpen(c.Status) {
+			return c.Status
+		}
+	}
+	return ""
+}
+
+func (s *Store) semesterHasCuti(id string) bool {
+	for _, c := range s.cuti {
+		if c.IDSemester == id {
+			return true
+		}
+	}
+	return false
+}
+
+// krsBlockedByCuti meniru trigger block_cuti pada tabel krs
+func krsBlockedByCuti(k KRS) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23514",
+		Message:        fmt.Sprintf("mahasiswa %s sedang cuti pada semester %s", k.IDMahasiswa, k.IDSemester),
+		TableName:      "krs",
+		ColumnName:     "id_semester",
+		ConstraintName: "krs_cuti",
+	}
+}
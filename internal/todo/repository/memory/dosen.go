@@ -0,0 +1,275 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+)
+
+type DosenRepository struct {
+	s *Store
+}
+
+func NewDosenRepository(s *Store) *DosenRepository {
+	return &DosenRepository{s: s}
+}
+
+var dosenColumns = columns[model.Dosen]{
+	"id_dosen":         func(d *model.Dosen) any { return d.IDDosen },
+	"nidn":             func(d *model.Dosen) any { return d.NIDN },
+	"nama_dosen":       func(d *model.Dosen) any { return d.NamaDosen },
+	"email":            func(d *model.Dosen) any { return d.Email },
+	"no_hp":            func(d *model.Dosen) any { return d.NoHP },
+	"jabatan_akademik": func(d *model.Dosen) any { return d.JabatanAkademik },
+	"created_at":       func(d *model.Dosen) any { return d.CreatedAt },
+	"updated_at":       func(d *model.Dosen) any { return d.UpdatedAt },
+}
+
+func dosenPK(d *model.Dosen) string { return d.IDDosen }
+
+func (r *DosenRepository) find(q, match string, filters []repo.Filter) ([]model.Dosen, error) {
+	var search func(*model.Dosen) bool
+	if q != "" {
+		search = func(d *model.Dosen) bool { return nameSearch(match, q, d.NamaDosen, d.NIDN, d.Email) }
+	}
+	return filterRows(values(r.s.dosen), dosenColumns, filters, search)
+}
+
+// List mengikuti repository PostgreSQL yang selalu memakai LIMIT/OFFSET apa adanya
+func (r *DosenRepository) List(ctx context.Context, q, match string, filters []repo.Filter, limit, offset int, orderBy string) ([]model.Dosen, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := r.find(q, match, filters)
+	if err != nil {
+		return nil, err
+	}
+	if orderBy == "" {
+		orderBy = "nama_dosen ASC"
+	}
+	keys, err := parseOrder(orderBy, dosenColumns)
+	if err != nil {
+		return nil, err
+	}
+	sortRows(rows, dosenColumns, keys, dosenPK)
+	if limit == 0 {
+		return []model.Dosen{}, nil
+	}
+	return page(rows, limit, offset), nil
+}
+
+func (r *DosenRepository) Count(ctx context.Context, q, match string, filters []repo.Filter) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := r.find(q, match, filters)
+	return int64(len(rows)), err
+}
+
+func (r *DosenRepository) GetByID(ctx context.Context, id string) (*model.Dosen, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	d, ok := r.s.dosen[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &d, nil
+}
+
+func (r *DosenRepository) ExistsID(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	_, ok := r.s.dosen[id]
+	return ok, nil
+}
+
+func (r *DosenRepository) ExistsNIDN(ctx context.Context, nidn string, excludeID *string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, d := range r.s.dosen {
+		if d.NIDN != nil && *d.NIDN == nidn && (excludeID == nil || d.IDDosen != *excludeID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *DosenRepository) ExistsEmail(ctx context.Context, email string, excludeID *string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, d := range r.s.dosen {
+		if d.Email != nil && *d.Email == email && (excludeID == nil || d.IDDosen != *excludeID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// check menerapkan constraint tabel dosen: nidn unik (email tidak unik di skema)
+func (r *DosenRepository) check(d *model.Dosen) error {
+	if d.NIDN == nil {
+		return nil
+	}
+	for _, x := range r.s.dosen {
+		if x.NIDN != nil && *x.NIDN == *d.NIDN && x.IDDosen != d.IDDosen {
+			return uniqueViolation("dosen", "dosen_nidn_key", "nidn", *d.NIDN)
+		}
+	}
+	return nil
+}
+
+func (r *DosenRepository) Create(ctx context.Context, d *model.Dosen) (*model.Dosen, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.dosen[d.IDDosen]; ok {
+		return nil, uniqueViolation("dosen", "dosen_pkey", "id_dosen", d.IDDosen)
+	}
+	now := r.s.Now()
+	out := model.Dosen{
+		IDDosen:         d.IDDosen,
+		NIDN:            cloneStr(d.NIDN),
+		NamaDosen:       d.NamaDosen,
+		Email:           cloneStr(d.Email),
+		NoHP:            cloneStr(d.NoHP),
+		JabatanAkademik: cloneStr(d.JabatanAkademik),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := r.check(&out); err != nil {
+		return nil, err
+	}
+	r.s.dosen[out.IDDosen] = out
+	return &out, nil
+}
+
+func (r *DosenRepository) UpdatePut(ctx context.Context, id string, d *model.Dosen) (*model.Dosen, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	out, ok := r.s.dosen[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	out.NIDN = cloneStr(d.NIDN)
+	out.NamaDosen = d.NamaDosen
+	out.Email = cloneStr(d.Email)
+	out.NoHP = cloneStr(d.NoHP)
+	out.JabatanAkademik = cloneStr(d.JabatanAkademik)
+	return r.save(out)
+}
+
+func (r *DosenRepository) UpdatePatch(ctx context.Context, id string, nidn, nama, email, nohp, jabatan *string) (*model.Dosen, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	out, ok := r.s.dosen[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	if nidn == nil && nama == nil && email == nil && nohp == nil && jabatan == nil {
+		return &out, nil
+	}
+	if nidn != nil {
+		out.NIDN = cloneStr(nidn)
+	}
+	if nama != nil {
+		out.NamaDosen = *nama
+	}
+	if email != nil {
+		out.Email = cloneStr(email)
+	}
+	if nohp != nil {
+		out.NoHP = cloneStr(nohp)
+	}
+	if jabatan != nil {
+		out.JabatanAkademik = cloneStr(jabatan)
+	}
+	return r.save(out)
+}
+
+func (r *DosenRepository) save(d model.Dosen) (*model.Dosen, error) {
+	if err := r.check(&d); err != nil {
+		return nil, err
+	}
+	d.UpdatedAt = r.s.Now()
+	r.s.dosen[d.IDDosen] = d
+	return &d, nil
+}
+
+func (r *DosenRepository) HasMataKuliahPenanggungJawab(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, mk := range r.s.mataKuliah {
+		if mk.IDDosenPJ != nil && *mk.IDDosenPJ == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *DosenRepository) HasKelasKuliahPengampu(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.s.dosenHasKelas(id), nil
+}
+
+func (r *DosenRepository) HasPembimbingRelated(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.s.dosenHasBimbingan(id), nil
+}
+
+// Delete mengikuti FK: kelas_kuliah dan pembimbing_akademik RESTRICT,
+// mata_kuliah.id_dosen_pj dan krs_pengajuan.id_dosen ON DELETE SET NULL
+func (r *DosenRepository) Delete(ctx context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.dosen[id]; !ok {
+		return pgx.ErrNoRows
+	}
+	if r.s.dosenHasKelas(id) {
+		return stillReferenced("dosen", "fk_kelas_dosen", "id_dosen", id, "kelas_kuliah")
+	}
+	if r.s.dosenHasBimbingan(id) {
+		return stillReferenced("dosen", "fk_pembimbing_dosen", "id_dosen", id, "pembimbing_akademik")
+	}
+	for k, mk := range r.s.mataKuliah {
+		if mk.IDDosenPJ != nil && *mk.IDDosenPJ == id {
+			mk.IDDosenPJ = nil
+			r.s.mataKuliah[k] = mk
+		}
+	}
+	for i, p := range r.s.krsPengajuan {
+		if p.IDDosen != nil && *p.IDDosen == id {
+			r.s.krsPengajuan[i].IDDosen = nil
+		}
+	}
+	delete(r.s.dosen, id)
+	return nil
+}
+
+func (s *Store) dosenHasBimbingan(id string) bool {
+	for _, p := range s.pembimbing {
+		if p.IDDosen == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) dosenHasKelas(id string) bool {
+	for _, k := range s.kelas {
+		if k.IDDosenPengampu == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *DosenRepository) LockByID(ctx context.Context, id string) error {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	if _, ok := r.s.dosen[id]; !ok {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+)
+
+type FakultasRepository struct {
+	s *Store
+}
+
+func NewFakultasRepository(s *Store) *FakultasRepository {
+	return &FakultasRepository{s: s}
+}
+
+var fakultasColumns = columns[model.Fakultas]{
+	"id_fakultas":   func(f *model.Fakultas) any { return f.IDFakultas },
+	"nama_fakultas": func(f *model.Fakultas) any { return f.NamaFakultas },
+	"singkatan":     func(f *model.Fakultas) any { return f.Singkatan },
+	"created_at":    func(f *model.Fakultas) any { return f.CreatedAt },
+	"updated_at":    func(f *model.Fakultas) any { return f.UpdatedAt },
+}
+
+func fakultasPK(f *model.Fakultas) string { return f.IDFakultas }
+
+func (r *FakultasRepository) find(search string, filters []repo.Filter) ([]model.Fakultas, error) {
+	var match func(*model.Fakultas) bool
+	if search != "" {
+		match = func(f *model.Fakultas) bool { return containsFold(f.NamaFakultas, search) }
+	}
+	return filterRows(values(r.s.fakultas), fakultasColumns, filters, match)
+}
+
+func (r *FakultasRepository) List(ctx context.Context, search string, filters []repo.Filter, limit, offset int) ([]model.Fakultas, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := r.find(search, filters)
+	if err != nil {
+		return nil, err
+	}
+	sortRows(rows, fakultasColumns, []orderKey{{col: "nama_fakultas"}}, fakultasPK)
+	return page(rows, limit, offset), nil
+}
+
+func (r *FakultasRepository) Count(ctx context.Context, search string, filters []repo.Filter) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := r.find(search, filters)
+	return int64(len(rows)), err
+}
+
+func (r *FakultasRepository) GetByID(ctx context.Context, id string) (*model.Fakultas, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	f, ok := r.s.fakultas[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &f, nil
+}
+
+func (r *FakultasRepository) ExistsID(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	_, ok := r.s.fakultas[id]
+	return ok, nil
+}
+
+func (r *FakultasRepository) ExistsNamaCI(ctx context.Context, nama string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, f := range r.s.fakultas {
+		if strings.EqualFold(f.NamaFakultas, nama) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *FakultasRepository) Create(ctx context.Context, f *model.Fakultas) (*model.Fakultas, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.fakultas[f.IDFakultas]; ok {
+		return nil, uniqueViolation("fakultas", "fakultas_pkey", "id_fakultas", f.IDFakultas)
+	}
+	now := r.s.Now()
+	out := model.Fakultas{
+		IDFakultas:   f.IDFakultas,
+		NamaFakultas: f.NamaFakultas,
+		Singkatan:    cloneStr(f.Singkatan),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	r.s.fakultas[out.IDFakultas] = out
+	return &out, nil
+}
+
+func (r *FakultasRepository) Update(ctx context.Context, id string, nama *string, singkatan *string) (*model.Fakultas, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	f, ok := r.s.fakultas[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	if nama == nil && singkatan == nil {
+		return &f, nil
+	}
+	if nama != nil {
+		f.NamaFakultas = *nama
+	}
+	if singkatan != nil {
+		f.Singkatan = cloneStr(singkatan)
+	}
+	f.UpdatedAt = r.s.Now()
+	r.s.fakultas[id] = f
+	return &f, nil
+}
+
+func (r *FakultasRepository) HasProdiRelated(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, p := range r.s.prodi {
+		if p.IDFakultas == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *FakultasRepository) Delete(ctx context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.fakultas[id]; !ok {
+		return pgx.ErrNoRows
+	}
+	for _, p := range r.s.prodi {
+		if p.IDFakultas == id {
+			return stillReferenced("fakultas", "fk_prodi_fakultas", "id_fakultas", id, "prodi")
+		}
+	}
+	delete(r.s.fakultas, id)
+	return nil
+}
+
+// LockByID hanya memeriksa keberadaan baris; penguncian digantikan Store.Do yang serial
+func (r *FakultasRepository) LockByID(ctx context.Context, id string) error {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	if _, ok := r.s.fakultas[id]; !ok {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+)
+
+type KRSRepository struct {
+	s *Store
+}
+
+func NewKRSRepository(s *Store) *KRSRepository {
+	return &KRSRepository{s: s}
+}
+
+// krsPengajuanColumns memakai nama kolom beralias seperti repo.KRSPengajuanFilterFields
+var krsPengajuanColumns = columns[model.KRSPengajuan]{
+	"p.id_pengajuan": func(p *model.KRSPengajuan) any { return p.IDPengajuan },
+	"p.status":       func(p *model.KRSPengajuan) any { return p.Status },
+	"p.id_semester":  func(p *model.KRSPengajuan) any { return p.IDSemester },
+	"p.id_mahasiswa": func(p *model.KRSPengajuan) any { return p.IDMahasiswa },
+	"p.diajukan_at":  func(p *model.KRSPengajuan) any { return p.DiajukanAt },
+	"p.updated_at":   func(p *model.KRSPengajuan) any { return p.UpdatedAt },
+}
+
+// pengajuan menyalin baris dan melengkapi nama mahasiswa serta total SKS (JOIN/subquery di PostgreSQL)
+func (r *KRSRepository) pengajuan(p model.KRSPengajuan) model.KRSPengajuan {
+	p.IDDosen = cloneStr(p.IDDosen)
+	p.Catatan = cloneStr(p.Catatan)
+	p.ReviewedAt = cloneTime(p.ReviewedAt)
+	p.NamaLengkap = r.s.mahasiswa[p.IDMahasiswa].NamaLengkap
+	p.TotalSKS = 0
+	for _, k := range r.kelas(p.IDMahasiswa, p.IDSemester) {
+		p.TotalSKS += k.SKS
+	}
+	return p
+}
+
+func (r *KRSRepository) kelas(idMahasiswa, idSemester string) []model.KRSKelas {
+	out := []model.KRSKelas{}
+	for _, k := range r.s.krs {
+		if k.IDMahasiswa != idMahasiswa || k.IDSemester != idSemester || k.Status != "Diambil" {
+			continue
+		}
+		kk := r.s.kelas[k.IDKelas]
+		mk := r.s.mataKuliah[kk.IDMK]
+		out = append(out, model.KRSKelas{
+			IDKRS: k.IDKRS, IDKelas: k.IDKelas, NamaKelas: kk.NamaKelas,
+			IDMK: mk.IDMK, KodeMK: mk.KodeMK, NamaMK: mk.NamaMK, SKS: mk.SKS,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].KodeMK != out[j].KodeMK {
+			return out[i].KodeMK < out[j].KodeMK
+		}
+		return out[i].NamaKelas < out[j].NamaKelas
+	})
+	return out
+}
+
+func (r *KRSRepository) ListKelas(ctx context.Context, idMahasiswa, idSemester string) ([]model.KRSKelas, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.kelas(idMahasiswa, idSemester), nil
+}
+
+func (r *KRSRepository) KelasOutside(ctx context.Context, idSemester string, ids []string) ([]string, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	out := []string{}
+	for _, id := range ids {
+		if k, ok := r.s.kelas[id]; !ok || k.IDSemester != idSemester {
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// ReplaceKelas menerapkan trigger block_cuti dan lock_final seperti AddKRS
+func (r *KRSRepository) ReplaceKelas(ctx context.Context, idMahasiswa, idSemester string, ids []string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	k := KRS{IDMahasiswa: idMahasiswa, IDSemester: idSemester}
+	if r.s.krsLocked(idMahasiswa, idSemester) {
+		return krsFinal(k)
+	}
+	if _, ok := r.s.semester[idSemester]; !ok {
+		return fkMissing("krs", "fk_krs_sem", "id_semester", idSemester, "semester")
+	}
+	kept := r.s.krs[:0:0]
+	for _, x := range r.s.krs {
+		if x.IDMahasiswa == idMahasiswa && x.IDSemester == idSemester && !slices.Contains(ids, x.IDKelas) {
+			delete(r.s.nilai, x.IDKRS)
+			continue
+		}
+		kept = append(kept, x)
+	}
+	r.s.krs = kept
+	for _, id := range ids {
+		if _, ok := r.s.kelas[id]; !ok {
+			return fkMissing("krs", "fk_krs_kelas", "id_kelas", id, "kelas_kuliah")
+		}
+		i := slices.IndexFunc(r.s.krs, func(x KRS) bool { return x.IDMahasiswa == idMahasiswa && x.IDKelas == id })
+		if i >= 0 {
+			r.s.krs[i].Status = "Diambil"
+			continue
+		}
+		if r.s.openCuti(idMahasiswa, idSemester) == "Disetujui" {
+			return krsBlockedByCuti(k)
+		}
+		r.s.krsSeq++
+		r.s.krs = append(r.s.krs, KRS{IDKRS: r.s.krsSeq, IDMahasiswa: idMahasiswa, IDKelas: id, IDSemester: idSemester, Status: "Diambil"})
+	}
+	return nil
+}
+
+func (r *KRSRepository) find(match func(*model.KRSPengajuan) bool) *model.KRSPengajuan {
+	for i := range r.s.krsPengajuan {
+		if match(&r.s.krsPengajuan[i]) {
+			return &r.s.krsPengajuan[i]
+		}
+	}
+	return nil
+}
+
+func (r *KRSRepository) GetPengajuan(ctx context.Context, idMahasiswa, idSemester string) (*model.KRSPengajuan, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	p := r.find(func(p *model.KRSPengajuan) bool { return p.IDMahasiswa == idMahasiswa && p.IDSemester == idSemester })
+	if p == nil {
+		return nil, pgx.ErrNoRows
+	}
+	out := r.pengajuan(*p)
+	return &out, nil
+}
+
+func (r *KRSRepository) GetPengajuanByID(ctx context.Context, id int64) (*model.KRSPengajuan, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	p := r.find(func(p *model.KRSPengajuan) bool { return p.IDPengajuan == id })
+	if p == nil {
+		return nil, pgx.ErrNoRows
+	}
+	out := r.pengajuan(*p)
+	return &out, nil
+}
+
+func (r *KRSRepository) LockPengajuan(ctx context.Context, id int64) error {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	if r.find(func(p *model.KRSPengajuan) bool { return p.IDPengajuan == id }) == nil {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Submit menerapkan FK dan upsert pada uq_krs_pengajuan
+func (r *KRSRepository) Submit(ctx context.Context, idMahasiswa, idSemester, idDosen string) (*model.KRSPengajuan, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.mahasiswa[idMahasiswa]; !ok {
+		return nil, fkMissing("krs_pengajuan", "fk_krs_pengajuan_mhs", "id_mahasiswa", idMahasiswa, "mahasiswa")
+	}
+	if _, ok := r.s.semester[idSemester]; !ok {
+		return nil, fkMissing("krs_pengajuan", "fk_krs_pengajuan_sem", "id_semester", idSemester, "semester")
+	}
+	if _, ok := r.s.dosen[idDosen]; !ok {
+		return nil, fkMissing("krs_pengajuan", "fk_krs_pengajuan_dosen", "id_dosen", idDosen, "dosen")
+	}
+	now := r.s.Now()
+	d := idDosen
+	p := r.find(func(p *model.KRSPengajuan) bool { return p.IDMahasiswa == idMahasiswa && p.IDSemester == idSemester })
+	if p == nil {
+		r.s.krsPengajuanSeq++
+		r.s.krsPengajuan = append(r.s.krsPengajuan, model.KRSPengajuan{
+			IDPengajuan: r.s.krsPengajuanSeq, IDMahasiswa: idMahasiswa, IDSemester: idSemester, CreatedAt: now,
+		})
+		p = &r.s.krsPengajuan[len(r.s.krsPengajuan)-1]
+	}
+	p.Status, p.IDDosen, p.Catatan, p.DiajukanAt, p.ReviewedAt, p.UpdatedAt = "Diajukan", &d, nil, now, nil, now
+	out := r.pengajuan(*p)
+	return &out, nil
+}
+
+func (r *KRSRepository) Review(ctx context.Context, id int64, status string, catatan *string) (*model.KRSPengajuan, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	p := r.find(func(p *model.KRSPengajuan) bool { return p.IDPengajuan == id })
+	if p == nil {
+		return nil, pgx.ErrNoRows
+	}
+	now := r.s.Now()
+	p.Status, p.Catatan, p.ReviewedAt, p.UpdatedAt = status, cloneStr(catatan), &now, now
+	out := r.pengajuan(*p)
+	return &out, nil
+}
+
+func (r *KRSRepository) byDosen(idDosen string) []model.KRSPengajuan {
+	out := []model.KRSPengajuan{}
+	for _, p := range r.s.krsPengajuan {
+		if p.IDDosen != nil && *p.IDDosen == idDosen {
+			out = append(out, r.pengajuan(p))
+		}
+	}
+	return out
+}
+
+func (r *KRSRepository) ListPengajuan(ctx context.Context, idDosen string, filters []repo.Filter, limit, offset int, orderBy string) ([]model.KRSPengajuan, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := filterRows(r.byDosen(idDosen), krsPengajuanColumns, filters, nil)
+	if err != nil {
+		return nil, err
+	}
+	if orderBy == "" {
+		orderBy = "p.diajukan_at DESC"
+	}
+	keys, err := parseOrder(orderBy+", p.id_pengajuan DESC", krsPengajuanColumns)
+	if err != nil {
+		return nil, err
+	}
+	sortRows(rows, krsPengajuanColumns, keys, func(p *model.KRSPengajuan) string { return fmt.Sprintf("%020d", p.IDPengajuan) })
+	return page(rows, limit, offset), nil
+}
+
+func (r *KRSRepository) CountPengajuan(ctx context.Context, idDosen string, filters []repo.Filter) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := filterRows(r.byDosen(idDosen), krsPengajuanColumns, filters, nil)
+	return int64(len(rows)), err
+}
+
+func (r *KRSRepository) ExistsSemester(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	_, ok := r.s.semester[id]
+	return ok, nil
+}
+
+// krsStatus mengembalikan status pengajuan KRS (mahasiswa, semester); "" bila belum diajukan
+func (s *Store) krsStatus(idMahasiswa, idSemester string) string {
+	for _, p := range s.krsPengajuan {
+		if p.IDMahasiswa == idMahasiswa && p.IDSemester == idSemester {
+			return p.Status
+		}
+	}
+	return ""
+}
+
+// krsLocked meniru kondisi trigger lock_final: KRS yang diajukan atau disetujui tidak boleh diubah
+func (s *Store) krsLocked(idMahasiswa, idSemester string) bool {
+	st := s.krsStatus(idMahasiswa, idSemester)
+	return st == "Diajukan" || st == "Disetujui"
+}
+
+func krsFinal(k KRS) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23514",
+		Message:        fmt.Sprintf("KRS mahasiswa %s semester %s sudah diajukan", k.IDMahasiswa, k.IDSemester),
+		TableName:      "krs",
+		ColumnName:     "id_kelas",
+		ConstraintName: "krs_final",
+	}
+}
+
+func (r *KRSRepository) BestBobot(ctx context.Context, idMahasiswa string, ids []string) (map[string]float64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	out := map[string]float64{}
+	for _, k := range r.s.krs {
+		if k.IDMahasiswa != idMahasiswa || k.Status != "Diambil" || r.s.krsStatus(k.IDMahasiswa, k.IDSemester) != "Disetujui" {
+			continue
+		}
+		bobot, ok := r.s.nilai[k.IDKRS]
+		mk := r.s.kelas[k.IDKelas].IDMK
+		if !ok || !slices.Contains(ids, mk) {
+			continue
+		}
+		if b, seen := out[mk]; !seen || bobot > b {
+			out[mk] = bobot
+		}
+	}
+	return out, nil
+}
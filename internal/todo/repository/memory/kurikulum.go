@@ -0,0 +1,361 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+)
+
+// kurikulumMKRow adalah baris tabel kurikulum_mk
+type kurikulumMKRow struct {
+	IDKurikulum int64
+	IDMK        string
+	Semester    int
+	Jenis       string
+}
+
+// prasyaratRow adalah baris tabel prasyarat_mk
+type prasyaratRow struct {
+	IDMK          string
+	IDMKPrasyarat string
+	NilaiMinimal  string
+}
+
+type KurikulumRepository struct {
+	s *Store
+}
+
+func NewKurikulumRepository(s *Store) *KurikulumRepository {
+	return &KurikulumRepository{s: s}
+}
+
+// kurikulumColumns memakai nama kolom beralias seperti repo.KurikulumFilterFields
+var kurikulumColumns = columns[model.Kurikulum]{
+	"k.id_kurikulum": func(k *model.Kurikulum) any { return k.IDKurikulum },
+	"k.id_prodi":     func(k *model.Kurikulum) any { return k.IDProdi },
+	"k.tahun":        func(k *model.Kurikulum) any { return k.Tahun },
+	"k.nama":         func(k *model.Kurikulum) any { return k.Nama },
+	"k.aktif::text":  func(k *model.Kurikulum) any { return strconv.FormatBool(k.Aktif) },
+	"k.created_at":   func(k *model.Kurikulum) any { return k.CreatedAt },
+}
+
+// kurikulum melengkapi jumlah mata kuliah dan total SKS (subquery di PostgreSQL)
+func (r *KurikulumRepository) kurikulum(k model.Kurikulum) model.Kurikulum {
+	k.JumlahMK, k.TotalSKS = 0, 0
+	for _, x := range r.s.kurikulumMK {
+		if x.IDKurikulum == k.IDKurikulum {
+			k.JumlahMK++
+			k.TotalSKS += r.s.mataKuliah[x.IDMK].SKS
+		}
+	}
+	return k
+}
+
+func (r *KurikulumRepository) index(id int64) int {
+	return slices.IndexFunc(r.s.kurikulum, func(k model.Kurikulum) bool { return k.IDKurikulum == id })
+}
+
+func (r *KurikulumRepository) all() []model.Kurikulum {
+	out := make([]model.Kurikulum, len(r.s.kurikulum))
+	for i, k := range r.s.kurikulum {
+		out[i] = r.kurikulum(k)
+	}
+	return out
+}
+
+func (r *KurikulumRepository) List(ctx context.Context, filters []repo.Filter, limit, offset int, orderBy string) ([]model.Kurikulum, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := filterRows(r.all(), kurikulumColumns, filters, nil)
+	if err != nil {
+		return nil, err
+	}
+	if orderBy == "" {
+		orderBy = "k.tahun DESC"
+	}
+	keys, err := parseOrder(orderBy+", k.id_kurikulum ASC", kurikulumColumns)
+	if err != nil {
+		return nil, err
+	}
+	sortRows(rows, kurikulumColumns, keys, func(k *model.Kurikulum) string { return fmt.Sprintf("%020d", k.IDKurikulum) })
+	return page(rows, limit, offset), nil
+}
+
+func (r *KurikulumRepository) Count(ctx context.Context, filters []repo.Filter) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := filterRows(r.all(), kurikulumColumns, filters, nil)
+	return int64(len(rows)), err
+}
+
+func (r *KurikulumRepository) GetByID(ctx context.Context, id int64) (*model.Kurikulum, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	i := r.index(id)
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+	out := r.kurikulum(r.s.kurikulum[i])
+	return &out, nil
+}
+
+func (r *KurikulumRepository) LockByID(ctx context.Context, id int64) error {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	if r.index(id) < 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *KurikulumRepository) ExistsProdi(ctx context.Context, idProdi string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	_, ok := r.s.prodi[idProdi]
+	return ok, nil
+}
+
+func (r *KurikulumRepository) ExistsTahun(ctx context.Context, idProdi string, tahun int, excludeID *int64) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.s.kurikulumTahun(idProdi, tahun, excludeID), nil
+}
+
+func (s *Store) kurikulumTahun(idProdi string, tahun int, excludeID *int64) bool {
+	for _, k := range s.kurikulum {
+		if k.IDProdi == idProdi && k.Tahun == tahun && (excludeID == nil || k.IDKurikulum != *excludeID) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkKurikulum menerapkan uq_kurikulum_tahun dan uq_kurikulum_aktif untuk baris k
+func (s *Store) checkKurikulum(k model.Kurikulum) error {
+	if s.kurikulumTahun(k.IDProdi, k.Tahun, &k.IDKurikulum) {
+		return uniqueViolation("kurikulum", "uq_kurikulum_tahun", "id_prodi, tahun", fmt.Sprintf("%s, %d", k.IDProdi, k.Tahun))
+	}
+	if k.Aktif && slices.ContainsFunc(s.kurikulum, func(x model.Kurikulum) bool {
+		return x.IDProdi == k.IDProdi && x.Aktif && x.IDKurikulum != k.IDKurikulum
+	}) {
+		return uniqueViolation("kurikulum", "uq_kurikulum_aktif", "id_prodi", k.IDProdi)
+	}
+	return nil
+}
+
+func (r *KurikulumRepository) Create(ctx context.Context, k *model.Kurikulum) (*model.Kurikulum, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.prodi[k.IDProdi]; !ok {
+		return nil, fkMissing("kurikulum", "fk_kurikulum_prodi", "id_prodi", k.IDProdi, "prodi")
+	}
+	row := model.Kurikulum{IDProdi: k.IDProdi, Tahun: k.Tahun, Nama: k.Nama, Aktif: k.Aktif}
+	if err := r.s.checkKurikulum(row); err != nil {
+		return nil, err
+	}
+	r.s.kurikulumSeq++
+	now := r.s.Now()
+	row.IDKurikulum, row.CreatedAt, row.UpdatedAt = r.s.kurikulumSeq, now, now
+	r.s.kurikulum = append(r.s.kurikulum, row)
+	out := r.kurikulum(row)
+	return &out, nil
+}
+
+func (r *KurikulumRepository) Update(ctx context.Context, id int64, k *model.Kurikulum) (*model.Kurikulum, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	i := r.index(id)
+	if i < 0 {
+		return nil, pgx.ErrNoRows
+	}
+	row := r.s.kurikulum[i]
+	row.Tahun, row.Nama, row.Aktif, row.UpdatedAt = k.Tahun, k.Nama, k.Aktif, r.s.Now()
+	if err := r.s.checkKurikulum(row); err != nil {
+		return nil, err
+	}
+	r.s.kurikulum[i] = row
+	out := r.kurikulum(row)
+	return &out, nil
+}
+
+func (r *KurikulumRepository) Deactivate(ctx context.Context, idProdi string, exceptID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	now := r.s.Now()
+	for i, k := range r.s.kurikulum {
+		if k.IDProdi == idProdi && k.Aktif && k.IDKurikulum != exceptID {
+			r.s.kurikulum[i].Aktif, r.s.kurikulum[i].UpdatedAt = false, now
+		}
+	}
+	return nil
+}
+
+func (r *KurikulumRepository) Delete(ctx context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	i := r.index(id)
+	if i < 0 {
+		return pgx.ErrNoRows
+	}
+	r.s.kurikulum = slices.Delete(r.s.kurikulum, i, i+1)
+	r.s.kurikulumMK = slices.DeleteFunc(r.s.kurikulumMK, func(x kurikulumMKRow) bool { return x.IDKurikulum == id })
+	return nil
+}
+
+func (r *KurikulumRepository) ListMataKuliah(ctx context.Context, id int64) ([]model.KurikulumMK, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	out := []model.KurikulumMK{}
+	for _, x := range r.s.kurikulumMK {
+		if x.IDKurikulum != id {
+			continue
+		}
+		mk := r.s.mataKuliah[x.IDMK]
+		out = append(out, model.KurikulumMK{IDMK: mk.IDMK, KodeMK: mk.KodeMK, NamaMK: mk.NamaMK, SKS: mk.SKS, Semester: x.Semester, Jenis: x.Jenis})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Semester != out[j].Semester {
+			return out[i].Semester < out[j].Semester
+		}
+		return out[i].KodeMK < out[j].KodeMK
+	})
+	return out, nil
+}
+
+// ReplaceMataKuliah menerapkan FK dan primary key (id_kurikulum, id_mk)
+func (r *KurikulumRepository) ReplaceMataKuliah(ctx context.Context, id int64, items []model.KurikulumMK) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.index(id) < 0 {
+		return fkMissing("kurikulum_mk", "fk_kurikulum_mk_kurikulum", "id_kurikulum", strconv.FormatInt(id, 10), "kurikulum")
+	}
+	rows := slices.DeleteFunc(slices.Clone(r.s.kurikulumMK), func(x kurikulumMKRow) bool { return x.IDKurikulum == id })
+	seen := map[string]bool{}
+	for _, m := range items {
+		if _, ok := r.s.mataKuliah[m.IDMK]; !ok {
+			return fkMissing("kurikulum_mk", "fk_kurikulum_mk_mk", "id_mk", m.IDMK, "mata_kuliah")
+		}
+		if seen[m.IDMK] {
+			return uniqueViolation("kurikulum_mk", "kurikulum_mk_pkey", "id_kurikulum, id_mk", fmt.Sprintf("%d, %s", id, m.IDMK))
+		}
+		seen[m.IDMK] = true
+		rows = append(rows, kurikulumMKRow{IDKurikulum: id, IDMK: m.IDMK, Semester: m.Semester, Jenis: m.Jenis})
+	}
+	r.s.kurikulumMK = rows
+	return nil
+}
+
+func (r *KurikulumRepository) MataKuliahMissing(ctx context.Context, ids []string) ([]string, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	out := []string{}
+	for _, id := range ids {
+		if _, ok := r.s.mataKuliah[id]; !ok {
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (r *KurikulumRepository) MataKuliahOutsideProdi(ctx context.Context, idProdi string, ids []string) ([]string, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	out := []string{}
+	for _, id := range ids {
+		if mk, ok := r.s.mataKuliah[id]; ok && mk.IDProdi != idProdi {
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (r *KurikulumRepository) MataKuliahProdi(ctx context.Context, idMK string) (string, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	mk, ok := r.s.mataKuliah[idMK]
+	if !ok {
+		return "", pgx.ErrNoRows
+	}
+	return mk.IDProdi, nil
+}
+
+func (r *KurikulumRepository) ListPrasyarat(ctx context.Context, ids []string) ([]model.Prasyarat, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	out := []model.Prasyarat{}
+	for _, p := range r.s.prasyarat {
+		if !slices.Contains(ids, p.IDMK) {
+			continue
+		}
+		mk := r.s.mataKuliah[p.IDMKPrasyarat]
+		out = append(out, model.Prasyarat{
+			IDMK: p.IDMK, IDMKPrasyarat: p.IDMKPrasyarat, KodeMKPrasyarat: mk.KodeMK, NamaMKPrasyarat: mk.NamaMK,
+			NilaiMinimal: p.NilaiMinimal,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].IDMK != out[j].IDMK {
+			return out[i].IDMK < out[j].IDMK
+		}
+		return out[i].KodeMKPrasyarat < out[j].KodeMKPrasyarat
+	})
+	return out, nil
+}
+
+// ReplacePrasyarat menerapkan FK, primary key dan ck_prasyarat_diri
+func (r *KurikulumRepository) ReplacePrasyarat(ctx context.Context, idMK string, items []model.Prasyarat) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.mataKuliah[idMK]; !ok {
+		return fkMissing("prasyarat_mk", "fk_prasyarat_mk", "id_mk", idMK, "mata_kuliah")
+	}
+	rows := slices.DeleteFunc(slices.Clone(r.s.prasyarat), func(p prasyaratRow) bool { return p.IDMK == idMK })
+	seen := map[string]bool{}
+	for _, p := range items {
+		if _, ok := r.s.mataKuliah[p.IDMKPrasyarat]; !ok {
+			return fkMissing("prasyarat_mk", "fk_prasyarat_mk_prasyarat", "id_mk_prasyarat", p.IDMKPrasyarat, "mata_kuliah")
+		}
+		if p.IDMKPrasyarat == idMK {
+			return checkViolation("prasyarat_mk", "ck_prasyarat_diri")
+		}
+		if seen[p.IDMKPrasyarat] {
+			return uniqueViolation("prasyarat_mk", "prasyarat_mk_pkey", "id_mk, id_mk_prasyarat", idMK+", "+p.IDMKPrasyarat)
+		}
+		seen[p.IDMKPrasyarat] = true
+		rows = append(rows, prasyaratRow{IDMK: idMK, IDMKPrasyarat: p.IDMKPrasyarat, NilaiMinimal: p.NilaiMinimal})
+	}
+	r.s.prasyarat = rows
+	return nil
+}
+
+func (r *KurikulumRepository) RequiredBy(ctx context.Context, idMK string) ([]string, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	seen := map[string]bool{}
+	queue := []string{idMK}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, p := range r.s.prasyarat {
+			if p.IDMKPrasyarat == cur && !seen[p.IDMK] {
+				seen[p.IDMK] = true
+				queue = append(queue, p.IDMK)
+			}
+		}
+	}
+	out := make([]string, 0, len(seen))
+	for id := range seen {
+		out = append(out, id)
+	}
+	sort.Strings(out)
+	return out, nil
+}
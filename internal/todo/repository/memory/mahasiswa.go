@@ -0,0 +1,389 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+)
+
+type MahasiswaRepository struct {
+	s *Store
+}
+
+func NewMahasiswaRepository(s *Store) *MahasiswaRepository {
+	return &MahasiswaRepository{s: s}
+}
+
+// columns memakai closure karena id_fakultas diambil dari tabel prodi (lihat repo.MahasiswaFilterFields)
+func (r *MahasiswaRepository) columns() columns[model.Mahasiswa] {
+	return columns[model.Mahasiswa]{
+		"id_mahasiswa":  func(m *model.Mahasiswa) any { return m.IDMahasiswa },
+		"id_prodi":      func(m *model.Mahasiswa) any { return m.IDProdi },
+		"nik":           func(m *model.Mahasiswa) any { return m.NIK },
+		"nama_lengkap":  func(m *model.Mahasiswa) any { return m.NamaLengkap },
+		"jenis_kelamin": func(m *model.Mahasiswa) any { return m.JenisKelamin },
+		"tempat_lahir":  func(m *model.Mahasiswa) any { return m.TempatLahir },
+		"tanggal_lahir": func(m *model.Mahasiswa) any { return m.TanggalLahir },
+		"alamat":        func(m *model.Mahasiswa) any { return m.Alamat },
+		"email":         func(m *model.Mahasiswa) any { return m.Email },
+		"no_hp":         func(m *model.Mahasiswa) any { return m.NoHP },
+		"tahun_masuk":   func(m *model.Mahasiswa) any { return m.TahunMasuk },
+		"status":        func(m *model.Mahasiswa) any { return m.Status },
+		"angkatan":      func(m *model.Mahasiswa) any { return m.Angkatan },
+		"created_at":    func(m *model.Mahasiswa) any { return m.CreatedAt },
+		"updated_at":    func(m *model.Mahasiswa) any { return m.UpdatedAt },
+		repo.MahasiswaFilterFields["id_fakultas"].Column: func(m *model.Mahasiswa) any {
+			if p, ok := r.s.prodi[m.IDProdi]; ok {
+				return p.IDFakultas
+			}
+			return nil
+		},
+	}
+}
+
+func mahasiswaPK(m *model.Mahasiswa) string { return m.IDMahasiswa }
+
+func (r *MahasiswaRepository) find(q, match string, filters []repo.Filter) ([]model.Mahasiswa, columns[model.Mahasiswa], error) {
+	cols := r.columns()
+	var search func(*model.Mahasiswa) bool
+	if q != "" {
+		search = func(m *model.Mahasiswa) bool {
+			id := m.IDMahasiswa
+			return nameSearch(match, q, m.NamaLengkap, m.Email, &id)
+		}
+	}
+	rows, err := filterRows(values(r.s.mahasiswa), cols, filters, search)
+	return rows, cols, err
+}
+
+func (r *MahasiswaRepository) List(ctx context.Context, q, match string, filters []repo.Filter, limit, offset int, orderBy string) ([]model.Mahasiswa, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, cols, err := r.find(q, match, filters)
+	if err != nil {
+		return nil, err
+	}
+	if orderBy == "" {
+		orderBy = "nama_lengkap ASC"
+	}
+	keys, err := parseOrder(orderBy, cols)
+	if err != nil {
+		return nil, err
+	}
+	sortRows(rows, cols, keys, mahasiswaPK)
+	return page(rows, limit, offset), nil
+}
+
+// ListKeyset mengurutkan berdasarkan (sortCol, id_mahasiswa) dan mengambil baris setelah posisi keyset
+func (r *MahasiswaRepository) ListKeyset(ctx context.Context, q, match string, filters []repo.Filter, limit int, sortCol string, desc bool, afterValue any, afterID *string) ([]model.Mahasiswa, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, cols, err := r.find(q, match, filters)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := cols[sortCol]; !ok {
+		return nil, fmt.Errorf("memory: unsupported order column %q", sortCol)
+	}
+	sortRows(rows, cols, []orderKey{{col: sortCol, desc: desc}, {col: "id_mahasiswa", desc: desc}}, mahasiswaPK)
+
+	out := []model.Mahasiswa{}
+	for i := range rows {
+		if afterID != nil {
+			c := compareCol(cols[sortCol](&rows[i]), afterValue)
+			if c == 0 {
+				c = compareCol(rows[i].IDMahasiswa, *afterID)
+			}
+			if (!desc && c <= 0) || (desc && c >= 0) {
+				continue
+			}
+		}
+		out = append(out, rows[i])
+		if len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (r *MahasiswaRepository) Count(ctx context.Context, q, match string, filters []repo.Filter) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, _, err := r.find(q, match, filters)
+	return int64(len(rows)), err
+}
+
+func (r *MahasiswaRepository) GetByID(ctx context.Context, id string) (*model.Mahasiswa, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	m, ok := r.s.mahasiswa[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &m, nil
+}
+
+func (r *MahasiswaRepository) ExistsID(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	_, ok := r.s.mahasiswa[id]
+	return ok, nil
+}
+
+func (r *MahasiswaRepository) ExistsEmail(ctx context.Context, email string, excludeID *string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, m := range r.s.mahasiswa {
+		if m.Email != nil && *m.Email == email && (excludeID == nil || m.IDMahasiswa != *excludeID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *MahasiswaRepository) ExistsNIK(ctx context.Context, nik string, excludeID *string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, m := range r.s.mahasiswa {
+		if m.NIK != nil && *m.NIK == nik && (excludeID == nil || m.IDMahasiswa != *excludeID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *MahasiswaRepository) ExistsProdi(ctx context.Context, idProdi string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	_, ok := r.s.prodi[idProdi]
+	return ok, nil
+}
+
+// check menerapkan constraint tabel mahasiswa: nik unik dan FK id_prodi (email tidak unik di skema)
+func (r *MahasiswaRepository) check(m *model.Mahasiswa) error {
+	if m.NIK != nil {
+		for _, x := range r.s.mahasiswa {
+			if x.NIK != nil && *x.NIK == *m.NIK && x.IDMahasiswa != m.IDMahasiswa {
+				return uniqueViolation("mahasiswa", "mahasiswa_nik_key", "nik", *m.NIK)
+			}
+		}
+	}
+	if _, ok := r.s.prodi[m.IDProdi]; !ok {
+		return fkMissing("mahasiswa", "fk_mhs_prodi", "id_prodi", m.IDProdi, "prodi")
+	}
+	return nil
+}
+
+func (r *MahasiswaRepository) Create(ctx context.Context, m *model.Mahasiswa) (*model.Mahasiswa, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.mahasiswa[m.IDMahasiswa]; ok {
+		return nil, uniqueViolation("mahasiswa", "mahasiswa_pkey", "id_mahasiswa", m.IDMahasiswa)
+	}
+	now := r.s.Now()
+	out := model.Mahasiswa{
+		IDMahasiswa:  m.IDMahasiswa,
+		IDProdi:      m.IDProdi,
+		NIK:          cloneStr(m.NIK),
+		NamaLengkap:  m.NamaLengkap,
+		JenisKelamin: m.JenisKelamin,
+		TempatLahir:  cloneStr(m.TempatLahir),
+		TanggalLahir: cloneTime(m.TanggalLahir),
+		Alamat:       cloneStr(m.Alamat),
+		Email:        cloneStr(m.Email),
+		NoHP:         cloneStr(m.NoHP),
+		TahunMasuk:   m.TahunMasuk,
+		Status:       m.Status,
+		Angkatan:     m.TahunMasuk, // trigger set_angkatan
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := r.check(&out); err != nil {
+		return nil, err
+	}
+	r.s.mahasiswa[out.IDMahasiswa] = out
+	return &out, nil
+}
+
+func (r *MahasiswaRepository) UpdatePut(ctx context.Context, id string, m *model.Mahasiswa) (*model.Mahasiswa, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	out, ok := r.s.mahasiswa[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	out.IDProdi = m.IDProdi
+	out.NIK = cloneStr(m.NIK)
+	out.NamaLengkap = m.NamaLengkap
+	out.JenisKelamin = m.JenisKelamin
+	out.TempatLahir = cloneStr(m.TempatLahir)
+	out.TanggalLahir = cloneTime(m.TanggalLahir)
+	out.Alamat = cloneStr(m.Alamat)
+	out.Email = cloneStr(m.Email)
+	out.NoHP = cloneStr(m.NoHP)
+	out.TahunMasuk = m.TahunMasuk
+	return r.save(out)
+}
+
+func (r *MahasiswaRepository) UpdatePatch(ctx context.Context, id string, idProdi, nik, nama, jk, tempat, alamat, email, nohp *string, tgl *time.Time, tahunMasuk *int) (*model.Mahasiswa, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	out, ok := r.s.mahasiswa[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	changed := false
+	set := func(dst **string, v *string) {
+		if v != nil {
+			*dst = cloneStr(v)
+			changed = true
+		}
+	}
+	setText := func(dst *string, v *string) {
+		if v != nil {
+			*dst = *v
+			changed = true
+		}
+	}
+	setText(&out.IDProdi, idProdi)
+	set(&out.NIK, nik)
+	setText(&out.NamaLengkap, nama)
+	setText(&out.JenisKelamin, jk)
+	set(&out.TempatLahir, tempat)
+	set(&out.Alamat, alamat)
+	set(&out.Email, email)
+	set(&out.NoHP, nohp)
+	if tgl != nil {
+		out.TanggalLahir = cloneTime(tgl)
+		changed = true
+	}
+	if tahunMasuk != nil {
+		out.TahunMasuk = *tahunMasuk
+		changed = true
+	}
+	if !changed {
+		return &out, nil
+	}
+	return r.save(out)
+}
+
+func (r *MahasiswaRepository) UpdateStatus(ctx context.Context, id, status string) (*model.Mahasiswa, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	out, ok := r.s.mahasiswa[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	out.Status = status
+	return r.save(out)
+}
+
+func (r *MahasiswaRepository) CreateStatusHistory(ctx context.Context, h *model.MahasiswaStatusHistory) (*model.MahasiswaStatusHistory, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.mahasiswa[h.IDMahasiswa]; !ok {
+		return nil, fkMissing("mahasiswa_status_history", "fk_status_history_mhs", "id_mahasiswa", h.IDMahasiswa, "mahasiswa")
+	}
+	r.s.statusHistorySeq++
+	out := *h
+	out.IDHistory = r.s.statusHistorySeq
+	out.NomorSK = cloneStr(h.NomorSK)
+	if h.IDUser != nil {
+		uid := *h.IDUser
+		out.IDUser = &uid
+	}
+	out.CreatedAt = r.s.Now()
+	r.s.statusHistory = append(r.s.statusHistory, out)
+	return &out, nil
+}
+
+// ListStatusHistory mengurutkan seperti implementasi PostgreSQL: tanggal_efektif DESC, id_history DESC
+func (r *MahasiswaRepository) ListStatusHistory(ctx context.Context, id string) ([]model.MahasiswaStatusHistory, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	out := []model.MahasiswaStatusHistory{}
+	for _, h := range r.s.statusHistory {
+		if h.IDMahasiswa == id {
+			out = append(out, h)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].TanggalEfektif.Equal(out[j].TanggalEfektif) {
+			return out[i].TanggalEfektif.After(out[j].TanggalEfektif)
+		}
+		return out[i].IDHistory > out[j].IDHistory
+	})
+	return out, nil
+}
+
+func (r *MahasiswaRepository) LatestStatusDate(ctx context.Context, id string) (*time.Time, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var latest *time.Time
+	for _, h := range r.s.statusHistory {
+		if h.IDMahasiswa == id && (latest == nil || h.TanggalEfektif.After(*latest)) {
+			t := h.TanggalEfektif
+			latest = &t
+		}
+	}
+	return latest, nil
+}
+
+func (r *MahasiswaRepository) save(m model.Mahasiswa) (*model.Mahasiswa, error) {
+	if err := r.check(&m); err != nil {
+		return nil, err
+	}
+	m.Angkatan = m.TahunMasuk
+	m.UpdatedAt = r.s.Now()
+	r.s.mahasiswa[m.IDMahasiswa] = m
+	return &m, nil
+}
+
+func (r *MahasiswaRepository) HasKRSRelated(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.s.mahasiswaHasKRS(id), nil
+}
+
+func (r *MahasiswaRepository) Delete(ctx context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.mahasiswa[id]; !ok {
+		return pgx.ErrNoRows
+	}
+	if r.s.mahasiswaHasKRS(id) {
+		return stillReferenced("mahasiswa", "fk_krs_mhs", "id_mahasiswa", id, "krs")
+	}
+	delete(r.s.mahasiswa, id)
+	// ON DELETE CASCADE pada mahasiswa_status_history, cuti, pembimbing_akademik dan krs_pengajuan
+	r.s.statusHistory = slices.DeleteFunc(r.s.statusHistory, func(h model.MahasiswaStatusHistory) bool { return h.IDMahasiswa == id })
+	r.s.cuti = slices.DeleteFunc(r.s.cuti, func(c cutiRow) bool { return c.IDMahasiswa == id })
+	r.s.pembimbing = slices.DeleteFunc(r.s.pembimbing, func(p model.PembimbingAkademik) bool { return p.IDMahasiswa == id })
+	r.s.krsPengajuan = slices.DeleteFunc(r.s.krsPengajuan, func(p model.KRSPengajuan) bool { return p.IDMahasiswa == id })
+	return nil
+}
+
+func (s *Store) mahasiswaHasKRS(id string) bool {
+	for _, k := range s.krs {
+		if k.IDMahasiswa == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MahasiswaRepository) LockByID(ctx context.Context, id string) error {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	if _, ok := r.s.mahasiswa[id]; !ok {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+)
+
+type PembimbingRepository struct {
+	s *Store
+}
+
+func NewPembimbingRepository(s *Store) *PembimbingRepository {
+	return &PembimbingRepository{s: s}
+}
+
+// bimbinganColumns memakai nama kolom beralias seperti repo.BimbinganFilterFields
+var bimbinganColumns = columns[model.Bimbingan]{
+	"m.id_mahasiswa": func(b *model.Bimbingan) any { return b.IDMahasiswa },
+	"m.nama_lengkap": func(b *model.Bimbingan) any { return b.NamaLengkap },
+	"m.status":       func(b *model.Bimbingan) any { return b.Status },
+	"m.id_prodi":     func(b *model.Bimbingan) any { return b.IDProdi },
+	"m.angkatan":     func(b *model.Bimbingan) any { return b.Angkatan },
+	"g.ipk":          func(b *model.Bimbingan) any { return b.IPK },
+}
+
+// row melengkapi nama dosen seperti JOIN dosen pada implementasi PostgreSQL
+func (r *PembimbingRepository) row(p model.PembimbingAkademik) model.PembimbingAkademik {
+	p.NamaDosen = r.s.dosen[p.IDDosen].NamaDosen
+	p.TanggalSelesai = cloneTime(p.TanggalSelesai)
+	p.Alasan = cloneStr(p.Alasan)
+	if p.IDUser != nil {
+		id := *p.IDUser
+		p.IDUser = &id
+	}
+	return p
+}
+
+func (r *PembimbingRepository) Current(ctx context.Context, idMahasiswa string) (*model.PembimbingAkademik, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	if i := r.s.currentPembimbing(idMahasiswa); i >= 0 {
+		out := r.row(r.s.pembimbing[i])
+		return &out, nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *PembimbingRepository) History(ctx context.Context, idMahasiswa string) ([]model.PembimbingAkademik, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	out := []model.PembimbingAkademik{}
+	for _, p := range r.s.pembimbing {
+		if p.IDMahasiswa == idMahasiswa {
+			out = append(out, r.row(p))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].TanggalMulai.Equal(out[j].TanggalMulai) {
+			return out[i].TanggalMulai.After(out[j].TanggalMulai)
+		}
+		return out[i].IDPembimbing > out[j].IDPembimbing
+	})
+	return out, nil
+}
+
+func (r *PembimbingRepository) End(ctx context.Context, id int64, tanggal time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for i, p := range r.s.pembimbing {
+		if p.IDPembimbing == id && p.TanggalSelesai == nil {
+			if tanggal.Before(p.TanggalMulai) {
+				return checkViolation("pembimbing_akademik", "ck_pembimbing_tanggal")
+			}
+			t := tanggal
+			r.s.pembimbing[i].TanggalSelesai = &t
+			return nil
+		}
+	}
+	return pgx.ErrNoRows
+}
+
+// Create menerapkan FK mahasiswa/dosen dan indeks unik uq_pembimbing_aktif
+func (r *PembimbingRepository) Create(ctx context.Context, p *model.PembimbingAkademik) (*model.PembimbingAkademik, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.mahasiswa[p.IDMahasiswa]; !ok {
+		return nil, fkMissing("pembimbing_akademik", "fk_pembimbing_mhs", "id_mahasiswa", p.IDMahasiswa, "mahasiswa")
+	}
+	if _, ok := r.s.dosen[p.IDDosen]; !ok {
+		return nil, fkMissing("pembimbing_akademik", "fk_pembimbing_dosen", "id_dosen", p.IDDosen, "dosen")
+	}
+	if r.s.currentPembimbing(p.IDMahasiswa) >= 0 {
+		return nil, uniqueViolation("pembimbing_akademik", "uq_pembimbing_aktif", "id_mahasiswa", p.IDMahasiswa)
+	}
+	r.s.pembimbingSeq++
+	row := model.PembimbingAkademik{
+		IDPembimbing: r.s.pembimbingSeq,
+		IDMahasiswa:  p.IDMahasiswa,
+		IDDosen:      p.IDDosen,
+		TanggalMulai: p.TanggalMulai,
+		Alasan:       cloneStr(p.Alasan),
+		Username:     p.Username,
+		CreatedAt:    r.s.Now(),
+	}
+	if p.IDUser != nil {
+		id := *p.IDUser
+		row.IDUser = &id
+	}
+	r.s.pembimbing = append(r.s.pembimbing, row)
+	out := r.row(row)
+	return &out, nil
+}
+
+func (r *PembimbingRepository) ReassignPending(ctx context.Context, idMahasiswa, idDosen string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	now := r.s.Now()
+	for i, p := range r.s.krsPengajuan {
+		if p.IDMahasiswa == idMahasiswa && p.Status == "Diajukan" {
+			d := idDosen
+			r.s.krsPengajuan[i].IDDosen = &d
+			r.s.krsPengajuan[i].UpdatedAt = now
+		}
+	}
+	return nil
+}
+
+func (r *PembimbingRepository) LockDosen(ctx context.Context, id string) error {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	if _, ok := r.s.dosen[id]; !ok {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *PembimbingRepository) LockMahasiswaAngkatan(ctx context.Context, idProdi string, angkatan int) ([]string, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	out := []string{}
+	for _, m := range r.s.mahasiswa {
+		if m.IDProdi == idProdi && m.Angkatan == angkatan && m.Status != "Lulus" && m.Status != "Drop Out" {
+			out = append(out, m.IDMahasiswa)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (r *PembimbingRepository) bimbingan(idDosen string) []model.Bimbingan {
+	out := []model.Bimbingan{}
+	for _, p := range r.s.pembimbing {
+		if p.IDDosen != idDosen || p.TanggalSelesai != nil {
+			continue
+		}
+		m := r.s.mahasiswa[p.IDMahasiswa]
+		ipk, sks := r.s.ipk(m.IDMahasiswa)
+		out = append(out, model.Bimbingan{
+			IDMahasiswa:  m.IDMahasiswa,
+			NamaLengkap:  m.NamaLengkap,
+			IDProdi:      m.IDProdi,
+			Angkatan:     m.Angkatan,
+			Status:       m.Status,
+			IPK:          ipk,
+			TotalSKS:     sks,
+			TanggalMulai: p.TanggalMulai,
+		})
+	}
+	return out
+}
+
+func (r *PembimbingRepository) ListBimbingan(ctx context.Context, idDosen string, filters []repo.Filter, limit, offset int, orderBy string) ([]model.Bimbingan, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := filterRows(r.bimbingan(idDosen), bimbinganColumns, filters, nil)
+	if err != nil {
+		return nil, err
+	}
+	if orderBy == "" {
+		orderBy = "m.id_mahasiswa ASC"
+	}
+	keys, err := parseOrder(orderBy, bimbinganColumns)
+	if err != nil {
+		return nil, err
+	}
+	sortRows(rows, bimbinganColumns, keys, func(b *model.Bimbingan) string { return b.IDMahasiswa })
+	return page(rows, limit, offset), nil
+}
+
+func (r *PembimbingRepository) CountBimbingan(ctx context.Context, idDosen string, filters []repo.Filter) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := filterRows(r.bimbingan(idDosen), bimbinganColumns, filters, nil)
+	return int64(len(rows)), err
+}
+
+func (r *PembimbingRepository) IsAdvisee(ctx context.Context, idDosen, idMahasiswa string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	i := r.s.currentPembimbing(idMahasiswa)
+	return i >= 0 && r.s.pembimbing[i].IDDosen == idDosen, nil
+}
+
+// currentPembimbing mengembalikan indeks pembimbing aktif mahasiswa, -1 bila tidak ada
+func (s *Store) currentPembimbing(idMahasiswa string) int {
+	for i, p := range s.pembimbing {
+		if p.IDMahasiswa == idMahasiswa && p.TanggalSelesai == nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// ipk meniru ipkLateral: nilai terbaik per mata kuliah dari KRS yang disetujui, dibulatkan 2 desimal
+func (s *Store) ipk(idMahasiswa string) (*float64, int) {
+	best := map[string]float64{}
+	for _, k := range s.krs {
+		if k.IDMahasiswa != idMahasiswa || k.Status != "Diambil" || s.krsStatus(k.IDMahasiswa, k.IDSemester) != "Disetujui" {
+			continue
+		}
+		bobot, ok := s.nilai[k.IDKRS]
+		if !ok {
+			continue
+		}
+		mk := s.kelas[k.IDKelas].IDMK
+		if b, seen := best[mk]; !seen || bobot > b {
+			best[mk] = bobot
+		}
+	}
+	var total float64
+	sks := 0
+	for mk, b := range best {
+		n := s.mataKuliah[mk].SKS
+		total += b * float64(n)
+		sks += n
+	}
+	if sks == 0 {
+		return nil, 0
+	}
+	v := math.Round(total/float64(sks)*100) / 100
+	return &v, sks
+}
+
+// checkViolation meniru pelanggaran CHECK constraint (SQLSTATE 23514)
+func checkViolation(table, constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23514",
+		Message:        fmt.Sprintf("new row for relation %q violates check constraint %q", table, constraint),
+		TableName:      table,
+		ConstraintName: constraint,
+	}
+}
@@ -0,0 +1,265 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+)
+
+type ProdiRepository struct {
+	s *Store
+}
+
+func NewProdiRepository(s *Store) *ProdiRepository {
+	return &ProdiRepository{s: s}
+}
+
+var prodiColumns = columns[model.Prodi]{
+	"id_prodi":    func(p *model.Prodi) any { return p.IDProdi },
+	"id_fakultas": func(p *model.Prodi) any { return p.IDFakultas },
+	"nama_prodi":  func(p *model.Prodi) any { return p.NamaProdi },
+	"jenjang":     func(p *model.Prodi) any { return p.Jenjang },
+	"kode_prodi":  func(p *model.Prodi) any { return p.KodeProdi },
+	"akreditasi":  func(p *model.Prodi) any { return p.Akreditasi },
+	"created_at":  func(p *model.Prodi) any { return p.CreatedAt },
+	"updated_at":  func(p *model.Prodi) any { return p.UpdatedAt },
+}
+
+func prodiPK(p *model.Prodi) string { return p.IDProdi }
+
+func (r *ProdiRepository) find(q string, filters []repo.Filter) ([]model.Prodi, error) {
+	var match func(*model.Prodi) bool
+	if q != "" {
+		match = func(p *model.Prodi) bool { return containsFold(p.NamaProdi, q) || containsFold(p.KodeProdi, q) }
+	}
+	return filterRows(values(r.s.prodi), prodiColumns, filters, match)
+}
+
+func (r *ProdiRepository) List(ctx context.Context, q string, filters []repo.Filter, limit, offset int, orderBy string) ([]model.Prodi, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := r.find(q, filters)
+	if err != nil {
+		return nil, err
+	}
+	if orderBy == "" {
+		orderBy = "nama_prodi ASC"
+	}
+	keys, err := parseOrder(orderBy, prodiColumns)
+	if err != nil {
+		return nil, err
+	}
+	sortRows(rows, prodiColumns, keys, prodiPK)
+	return page(rows, limit, offset), nil
+}
+
+func (r *ProdiRepository) Count(ctx context.Context, q string, filters []repo.Filter) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := r.find(q, filters)
+	return int64(len(rows)), err
+}
+
+func (r *ProdiRepository) GetByID(ctx context.Context, id string) (*model.Prodi, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	p, ok := r.s.prodi[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &p, nil
+}
+
+func (r *ProdiRepository) ExistsID(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	_, ok := r.s.prodi[id]
+	return ok, nil
+}
+
+func (r *ProdiRepository) ExistsFakultas(ctx context.Context, idFak string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	_, ok := r.s.fakultas[idFak]
+	return ok, nil
+}
+
+func (r *ProdiRepository) ExistsKode(ctx context.Context, kode string, excludeID *string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, p := range r.s.prodi {
+		if p.KodeProdi == kode && (excludeID == nil || p.IDProdi != *excludeID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *ProdiRepository) ExistsNamaPerFakultasJenjangCI(ctx context.Context, idFakultas, jenjang, nama string, excludeID *string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, p := range r.s.prodi {
+		if p.IDFakultas == idFakultas && p.Jenjang == jenjang && strings.EqualFold(p.NamaProdi, nama) &&
+			(excludeID == nil || p.IDProdi != *excludeID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// check menerapkan constraint tabel prodi: kode_prodi unik dan FK id_fakultas
+func (r *ProdiRepository) check(p *model.Prodi) error {
+	for _, x := range r.s.prodi {
+		if x.KodeProdi == p.KodeProdi && x.IDProdi != p.IDProdi {
+			return uniqueViolation("prodi", "prodi_kode_prodi_key", "kode_prodi", p.KodeProdi)
+		}
+	}
+	if _, ok := r.s.fakultas[p.IDFakultas]; !ok {
+		return fkMissing("prodi", "fk_prodi_fakultas", "id_fakultas", p.IDFakultas, "fakultas")
+	}
+	return nil
+}
+
+func (r *ProdiRepository) Create(ctx context.Context, p *model.Prodi) (*model.Prodi, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.prodi[p.IDProdi]; ok {
+		return nil, uniqueViolation("prodi", "prodi_pkey", "id_prodi", p.IDProdi)
+	}
+	now := r.s.Now()
+	out := model.Prodi{
+		IDProdi:    p.IDProdi,
+		IDFakultas: p.IDFakultas,
+		NamaProdi:  p.NamaProdi,
+		Jenjang:    p.Jenjang,
+		KodeProdi:  p.KodeProdi,
+		Akreditasi: cloneStr(p.Akreditasi),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := r.check(&out); err != nil {
+		return nil, err
+	}
+	r.s.prodi[out.IDProdi] = out
+	return &out, nil
+}
+
+func (r *ProdiRepository) UpdatePut(ctx context.Context, id string, p *model.Prodi) (*model.Prodi, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	out, ok := r.s.prodi[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	out.IDFakultas = p.IDFakultas
+	out.NamaProdi = p.NamaProdi
+	out.Jenjang = p.Jenjang
+	out.KodeProdi = p.KodeProdi
+	out.Akreditasi = cloneStr(p.Akreditasi)
+	return r.save(out)
+}
+
+func (r *ProdiRepository) UpdatePatch(ctx context.Context, id string, idFakultas, nama, jenjang, kode *string, akreditasi *string) (*model.Prodi, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	out, ok := r.s.prodi[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	if idFakultas == nil && nama == nil && jenjang == nil && kode == nil && akreditasi == nil {
+		return &out, nil
+	}
+	if idFakultas != nil {
+		out.IDFakultas = *idFakultas
+	}
+	if nama != nil {
+		out.NamaProdi = *nama
+	}
+	if jenjang != nil {
+		out.Jenjang = *jenjang
+	}
+	if kode != nil {
+		out.KodeProdi = *kode
+	}
+	if akreditasi != nil {
+		out.Akreditasi = cloneStr(akreditasi)
+	}
+	return r.save(out)
+}
+
+func (r *ProdiRepository) save(p model.Prodi) (*model.Prodi, error) {
+	if err := r.check(&p); err != nil {
+		return nil, err
+	}
+	p.UpdatedAt = r.s.Now()
+	r.s.prodi[p.IDProdi] = p
+	return &p, nil
+}
+
+func (r *ProdiRepository) HasMahasiswaRelated(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.s.prodiHasMahasiswa(id), nil
+}
+
+func (r *ProdiRepository) HasMataKuliahRelated(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.s.prodiHasMataKuliah(id), nil
+}
+
+func (r *ProdiRepository) Delete(ctx context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.prodi[id]; !ok {
+		return pgx.ErrNoRows
+	}
+	if r.s.prodiHasMahasiswa(id) {
+		return stillReferenced("prodi", "fk_mhs_prodi", "id_prodi", id, "mahasiswa")
+	}
+	if r.s.prodiHasMataKuliah(id) {
+		return stillReferenced("prodi", "fk_mk_prodi", "id_prodi", id, "mata_kuliah")
+	}
+	delete(r.s.prodi, id)
+	delete(r.s.cutiPolicy, id) // ON DELETE CASCADE pada prodi_cuti_policy
+	// ON DELETE CASCADE pada kurikulum dan kurikulum_mk
+	for _, k := range r.s.kurikulum {
+		if k.IDProdi == id {
+			r.s.kurikulumMK = slices.DeleteFunc(r.s.kurikulumMK, func(x kurikulumMKRow) bool { return x.IDKurikulum == k.IDKurikulum })
+		}
+	}
+	r.s.kurikulum = slices.DeleteFunc(r.s.kurikulum, func(k model.Kurikulum) bool { return k.IDProdi == id })
+	return nil
+}
+
+func (s *Store) prodiHasMahasiswa(id string) bool {
+	for _, m := range s.mahasiswa {
+		if m.IDProdi == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) prodiHasMataKuliah(id string) bool {
+	for _, mk := range s.mataKuliah {
+		if mk.IDProdi == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ProdiRepository) LockByID(ctx context.Context, id string) error {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	if _, ok := r.s.prodi[id]; !ok {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+)
+
+// columns memetakan ekspresi kolom (Filter.Column / orderBy) ke nilai kolom suatu baris.
+// Nilai yang dikembalikan: string, *string, int, int64, *float64, time.Time atau *time.Time (nil = NULL).
+type columns[T any] map[string]func(*T) any
+
+// normalize menyeragamkan nilai kolom agar bisa dibandingkan; null=true untuk NULL
+func normalize(v any) (any, bool) {
+	switch x := v.(type) {
+	case nil:
+		return nil, true
+	case *string:
+		if x == nil {
+			return nil, true
+		}
+		return *x, false
+	case *time.Time:
+		if x == nil {
+			return nil, true
+		}
+		return *x, false
+	case int:
+		return int64(x), false
+	case *int:
+		if x == nil {
+			return nil, true
+		}
+		return int64(*x), false
+	case *float64:
+		if x == nil {
+			return nil, true
+		}
+		return *x, false
+	default:
+		return v, false
+	}
+}
+
+// compare membandingkan dua nilai ter-normalisasi bertipe sama
+func compare(a, b any) int {
+	switch x := a.(type) {
+	case string:
+		return strings.Compare(x, b.(string))
+	case int64:
+		y := b.(int64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case float64:
+		return cmp.Compare(x, b.(float64))
+	case time.Time:
+		return x.Compare(b.(time.Time))
+	}
+	panic(fmt.Sprintf("memory: cannot compare %T", a))
+}
+
+func contains(list any, v any) bool {
+	switch l := list.(type) {
+	case []string:
+		for _, x := range l {
+			if x == v {
+				return true
+			}
+		}
+	case []int64:
+		for _, x := range l {
+			if x == v {
+				return true
+			}
+		}
+	case []time.Time:
+		for _, x := range l {
+			if x.Equal(v.(time.Time)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchFilter mengevaluasi satu Filter dengan semantik SQL (perbandingan dengan NULL selalu false)
+func matchFilter(f repo.Filter, raw any) bool {
+	v, null := normalize(raw)
+	switch f.Op {
+	case "is":
+		return null
+	case "isnot":
+		return !null
+	}
+	if null {
+		return false
+	}
+	switch f.Op {
+	case "in":
+		return contains(f.Values[0], v)
+	case "nin":
+		return !contains(f.Values[0], v)
+	case "between":
+		return compare(v, f.Values[0]) >= 0 && compare(v, f.Values[1]) <= 0
+	}
+	c := compare(v, f.Values[0])
+	switch f.Op {
+	case "eq":
+		return c == 0
+	case "ne":
+		return c != 0
+	case "gt":
+		return c > 0
+	case "gte":
+		return c >= 0
+	case "lt":
+		return c < 0
+	case "lte":
+		return c <= 0
+	}
+	return false
+}
+
+// filterRows mengembalikan baris yang lolos pencarian (search boleh nil) dan seluruh filters
+func filterRows[T any](rows []T, cols columns[T], filters []repo.Filter, search func(*T) bool) ([]T, error) {
+	for _, f := range filters {
+		if _, ok := cols[f.Column]; !ok {
+			return nil, fmt.Errorf("memory: unsupported filter column %q", f.Column)
+		}
+	}
+	out := []T{}
+	for i := range rows {
+		row := &rows[i]
+		if search != nil && !search(row) {
+			continue
+		}
+		ok := true
+		for _, f := range filters {
+			if !matchFilter(f, cols[f.Column](row)) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			out = append(out, *row)
+		}
+	}
+	return out, nil
+}
+
+type orderKey struct {
+	col  string
+	desc bool
+}
+
+// parseOrder mem-parse orderBy seperti "nama_lengkap ASC, created_at DESC"
+func parseOrder[T any](orderBy string, cols columns[T]) ([]orderKey, error) {
+	var keys []orderKey
+	for _, part := range strings.Split(orderBy, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		k := orderKey{col: fields[0]}
+		if len(fields) > 1 {
+			k.desc = strings.EqualFold(fields[1], "desc")
+		}
+		if _, ok := cols[k.col]; !ok {
+			return nil, fmt.Errorf("memory: unsupported order column %q", k.col)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// compareCol membandingkan satu kolom; NULL dianggap paling besar seperti default PostgreSQL
+// (NULLS LAST untuk ASC, NULLS FIRST untuk DESC)
+func compareCol(a, b any) int {
+	va, na := normalize(a)
+	vb, nb := normalize(b)
+	switch {
+	case na && nb:
+		return 0
+	case na:
+		return 1
+	case nb:
+		return -1
+	}
+	return compare(va, vb)
+}
+
+// sortRows mengurutkan berdasarkan keys lalu primary key sebagai pemecah seri yang deterministik
+func sortRows[T any](rows []T, cols columns[T], keys []orderKey, pk func(*T) string) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, k := range keys {
+			c := compareCol(cols[k.col](&rows[i]), cols[k.col](&rows[j]))
+			if k.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return pk(&rows[i]) < pk(&rows[j])
+	})
+}
+
+// page menerapkan LIMIT/OFFSET dengan aturan yang sama seperti repository PostgreSQL (0 = tanpa batas)
+func page[T any](rows []T, limit, offset int) []T {
+	if offset > 0 {
+		if offset >= len(rows) {
+			return []T{}
+		}
+		rows = rows[offset:]
+	}
+	if limit > 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// nameSearch meniru nameSearchClause: exact = substring tanpa membedakan huruf besar/kecil,
+// prefix = awalan. Mode fuzzy (pg_trgm) didekati dengan substring pada nama dan kolom lain.
+func nameSearch(match, q, name string, others ...*string) bool {
+	test := containsFold
+	if match == repo.MatchPrefix {
+		test = hasPrefixFold
+	}
+	if test(name, q) {
+		return true
+	}
+	for _, o := range others {
+		if o != nil && test(*o, q) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(s, sub string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(sub))
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return strings.HasPrefix(strings.ToLower(s), strings.ToLower(prefix))
+}
+
+// values mengambil isi map sebagai slice
+func values[K comparable, T any](m map[K]T) []T {
+	out := make([]T, 0, len(m))
+	for _, v := range m {
+		out = append(out, v)
+	}
+	return out
+}
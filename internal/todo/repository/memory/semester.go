@@ -0,0 +1,209 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+)
+
+type SemesterRepository struct {
+	s *Store
+}
+
+func NewSemesterRepository(s *Store) *SemesterRepository {
+	return &SemesterRepository{s: s}
+}
+
+var semesterColumns = columns[model.Semester]{
+	"id_semester":     func(s *model.Semester) any { return s.IDSemester },
+	"tahun_ajaran":    func(s *model.Semester) any { return s.TahunAjaran },
+	"term":            func(s *model.Semester) any { return s.Term },
+	"tanggal_mulai":   func(s *model.Semester) any { return s.TanggalMulai },
+	"tanggal_selesai": func(s *model.Semester) any { return s.TanggalSelesai },
+	"created_at":      func(s *model.Semester) any { return s.CreatedAt },
+	"updated_at":      func(s *model.Semester) any { return s.UpdatedAt },
+}
+
+func semesterPK(s *model.Semester) string { return s.IDSemester }
+
+func (r *SemesterRepository) find(q string, filters []repo.Filter) ([]model.Semester, error) {
+	var match func(*model.Semester) bool
+	if q != "" {
+		match = func(s *model.Semester) bool {
+			return containsFold(s.IDSemester, q) || containsFold(s.TahunAjaran, q) || containsFold(s.Term, q)
+		}
+	}
+	return filterRows(values(r.s.semester), semesterColumns, filters, match)
+}
+
+func (r *SemesterRepository) List(ctx context.Context, q string, filters []repo.Filter, limit, offset int, orderBy string) ([]model.Semester, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := r.find(q, filters)
+	if err != nil {
+		return nil, err
+	}
+	if orderBy == "" {
+		orderBy = "id_semester DESC"
+	}
+	keys, err := parseOrder(orderBy, semesterColumns)
+	if err != nil {
+		return nil, err
+	}
+	sortRows(rows, semesterColumns, keys, semesterPK)
+	return page(rows, limit, offset), nil
+}
+
+func (r *SemesterRepository) Count(ctx context.Context, q string, filters []repo.Filter) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rows, err := r.find(q, filters)
+	return int64(len(rows)), err
+}
+
+func (r *SemesterRepository) GetByID(ctx context.Context, id string) (*model.Semester, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	s, ok := r.s.semester[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &s, nil
+}
+
+func (r *SemesterRepository) ExistsID(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	_, ok := r.s.semester[id]
+	return ok, nil
+}
+
+func (r *SemesterRepository) Create(ctx context.Context, s *model.Semester) (*model.Semester, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.semester[s.IDSemester]; ok {
+		return nil, uniqueViolation("semester", "semester_pkey", "id_semester", s.IDSemester)
+	}
+	now := r.s.Now()
+	out := model.Semester{
+		IDSemester:     s.IDSemester,
+		TahunAjaran:    s.TahunAjaran,
+		Term:           s.Term,
+		TanggalMulai:   cloneTime(s.TanggalMulai),
+		TanggalSelesai: cloneTime(s.TanggalSelesai),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	r.s.semester[out.IDSemester] = out
+	return &out, nil
+}
+
+func (r *SemesterRepository) UpdatePut(ctx context.Context, id string, s *model.Semester) (*model.Semester, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	out, ok := r.s.semester[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	out.TahunAjaran = s.TahunAjaran
+	out.Term = s.Term
+	out.TanggalMulai = cloneTime(s.TanggalMulai)
+	out.TanggalSelesai = cloneTime(s.TanggalSelesai)
+	out.UpdatedAt = r.s.Now()
+	r.s.semester[id] = out
+	return &out, nil
+}
+
+func (r *SemesterRepository) UpdatePatch(ctx context.Context, id string, tahunAjaran, term *string, tglMulai, tglSelesai *time.Time) (*model.Semester, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	out, ok := r.s.semester[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	if tahunAjaran == nil && term == nil && tglMulai == nil && tglSelesai == nil {
+		return &out, nil
+	}
+	if tahunAjaran != nil {
+		out.TahunAjaran = *tahunAjaran
+	}
+	if term != nil {
+		out.Term = *term
+	}
+	if tglMulai != nil {
+		out.TanggalMulai = cloneTime(tglMulai)
+	}
+	if tglSelesai != nil {
+		out.TanggalSelesai = cloneTime(tglSelesai)
+	}
+	out.UpdatedAt = r.s.Now()
+	r.s.semester[id] = out
+	return &out, nil
+}
+
+func (r *SemesterRepository) HasKelasRelated(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.s.semesterHasKelas(id), nil
+}
+
+func (r *SemesterRepository) HasKRSRelated(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.s.semesterHasKRS(id), nil
+}
+
+func (r *SemesterRepository) Delete(ctx context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.semester[id]; !ok {
+		return pgx.ErrNoRows
+	}
+	if r.s.semesterHasKelas(id) {
+		return stillReferenced("semester", "fk_kelas_semester", "id_semester", id, "kelas_kuliah")
+	}
+	if r.s.semesterHasKRS(id) {
+		return stillReferenced("semester", "fk_krs_sem", "id_semester", id, "krs")
+	}
+	if r.s.semesterHasCuti(id) {
+		return stillReferenced("semester", "fk_cuti_semester", "id_semester", id, "cuti")
+	}
+	for _, p := range r.s.krsPengajuan {
+		if p.IDSemester == id {
+			return stillReferenced("semester", "fk_krs_pengajuan_sem", "id_semester", id, "krs_pengajuan")
+		}
+	}
+	delete(r.s.semester, id)
+	return nil
+}
+
+func (s *Store) semesterHasKelas(id string) bool {
+	for _, k := range s.kelas {
+		if k.IDSemester == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) semesterHasKRS(id string) bool {
+	for _, k := range s.krs {
+		if k.IDSemester == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *SemesterRepository) LockByID(ctx context.Context, id string) error {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	if _, ok := r.s.semester[id]; !ok {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+// Package memory berisi implementasi repository in-memory untuk unit test service tanpa PostgreSQL.
+//
+// Seluruh repository berbagi satu Store sehingga aturan antar tabel (FK dan ON DELETE RESTRICT)
+// berlaku seperti di database. Error yang dikembalikan meniru pgx: data tidak ada -> pgx.ErrNoRows,
+// pelanggaran unique/FK -> *pgconn.PgError dengan SQLSTATE dan Detail yang sama dengan PostgreSQL,
+// sehingga apperror.FromDB menerjemahkannya persis seperti pada implementasi PostgreSQL.
+package memory
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	authmodel "pencatatan-data-mahasiswa/internal/todo/model/auth"
+)
+
+// MataKuliah adalah baris minimal tabel mata_kuliah yang dibutuhkan aturan relasi
+type MataKuliah struct {
+	IDMK      string
+	KodeMK    string
+	NamaMK    string
+	SKS       int
+	IDProdi   string
+	IDDosenPJ *string
+}
+
+// KelasKuliah adalah baris minimal tabel kelas_kuliah
+type KelasKuliah struct {
+	IDKelas         string
+	NamaKelas       string
+	IDMK            string
+	IDSemester      string
+	IDDosenPengampu string
+}
+
+// KRS adalah baris minimal tabel krs; IDKRS diisi store dan Status kosong berarti Diambil
+type KRS struct {
+	IDKRS       int64
+	IDMahasiswa string
+	IDKelas     string
+	IDSemester  string
+	Status      string
+}
+
+// Store menyimpan seluruh tabel; aman dipakai bersamaan dari beberapa goroutine
+type Store struct {
+	mu sync.RWMutex
+	// txMu menjalankan transaksi (Do) satu per satu, pengganti lock baris/advisory di PostgreSQL
+	txMu sync.Mutex
+
+	fakultas   map[string]model.Fakultas
+	prodi      map[string]model.Prodi
+	dosen      map[string]model.Dosen
+	mahasiswa  map[string]model.Mahasiswa
+	semester   map[string]model.Semester
+	mataKuliah map[string]MataKuliah
+	kelas      map[string]KelasKuliah
+	krs        []KRS
+	krsSeq     int64
+	nilai      map[int64]float64 // id_krs -> bobot
+	users      map[int64]authmodel.User
+	userSeq    int64
+
+	statusHistory    []model.MahasiswaStatusHistory
+	statusHistorySeq int64
+
+	cuti       []cutiRow
+	cutiSeq    int64
+	cutiPolicy map[string]model.CutiPolicy
+
+	pembimbing      []model.PembimbingAkademik
+	pembimbingSeq   int64
+	krsPengajuan    []model.KRSPengajuan
+	krsPengajuanSeq int64
+
+	kurikulum    []model.Kurikulum
+	kurikulumSeq int64
+	kurikulumMK  []kurikulumMKRow
+	prasyarat    []prasyaratRow
+
+	// Now dipakai untuk created_at/updated_at; bisa diganti agar hasil test deterministik
+	Now func() time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		fakultas:   map[string]model.Fakultas{},
+		prodi:      map[string]model.Prodi{},
+		dosen:      map[string]model.Dosen{},
+		mahasiswa:  map[string]model.Mahasiswa{},
+		semester:   map[string]model.Semester{},
+		mataKuliah: map[string]MataKuliah{},
+		kelas:      map[string]KelasKuliah{},
+		nilai:      map[int64]float64{},
+		users:      map[int64]authmodel.User{},
+		cutiPolicy: map[string]model.CutiPolicy{},
+		Now:        func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// AddMataKuliah menambahkan mata kuliah (kode_mk unik, FK ke prodi dan dosen penanggung jawab)
+func (s *Store) AddMataKuliah(mk MataKuliah) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.mataKuliah[mk.IDMK]; ok {
+		return uniqueViolation("mata_kuliah", "mata_kuliah_pkey", "id_mk", mk.IDMK)
+	}
+	for _, x := range s.mataKuliah {
+		if x.KodeMK == mk.KodeMK {
+			return uniqueViolation("mata_kuliah", "mata_kuliah_kode_mk_key", "kode_mk", mk.KodeMK)
+		}
+	}
+	if _, ok := s.prodi[mk.IDProdi]; !ok {
+		return fkMissing("mata_kuliah", "fk_mk_prodi", "id_prodi", mk.IDProdi, "prodi")
+	}
+	if mk.IDDosenPJ != nil {
+		if _, ok := s.dosen[*mk.IDDosenPJ]; !ok {
+			return fkMissing("mata_kuliah", "fk_mk_dosenpj", "id_dosen_pj", *mk.IDDosenPJ, "dosen")
+		}
+		mk.IDDosenPJ = cloneStr(mk.IDDosenPJ)
+	}
+	s.mataKuliah[mk.IDMK] = mk
+	return nil
+}
+
+// AddKelas menambahkan kelas kuliah (FK ke mata_kuliah, semester dan dosen pengampu)
+func (s *Store) AddKelas(k KelasKuliah) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.kelas[k.IDKelas]; ok {
+		return uniqueViolation("kelas_kuliah", "kelas_kuliah_pkey", "id_kelas", k.IDKelas)
+	}
+	if _, ok := s.mataKuliah[k.IDMK]; !ok {
+		return fkMissing("kelas_kuliah", "fk_kelas_mk", "id_mk", k.IDMK, "mata_kuliah")
+	}
+	if _, ok := s.semester[k.IDSemester]; !ok {
+		return fkMissing("kelas_kuliah", "fk_kelas_semester", "id_semester", k.IDSemester, "semester")
+	}
+	if _, ok := s.dosen[k.IDDosenPengampu]; !ok {
+		return fkMissing("kelas_kuliah", "fk_kelas_dosen", "id_dosen_pengampu", k.IDDosenPengampu, "dosen")
+	}
+	s.kelas[k.IDKelas] = k
+	return nil
+}
+
+// AddKRS menambahkan KRS ((id_mahasiswa, id_kelas) unik; FK ke mahasiswa, kelas dan semester;
+// ditolak bila mahasiswa punya cuti yang disetujui pada semester tersebut)
+func (s *Store) AddKRS(k KRS) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, x := range s.krs {
+		if x.IDMahasiswa == k.IDMahasiswa && x.IDKelas == k.IDKelas {
+			return uniqueViolation("krs", "uq_krs", "id_mahasiswa, id_kelas", k.IDMahasiswa+", "+k.IDKelas)
+		}
+	}
+	if _, ok := s.mahasiswa[k.IDMahasiswa]; !ok {
+		return fkMissing("krs", "fk_krs_mhs", "id_mahasiswa", k.IDMahasiswa, "mahasiswa")
+	}
+	if _, ok := s.kelas[k.IDKelas]; !ok {
+		return fkMissing("krs", "fk_krs_kelas", "id_kelas", k.IDKelas, "kelas_kuliah")
+	}
+	if _, ok := s.semester[k.IDSemester]; !ok {
+		return fkMissing("krs", "fk_krs_sem", "id_semester", k.IDSemester, "semester")
+	}
+	if s.openCuti(k.IDMahasiswa, k.IDSemester) == "Disetujui" {
+		return krsBlockedByCuti(k)
+	}
+	if s.krsLocked(k.IDMahasiswa, k.IDSemester) {
+		return krsFinal(k)
+	}
+	if k.Status == "" {
+		k.Status = "Diambil"
+	}
+	s.krsSeq++
+	k.IDKRS = s.krsSeq
+	s.krs = append(s.krs, k)
+	return nil
+}
+
+// AddNilai mengisi bobot nilai untuk KRS (id_mahasiswa, id_kelas)
+func (s *Store) AddNilai(idMahasiswa, idKelas string, bobot float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range s.krs {
+		if k.IDMahasiswa == idMahasiswa && k.IDKelas == idKelas {
+			s.nilai[k.IDKRS] = bobot
+			return nil
+		}
+	}
+	return fkMissing("nilai", "fk_nilai_krs", "id_krs", idMahasiswa+"/"+idKelas, "krs")
+}
+
+// Error bergaya PostgreSQL; Detail mengikuti format asli agar apperror.pgField dapat membaca nama kolom
+
+func uniqueViolation(table, constraint, column, value string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23505",
+		Message:        fmt.Sprintf("duplicate key value violates unique constraint %q", constraint),
+		Detail:         fmt.Sprintf("Key (%s)=(%s) already exists.", column, value),
+		TableName:      table,
+		ConstraintName: constraint,
+	}
+}
+
+func fkMissing(table, constraint, column, value, refTable string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23503",
+		Message:        fmt.Sprintf("insert or update on table %q violates foreign key constraint %q", table, constraint),
+		Detail:         fmt.Sprintf("Key (%s)=(%s) is not present in table %q.", column, value, refTable),
+		TableName:      table,
+		ConstraintName: constraint,
+	}
+}
+
+func stillReferenced(table, constraint, column, value, fromTable string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23503",
+		Message:        fmt.Sprintf("update or delete on table %q violates foreign key constraint %q on table %q", table, constraint, fromTable),
+		Detail:         fmt.Sprintf("Key (%s)=(%s) is still referenced from table %q.", column, value, fromTable),
+		TableName:      table,
+		ConstraintName: constraint,
+	}
+}
+
+func cloneStr(p *string) *string {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+func cloneTime(p *time.Time) *time.Time {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
@@ -0,0 +1,190 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+)
+
+func seed(t *testing.T) (*Store, *MahasiswaRepository) {
+	t.Helper()
+	ctx := context.Background()
+	s := NewStore()
+	if _, err := NewFakultasRepository(s).Create(ctx, &model.Fakultas{IDFakultas: "FAK00001", NamaFakultas: "Teknik"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewProdiRepository(s).Create(ctx, &model.Prodi{IDProdi: "PRD00001", IDFakultas: "FAK00001", NamaProdi: "Informatika", Jenjang: "S1", KodeProdi: "IF"}); err != nil {
+		t.Fatal(err)
+	}
+	r := NewMahasiswaRepository(s)
+	for i, nama := range []string{"Citra", "Andi", "Budi", "Dewi", "Eka"} {
+		m := &model.Mahasiswa{IDMahasiswa: fmt.Sprintf("20240000000%d", i), IDProdi: "PRD00001", NamaLengkap: nama, JenisKelamin: "L", TahunMasuk: 2020 + i, Status: "Aktif"}
+		if i%2 == 1 {
+			m.Status = "Cuti"
+		}
+		if _, err := r.Create(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s, r
+}
+
+func TestConstraintErrorsMatchPostgres(t *testing.T) {
+	ctx := context.Background()
+	s, r := seed(t)
+	nik := "3201010101010001"
+	if _, err := r.UpdatePatch(ctx, "202400000000", nil, &nik, nil, nil, nil, nil, nil, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantKind   error
+		wantFields map[string]string
+	}{
+		{name: "duplicate pk", err: second(r.Create(ctx, &model.Mahasiswa{IDMahasiswa: "202400000001", IDProdi: "PRD00001"})),
+			wantKind: apperror.ErrConflict, wantFields: map[string]string{"id_mahasiswa": apperror.CodeTaken}},
+		{name: "duplicate nik", err: second(r.Create(ctx, &model.Mahasiswa{IDMahasiswa: "202400000009", IDProdi: "PRD00001", NIK: &nik})),
+			wantKind: apperror.ErrConflict, wantFields: map[string]string{"nik": apperror.CodeTaken}},
+		{name: "missing prodi", err: second(r.Create(ctx, &model.Mahasiswa{IDMahasiswa: "202400000009", IDProdi: "PRD99999"})),
+			wantKind: apperror.ErrUnprocessable, wantFields: map[string]string{"id_prodi": apperror.CodeNotFound}},
+		{name: "duplicate kode prodi", err: second(NewProdiRepository(s).Create(ctx, &model.Prodi{IDProdi: "PRD00002", IDFakultas: "FAK00001", KodeProdi: "IF"})),
+			wantKind: apperror.ErrConflict, wantFields: map[string]string{"kode_prodi": apperror.CodeTaken}},
+		{name: "delete referenced prodi", err: NewProdiRepository(s).Delete(ctx, "PRD00001"),
+			wantKind: apperror.ErrConflict, wantFields: map[string]string{"id_prodi": "still_referenced"}},
+		{name: "not found", err: second(r.GetByID(ctx, "209900000000")), wantKind: apperror.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := apperror.FromDB(tt.err)
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("error = %v, want kind %v", err, tt.wantKind)
+			}
+			if got := apperror.FieldsOf(err); tt.wantFields != nil && !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func second[T any](_ T, err error) error { return err }
+
+func TestMahasiswaListFiltersAndKeyset(t *testing.T) {
+	ctx := context.Background()
+	_, r := seed(t)
+	names := func(ms []model.Mahasiswa) []string {
+		out := []string{}
+		for _, m := range ms {
+			out = append(out, m.NamaLengkap)
+		}
+		return out
+	}
+
+	filters, err := repo.ParseFilters(map[string][]string{
+		"status":      {"in:Aktif"},
+		"angkatan":    {"gte:2021"},
+		"id_fakultas": {"FAK00001"},
+	}, repo.MahasiswaFilterFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.List(ctx, "", repo.MatchExact, filters, 0, 0, "tahun_masuk DESC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Eka", "Budi"}; !reflect.DeepEqual(names(got), want) {
+		t.Errorf("List = %v, want %v", names(got), want)
+	}
+	if n, _ := r.Count(ctx, "i", repo.MatchExact, nil); n != 4 { // Citra, Andi, Budi, Dewi
+		t.Errorf("Count(q=i) = %d, want 4", n)
+	}
+
+	// keyset: gabungan seluruh halaman harus sama dengan urutan penuh
+	var (
+		all     []string
+		afterV  any
+		afterID *string
+	)
+	for {
+		page, err := r.ListKeyset(ctx, "", repo.MatchExact, nil, 2, "nama_lengkap", false, afterV, afterID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		all = append(all, names(page)...)
+		last := page[len(page)-1]
+		afterV, afterID = last.NamaLengkap, &last.IDMahasiswa
+	}
+	if want := []string{"Andi", "Budi", "Citra", "Dewi", "Eka"}; !reflect.DeepEqual(all, want) {
+		t.Errorf("keyset pages = %v, want %v", all, want)
+	}
+}
+
+func TestParseFiltersCodes(t *testing.T) {
+	tests := []struct {
+		name, field, expr, want string
+	}{
+		{"integer", "angkatan", "gte:dua", repo.CodeInvalidInteger},
+		{"date", "tanggal_lahir", "2024-13-01", repo.CodeInvalidDate},
+		{"timestamp", "created_at", "kemarin", repo.CodeInvalidDate},
+		{"enum", "status", "in:Aktif,Libur", repo.CodeInvalidChoice},
+		{"is value", "email", "is:kosong", repo.CodeInvalidChoice},
+		{"range on text", "email", "gt:a", repo.CodeOpNotSupported},
+		{"is not nullable", "angkatan", "is:null", repo.CodeOpNotSupported},
+		{"between arity", "angkatan", "between:2020", apperror.CodeInvalid},
+		{"unknown field", "hobi", "catur", repo.CodeUnknownField},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := repo.ParseFilters(map[string][]string{tt.field: {tt.expr}}, repo.MahasiswaFilterFields)
+			if !errors.Is(err, apperror.ErrInvalidInput) {
+				t.Fatalf("error = %v, want invalid input", err)
+			}
+			if got := apperror.FieldsOf(err)[tt.field]; got != tt.want {
+				t.Errorf("fields[%s] = %q, want %q", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoRollsBackOnError(t *testing.T) {
+	ctx := context.Background()
+	s, r := seed(t)
+	boom := errors.New("boom")
+
+	err := s.Do(ctx, func(ctx context.Context) error {
+		if err := s.LockKey(ctx, "mahasiswa.email", "x@kampus.ac.id"); err != nil {
+			return err
+		}
+		if err := r.LockByID(ctx, "202400000000"); err != nil {
+			return err
+		}
+		if err := r.Delete(ctx, "202400000000"); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("Do error = %v, want boom", err)
+	}
+	if _, err := r.GetByID(ctx, "202400000000"); err != nil {
+		t.Errorf("row deleted inside a failed Do must be restored: %v", err)
+	}
+	if err := s.LockKey(ctx, "mahasiswa.email", "x@kampus.ac.id"); err == nil {
+		t.Error("LockKey outside Do must fail")
+	}
+	if err := r.LockByID(ctx, "209900000000"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("LockByID missing row = %v, want pgx.ErrNoRows", err)
+	}
+}
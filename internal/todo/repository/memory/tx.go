@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	authmodel "pencatatan-data-mahasiswa/internal/todo/model/auth"
+)
+
+type txKey struct{}
+
+var errNoTx = errors.New("memory: LockKey called outside Store.Do")
+
+// snapshot adalah salinan seluruh tabel untuk rollback
+type snapshot struct {
+	fakultas   map[string]model.Fakultas
+	prodi      map[string]model.Prodi
+	dosen      map[string]model.Dosen
+	mahasiswa  map[string]model.Mahasiswa
+	semester   map[string]model.Semester
+	mataKuliah map[string]MataKuliah
+	kelas      map[string]KelasKuliah
+	krs        []KRS
+	krsSeq     int64
+	nilai      map[int64]float64
+	users      map[int64]authmodel.User
+	userSeq    int64
+
+	statusHistory    []model.MahasiswaStatusHistory
+	statusHistorySeq int64
+
+	cuti       []cutiRow
+	cutiSeq    int64
+	cutiPolicy map[string]model.CutiPolicy
+
+	pembimbing      []model.PembimbingAkademik
+	pembimbingSeq   int64
+	krsPengajuan    []model.KRSPengajuan
+	krsPengajuanSeq int64
+
+	kurikulum    []model.Kurikulum
+	kurikulumSeq int64
+	kurikulumMK  []kurikulumMKRow
+	prasyarat    []prasyaratRow
+}
+
+// Do meniru db.TxManager.Do: transaksi berjalan bergantian dan seluruh tabel dikembalikan
+// ke keadaan sebelum fn bila fn gagal atau panic. Panggilan bertingkat ikut transaksi terluar.
+func (s *Store) Do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if ctx.Value(txKey{}) != nil {
+		return fn(ctx)
+	}
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	snap := s.snapshot()
+	committed := false
+	defer func() {
+		if !committed {
+			s.restore(snap)
+		}
+	}()
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// LockKey tidak perlu mengunci apa pun karena Do sudah serial; tetap menolak pemanggilan di luar Do
+// agar service yang lupa membuka transaksi ketahuan di unit test
+func (s *Store) LockKey(ctx context.Context, scope, key string) error {
+	if ctx.Value(txKey{}) == nil {
+		return errNoTx
+	}
+	return nil
+}
+
+func (s *Store) snapshot() snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return snapshot{
+		fakultas:   maps.Clone(s.fakultas),
+		prodi:      maps.Clone(s.prodi),
+		dosen:      maps.Clone(s.dosen),
+		mahasiswa:  maps.Clone(s.mahasiswa),
+		semester:   maps.Clone(s.semester),
+		mataKuliah: maps.Clone(s.mataKuliah),
+		kelas:      maps.Clone(s.kelas),
+		krs:        slices.Clone(s.krs),
+		krsSeq:     s.krsSeq,
+		nilai:      maps.Clone(s.nilai),
+		users:      maps.Clone(s.users),
+		userSeq:    s.userSeq,
+
+		statusHistory:    slices.Clone(s.statusHistory),
+		statusHistorySeq: s.statusHistorySeq,
+
+		cuti:       slices.Clone(s.cuti),
+		cutiSeq:    s.cutiSeq,
+		cutiPolicy: maps.Clone(s.cutiPolicy),
+
+		pembimbing:      slices.Clone(s.pembimbing),
+		pembimbingSeq:   s.pembimbingSeq,
+		krsPengajuan:    slices.Clone(s.krsPengajuan),
+		krsPengajuanSeq: s.krsPengajuanSeq,
+
+		kurikulum:    slices.Clone(s.kurikulum),
+		kurikulumSeq: s.kurikulumSeq,
+		kurikulumMK:  slices.Clone(s.kurikulumMK),
+		prasyarat:    slices.Clone(s.prasyarat),
+	}
+}
+
+func (s *Store) restore(snap snapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fakultas, s.prodi, s.dosen, s.mahasiswa, s.semester = snap.fakultas, snap.prodi, snap.dosen, snap.mahasiswa, snap.semester
+	s.mataKuliah, s.kelas, s.krs, s.krsSeq, s.nilai = snap.mataKuliah, snap.kelas, snap.krs, snap.krsSeq, snap.nilai
+	s.users, s.userSeq = snap.users, snap.userSeq
+	s.statusHistory, s.statusHistorySeq = snap.statusHistory, snap.statusHistorySeq
+	s.cuti, s.cutiSeq, s.cutiPolicy = snap.cuti, snap.cutiSeq, snap.cutiPolicy
+	s.pembimbing, s.pembimbingSeq = snap.pembimbing, snap.pembimbingSeq
+	s.krsPengajuan, s.krsPengajuanSeq = snap.krsPengajuan, snap.krsPengajuanSeq
+	s.kurikulum, s.kurikulumSeq, s.kurikulumMK, s.prasyarat = snap.kurikulum, snap.kurikulumSeq, snap.kurikulumMK, snap.prasyarat
+}
@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	model "pencatatan-data-mahasiswa/internal/todo/model/auth"
+)
+
+// UserRepository adalah padanan in-memory dari repository auth (tabel users)
+type UserRepository struct {
+	s *Store
+}
+
+func NewUserRepository(s *Store) *UserRepository {
+	return &UserRepository{s: s}
+}
+
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, u := range r.s.users {
+		if u.Username == username {
+			return &u, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id int64) (*model.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	u, ok := r.s.users[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &u, nil
+}
+
+func (r *UserRepository) GetByActivationHash(ctx context.Context, hash string) (*model.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, u := range r.s.users {
+		if u.ActivationTokenHash != nil && *u.ActivationTokenHash == hash {
+			return &u, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (r *UserRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	_, err := r.GetByUsername(ctx, username)
+	return err == nil, nil
+}
+
+func (r *UserRepository) ExistsMahasiswaByID(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	_, ok := r.s.mahasiswa[id]
+	return ok, nil
+}
+
+func (r *UserRepository) ExistsDosenByID(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	_, ok := r.s.dosen[id]
+	return ok, nil
+}
+
+func (r *UserRepository) ExistsProdiByID(ctx context.Context, id string) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	_, ok := r.s.prodi[id]
+	return ok, nil
+}
+
+// Create menyimpan user baru dengan id_user berurutan; username unik, ref_id kosong menjadi NULL
+func (r *UserRepository) Create(ctx context.Context, u *model.User) (*model.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, x := range r.s.users {
+		if x.Username == u.Username {
+			return nil, uniqueViolation("users", "users_username_key", "username", u.Username)
+		}
+	}
+	r.s.userSeq++
+	now := r.s.Now()
+	out := model.User{
+		IDUser:       r.s.userSeq,
+		Username:     u.Username,
+		PasswordHash: u.PasswordHash,
+		Role:         u.Role,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+
+		MustChangePassword:  u.MustChangePassword,
+		ActivationTokenHash: cloneStr(u.ActivationTokenHash),
+		ActivationExpiresAt: cloneTime(u.ActivationExpiresAt),
+	}
+	if u.RefID != nil && *u.RefID != "" {
+		out.RefID = cloneStr(u.RefID)
+	}
+	r.s.users[out.IDUser] = out
+	return &out, nil
+}
+
+// SetPassword meniru UPDATE repository auth: token aktivasi ikut dihapus
+func (r *UserRepository) SetPassword(ctx context.Context, id int64, hash string, mustChange bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	u, ok := r.s.users[id]
+	if !ok {
+		return pgx.ErrNoRows
+	}
+	u.PasswordHash = hash
+	u.MustChangePassword = mustChange
+	u.ActivationTokenHash, u.ActivationExpiresAt = nil, nil
+	u.UpdatedAt = r.s.Now()
+	r.s.users[id] = u
+	return nil
+}
@@ -0,0 +1,41 @@
+package admin
+
+import "time"
+
+// defaultLocation dipakai Clock tanpa Location; offset tetap bila tzdata tidak tersedia
+var defaultLocation = func() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Jakarta"); err == nil {
+		return loc
+	}
+	return time.FixedZone("WIB", 7*60*60)
+}()
+
+// Clock menentukan "hari ini" menurut zona waktu kampus, bukan UTC. Nilai nol memakai time.Now
+// dan Asia/Jakarta.
+type Clock struct {
+	Now      func() time.Time
+	Location *time.Location
+}
+
+// Today mengembalikan tanggal lokal hari ini sebagai tengah malam UTC, sama dengan kolom DATE
+// yang dibaca pgx, sehingga aman dibandingkan dengan tanggal semester/cuti
+func (c Clock) Today() time.Time {
+	return c.Date(c.now())
+}
+
+// Date mengembalikan tanggal lokal t sebagai tengah malam UTC
+func (c Clock) Date(t time.Time) time.Time {
+	loc := c.Location
+	if loc == nil {
+		loc = defaultLocation
+	}
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func (c Clock) now() time.Time {
+	if c.Now == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"testing"
+	"time"
+)
+
+// TestClockToday: "hari ini" mengikuti tanggal lokal, bukan tanggal UTC
+func TestClockToday(t *testing.T) {
+	cases := []struct {
+		name string
+		now  string
+		loc  *time.Location
+		want string
+	}{
+		{"WIB dini hari masih tanggal UTC kemarin", "2024-09-01T23:30:00Z", nil, "2024-09-02"},
+		{"WIB siang", "2024-09-02T05:00:00Z", nil, "2024-09-02"},
+		{"lokasi dari konfigurasi", "2024-09-01T23:30:00Z", time.UTC, "2024-09-01"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			now, _ := time.Parse(time.RFC3339, tc.now)
+			got := Clock{Now: func() time.Time { return now }, Location: tc.loc}.Today()
+			if got.Format(time.DateOnly) != tc.want || got.Location() != time.UTC || !got.Equal(got.Truncate(24*time.Hour)) {
+				t.Errorf("Today() = %v, want %s 00:00 UTC", got, tc.want)
+			}
+		})
+	}
+}
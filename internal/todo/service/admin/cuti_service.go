@@ -0,0 +1,587 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+	"pencatatan-data-mahasiswa/internal/tracing"
+)
+
+// Status pengajuan cuti
+const (
+	CutiDiajukan   = "Diajukan"
+	CutiDisetujui  = "Disetujui"
+	CutiDitolak    = "Ditolak"
+	CutiDibatalkan = "Dibatalkan"
+)
+
+// Kode detail field pengajuan cuti
+const (
+	codeNotEligible      = "not_eligible"
+	codeRegularOnly      = "regular_semester_only"
+	codeNoSchedule       = "no_schedule"
+	codeSemesterEnded    = "semester_ended"
+	codeAlreadySubmitted = "already_submitted"
+	codeLimitReached     = "limit_reached"
+	codeNotPending       = "not_pending"
+	codeInvalidType      = "invalid_type"
+	codeKRSExists        = "krs_exists"
+)
+
+// cutiLampiranTypes adalah tipe lampiran yang diterima (dideteksi dari isi file, bukan nama)
+var cutiLampiranTypes = map[string]struct{}{"application/pdf": {}, "image/jpeg": {}, "image/png": {}}
+
+// systemActor dicatat pada riwayat status untuk perubahan oleh Sync
+var systemActor = Actor{Username: "system", Role: "system"}
+
+// CutiOptions: DefaultMaxSemesters untuk prodi tanpa aturan sendiri, MaxLampiranBytes batas ukuran
+// lampiran, Clock sumber "hari ini" (test bisa menentukannya lewat Clock.Now)
+type CutiOptions struct {
+	DefaultMaxSemesters int
+	MaxLampiranBytes    int64
+	Clock               Clock
+}
+
+// CutiSubmission adalah pengajuan cuti dari mahasiswa
+type CutiSubmission struct {
+	IDSemester   string
+	Alasan       string
+	LampiranNama string
+	Lampiran     []byte
+}
+
+// CutiSyncResult: jumlah cuti yang mulai berlaku dan yang berakhir pada satu kali Sync
+type CutiSyncResult struct {
+	Applied  int
+	Finished int
+}
+
+// CutiService mengelola pengajuan cuti: diajukan mahasiswa, diproses operator prodinya,
+// lalu diterapkan ke status mahasiswa lewat MahasiswaService.Transition
+type CutiService struct {
+	repo      CutiRepository
+	mahasiswa *MahasiswaService
+	tx        UnitOfWork
+	opt       CutiOptions
+}
+
+func NewCutiService(r CutiRepository, mahasiswa *MahasiswaService, tx UnitOfWork, opt CutiOptions) *CutiService {
+	return &CutiService{repo: r, mahasiswa: mahasiswa, tx: tx, opt: opt}
+}
+
+func (s *CutiService) today() time.Time {
+	return s.opt.Clock.Today()
+}
+
+var (
+	errCutiNotPending = &apperror.Error{
+		Kind:   apperror.ErrConflict,
+		Code:   i18n.MsgCutiAlreadyProcessed,
+		Fields: map[string]string{"status": codeNotPending},
+	}
+	errCutiLimit = &apperror.Error{
+		Kind:   ErrUnprocessable,
+		Code:   i18n.MsgCutiLimitReached,
+		Fields: map[string]string{"id_semester": codeLimitReached},
+	}
+	errCutiNotEligible = &apperror.Error{
+		Kind:   ErrUnprocessable,
+		Code:   i18n.MsgCutiNotEligible,
+		Fields: map[string]string{"status": codeNotEligible},
+	}
+	errCutiKRSExists = &apperror.Error{
+		Kind:   apperror.ErrConflict,
+		Code:   i18n.MsgCutiKRSExists,
+		Fields: map[string]string{"id_semester": codeKRSExists},
+	}
+)
+
+// visible: mahasiswa hanya melihat pengajuannya sendiri, operator hanya prodi ref_id-nya; operator
+// tanpa ref_id tidak mengelola cuti
+func (a Actor) visible(c *model.Cuti) bool {
+	switch a.Role {
+	case "admin":
+		return true
+	case "operator":
+		return a.managesProdi(c.IDProdi)
+	case "mahasiswa":
+		return a.RefID != nil && *a.RefID == c.IDMahasiswa
+	}
+	return false
+}
+
+// scope mengembalikan filter tambahan sesuai visible
+func (a Actor) scope() ([]repo.Filter, error) {
+	switch a.Role {
+	case "admin":
+		return nil, nil
+	case "operator":
+		if a.RefID != nil {
+			return []repo.Filter{{Column: repo.CutiFilterFields["id_prodi"].Column, Op: "eq", Values: []any{*a.RefID}}}, nil
+		}
+	case "mahasiswa":
+		if a.RefID != nil {
+			return []repo.Filter{{Column: repo.CutiFilterFields["id_mahasiswa"].Column, Op: "eq", Values: []any{*a.RefID}}}, nil
+		}
+	}
+	return nil, errForbidden
+}
+
+// Submit menyimpan pengajuan cuti mahasiswa yang login untuk satu semester
+func (s *CutiService) Submit(ctx context.Context, in CutiSubmission, actor Actor) (*model.Cuti, error) {
+	ctx, span := tracing.Start(ctx, "CutiService.Submit")
+	defer span.End()
+
+	if actor.Role != "mahasiswa" || actor.RefID == nil {
+		return nil, errForbidden
+	}
+	in.IDSemester = strings.TrimSpace(in.IDSemester)
+	in.Alasan = strings.TrimSpace(in.Alasan)
+	in.LampiranNama = strings.TrimSpace(filepath.Base(in.LampiranNama))
+
+	fe := apperror.Fields{}
+	if in.IDSemester == "" {
+		fe.Add("id_semester", apperror.CodeRequired)
+	} else if !semIDPattern.MatchString(in.IDSemester) {
+		fe.Add("id_semester", codeSemesterID)
+	}
+	checkLength(fe, "alasan", in.Alasan, 10, 1000)
+	tipe := ""
+	switch {
+	case len(in.Lampiran) == 0:
+		fe.Add("lampiran", apperror.CodeRequired)
+	case len(in.LampiranNama) > 255:
+		fe.Add("lampiran", codeMax(255))
+	default:
+		tipe = strings.SplitN(http.DetectContentType(in.Lampiran), ";", 2)[0]
+		if _, ok := cutiLampiranTypes[tipe]; !ok {
+			fe.Add("lampiran", codeInvalidType)
+		}
+	}
+	if err := fe.Err(); err != nil {
+		return nil, err
+	}
+	if s.opt.MaxLampiranBytes > 0 && int64(len(in.Lampiran)) > s.opt.MaxLampiranBytes {
+		return nil, &apperror.Error{
+			Kind:   apperror.ErrTooLarge,
+			Code:   i18n.MsgPayloadTooLarge,
+			Fields: map[string]string{"lampiran": fmt.Sprintf("max_%d_bytes", s.opt.MaxLampiranBytes)},
+		}
+	}
+
+	nim := *actor.RefID
+	return inTx(ctx, s.tx, func(ctx context.Context) (*model.Cuti, error) {
+		// lock mahasiswa: pengajuan bersamaan tidak boleh sama-sama lolos cek batas
+		if err := s.mahasiswa.repo.LockByID(ctx, nim); err != nil {
+			return nil, err
+		}
+		m, err := s.mahasiswa.repo.GetByID(ctx, nim)
+		if err != nil {
+			return nil, err
+		}
+		if m.Status != StatusAktif && m.Status != StatusCuti {
+			return nil, errCutiNotEligible
+		}
+		sem, err := s.semesterOpen(ctx, in.IDSemester)
+		if err != nil {
+			return nil, err
+		}
+		if st, err := s.repo.OpenStatus(ctx, nim, sem.IDSemester); err != nil {
+			return nil, err
+		} else if st != "" {
+			return nil, apperror.Field(apperror.ErrConflict, "id_semester", codeAlreadySubmitted)
+		}
+		if err := s.checkLimit(ctx, m, 0); err != nil {
+			return nil, err
+		}
+		return s.repo.Create(ctx, &model.Cuti{
+			IDMahasiswa:  nim,
+			IDSemester:   sem.IDSemester,
+			Alasan:       in.Alasan,
+			LampiranNama: in.LampiranNama,
+			LampiranTipe: tipe,
+		}, in.Lampiran)
+	})
+}
+
+// semesterOpen memastikan semester reguler, berjadwal dan belum berakhir
+func (s *CutiService) semesterOpen(ctx context.Context, id string) (*model.Semester, error) {
+	sem, err := s.repo.GetSemester(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperror.Field(ErrUnprocessable, "id_semester", "not_found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case sem.Term == "Antara":
+		return nil, apperror.Field(ErrUnprocessable, "id_semester", codeRegularOnly)
+	case sem.TanggalMulai == nil || sem.TanggalSelesai == nil:
+		return nil, apperror.Field(ErrUnprocessable, "id_semester", codeNoSchedule)
+	case sem.TanggalSelesai.Before(s.today()):
+		return nil, apperror.Field(ErrUnprocessable, "id_semester", codeSemesterEnded)
+	}
+	return sem, nil
+}
+
+// checkLimit menolak bila pengajuan aktif (Diajukan/Disetujui) selain excludeID sudah mencapai batas prodi
+func (s *CutiService) checkLimit(ctx context.Context, m *model.Mahasiswa, excludeID int64) error {
+	policy, err := s.policy(ctx, m.IDProdi)
+	if err != nil {
+		return err
+	}
+	n, err := s.repo.CountActive(ctx, m.IDMahasiswa, excludeID)
+	if err != nil {
+		return err
+	}
+	if n >= policy.MaksSemester {
+		return errCutiLimit
+	}
+	return nil
+}
+
+// List mengembalikan pengajuan cuti yang boleh dilihat actor
+func (s *CutiService) List(ctx context.Context, rawFilters map[string][]string, limit, offset int, orderBy string, actor Actor) ([]model.Cuti, int64, error) {
+	ctx, span := tracing.Start(ctx, "CutiService.List")
+	defer span.End()
+
+	if limit < 0 || offset < 0 {
+		return nil, 0, ErrInvalidInput
+	}
+	filters, err := repo.ParseFilters(rawFilters, repo.CutiFilterFields)
+	if err != nil {
+		return nil, 0, err
+	}
+	scope, err := actor.scope()
+	if err != nil {
+		return nil, 0, err
+	}
+	filters = append(filters, scope...)
+
+	total, err := s.repo.Count(ctx, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := s.repo.List(ctx, filters, limit, offset, orderBy)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, total, nil
+}
+
+// Get mengembalikan satu pengajuan; pengajuan di luar jangkauan actor dianggap tidak ada
+func (s *CutiService) Get(ctx context.Context, id int64, actor Actor) (*model.Cuti, error) {
+	ctx, span := tracing.Start(ctx, "CutiService.Get")
+	defer span.End()
+
+	c, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.visible(c) {
+		return nil, pgx.ErrNoRows
+	}
+	return c, nil
+}
+
+// Lampiran mengembalikan berkas pendukung pengajuan
+func (s *CutiService) Lampiran(ctx context.Context, id int64, actor Actor) (*model.CutiLampiran, error) {
+	ctx, span := tracing.Start(ctx, "CutiService.Lampiran")
+	defer span.End()
+
+	if _, err := s.Get(ctx, id, actor); err != nil {
+		return nil, err
+	}
+	return s.repo.GetLampiran(ctx, id)
+}
+
+// Cancel membatalkan pengajuan milik mahasiswa selama belum diproses
+func (s *CutiService) Cancel(ctx context.Context, id int64, actor Actor) (*model.Cuti, error) {
+	ctx, span := tracing.Start(ctx, "CutiService.Cancel")
+	defer span.End()
+
+	return inTx(ctx, s.tx, func(ctx context.Context) (*model.Cuti, error) {
+		c, err := s.lockVisible(ctx, id, actor)
+		if err != nil {
+			return nil, err
+		}
+		if actor.Role != "mahasiswa" {
+			return nil, errForbidden
+		}
+		if c.Status != CutiDiajukan {
+			return nil, errCutiNotPending
+		}
+		return s.repo.SetStatus(ctx, id, CutiDibatalkan, nil, nil)
+	})
+}
+
+// Review menyetujui atau menolak pengajuan (admin atau operator prodinya). Penolakan wajib catatan; persetujuan
+// ditolak (409) selama mahasiswa masih punya KRS pada semester cuti.
+// Pengajuan yang disetujui untuk semester yang sudah berjalan langsung diterapkan; selain itu
+// diterapkan Sync saat semesternya dimulai.
+func (s *CutiService) Review(ctx context.Context, id int64, approve bool, catatan *string, actor Actor) (*model.Cuti, error) {
+	ctx, span := tracing.Start(ctx, "CutiService.Review")
+	defer span.End()
+
+	if actor.Role != "admin" && (actor.Role != "operator" || actor.RefID == nil) {
+		return nil, errForbidden
+	}
+	if catatan != nil {
+		v := strings.TrimSpace(*catatan)
+		catatan = &v
+		if v == "" {
+			catatan = nil
+		} else if len(v) > 1000 {
+			return nil, apperror.Field(ErrInvalidInput, "catatan", codeMax(1000))
+		}
+	}
+	if !approve && catatan == nil {
+		return nil, apperror.Field(ErrInvalidInput, "catatan", apperror.CodeRequired)
+	}
+
+	return inTx(ctx, s.tx, func(ctx context.Context) (*model.Cuti, error) {
+		c, err := s.lockVisible(ctx, id, actor)
+		if err != nil {
+			return nil, err
+		}
+		if c.Status != CutiDiajukan {
+			return nil, errCutiNotPending
+		}
+		var reviewer *int64
+		if actor.IDUser != 0 {
+			reviewer = &actor.IDUser
+		}
+		if !approve {
+			return s.repo.SetStatus(ctx, id, CutiDitolak, catatan, reviewer)
+		}
+
+		// kondisi saat pengajuan bisa berubah sebelum diproses: cek ulang status, semester dan batas
+		if err := s.mahasiswa.repo.LockByID(ctx, c.IDMahasiswa); err != nil {
+			return nil, err
+		}
+		m, err := s.mahasiswa.repo.GetByID(ctx, c.IDMahasiswa)
+		if err != nil {
+			return nil, err
+		}
+		if m.Status != StatusAktif && m.Status != StatusCuti {
+			return nil, errCutiNotEligible
+		}
+		sem, err := s.semesterOpen(ctx, c.IDSemester)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkLimit(ctx, m, c.IDCuti); err != nil {
+			return nil, err
+		}
+		// trigger krs_block_cuti hanya menahan KRS baru; KRS yang sudah ada harus dikosongkan dulu
+		hasKRS, err := s.repo.HasKRS(ctx, c.IDMahasiswa, c.IDSemester)
+		if err != nil {
+			return nil, err
+		}
+		if hasKRS {
+			return nil, errCutiKRSExists
+		}
+		c, err = s.repo.SetStatus(ctx, id, CutiDisetujui, catatan, reviewer)
+		if err != nil {
+			return nil, err
+		}
+		if !sem.TanggalMulai.After(s.today()) {
+			if err := s.apply(ctx, c, sem, actor); err != nil {
+				return nil, err
+			}
+			return s.repo.GetByID(ctx, id)
+		}
+		return c, nil
+	})
+}
+
+func (s *CutiService) lockVisible(ctx context.Context, id int64, actor Actor) (*model.Cuti, error) {
+	if err := s.repo.LockByID(ctx, id); err != nil {
+		return nil, err
+	}
+	c, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.visible(c) {
+		return nil, pgx.ErrNoRows
+	}
+	return c, nil
+}
+
+// effectiveDate: tanggal mulai semester, tetapi tidak sebelum transisi status terakhir
+func (s *CutiService) effectiveDate(ctx context.Context, nim string, start time.Time) (time.Time, error) {
+	last, err := s.mahasiswa.repo.LatestStatusDate(ctx, nim)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if last != nil && last.After(start) {
+		return *last, nil
+	}
+	return start, nil
+}
+
+// apply mengubah status mahasiswa ke Cuti untuk pengajuan c. Mahasiswa yang sudah Cuti (cuti
+// berturut-turut) cukup ditandai; status lain (mis. Lulus) dibiarkan dan tidak ditandai.
+func (s *CutiService) apply(ctx context.Context, c *model.Cuti, sem *model.Semester, actor Actor) error {
+	m, err := s.mahasiswa.repo.GetByID(ctx, c.IDMahasiswa)
+	if err != nil {
+		return err
+	}
+	switch m.Status {
+	case StatusCuti:
+	case StatusAktif:
+		date, err := s.effectiveDate(ctx, m.IDMahasiswa, *sem.TanggalMulai)
+		if err != nil {
+			return err
+		}
+		in := StatusTransition{
+			Status:         StatusCuti,
+			TanggalEfektif: date,
+			Alasan:         fmt.Sprintf("Cuti akademik semester %s (pengajuan #%d)", sem.IDSemester, c.IDCuti),
+		}
+		if _, err := s.mahasiswa.Transition(ctx, m.IDMahasiswa, in, actor); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	return s.repo.MarkApplied(ctx, c.IDCuti)
+}
+
+// finish mengakhiri cuti c karena semester current sudah dimulai: status kembali Aktif kecuali
+// mahasiswa punya cuti yang disetujui untuk semester current
+func (s *CutiService) finish(ctx context.Context, c *model.Cuti, current *model.Semester) error {
+	if err := s.mahasiswa.repo.LockByID(ctx, c.IDMahasiswa); err != nil {
+		return err
+	}
+	next, err := s.repo.OpenStatus(ctx, c.IDMahasiswa, current.IDSemester)
+	if err != nil {
+		return err
+	}
+	m, err := s.mahasiswa.repo.GetByID(ctx, c.IDMahasiswa)
+	if err != nil {
+		return err
+	}
+	if next != CutiDisetujui && m.Status == StatusCuti {
+		date, err := s.effectiveDate(ctx, m.IDMahasiswa, *current.TanggalMulai)
+		if err != nil {
+			return err
+		}
+		in := StatusTransition{
+			Status:         StatusAktif,
+			TanggalEfektif: date,
+			Alasan:         fmt.Sprintf("Masa cuti semester %s berakhir (pengajuan #%d)", c.IDSemester, c.IDCuti),
+		}
+		if _, err := s.mahasiswa.Transition(ctx, m.IDMahasiswa, in, systemActor); err != nil {
+			return err
+		}
+	}
+	return s.repo.MarkFinished(ctx, c.IDCuti)
+}
+
+// Sync menerapkan cuti yang disetujui untuk semester yang sedang berjalan pada today dan
+// mengembalikan status Aktif bagi mahasiswa yang masa cutinya sudah lewat. Setiap pengajuan
+// diproses dalam transaksinya sendiri; kegagalan satu pengajuan tidak menghentikan yang lain.
+func (s *CutiService) Sync(ctx context.Context, today time.Time) (CutiSyncResult, error) {
+	ctx, span := tracing.Start(ctx, "CutiService.Sync")
+	defer span.End()
+
+	var res CutiSyncResult
+	current, err := s.repo.CurrentSemester(ctx, s.opt.Clock.Date(today))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return res, nil
+	}
+	if err != nil {
+		return res, err
+	}
+
+	var errs []error
+	toApply, err := s.repo.ListToApply(ctx, current.IDSemester)
+	if err != nil {
+		return res, err
+	}
+	for i := range toApply {
+		c := &toApply[i]
+		err := s.tx.Do(ctx, func(ctx context.Context) error {
+			if err := s.mahasiswa.repo.LockByID(ctx, c.IDMahasiswa); err != nil {
+				return err
+			}
+			return s.apply(ctx, c, current, systemActor)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cuti %d: %w", c.IDCuti, err))
+			continue
+		}
+		res.Applied++
+	}
+
+	toFinish, err := s.repo.ListToFinish(ctx, current.IDSemester)
+	if err != nil {
+		return res, err
+	}
+	for i := range toFinish {
+		c := &toFinish[i]
+		if err := s.tx.Do(ctx, func(ctx context.Context) error { return s.finish(ctx, c, current) }); err != nil {
+			errs = append(errs, fmt.Errorf("cuti %d: %w", c.IDCuti, err))
+			continue
+		}
+		res.Finished++
+	}
+	return res, errors.Join(errs...)
+}
+
+// Policy mengembalikan batas cuti prodi, atau nilai default konfigurasi bila belum diatur
+func (s *CutiService) Policy(ctx context.Context, idProdi string) (*model.CutiPolicy, error) {
+	ctx, span := tracing.Start(ctx, "CutiService.Policy")
+	defer span.End()
+
+	idProdi = strings.TrimSpace(idProdi)
+	if ok, err := s.mahasiswa.repo.ExistsProdi(ctx, idProdi); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return s.policy(ctx, idProdi)
+}
+
+func (s *CutiService) policy(ctx context.Context, idProdi string) (*model.CutiPolicy, error) {
+	p, err := s.repo.GetPolicy(ctx, idProdi)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &model.CutiPolicy{IDProdi: idProdi, MaksSemester: s.opt.DefaultMaxSemesters, Default: true}, nil
+	}
+	return p, err
+}
+
+// SetPolicy mengatur batas semester cuti prodi; operator hanya untuk prodi ref_id-nya
+func (s *CutiService) SetPolicy(ctx context.Context, idProdi string, maks int, actor Actor) (*model.CutiPolicy, error) {
+	ctx, span := tracing.Start(ctx, "CutiService.SetPolicy")
+	defer span.End()
+
+	idProdi = strings.TrimSpace(idProdi)
+	if actor.Role == "operator" && !actor.managesProdi(idProdi) {
+		return nil, errForbidden
+	}
+	if maks < 0 || maks > 14 {
+		return nil, apperror.Field(ErrInvalidInput, "maks_semester", "range_0_14")
+	}
+	return inTx(ctx, s.tx, func(ctx context.Context) (*model.CutiPolicy, error) {
+		if ok, err := s.mahasiswa.repo.ExistsProdi(ctx, idProdi); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, pgx.ErrNoRows
+		}
+		return s.repo.UpsertPolicy(ctx, idProdi, maks)
+	})
+}
@@ -0,0 +1,287 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	"pencatatan-data-mahasiswa/internal/todo/repository/memory"
+)
+
+var (
+	pdf            = []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\n")
+	mahasiswaActor = Actor{IDUser: 3, Username: fixMahasiswa, Role: "mahasiswa", RefID: strPtr(fixMahasiswa)}
+	prodiOperator  = Actor{IDUser: 4, Username: "operator-if", Role: "operator", RefID: strPtr(fixProdi)}
+	otherOperator  = Actor{IDUser: 5, Username: "operator-si", Role: "operator", RefID: strPtr("PRD00002")}
+)
+
+const fixSemesterGenap = "20242"
+
+// newCutiService menyiapkan semester 20241 (Sep-Jan) dan 20242 (Feb-Jul) dengan "hari ini" = today
+func newCutiService(t *testing.T, today string) (*CutiService, *memory.Store) {
+	t.Helper()
+	ctx := context.Background()
+	s := seedStore(t)
+	sems := memory.NewSemesterRepository(s)
+	start, end := date("2024-09-01"), date("2025-01-31")
+	if _, err := sems.UpdatePatch(ctx, fixSemester, nil, nil, &start, &end); err != nil {
+		t.Fatal(err)
+	}
+	start, end = date("2025-02-01"), date("2025-07-31")
+	if _, err := sems.Create(ctx, &model.Semester{IDSemester: fixSemesterGenap, TahunAjaran: "2024/2025", Term: "Genap", TanggalMulai: &start, TanggalSelesai: &end}); err != nil {
+		t.Fatal(err)
+	}
+	mhs := NewMahasiswaService(memory.NewMahasiswaRepository(s), s, nil, Clock{})
+	svc := NewCutiService(memory.NewCutiRepository(s), mhs, s, CutiOptions{
+		DefaultMaxSemesters: 2,
+		MaxLampiranBytes:    1 << 10,
+		Clock:               Clock{Now: func() time.Time { return date(today) }},
+	})
+	return svc, s
+}
+
+func submission(sem string) CutiSubmission {
+	return CutiSubmission{IDSemester: sem, Alasan: "Merawat orang tua yang sakit", LampiranNama: "surat.pdf", Lampiran: pdf}
+}
+
+func mahasiswaStatus(t *testing.T, s *memory.Store) string {
+	t.Helper()
+	m, err := memory.NewMahasiswaRepository(s).GetByID(context.Background(), fixMahasiswa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m.Status
+}
+
+// TestCutiWorkflow: diajukan sebelum semester, disetujui operator prodi, KRS diblokir,
+// diterapkan Sync saat semester dimulai dan kembali Aktif saat semester berikutnya dimulai
+func TestCutiWorkflow(t *testing.T) {
+	ctx := context.Background()
+	svc, s := newCutiService(t, "2024-08-15")
+
+	c, err := svc.Submit(ctx, submission(fixSemester), mahasiswaActor)
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if c.Status != CutiDiajukan || c.IDProdi != fixProdi || c.LampiranTipe != "application/pdf" || c.LampiranUkuran != len(pdf) {
+		t.Fatalf("submitted = %+v", c)
+	}
+	if _, err := svc.Get(ctx, c.IDCuti, otherOperator); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("operator of another prodi: error = %v, want not found", err)
+	}
+	if list, total, err := svc.List(ctx, nil, 10, 0, "", otherOperator); err != nil || total != 0 || len(list) != 0 {
+		t.Errorf("list for another prodi = (%d, %v), want empty", total, err)
+	}
+	if _, total, _ := svc.List(ctx, map[string][]string{"status": {CutiDiajukan}}, 10, 0, "", prodiOperator); total != 1 {
+		t.Errorf("list for prodi operator: total = %d, want 1", total)
+	}
+	if l, err := svc.Lampiran(ctx, c.IDCuti, mahasiswaActor); err != nil || string(l.Data) != string(pdf) {
+		t.Errorf("Lampiran = (%v, %v)", l, err)
+	}
+
+	c, err = svc.Review(ctx, c.IDCuti, true, nil, prodiOperator)
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if c.Status != CutiDisetujui || c.Diterapkan || c.IDReviewer == nil || *c.IDReviewer != prodiOperator.IDUser {
+		t.Fatalf("approved = %+v, want not yet applied", c)
+	}
+	if got := mahasiswaStatus(t, s); got != StatusAktif {
+		t.Errorf("status before semester starts = %s, want Aktif", got)
+	}
+	seedKelas(t, s)
+	err = s.AddKRS(memory.KRS{IDMahasiswa: fixMahasiswa, IDKelas: fixKelas, IDSemester: fixSemester})
+	if !errors.Is(apperror.FromDB(err), apperror.ErrUnprocessable) {
+		t.Errorf("KRS during approved cuti: error = %v, want unprocessable", err)
+	}
+
+	res, err := svc.Sync(ctx, date("2024-09-02"))
+	if err != nil || res.Applied != 1 || mahasiswaStatus(t, s) != StatusCuti {
+		t.Fatalf("Sync at semester start = (%+v, %v), status %s", res, err, mahasiswaStatus(t, s))
+	}
+	if res, err := svc.Sync(ctx, date("2024-09-03")); err != nil || res != (CutiSyncResult{}) {
+		t.Errorf("second Sync = (%+v, %v), want no-op", res, err)
+	}
+
+	res, err = svc.Sync(ctx, date("2025-02-03"))
+	if err != nil || res.Finished != 1 || mahasiswaStatus(t, s) != StatusAktif {
+		t.Fatalf("Sync at next semester = (%+v, %v), status %s", res, err, mahasiswaStatus(t, s))
+	}
+	hist, _ := svc.mahasiswa.StatusHistory(ctx, fixMahasiswa)
+	if len(hist) != 2 || hist[0].StatusBaru != StatusAktif || !hist[0].TanggalEfektif.Equal(date("2025-02-01")) ||
+		hist[1].StatusBaru != StatusCuti || !hist[1].TanggalEfektif.Equal(date("2024-09-01")) || hist[0].Username != "system" {
+		t.Errorf("history = %+v", hist)
+	}
+	if c, _ := svc.Get(ctx, c.IDCuti, prodiOperator); !c.Diterapkan || !c.Selesai {
+		t.Errorf("cuti after Sync = %+v, want diterapkan and selesai", c)
+	}
+}
+
+// TestCutiConsecutive: cuti dua semester berturut-turut tidak kembali Aktif di antaranya
+func TestCutiConsecutive(t *testing.T) {
+	ctx := context.Background()
+	svc, s := newCutiService(t, "2024-08-15")
+	for _, sem := range []string{fixSemester, fixSemesterGenap} {
+		c, err := svc.Submit(ctx, submission(sem), mahasiswaActor)
+		if err != nil {
+			t.Fatalf("Submit %s: %v", sem, err)
+		}
+		if _, err := svc.Review(ctx, c.IDCuti, true, nil, adminActor); err != nil {
+			t.Fatalf("approve %s: %v", sem, err)
+		}
+	}
+	if _, err := svc.Sync(ctx, date("2024-09-02")); err != nil {
+		t.Fatal(err)
+	}
+	res, err := svc.Sync(ctx, date("2025-02-03"))
+	if err != nil || res != (CutiSyncResult{Applied: 1, Finished: 1}) || mahasiswaStatus(t, s) != StatusCuti {
+		t.Errorf("Sync into second cuti semester = (%+v, %v), status %s", res, err, mahasiswaStatus(t, s))
+	}
+	if hist, _ := svc.mahasiswa.StatusHistory(ctx, fixMahasiswa); len(hist) != 1 {
+		t.Errorf("history entries = %d, want a single Aktif>Cuti", len(hist))
+	}
+}
+
+func TestCutiSubmitRules(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newCutiService(t, "2025-03-01")
+	if _, err := svc.Submit(ctx, submission(fixSemesterGenap), mahasiswaActor); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	edit := func(f func(*CutiSubmission)) CutiSubmission {
+		in := submission(fixSemesterGenap)
+		f(&in)
+		return in
+	}
+	tests := []struct {
+		name  string
+		in    CutiSubmission
+		field string
+		want  string
+	}{
+		{"lampiran required", edit(func(in *CutiSubmission) { in.Lampiran = nil }), "lampiran", apperror.CodeRequired},
+		{"lampiran type sniffed", edit(func(in *CutiSubmission) { in.Lampiran = []byte("MZ\x90\x00 not a document") }), "lampiran", codeInvalidType},
+		{"alasan too short", edit(func(in *CutiSubmission) { in.Alasan = "sakit" }), "alasan", codeLength(10, 1000)},
+		{"unknown semester", edit(func(in *CutiSubmission) { in.IDSemester = "20301" }), "id_semester", "not_found"},
+		{"semester ended", edit(func(in *CutiSubmission) { in.IDSemester = fixSemester }), "id_semester", codeSemesterEnded},
+		{"duplicate", submission(fixSemesterGenap), "id_semester", codeAlreadySubmitted},
+		{"too large", edit(func(in *CutiSubmission) { in.Lampiran = append(append([]byte{}, pdf...), make([]byte, 1<<10)...) }), "lampiran", "max_1024_bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Submit(ctx, tt.in, mahasiswaActor)
+			if got := apperror.FieldsOf(err)[tt.field]; got != tt.want {
+				t.Errorf("error = %v, want %s=%s", err, tt.field, tt.want)
+			}
+		})
+	}
+	if _, err := svc.Submit(ctx, submission(fixSemesterGenap), prodiOperator); !errors.Is(err, apperror.ErrForbidden) {
+		t.Errorf("submit as operator: error = %v, want forbidden", err)
+	}
+}
+
+func TestCutiLimitPerProdi(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newCutiService(t, "2024-08-15")
+
+	p, err := svc.Policy(ctx, fixProdi)
+	if err != nil || !p.Default || p.MaksSemester != 2 {
+		t.Fatalf("default policy = (%+v, %v)", p, err)
+	}
+	if _, err := svc.SetPolicy(ctx, fixProdi, 1, otherOperator); !errors.Is(err, apperror.ErrForbidden) {
+		t.Errorf("policy by operator of another prodi: error = %v, want forbidden", err)
+	}
+	if _, err := svc.SetPolicy(ctx, fixProdi, -1, adminActor); !errors.Is(err, apperror.ErrInvalidInput) {
+		t.Errorf("negative limit: error = %v, want invalid input", err)
+	}
+	if p, err := svc.SetPolicy(ctx, fixProdi, 1, prodiOperator); err != nil || p.Default || p.MaksSemester != 1 {
+		t.Fatalf("SetPolicy = (%+v, %v)", p, err)
+	}
+
+	c, err := svc.Submit(ctx, submission(fixSemester), mahasiswaActor)
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if _, err := svc.Submit(ctx, submission(fixSemesterGenap), mahasiswaActor); apperror.FieldsOf(err)["id_semester"] != codeLimitReached {
+		t.Errorf("second semester over limit: error = %v, want %s", err, codeLimitReached)
+	}
+	// pengajuan yang dibatalkan tidak dihitung
+	if _, err := svc.Cancel(ctx, c.IDCuti, mahasiswaActor); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if _, err := svc.Submit(ctx, submission(fixSemesterGenap), mahasiswaActor); err != nil {
+		t.Errorf("submit after cancel: %v", err)
+	}
+}
+
+func TestCutiReview(t *testing.T) {
+	ctx := context.Background()
+	svc, s := newCutiService(t, "2024-09-10")
+	c, err := svc.Submit(ctx, submission(fixSemester), mahasiswaActor)
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	if _, err := svc.Review(ctx, c.IDCuti, false, strPtr(" "), prodiOperator); apperror.FieldsOf(err)["catatan"] != apperror.CodeRequired {
+		t.Errorf("reject without catatan: error = %v, want catatan required", err)
+	}
+	if _, err := svc.Review(ctx, c.IDCuti, true, nil, otherOperator); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("approve by operator of another prodi: error = %v, want not found", err)
+	}
+	if _, err := svc.Review(ctx, c.IDCuti, true, nil, mahasiswaActor); !errors.Is(err, apperror.ErrForbidden) {
+		t.Errorf("approve by mahasiswa: error = %v, want forbidden", err)
+	}
+	// operator tanpa ref_id tidak punya prodi, jadi tidak melihat atau memproses cuti
+	if _, err := svc.Review(ctx, c.IDCuti, true, nil, operatorActor); !errors.Is(err, apperror.ErrForbidden) {
+		t.Errorf("approve by operator without prodi: error = %v, want forbidden", err)
+	}
+	if _, _, err := svc.List(ctx, nil, 10, 0, "", operatorActor); !errors.Is(err, apperror.ErrForbidden) {
+		t.Errorf("list by operator without prodi: error = %v, want forbidden", err)
+	}
+	if _, err := svc.Get(ctx, c.IDCuti, operatorActor); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("get by operator without prodi: error = %v, want not found", err)
+	}
+	if _, err := svc.SetPolicy(ctx, fixProdi, 4, operatorActor); !errors.Is(err, apperror.ErrForbidden) {
+		t.Errorf("set policy by operator without prodi: error = %v, want forbidden", err)
+	}
+
+	// semester sudah berjalan: persetujuan langsung mengubah status
+	c, err = svc.Review(ctx, c.IDCuti, true, strPtr("Lengkap"), prodiOperator)
+	if err != nil || !c.Diterapkan || mahasiswaStatus(t, s) != StatusCuti {
+		t.Fatalf("approve during semester = (%+v, %v)", c, err)
+	}
+	if _, err := svc.Cancel(ctx, c.IDCuti, mahasiswaActor); apperror.FieldsOf(err)["status"] != codeNotPending {
+		t.Errorf("cancel after approval: error = %v, want %s", err, codeNotPending)
+	}
+	if _, err := svc.Review(ctx, c.IDCuti, false, strPtr("Ditarik"), prodiOperator); !errors.Is(err, apperror.ErrConflict) {
+		t.Errorf("second review: error = %v, want conflict", err)
+	}
+
+	c2, err := svc.Submit(ctx, submission(fixSemesterGenap), mahasiswaActor)
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	// KRS semester cuti yang sudah terisi menahan persetujuan
+	if err := s.AddMataKuliah(memory.MataKuliah{IDMK: fixMK, KodeMK: "IF101", IDProdi: fixProdi}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddKelas(memory.KelasKuliah{IDKelas: fixKelas, IDMK: fixMK, IDSemester: fixSemesterGenap, IDDosenPengampu: fixDosen}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddKRS(memory.KRS{IDMahasiswa: fixMahasiswa, IDKelas: fixKelas, IDSemester: fixSemesterGenap}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Review(ctx, c2.IDCuti, true, nil, adminActor); apperror.FieldsOf(err)["id_semester"] != codeKRSExists || !errors.Is(err, apperror.ErrConflict) {
+		t.Errorf("approve with krs in semester: error = %v, want conflict %s", err, codeKRSExists)
+	}
+	c2, err = svc.Review(ctx, c2.IDCuti, false, strPtr("Dokumen tidak sah"), adminActor)
+	if err != nil || c2.Status != CutiDitolak || c2.Catatan == nil || c2.ReviewedAt == nil {
+		t.Errorf("reject = (%+v, %v)", c2, err)
+	}
+}
@@ -0,0 +1,222 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	"pencatatan-data-mahasiswa/internal/todo/repository/memory"
+)
+
+// TestDeleteConflict memastikan Delete menolak baris yang masih dirujuk dengan error domain yang
+// tepat, dan tetap aman bila pengecekan service terlewati (FK RESTRICT di repository).
+func TestDeleteConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		seed     func(t *testing.T, s *memory.Store)
+		del      func(ctx context.Context, s *memory.Store) error
+		wantKind error  // nil berarti berhasil
+		wantCode string // kode pesan i18n yang diharapkan (opsional)
+	}{
+		{
+			name: "fakultas with prodi",
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewService(memory.NewFakultasRepository(s), s).Delete(ctx, fixFakultas)
+			},
+			wantKind: apperror.ErrConflict,
+			wantCode: i18n.MsgFakultasInUse,
+		},
+		{
+			name: "fakultas invalid id",
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewService(memory.NewFakultasRepository(s), s).Delete(ctx, "FAK-1")
+			},
+			wantKind: apperror.ErrInvalidInput,
+		},
+		{
+			name: "fakultas not found",
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewService(memory.NewFakultasRepository(s), s).Delete(ctx, "FAK99999")
+			},
+			wantKind: apperror.ErrNotFound,
+		},
+		{
+			name: "prodi with mahasiswa",
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewProdiService(memory.NewProdiRepository(s), s).Delete(ctx, fixProdi)
+			},
+			wantKind: apperror.ErrConflict,
+			wantCode: i18n.MsgProdiInUse,
+		},
+		{
+			name: "prodi with mata kuliah only",
+			seed: func(t *testing.T, s *memory.Store) {
+				if err := NewMahasiswaService(memory.NewMahasiswaRepository(s), s, nil, Clock{}).Delete(context.Background(), fixMahasiswa); err != nil {
+					t.Fatal(err)
+				}
+				seedKelas(t, s)
+			},
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewProdiService(memory.NewProdiRepository(s), s).Delete(ctx, fixProdi)
+			},
+			wantKind: apperror.ErrConflict,
+			wantCode: i18n.MsgProdiInUse,
+		},
+		{
+			name: "dosen teaching a kelas",
+			seed: seedKelas,
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewDosenService(memory.NewDosenRepository(s), s, nil).Delete(ctx, fixDosen)
+			},
+			wantKind: apperror.ErrConflict,
+			wantCode: i18n.MsgDosenInUse,
+		},
+		{
+			name: "dosen as pembimbing akademik",
+			seed: func(t *testing.T, s *memory.Store) {
+				pa := NewPembimbingService(memory.NewPembimbingRepository(s), memory.NewMahasiswaRepository(s), s, Clock{})
+				if _, err := pa.Assign(context.Background(), fixMahasiswa, PembimbingAssignment{IDDosen: fixDosen}, adminActor); err != nil {
+					t.Fatal(err)
+				}
+			},
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewDosenService(memory.NewDosenRepository(s), s, nil).Delete(ctx, fixDosen)
+			},
+			wantKind: apperror.ErrConflict,
+			wantCode: i18n.MsgDosenInUse,
+		},
+		{
+			name: "dosen only penanggung jawab",
+			seed: func(t *testing.T, s *memory.Store) {
+				if err := s.AddMataKuliah(memory.MataKuliah{IDMK: fixMK, KodeMK: "IF101", IDProdi: fixProdi, IDDosenPJ: strPtr(fixDosen)}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewDosenService(memory.NewDosenRepository(s), s, nil).Delete(ctx, fixDosen)
+			},
+			wantKind: apperror.ErrConflict,
+			wantCode: i18n.MsgDosenInUse,
+		},
+		{
+			name: "dosen without relations",
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewDosenService(memory.NewDosenRepository(s), s, nil).Delete(ctx, fixDosen)
+			},
+		},
+		{
+			name: "mahasiswa with krs",
+			seed: seedKRS,
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewMahasiswaService(memory.NewMahasiswaRepository(s), s, nil, Clock{}).Delete(ctx, fixMahasiswa)
+			},
+			wantKind: apperror.ErrConflict,
+			wantCode: i18n.MsgMahasiswaInUse,
+		},
+		{
+			name: "mahasiswa without krs",
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewMahasiswaService(memory.NewMahasiswaRepository(s), s, nil, Clock{}).Delete(ctx, fixMahasiswa)
+			},
+		},
+		{
+			name: "mahasiswa invalid nim",
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewMahasiswaService(memory.NewMahasiswaRepository(s), s, nil, Clock{}).Delete(ctx, "123")
+			},
+			wantKind: apperror.ErrInvalidInput,
+		},
+		{
+			name: "mahasiswa not found",
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewMahasiswaService(memory.NewMahasiswaRepository(s), s, nil, Clock{}).Delete(ctx, "209900000001")
+			},
+			wantKind: apperror.ErrNotFound,
+		},
+		{
+			name: "semester with kelas",
+			seed: seedKelas,
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewSemesterService(memory.NewSemesterRepository(s), s).Delete(ctx, fixSemester)
+			},
+			wantKind: apperror.ErrConflict,
+			wantCode: i18n.MsgSemesterInUse,
+		},
+		{
+			name: "semester with krs only",
+			seed: func(t *testing.T, s *memory.Store) {
+				// kelas berada di semester lain, KRS tetap merujuk fixSemester
+				ctx := context.Background()
+				if _, err := memory.NewSemesterRepository(s).Create(ctx, &model.Semester{IDSemester: "20242", TahunAjaran: "2024/2025", Term: "Genap"}); err != nil {
+					t.Fatal(err)
+				}
+				for _, err := range []error{
+					s.AddMataKuliah(memory.MataKuliah{IDMK: fixMK, KodeMK: "IF101", IDProdi: fixProdi}),
+					s.AddKelas(memory.KelasKuliah{IDKelas: fixKelas, IDMK: fixMK, IDSemester: "20242", IDDosenPengampu: fixDosen}),
+					s.AddKRS(memory.KRS{IDMahasiswa: fixMahasiswa, IDKelas: fixKelas, IDSemester: fixSemester}),
+				} {
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+			},
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewSemesterService(memory.NewSemesterRepository(s), s).Delete(ctx, fixSemester)
+			},
+			wantKind: apperror.ErrConflict,
+			wantCode: i18n.MsgSemesterInUse,
+		},
+		{
+			name: "semester not found",
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewSemesterService(memory.NewSemesterRepository(s), s).Delete(ctx, "20991")
+			},
+			wantKind: apperror.ErrNotFound,
+		},
+		{
+			name: "semester without relations",
+			del: func(ctx context.Context, s *memory.Store) error {
+				return NewSemesterService(memory.NewSemesterRepository(s), s).Delete(ctx, fixSemester)
+			},
+		},
+		{
+			// pengecekan service terlewati (race): FK RESTRICT tetap menolak dan dipetakan FromDB
+			name: "repository restrict without service check",
+			seed: seedKRS,
+			del: func(ctx context.Context, s *memory.Store) error {
+				return memory.NewMahasiswaRepository(s).Delete(ctx, fixMahasiswa)
+			},
+			wantKind: apperror.ErrConflict,
+			wantCode: i18n.MsgStillReferenced,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := seedStore(t)
+			if tt.seed != nil {
+				tt.seed(t, s)
+			}
+			// handler menerjemahkan error repository lewat apperror.FromDB; lakukan hal yang sama
+			err := apperror.FromDB(tt.del(context.Background(), s))
+			if tt.wantKind == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("error = %v, want kind %v", err, tt.wantKind)
+			}
+			if tt.wantCode != "" {
+				var ae *apperror.Error
+				if !errors.As(err, &ae) || ae.Code != tt.wantCode {
+					t.Fatalf("error = %v, want code %q", err, tt.wantCode)
+				}
+			}
+		})
+	}
+}
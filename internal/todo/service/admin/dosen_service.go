@@ -7,16 +7,22 @@ import (
     "regexp"
     "strings"
 
+    "pencatatan-data-mahasiswa/internal/apperror"
+    "pencatatan-data-mahasiswa/internal/i18n"
     model "pencatatan-data-mahasiswa/internal/todo/model/admin"
     repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+    authsvc "pencatatan-data-mahasiswa/internal/todo/service/auth"
+    "pencatatan-data-mahasiswa/internal/tracing"
 )
 
 type DosenService struct {
-    repo *repo.DosenRepository
+    repo     DosenRepository
+    tx       UnitOfWork
+    accounts AccountProvisioner
 }
 
-func NewDosenService(r *repo.DosenRepository) *DosenService {
-    return &DosenService{repo: r}
+func NewDosenService(r DosenRepository, tx UnitOfWork, accounts AccountProvisioner) *DosenService {
+    return &DosenService{repo: r, tx: tx, accounts: accounts}
 }
 
 var (
@@ -24,22 +30,41 @@ var (
     hpPattern      = regexp.MustCompile(`^[0-9+]{1,20}$`)
     emailPattern   = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
     numericPattern = regexp.MustCompile(`^[0-9]+$`)
+    matchSet       = map[string]struct{}{repo.MatchExact: {}, repo.MatchPrefix: {}, repo.MatchFuzzy: {}}
+    errDosenInUse  = apperror.New(ErrConflict, i18n.MsgDosenInUse)
+
+    errInvalidDosenID = invalidID("id_dosen", codeDosenID)
 )
 
-func (s *DosenService) validateCommon(d *model.Dosen) error {
-    d.NamaDosen = strings.TrimSpace(d.NamaDosen)
-    if d.NamaDosen == "" || len(d.NamaDosen) < 3 || len(d.NamaDosen) > 120 {
-        return ErrInvalidInput
+// Kode detail field validasi dosen
+const (
+    codeDosenID = "pattern_10_alnum"
+    codeNIDN    = "pattern_max_16_digits"
+)
+
+// normalizeMatch memvalidasi mode pencarian; kosong berarti exact (perilaku lama)
+func normalizeMatch(match string) (string, error) {
+    match = strings.ToLower(strings.TrimSpace(match))
+    if match == "" {
+        return repo.MatchExact, nil
+    }
+    if _, ok := matchSet[match]; !ok {
+        return "", apperror.Field(ErrInvalidInput, "match", codeInvalidChoice)
     }
+    return match, nil
+}
+
+// validateCommon menormalisasi dan memvalidasi field dosen, mencatat semua kegagalan ke fe
+func (s *DosenService) validateCommon(d *model.Dosen, fe apperror.Fields) {
+    d.NamaDosen = strings.TrimSpace(d.NamaDosen)
+    checkLength(fe, "nama_dosen", d.NamaDosen, 3, 120)
 
     if d.NIDN != nil {
         v := strings.TrimSpace(*d.NIDN)
         if v == "" {
             d.NIDN = nil
         } else {
-            if len(v) > 16 || !numericPattern.MatchString(v) {
-                return ErrInvalidInput
-            }
+            validateNIDN(v, fe)
             d.NIDN = &v
         }
     }
@@ -48,9 +73,7 @@ func (s *DosenService) validateCommon(d *model.Dosen) error {
         if v == "" {
             d.Email = nil
         } else {
-            if len(v) > 120 || !emailPattern.MatchString(v) {
-                return ErrInvalidInput
-            }
+            validateEmail(v, fe)
             d.Email = &v
         }
     }
@@ -60,7 +83,7 @@ func (s *DosenService) validateCommon(d *model.Dosen) error {
             d.NoHP = nil
         } else {
             if len(v) > 20 || !hpPattern.MatchString(v) {
-                return ErrInvalidInput
+                fe.Add("no_hp", codeInvalidPhone)
             }
             d.NoHP = &v
         }
@@ -70,11 +93,49 @@ func (s *DosenService) validateCommon(d *model.Dosen) error {
         if v == "" {
             d.JabatanAkademik = nil
         } else if len(v) > 60 {
-            return ErrInvalidInput
+            fe.Add("jabatan_akademik", codeMax(60))
         } else {
             d.JabatanAkademik = &v
         }
     }
+}
+
+// validateNIDN: maksimal 16 digit angka
+func validateNIDN(v string, fe apperror.Fields) {
+    if len(v) > 16 || !numericPattern.MatchString(v) {
+        fe.Add("nidn", codeNIDN)
+    }
+}
+
+// validateEmail: maksimal 120 karakter dan berformat email
+func validateEmail(v string, fe apperror.Fields) {
+    if len(v) > 120 {
+        fe.Add("email", codeMax(120))
+    } else if !emailPattern.MatchString(v) {
+        fe.Add("email", codeInvalidEmail)
+    }
+}
+
+// checkUnique mengumpulkan field unik dosen (nidn, email) yang sudah dipakai dosen lain.
+// Harus dipanggil di dalam s.tx.Do: email tidak punya constraint unik sehingga dikunci dulu.
+func (s *DosenService) checkUnique(ctx context.Context, nidn, email, excludeID *string, taken apperror.Fields) error {
+    if nidn != nil {
+        if exist, err := s.repo.ExistsNIDN(ctx, *nidn, excludeID); err != nil {
+            return err
+        } else if exist {
+            taken.Add("nidn", apperror.CodeTaken)
+        }
+    }
+    if email != nil {
+        if err := s.tx.LockKey(ctx, lockDosenEmail, strings.ToLower(*email)); err != nil {
+            return err
+        }
+        if exist, err := s.repo.ExistsEmail(ctx, *email, excludeID); err != nil {
+            return err
+        } else if exist {
+            taken.Add("email", apperror.CodeTaken)
+        }
+    }
     return nil
 }
 
@@ -102,131 +163,168 @@ func (s *DosenService) generateUniqueID(ctx context.Context) (string, error) {
     return "", ErrConflict
 }
 
-// List dosen dengan pencarian q pada nama/nidn/email
-func (s *DosenService) List(ctx context.Context, q string, limit, offset int, orderBy string) ([]model.Dosen, error) {
+// List dosen dengan pencarian q pada nama/nidn/email, beserta total baris yang cocok.
+// match menentukan mode pencarian nama: exact, prefix atau fuzzy; rawFilters memakai grammar filter repository
+func (s *DosenService) List(ctx context.Context, q, match string, rawFilters map[string][]string, limit, offset int, orderBy string) ([]model.Dosen, int64, error) {
+    ctx, span := tracing.Start(ctx, "DosenService.List")
+    defer span.End()
+
     if limit < 0 || offset < 0 {
-        return nil, ErrInvalidInput
+        return nil, 0, ErrInvalidInput
+    }
+    match, err := normalizeMatch(match)
+    if err != nil {
+        return nil, 0, err
+    }
+    filters, err := repo.ParseFilters(rawFilters, repo.DosenFilterFields)
+    if err != nil {
+        return nil, 0, err
     }
     q = strings.TrimSpace(q)
-    return s.repo.List(ctx, q, limit, offset, orderBy)
+    total, err := s.repo.Count(ctx, q, match, filters)
+    if err != nil {
+        return nil, 0, err
+    }
+    data, err := s.repo.List(ctx, q, match, filters, limit, offset, orderBy)
+    if err != nil {
+        return nil, 0, err
+    }
+    return data, total, nil
 }
 
 func (s *DosenService) Get(ctx context.Context, id string) (*model.Dosen, error) {
+    ctx, span := tracing.Start(ctx, "DosenService.Get")
+    defer span.End()
+
     id = strings.TrimSpace(id)
     if !dosenIDPattern.MatchString(id) {
-        return nil, ErrInvalidInput
+        return nil, errInvalidDosenID
     }
     return s.repo.GetByID(ctx, id)
 }
 
 func (s *DosenService) Create(ctx context.Context, d *model.Dosen) (*model.Dosen, error) {
+    ctx, span := tracing.Start(ctx, "DosenService.Create")
+    defer span.End()
+
+    fe := apperror.Fields{}
     d.IDDosen = strings.TrimSpace(d.IDDosen)
-    // Jika ID kosong, generate otomatis; jika diisi, validasi pola dan unik
-    if d.IDDosen == "" {
-        id, err := s.generateUniqueID(ctx)
-        if err != nil {
-            return nil, err
+    if d.IDDosen != "" && !dosenIDPattern.MatchString(d.IDDosen) {
+        fe.Add("id_dosen", codeDosenID)
+    }
+    s.validateCommon(d, fe)
+    if err := fe.Err(); err != nil {
+        return nil, err
+    }
+
+    return inTx(ctx, s.tx, func(ctx context.Context) (*model.Dosen, error) {
+        // Unik ID (bila diisi), NIDN dan email
+        taken := apperror.Fields{}
+        if d.IDDosen != "" {
+            if exist, err := s.repo.ExistsID(ctx, d.IDDosen); err != nil {
+                return nil, err
+            } else if exist {
+                taken.Add("id_dosen", apperror.CodeTaken)
+            }
         }
-        d.IDDosen = id
-    } else {
-        if !dosenIDPattern.MatchString(d.IDDosen) {
-            return nil, ErrInvalidInput
+        if err := s.checkUnique(ctx, d.NIDN, d.Email, nil, taken); err != nil {
+            return nil, err
         }
-        if exist, err := s.repo.ExistsID(ctx, d.IDDosen); err != nil {
+        if err := taken.As(ErrConflict); err != nil {
             return nil, err
-        } else if exist {
-            return nil, ErrConflict
         }
-    }
 
-    if err := s.validateCommon(d); err != nil {
-        return nil, err
-    }
-    // Unik NIDN bila ada
-    if d.NIDN != nil {
-        if exist, err := s.repo.ExistsNIDN(ctx, *d.NIDN, nil); err != nil {
-            return nil, err
-        } else if exist {
-            return nil, ErrConflict
+        // Jika ID kosong, generate otomatis
+        if d.IDDosen == "" {
+            id, err := s.generateUniqueID(ctx)
+            if err != nil {
+                return nil, err
+            }
+            d.IDDosen = id
         }
+
+        return s.repo.Create(ctx, d)
+    })
+}
+
+// CreateWithAccount membuat dosen dan akun login-nya (username = id_dosen, termasuk id yang
+// di-generate) dalam satu transaksi. mode kosong sama dengan Create.
+func (s *DosenService) CreateWithAccount(ctx context.Context, d *model.Dosen, mode authsvc.AccountMode) (*model.Dosen, *authsvc.Account, error) {
+    if mode == "" {
+        out, err := s.Create(ctx, d)
+        return out, nil, err
     }
-    // Unik email bila ada
-    if d.Email != nil {
-        if exist, err := s.repo.ExistsEmail(ctx, *d.Email, nil); err != nil {
-            return nil, err
-        } else if exist {
-            return nil, ErrConflict
+    var (
+        out *model.Dosen
+        acc *authsvc.Account
+    )
+    err := s.tx.Do(ctx, func(ctx context.Context) error {
+        var err error
+        if out, err = s.Create(ctx, d); err != nil {
+            return err
         }
+        acc, err = s.accounts.Provision(ctx, "dosen", out.IDDosen, mode)
+        return err
+    })
+    if err != nil {
+        return nil, nil, err
     }
-
-    return s.repo.Create(ctx, d)
+    return out, acc, nil
 }
 
 func (s *DosenService) UpdatePut(ctx context.Context, id string, d *model.Dosen) (*model.Dosen, error) {
+    ctx, span := tracing.Start(ctx, "DosenService.UpdatePut")
+    defer span.End()
+
     id = strings.TrimSpace(id)
     if !dosenIDPattern.MatchString(id) {
-        return nil, ErrInvalidInput
+        return nil, errInvalidDosenID
     }
-    if err := s.validateCommon(d); err != nil {
+    fe := apperror.Fields{}
+    s.validateCommon(d, fe)
+    if err := fe.Err(); err != nil {
         return nil, err
     }
-    if d.NIDN != nil {
-        if exist, err := s.repo.ExistsNIDN(ctx, *d.NIDN, &id); err != nil {
+    return inTx(ctx, s.tx, func(ctx context.Context) (*model.Dosen, error) {
+        taken := apperror.Fields{}
+        if err := s.checkUnique(ctx, d.NIDN, d.Email, &id, taken); err != nil {
             return nil, err
-        } else if exist {
-            return nil, ErrConflict
         }
-    }
-    if d.Email != nil {
-        if exist, err := s.repo.ExistsEmail(ctx, *d.Email, &id); err != nil {
+        if err := taken.As(ErrConflict); err != nil {
             return nil, err
-        } else if exist {
-            return nil, ErrConflict
         }
-    }
-    return s.repo.UpdatePut(ctx, id, d)
+        return s.repo.UpdatePut(ctx, id, d)
+    })
 }
 
 func (s *DosenService) UpdatePatch(ctx context.Context, id string, nidn, nama, email, nohp, jabatan *string) (*model.Dosen, error) {
+    ctx, span := tracing.Start(ctx, "DosenService.UpdatePatch")
+    defer span.End()
+
     id = strings.TrimSpace(id)
     if !dosenIDPattern.MatchString(id) {
-        return nil, ErrInvalidInput
+        return nil, errInvalidDosenID
     }
 
+    fe := apperror.Fields{}
     if nidn != nil {
         *nidn = strings.TrimSpace(*nidn)
         if *nidn == "" {
             nidn = nil
         } else {
-            if len(*nidn) > 16 || !numericPattern.MatchString(*nidn) {
-                return nil, ErrInvalidInput
-            }
-            if exist, err := s.repo.ExistsNIDN(ctx, *nidn, &id); err != nil {
-                return nil, err
-            } else if exist {
-                return nil, ErrConflict
-            }
+            validateNIDN(*nidn, fe)
         }
     }
     if nama != nil {
         *nama = strings.TrimSpace(*nama)
-        if len(*nama) < 3 || len(*nama) > 120 {
-            return nil, ErrInvalidInput
-        }
+        checkLength(fe, "nama_dosen", *nama, 3, 120)
     }
     if email != nil {
         *email = strings.TrimSpace(*email)
         if *email == "" {
             email = nil
         } else {
-            if len(*email) > 120 || !emailPattern.MatchString(*email) {
-                return nil, ErrInvalidInput
-            }
-            if exist, err := s.repo.ExistsEmail(ctx, *email, &id); err != nil {
-                return nil, err
-            } else if exist {
-                return nil, ErrConflict
-            }
+            validateEmail(*email, fe)
         }
     }
     if nohp != nil {
@@ -235,7 +333,7 @@ func (s *DosenService) UpdatePatch(ctx context.Context, id string, nidn, nama, e
             nohp = nil
         } else {
             if len(*nohp) > 20 || !hpPattern.MatchString(*nohp) {
-                return nil, ErrInvalidInput
+                fe.Add("no_hp", codeInvalidPhone)
             }
         }
     }
@@ -244,27 +342,53 @@ func (s *DosenService) UpdatePatch(ctx context.Context, id string, nidn, nama, e
         if *jabatan == "" {
             jabatan = nil
         } else if len(*jabatan) > 60 {
-            return nil, ErrInvalidInput
+            fe.Add("jabatan_akademik", codeMax(60))
         }
     }
+    if err := fe.Err(); err != nil {
+        return nil, err
+    }
+
+    return inTx(ctx, s.tx, func(ctx context.Context) (*model.Dosen, error) {
+        taken := apperror.Fields{}
+        if err := s.checkUnique(ctx, nidn, email, &id, taken); err != nil {
+            return nil, err
+        }
+        if err := taken.As(ErrConflict); err != nil {
+            return nil, err
+        }
 
-    return s.repo.UpdatePatch(ctx, id, nidn, nama, email, nohp, jabatan)
+        return s.repo.UpdatePatch(ctx, id, nidn, nama, email, nohp, jabatan)
+    })
 }
 
 func (s *DosenService) Delete(ctx context.Context, id string) error {
+    ctx, span := tracing.Start(ctx, "DosenService.Delete")
+    defer span.End()
+
     id = strings.TrimSpace(id)
     if !dosenIDPattern.MatchString(id) {
-        return ErrInvalidInput
-    }
-    if has, err := s.repo.HasMataKuliahPenanggungJawab(ctx, id); err != nil {
-        return err
-    } else if has {
-        return ErrConflict
-    }
-    if has, err := s.repo.HasKelasKuliahPengampu(ctx, id); err != nil {
-        return err
-    } else if has {
-        return ErrConflict
+        return errInvalidDosenID
     }
-    return s.repo.Delete(ctx, id)
-}
\ No newline at end of file
+    return s.tx.Do(ctx, func(ctx context.Context) error {
+        if err := s.repo.LockByID(ctx, id); err != nil {
+            return err
+        }
+        if has, err := s.repo.HasMataKuliahPenanggungJawab(ctx, id); err != nil {
+            return err
+        } else if has {
+            return errDosenInUse
+        }
+        if has, err := s.repo.HasKelasKuliahPengampu(ctx, id); err != nil {
+            return err
+        } else if has {
+            return errDosenInUse
+        }
+        if has, err := s.repo.HasPembimbingRelated(ctx, id); err != nil {
+            return err
+        } else if has {
+            return errDosenInUse
+        }
+        return s.repo.Delete(ctx, id)
+    })
+}
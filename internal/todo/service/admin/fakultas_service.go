@@ -3,44 +3,70 @@ package admin
 import (
     "context"
     "crypto/rand"
-    "errors"
     "fmt"
     "math/big"
     "regexp"
     "strings"
 
+    "pencatatan-data-mahasiswa/internal/apperror"
+    "pencatatan-data-mahasiswa/internal/i18n"
     model "pencatatan-data-mahasiswa/internal/todo/model/admin"
     repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+    "pencatatan-data-mahasiswa/internal/tracing"
 )
 
 type Service struct {
-    repo *repo.FakultasRepository
+    repo FakultasRepository
+    tx   UnitOfWork
 }
 
-func NewService(r *repo.FakultasRepository) *Service {
-    return &Service{repo: r}
+func NewService(r FakultasRepository, tx UnitOfWork) *Service {
+    return &Service{repo: r, tx: tx}
 }
 
+// Error domain dipakai bersama oleh seluruh service admin; pemetaan ke status HTTP ada di apperror
 var (
-    ErrInvalidInput = errors.New("invalid input")
-    ErrConflict     = errors.New("conflict")
+    ErrInvalidInput = apperror.ErrInvalidInput
+    ErrConflict     = apperror.ErrConflict
+    ErrNotFound     = apperror.ErrNotFound
     idPattern       = regexp.MustCompile(`^[A-Za-z0-9]{8}$`)
 )
 
-// List with optional search and pagination
-func (s *Service) List(ctx context.Context, search string, limit, offset int) ([]model.Fakultas, error) {
+var errInvalidFakultasID = invalidID("id_fakultas", codeAlnum8)
+
+// List with optional search, filters (grammar filter repository) and pagination;
+// also returns the total number of matching rows
+func (s *Service) List(ctx context.Context, search string, rawFilters map[string][]string, limit, offset int) ([]model.Fakultas, int64, error) {
+    ctx, span := tracing.Start(ctx, "FakultasService.List")
+    defer span.End()
+
     search = strings.TrimSpace(search)
     if limit < 0 || offset < 0 {
-        return nil, ErrInvalidInput
+        return nil, 0, ErrInvalidInput
+    }
+    filters, err := repo.ParseFilters(rawFilters, repo.FakultasFilterFields)
+    if err != nil {
+        return nil, 0, err
+    }
+    total, err := s.repo.Count(ctx, search, filters)
+    if err != nil {
+        return nil, 0, err
+    }
+    data, err := s.repo.List(ctx, search, filters, limit, offset)
+    if err != nil {
+        return nil, 0, err
     }
-    return s.repo.List(ctx, search, limit, offset)
+    return data, total, nil
 }
 
 // Get detail by id
 func (s *Service) Get(ctx context.Context, id string) (*model.Fakultas, error) {
+    ctx, span := tracing.Start(ctx, "FakultasService.Get")
+    defer span.End()
+
     id = strings.TrimSpace(id)
     if !idPattern.MatchString(id) {
-        return nil, ErrInvalidInput
+        return nil, errInvalidFakultasID
     }
     return s.repo.GetByID(ctx, id)
 }
@@ -66,6 +92,9 @@ func (s *Service) generateUniqueID(ctx context.Context) (string, error) {
 
 // Create new fakultas with validations
 func (s *Service) Create(ctx context.Context, f *model.Fakultas) (*model.Fakultas, error) {
+    ctx, span := tracing.Start(ctx, "FakultasService.Create")
+    defer span.End()
+
     f.IDFakultas = strings.TrimSpace(f.IDFakultas)
     f.NamaFakultas = strings.TrimSpace(f.NamaFakultas)
     if f.Singkatan != nil {
@@ -77,62 +106,72 @@ func (s *Service) Create(ctx context.Context, f *model.Fakultas) (*model.Fakulta
         }
     }
 
-    // Validasi nama & singkatan
-    if len(f.NamaFakultas) < 3 || len(f.NamaFakultas) > 100 {
-        return nil, ErrInvalidInput
-    }
+    // Validasi nama, singkatan & ID (bila diisi)
+    fe := apperror.Fields{}
+    checkLength(fe, "nama_fakultas", f.NamaFakultas, 3, 100)
     if f.Singkatan != nil && len(*f.Singkatan) > 20 {
-        return nil, ErrInvalidInput
+        fe.Add("singkatan", codeMax(20))
     }
-
-    // Cek unik nama (case-insensitive)
-    if exists, err := s.repo.ExistsNamaCI(ctx, f.NamaFakultas); err != nil {
+    if f.IDFakultas != "" && !idPattern.MatchString(f.IDFakultas) {
+        fe.Add("id_fakultas", codeAlnum8)
+    }
+    if err := fe.Err(); err != nil {
         return nil, err
-    } else if exists {
-        return nil, ErrConflict
     }
 
-    // Jika ID kosong, generate otomatis. Jika diisi, validasi pola dan unik
-    if f.IDFakultas == "" {
-        id, err := s.generateUniqueID(ctx)
-        if err != nil {
+    return inTx(ctx, s.tx, func(ctx context.Context) (*model.Fakultas, error) {
+        // nama unik hanya dijaga di sini (tanpa constraint): kunci agar request bersamaan bergiliran
+        if err := s.tx.LockKey(ctx, lockFakultasNama, strings.ToLower(f.NamaFakultas)); err != nil {
             return nil, err
         }
-        f.IDFakultas = id
-    } else {
-        if !idPattern.MatchString(f.IDFakultas) {
-            return nil, ErrInvalidInput
-        }
-        if exists, err := s.repo.ExistsID(ctx, f.IDFakultas); err != nil {
+
+        // Cek unik nama (case-insensitive) dan ID bila diisi
+        taken := apperror.Fields{}
+        if exists, err := s.repo.ExistsNamaCI(ctx, f.NamaFakultas); err != nil {
             return nil, err
         } else if exists {
-            return nil, ErrConflict
+            taken.Add("nama_fakultas", apperror.CodeTaken)
+        }
+        if f.IDFakultas != "" {
+            if exists, err := s.repo.ExistsID(ctx, f.IDFakultas); err != nil {
+                return nil, err
+            } else if exists {
+                taken.Add("id_fakultas", apperror.CodeTaken)
+            }
+        }
+        if err := taken.As(ErrConflict); err != nil {
+            return nil, err
         }
-    }
 
-    return s.repo.Create(ctx, f)
+        // Jika ID kosong, generate otomatis
+        if f.IDFakultas == "" {
+            id, err := s.generateUniqueID(ctx)
+            if err != nil {
+                return nil, err
+            }
+            f.IDFakultas = id
+        }
+
+        return s.repo.Create(ctx, f)
+    })
 }
 
 // Update existing fakultas by id. Fields are optional.
 func (s *Service) Update(ctx context.Context, id string, nama *string, singkatan *string) (*model.Fakultas, error) {
+    ctx, span := tracing.Start(ctx, "FakultasService.Update")
+    defer span.End()
+
     id = strings.TrimSpace(id)
     if !idPattern.MatchString(id) {
-        return nil, ErrInvalidInput
+        return nil, errInvalidFakultasID
     }
 
+    fe := apperror.Fields{}
     var namaV *string
     if nama != nil {
         trimmed := strings.TrimSpace(*nama)
-        if len(trimmed) == 0 || len(trimmed) < 3 || len(trimmed) > 100 {
-            return nil, ErrInvalidInput
-        }
+        checkLength(fe, "nama_fakultas", trimmed, 3, 100)
         namaV = &trimmed
-        // cek unik nama baru, case-insensitive
-        if exists, err := s.repo.ExistsNamaCI(ctx, trimmed); err != nil {
-            return nil, err
-        } else if exists {
-            return nil, ErrConflict
-        }
     }
 
     var singV *string
@@ -142,26 +181,52 @@ func (s *Service) Update(ctx context.Context, id string, nama *string, singkatan
             singV = nil
         } else {
             if len(trimmed) > 20 {
-                return nil, ErrInvalidInput
+                fe.Add("singkatan", codeMax(20))
             }
             singV = &trimmed
         }
     }
+    if err := fe.Err(); err != nil {
+        return nil, err
+    }
 
-    return s.repo.Update(ctx, id, namaV, singV)
+    return inTx(ctx, s.tx, func(ctx context.Context) (*model.Fakultas, error) {
+        // cek unik nama baru, case-insensitive
+        if namaV != nil {
+            if err := s.tx.LockKey(ctx, lockFakultasNama, strings.ToLower(*namaV)); err != nil {
+                return nil, err
+            }
+            if exists, err := s.repo.ExistsNamaCI(ctx, *namaV); err != nil {
+                return nil, err
+            } else if exists {
+                return nil, apperror.Field(ErrConflict, "nama_fakultas", apperror.CodeTaken)
+            }
+        }
+
+        return s.repo.Update(ctx, id, namaV, singV)
+    })
 }
 
 // Delete a fakultas. Reject if prodi exists.
 func (s *Service) Delete(ctx context.Context, id string) error {
+    ctx, span := tracing.Start(ctx, "FakultasService.Delete")
+    defer span.End()
+
     id = strings.TrimSpace(id)
     if !idPattern.MatchString(id) {
-        return ErrInvalidInput
+        return errInvalidFakultasID
     }
-    // Tolak jika masih ada prodi terkait
-    if has, err := s.repo.HasProdiRelated(ctx, id); err != nil {
-        return err
-    } else if has {
-        return ErrConflict
-    }
-    return s.repo.Delete(ctx, id)
-}
\ No newline at end of file
+    return s.tx.Do(ctx, func(ctx context.Context) error {
+        // kunci baris dulu agar insert yang merujuknya menunggu sampai delete selesai
+        if err := s.repo.LockByID(ctx, id); err != nil {
+            return err
+        }
+        // Tolak jika masih ada prodi terkait
+        if has, err := s.repo.HasProdiRelated(ctx, id); err != nil {
+            return err
+        } else if has {
+            return apperror.New(ErrConflict, i18n.MsgFakultasInUse)
+        }
+        return s.repo.Delete(ctx, id)
+    })
+}
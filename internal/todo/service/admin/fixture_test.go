@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"context"
+	"testing"
+
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	"pencatatan-data-mahasiswa/internal/todo/repository/memory"
+)
+
+var (
+	_ FakultasRepository   = (*memory.FakultasRepository)(nil)
+	_ ProdiRepository      = (*memory.ProdiRepository)(nil)
+	_ DosenRepository      = (*memory.DosenRepository)(nil)
+	_ MahasiswaRepository  = (*memory.MahasiswaRepository)(nil)
+	_ SemesterRepository   = (*memory.SemesterRepository)(nil)
+	_ CutiRepository       = (*memory.CutiRepository)(nil)
+	_ PembimbingRepository = (*memory.PembimbingRepository)(nil)
+	_ KRSRepository        = (*memory.KRSRepository)(nil)
+	_ KurikulumRepository  = (*memory.KurikulumRepository)(nil)
+	_ UnitOfWork           = (*memory.Store)(nil)
+)
+
+// ID data contoh yang dibuat seedStore
+const (
+	fixFakultas  = "FAK00001"
+	fixProdi     = "PRD00001"
+	fixDosen     = "DSN0000001"
+	fixMahasiswa = "202400000001"
+	fixSemester  = "20241"
+	fixMK        = "MK00000001"
+	fixKelas     = "KLS000000001"
+)
+
+func strPtr(s string) *string { return &s }
+
+// seedStore mengisi satu baris per tabel master tanpa relasi kelas/KRS
+func seedStore(t *testing.T) *memory.Store {
+	t.Helper()
+	ctx := context.Background()
+	s := memory.NewStore()
+	must := func(_ any, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	must(memory.NewFakultasRepository(s).Create(ctx, &model.Fakultas{IDFakultas: fixFakultas, NamaFakultas: "Fakultas Teknik"}))
+	must(memory.NewProdiRepository(s).Create(ctx, &model.Prodi{IDProdi: fixProdi, IDFakultas: fixFakultas, NamaProdi: "Informatika", Jenjang: "S1", KodeProdi: "IF"}))
+	must(memory.NewDosenRepository(s).Create(ctx, &model.Dosen{IDDosen: fixDosen, NIDN: strPtr("0011223344"), NamaDosen: "Dr. Budi"}))
+	must(memory.NewMahasiswaRepository(s).Create(ctx, &model.Mahasiswa{IDMahasiswa: fixMahasiswa, IDProdi: fixProdi, NamaLengkap: "Siti Aminah", JenisKelamin: "P", TahunMasuk: 2024, Status: "Aktif", NIK: strPtr("3201010101010001")}))
+	must(memory.NewSemesterRepository(s).Create(ctx, &model.Semester{IDSemester: fixSemester, TahunAjaran: "2024/2025", Term: "Ganjil"}))
+	return s
+}
+
+// seedKelas menambahkan mata kuliah (penanggung jawab fixDosen) dan satu kelas di fixSemester
+func seedKelas(t *testing.T, s *memory.Store) {
+	t.Helper()
+	if err := s.AddMataKuliah(memory.MataKuliah{IDMK: fixMK, KodeMK: "IF101", IDProdi: fixProdi, IDDosenPJ: strPtr(fixDosen)}); err != nil {
+		t.Fatalf("seed mata kuliah: %v", err)
+	}
+	if err := s.AddKelas(memory.KelasKuliah{IDKelas: fixKelas, IDMK: fixMK, IDSemester: fixSemester, IDDosenPengampu: fixDosen}); err != nil {
+		t.Fatalf("seed kelas: %v", err)
+	}
+}
+
+// seedKRS menambahkan kelas lewat seedKelas lalu KRS fixMahasiswa di kelas tersebut
+func seedKRS(t *testing.T, s *memory.Store) {
+	t.Helper()
+	seedKelas(t, s)
+	if err := s.AddKRS(memory.KRS{IDMahasiswa: fixMahasiswa, IDKelas: fixKelas, IDSemester: fixSemester}); err != nil {
+		t.Fatalf("seed krs: %v", err)
+	}
+}
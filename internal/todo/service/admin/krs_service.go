@@ -0,0 +1,459 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	"pencatatan-data-mahasiswa/internal/metrics"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+	"pencatatan-data-mahasiswa/internal/tracing"
+)
+
+// Status KRS. KRSDraft tidak disimpan: KRS tanpa baris krs_pengajuan masih disusun mahasiswa.
+const (
+	KRSDraft        = "Draft"
+	KRSDiajukan     = "Diajukan"
+	KRSDikembalikan = "Dikembalikan"
+	KRSDisetujui    = "Disetujui"
+)
+
+// maxKRSKelas membatasi jumlah kelas dalam satu permintaan ReplaceKelas
+const maxKRSKelas = 30
+
+// Kode detail field KRS
+const (
+	codeNotInSemester = "not_in_semester"
+	codeDuplicate     = "duplicate"
+	codeOnCuti        = "on_cuti"
+)
+
+var (
+	errKRSNotEligible = &apperror.Error{
+		Kind:   ErrUnprocessable,
+		Code:   i18n.MsgKRSNotEligible,
+		Fields: map[string]string{"status": codeNotEligible},
+	}
+	errKRSOnCuti = &apperror.Error{
+		Kind:   ErrUnprocessable,
+		Code:   i18n.MsgKRSOnCuti,
+		Fields: map[string]string{"id_semester": codeOnCuti},
+	}
+	errKRSLocked = &apperror.Error{
+		Kind:   apperror.ErrConflict,
+		Code:   i18n.MsgKRSLocked,
+		Fields: map[string]string{"status": codeAlreadySubmitted},
+	}
+	errKRSEmpty = &apperror.Error{
+		Kind:   ErrUnprocessable,
+		Code:   i18n.MsgKRSEmpty,
+		Fields: map[string]string{"kelas": apperror.CodeRequired},
+	}
+	errKRSNoPembimbing = &apperror.Error{
+		Kind:   ErrUnprocessable,
+		Code:   i18n.MsgKRSNoPembimbing,
+		Fields: map[string]string{"id_dosen": "no_pembimbing"},
+	}
+	errKRSNotPending = &apperror.Error{
+		Kind:   apperror.ErrConflict,
+		Code:   i18n.MsgKRSAlreadyProcessed,
+		Fields: map[string]string{"status": codeNotPending},
+	}
+)
+
+// KRSService mengelola KRS mahasiswa: disusun dan diajukan mahasiswa, lalu disetujui atau
+// dikembalikan PA. KRS yang diajukan atau disetujui dikunci trigger lock_final di database.
+type KRSService struct {
+	repo       KRSRepository
+	kurikulum  KurikulumRepository
+	pembimbing PembimbingRepository
+	mahasiswa  MahasiswaRepository
+	cuti       CutiRepository
+	tx         UnitOfWork
+	clock      Clock
+}
+
+func NewKRSService(r KRSRepository, kurikulum KurikulumRepository, pembimbing PembimbingRepository, mahasiswa MahasiswaRepository,
+	cuti CutiRepository, tx UnitOfWork, clock Clock) *KRSService {
+	return &KRSService{repo: r, kurikulum: kurikulum, pembimbing: pembimbing, mahasiswa: mahasiswa, cuti: cuti, tx: tx, clock: clock}
+}
+
+// owner mengembalikan NIM mahasiswa yang login; role lain ditolak
+func (a Actor) owner() (string, error) {
+	if a.Role != "mahasiswa" || a.RefID == nil {
+		return "", errForbidden
+	}
+	return *a.RefID, nil
+}
+
+// advisor mengembalikan id_dosen PA yang login; role lain ditolak
+func (a Actor) advisor() (string, error) {
+	if a.Role != "dosen" || a.RefID == nil {
+		return "", errForbidden
+	}
+	return *a.RefID, nil
+}
+
+func checkSemesterID(id string) (string, error) {
+	id = strings.TrimSpace(id)
+	if !semIDPattern.MatchString(id) {
+		return "", apperror.Field(ErrInvalidInput, "id_semester", codeSemesterID)
+	}
+	return id, nil
+}
+
+// krs menyusun KRS (mahasiswa, semester) dari kelas yang diambil dan pengajuannya
+func (s *KRSService) krs(ctx context.Context, nim, sem string) (*model.KRS, error) {
+	kelas, err := s.repo.ListKelas(ctx, nim, sem)
+	if err != nil {
+		return nil, err
+	}
+	out := &model.KRS{IDMahasiswa: nim, IDSemester: sem, Status: KRSDraft, Kelas: kelas}
+	for _, k := range kelas {
+		out.TotalSKS += k.SKS
+	}
+	p, err := s.repo.GetPengajuan(ctx, nim, sem)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return nil, err
+	default:
+		out.Status = p.Status
+		out.Pengajuan = p
+	}
+	return out, nil
+}
+
+// semester memastikan semester ada; semester yang tidak ada dianggap tidak ditemukan (parameter path)
+func (s *KRSService) semester(ctx context.Context, id string) error {
+	ok, err := s.repo.ExistsSemester(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Get mengembalikan KRS mahasiswa yang login pada semester
+func (s *KRSService) Get(ctx context.Context, sem string, actor Actor) (*model.KRS, error) {
+	ctx, span := tracing.Start(ctx, "KRSService.Get")
+	defer span.End()
+
+	nim, err := actor.owner()
+	if err != nil {
+		return nil, err
+	}
+	if sem, err = checkSemesterID(sem); err != nil {
+		return nil, err
+	}
+	if err := s.semester(ctx, sem); err != nil {
+		return nil, err
+	}
+	return s.krs(ctx, nim, sem)
+}
+
+// eligible mengunci mahasiswa dan memastikan ia boleh mengisi KRS semester sem: berstatus Aktif,
+// atau Cuti bila sem setelah semester yang sedang berjalan, dan tidak punya cuti yang disetujui
+// pada sem itu sendiri
+func (s *KRSService) eligible(ctx context.Context, nim, sem string) error {
+	if err := s.mahasiswa.LockByID(ctx, nim); err != nil {
+		return err
+	}
+	m, err := s.mahasiswa.GetByID(ctx, nim)
+	if err != nil {
+		return err
+	}
+	switch m.Status {
+	case StatusAktif:
+	case StatusCuti:
+		// id semester (tahun + term) urut kronologis
+		cur, err := s.cuti.CurrentSemester(ctx, s.clock.Today())
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		if cur != nil && sem <= cur.IDSemester {
+			return errKRSNotEligible
+		}
+	default:
+		return errKRSNotEligible
+	}
+	status, err := s.cuti.OpenStatus(ctx, nim, sem)
+	if err != nil {
+		return err
+	}
+	if status == CutiDisetujui {
+		return errKRSOnCuti
+	}
+	return nil
+}
+
+// Replace mengganti daftar kelas KRS mahasiswa yang login. Hanya KRS yang belum diajukan atau
+// dikembalikan PA yang bisa diubah.
+func (s *KRSService) Replace(ctx context.Context, sem string, kelas []string, actor Actor) (*model.KRS, error) {
+	ctx, span := tracing.Start(ctx, "KRSService.Replace")
+	defer span.End()
+
+	nim, err := actor.owner()
+	if err != nil {
+		return nil, err
+	}
+	if sem, err = checkSemesterID(sem); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(kelas))
+	for _, k := range kelas {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			return nil, apperror.Field(ErrInvalidInput, "kelas", apperror.CodeRequired)
+		}
+		if slices.Contains(ids, k) {
+			return nil, apperror.Field(ErrInvalidInput, "kelas", codeDuplicate)
+		}
+		ids = append(ids, k)
+	}
+	if len(ids) > maxKRSKelas {
+		return nil, apperror.Field(ErrInvalidInput, "kelas", codeMax(maxKRSKelas))
+	}
+
+	return inTx(ctx, s.tx, func(ctx context.Context) (*model.KRS, error) {
+		if err := s.semester(ctx, sem); err != nil {
+			return nil, err
+		}
+		if err := s.eligible(ctx, nim, sem); err != nil {
+			return nil, err
+		}
+		if p, err := s.repo.GetPengajuan(ctx, nim, sem); err == nil {
+			if p.Status != KRSDikembalikan {
+				return nil, errKRSLocked
+			}
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		outside, err := s.repo.KelasOutside(ctx, sem, ids)
+		if err != nil {
+			return nil, err
+		}
+		if len(outside) > 0 {
+			return nil, apperror.Field(ErrUnprocessable, "kelas", codeNotInSemester)
+		}
+		if err := s.repo.ReplaceKelas(ctx, nim, sem, ids); err != nil {
+			return nil, err
+		}
+		return s.krs(ctx, nim, sem)
+	})
+}
+
+// prasyarat mengembalikan prasyarat kelas KRS yang belum terpenuhi: nilai terbaik mata kuliah
+// prasyarat pada KRS yang sudah disetujui harus minimal nilai_minimal
+func (s *KRSService) prasyarat(ctx context.Context, nim string, kelas []model.KRSKelas) ([]model.PrasyaratBelumTerpenuhi, error) {
+	ids := make([]string, len(kelas))
+	for i, k := range kelas {
+		ids[i] = k.IDMK
+	}
+	rules, err := s.kurikulum.ListPrasyarat(ctx, ids)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	req := make([]string, len(rules))
+	for i, p := range rules {
+		req[i] = p.IDMKPrasyarat
+	}
+	best, err := s.repo.BestBobot(ctx, nim, req)
+	if err != nil {
+		return nil, err
+	}
+	var out []model.PrasyaratBelumTerpenuhi
+	for _, k := range kelas {
+		for _, p := range rules {
+			if p.IDMK != k.IDMK {
+				continue
+			}
+			b, ok := best[p.IDMKPrasyarat]
+			if ok && b >= bobotHuruf[p.NilaiMinimal] {
+				continue
+			}
+			u := model.PrasyaratBelumTerpenuhi{KodeMK: k.KodeMK, NamaMK: k.NamaMK, Prasyarat: p}
+			if ok {
+				h := hurufOf(b)
+				u.NilaiTerbaik = &h
+			}
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+// Submit mengajukan KRS mahasiswa yang login ke PA-nya. Syarat: lolos eligible, punya PA, KRS berisi
+// kelas, prasyarat setiap mata kuliah terpenuhi, dan belum diajukan atau sudah dikembalikan.
+func (s *KRSService) Submit(ctx context.Context, sem string, actor Actor) (*model.KRS, error) {
+	ctx, span := tracing.Start(ctx, "KRSService.Submit")
+	defer span.End()
+
+	nim, err := actor.owner()
+	if err != nil {
+		return nil, err
+	}
+	if sem, err = checkSemesterID(sem); err != nil {
+		return nil, err
+	}
+
+	rejected := false
+	out, err := inTx(ctx, s.tx, func(ctx context.Context) (*model.KRS, error) {
+		if err := s.semester(ctx, sem); err != nil {
+			return nil, err
+		}
+		reject := func(err error) (*model.KRS, error) {
+			rejected = true
+			return nil, err
+		}
+		if err := s.eligible(ctx, nim, sem); errors.Is(err, errKRSNotEligible) || errors.Is(err, errKRSOnCuti) {
+			return reject(err)
+		} else if err != nil {
+			return nil, err
+		}
+		cur, err := s.krs(ctx, nim, sem)
+		if err != nil {
+			return nil, err
+		}
+		if cur.Status != KRSDraft && cur.Status != KRSDikembalikan {
+			return reject(errKRSLocked)
+		}
+		if len(cur.Kelas) == 0 {
+			return reject(errKRSEmpty)
+		}
+		unmet, err := s.prasyarat(ctx, nim, cur.Kelas)
+		if err != nil {
+			return nil, err
+		}
+		if len(unmet) > 0 {
+			return reject(&apperror.Error{
+				Kind:    ErrUnprocessable,
+				Code:    i18n.MsgKRSPrasyarat,
+				Fields:  map[string]string{"kelas": codePrasyarat},
+				Details: unmet,
+			})
+		}
+		pa, err := s.pembimbing.Current(ctx, nim)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return reject(errKRSNoPembimbing)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.repo.Submit(ctx, nim, sem, pa.IDDosen); err != nil {
+			return nil, err
+		}
+		return s.krs(ctx, nim, sem)
+	})
+	if err == nil || rejected {
+		metrics.KRSSubmission(err == nil)
+	}
+	return out, err
+}
+
+// ListPengajuan mengembalikan pengajuan KRS yang ditujukan ke PA yang login
+func (s *KRSService) ListPengajuan(ctx context.Context, rawFilters map[string][]string, limit, offset int, orderBy string, actor Actor) ([]model.KRSPengajuan, int64, error) {
+	ctx, span := tracing.Start(ctx, "KRSService.ListPengajuan")
+	defer span.End()
+
+	idDosen, err := actor.advisor()
+	if err != nil {
+		return nil, 0, err
+	}
+	if limit < 0 || offset < 0 {
+		return nil, 0, ErrInvalidInput
+	}
+	filters, err := repo.ParseFilters(rawFilters, repo.KRSPengajuanFilterFields)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.repo.CountPengajuan(ctx, idDosen, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := s.repo.ListPengajuan(ctx, idDosen, filters, limit, offset, orderBy)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, total, nil
+}
+
+// pengajuan mengembalikan pengajuan id bila ditujukan ke PA yang login; selain itu dianggap tidak ada
+func (s *KRSService) pengajuan(ctx context.Context, id int64, actor Actor) (*model.KRSPengajuan, error) {
+	idDosen, err := actor.advisor()
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.repo.GetPengajuanByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.IDDosen == nil || *p.IDDosen != idDosen {
+		return nil, pgx.ErrNoRows
+	}
+	return p, nil
+}
+
+// GetPengajuan mengembalikan KRS lengkap (beserta kelas) dari satu pengajuan untuk PA-nya
+func (s *KRSService) GetPengajuan(ctx context.Context, id int64, actor Actor) (*model.KRS, error) {
+	ctx, span := tracing.Start(ctx, "KRSService.GetPengajuan")
+	defer span.End()
+
+	p, err := s.pengajuan(ctx, id, actor)
+	if err != nil {
+		return nil, err
+	}
+	return s.krs(ctx, p.IDMahasiswa, p.IDSemester)
+}
+
+// Review menyetujui atau mengembalikan KRS yang diajukan. Pengembalian wajib catatan agar
+// mahasiswa tahu apa yang perlu diperbaiki.
+func (s *KRSService) Review(ctx context.Context, id int64, approve bool, catatan *string, actor Actor) (*model.KRS, error) {
+	ctx, span := tracing.Start(ctx, "KRSService.Review")
+	defer span.End()
+
+	if _, err := actor.advisor(); err != nil {
+		return nil, err
+	}
+	if catatan != nil {
+		v := strings.TrimSpace(*catatan)
+		catatan = &v
+		if v == "" {
+			catatan = nil
+		} else if len(v) > 1000 {
+			return nil, apperror.Field(ErrInvalidInput, "catatan", codeMax(1000))
+		}
+	}
+	if !approve && catatan == nil {
+		return nil, apperror.Field(ErrInvalidInput, "catatan", apperror.CodeRequired)
+	}
+
+	return inTx(ctx, s.tx, func(ctx context.Context) (*model.KRS, error) {
+		if err := s.repo.LockPengajuan(ctx, id); err != nil {
+			return nil, err
+		}
+		p, err := s.pengajuan(ctx, id, actor)
+		if err != nil {
+			return nil, err
+		}
+		if p.Status != KRSDiajukan {
+			return nil, errKRSNotPending
+		}
+		status := KRSDikembalikan
+		if approve {
+			status = KRSDisetujui
+		}
+		if _, err := s.repo.Review(ctx, id, status, catatan); err != nil {
+			return nil, err
+		}
+		return s.krs(ctx, p.IDMahasiswa, p.IDSemester)
+	})
+}
@@ -0,0 +1,222 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	"pencatatan-data-mahasiswa/internal/todo/repository/memory"
+)
+
+const fixKelas2 = "KLS000000002"
+
+// newKRSService menyiapkan dua kelas di fixSemester (3 dan 2 SKS) dan fixDosen sebagai PA fixMahasiswa;
+// fixSemester sedang berjalan pada "hari ini" 2024-10-01
+func newKRSService(t *testing.T) (*KRSService, *PembimbingService, *memory.Store) {
+	t.Helper()
+	pa, s := newPembimbingService(t)
+	start, end := date("2024-09-01"), date("2025-01-31")
+	if _, err := memory.NewSemesterRepository(s).UpdatePatch(context.Background(), fixSemester, nil, nil, &start, &end); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddMataKuliah(memory.MataKuliah{IDMK: fixMK, KodeMK: "IF101", NamaMK: "Algoritma", SKS: 3, IDProdi: fixProdi}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddMataKuliah(memory.MataKuliah{IDMK: "MK00000002", KodeMK: "IF102", NamaMK: "Basis Data", SKS: 2, IDProdi: fixProdi}); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []memory.KelasKuliah{
+		{IDKelas: fixKelas, NamaKelas: "A", IDMK: fixMK, IDSemester: fixSemester, IDDosenPengampu: fixDosen},
+		{IDKelas: fixKelas2, NamaKelas: "A", IDMK: "MK00000002", IDSemester: fixSemester, IDDosenPengampu: fixDosen},
+	} {
+		if err := s.AddKelas(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pa.Assign(context.Background(), fixMahasiswa, PembimbingAssignment{IDDosen: fixDosen}, adminActor); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewKRSService(memory.NewKRSRepository(s), memory.NewKurikulumRepository(s), memory.NewPembimbingRepository(s),
+		memory.NewMahasiswaRepository(s), memory.NewCutiRepository(s), s, Clock{Now: func() time.Time { return date("2024-10-01") }})
+	return svc, pa, s
+}
+
+// TestKRSApprovalWorkflow: disusun, diajukan (terkunci), dikembalikan dengan catatan, diperbaiki,
+// diajukan ulang lalu disetujui PA
+func TestKRSApprovalWorkflow(t *testing.T) {
+	ctx := context.Background()
+	svc, _, s := newKRSService(t)
+	dosen2 := Actor{Username: fixDosen2, Role: "dosen", RefID: strPtr(fixDosen2)}
+
+	if _, err := svc.Submit(ctx, fixSemester, mahasiswaActor); !errors.Is(err, errKRSEmpty) {
+		t.Errorf("submit empty krs: error = %v, want %v", err, errKRSEmpty)
+	}
+	k, err := svc.Replace(ctx, fixSemester, []string{fixKelas, fixKelas2}, mahasiswaActor)
+	if err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+	if k.Status != KRSDraft || k.TotalSKS != 5 || len(k.Kelas) != 2 {
+		t.Errorf("draft krs = %+v", k)
+	}
+	if k, err = svc.Submit(ctx, fixSemester, mahasiswaActor); err != nil || k.Status != KRSDiajukan {
+		t.Fatalf("Submit = %+v, %v", k, err)
+	}
+	if _, err := svc.Replace(ctx, fixSemester, []string{fixKelas}, mahasiswaActor); !errors.Is(err, errKRSLocked) {
+		t.Errorf("replace submitted krs: error = %v, want %v", err, errKRSLocked)
+	}
+	if err := s.AddKRS(memory.KRS{IDMahasiswa: fixMahasiswa, IDKelas: "KLS000000009", IDSemester: fixSemester}); err == nil {
+		t.Error("AddKRS on submitted krs: want error")
+	}
+
+	list, total, err := svc.ListPengajuan(ctx, map[string][]string{"status": {KRSDiajukan}}, 10, 0, "", dosenActor)
+	if err != nil || total != 1 || list[0].TotalSKS != 5 || list[0].NamaLengkap == "" {
+		t.Fatalf("ListPengajuan = %d %+v, %v", total, list, err)
+	}
+	id := list[0].IDPengajuan
+	if _, err := svc.GetPengajuan(ctx, id, dosen2); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("other dosen: error = %v, want not found", err)
+	}
+	if _, err := svc.Review(ctx, id, false, strPtr("  "), dosenActor); apperror.FieldsOf(err)["catatan"] != apperror.CodeRequired {
+		t.Errorf("return without catatan: error = %v", err)
+	}
+	if k, err = svc.Review(ctx, id, false, strPtr("Basis Data belum waktunya"), dosenActor); err != nil || k.Status != KRSDikembalikan {
+		t.Fatalf("return = %+v, %v", k, err)
+	}
+
+	if k, err = svc.Replace(ctx, fixSemester, []string{fixKelas}, mahasiswaActor); err != nil || k.TotalSKS != 3 {
+		t.Fatalf("replace returned krs = %+v, %v", k, err)
+	}
+	if k, err = svc.Submit(ctx, fixSemester, mahasiswaActor); err != nil || k.Pengajuan.Catatan != nil {
+		t.Fatalf("resubmit = %+v, %v", k, err)
+	}
+	if _, err := svc.Review(ctx, id, true, nil, mahasiswaActor); !errors.Is(err, apperror.ErrForbidden) {
+		t.Errorf("approve by mahasiswa: error = %v, want forbidden", err)
+	}
+	if k, err = svc.Review(ctx, id, true, nil, dosenActor); err != nil || k.Status != KRSDisetujui {
+		t.Fatalf("approve = %+v, %v", k, err)
+	}
+	if _, err := svc.Review(ctx, id, true, nil, dosenActor); !errors.Is(err, errKRSNotPending) {
+		t.Errorf("approve twice: error = %v, want %v", err, errKRSNotPending)
+	}
+	if _, err := svc.Submit(ctx, fixSemester, mahasiswaActor); !errors.Is(err, errKRSLocked) {
+		t.Errorf("submit approved krs: error = %v, want %v", err, errKRSLocked)
+	}
+}
+
+func TestKRSSubmitRules(t *testing.T) {
+	ctx := context.Background()
+	svc, pa, s := newKRSService(t)
+
+	if _, err := svc.Replace(ctx, fixSemester, []string{fixKelas, fixKelas}, mahasiswaActor); apperror.FieldsOf(err)["kelas"] != codeDuplicate {
+		t.Errorf("duplicate kelas: error = %v", err)
+	}
+	if _, err := svc.Replace(ctx, fixSemester, []string{"KLS999999999"}, mahasiswaActor); apperror.FieldsOf(err)["kelas"] != codeNotInSemester {
+		t.Errorf("unknown kelas: error = %v", err)
+	}
+	if _, err := svc.Get(ctx, "20249", mahasiswaActor); !errors.Is(err, apperror.ErrInvalidInput) {
+		t.Errorf("invalid semester id: error = %v", err)
+	}
+	if _, err := svc.Get(ctx, fixSemesterGenap, mahasiswaActor); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("unknown semester: error = %v, want not found", err)
+	}
+	if _, err := svc.Get(ctx, fixSemester, dosenActor); !errors.Is(err, apperror.ErrForbidden) {
+		t.Errorf("dosen reading own krs: error = %v, want forbidden", err)
+	}
+
+	// mahasiswa tanpa PA tidak bisa mengajukan
+	other := Actor{Username: fixMahasiswa2, Role: "mahasiswa", RefID: strPtr(fixMahasiswa2)}
+	if _, err := svc.Replace(ctx, fixSemester, []string{fixKelas}, other); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Submit(ctx, fixSemester, other); !errors.Is(err, errKRSNoPembimbing) {
+		t.Errorf("submit without pembimbing: error = %v, want %v", err, errKRSNoPembimbing)
+	}
+
+	// pengajuan yang masih menunggu ikut pindah ke PA baru
+	if _, err := svc.Replace(ctx, fixSemester, []string{fixKelas}, mahasiswaActor); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Submit(ctx, fixSemester, mahasiswaActor); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pa.Assign(ctx, fixMahasiswa, PembimbingAssignment{IDDosen: fixDosen2}, adminActor); err != nil {
+		t.Fatal(err)
+	}
+	dosen2 := Actor{Username: fixDosen2, Role: "dosen", RefID: strPtr(fixDosen2)}
+	if _, total, _ := svc.ListPengajuan(ctx, nil, 10, 0, "", dosen2); total != 1 {
+		t.Errorf("pengajuan for new pembimbing = %d, want 1", total)
+	}
+
+	if _, err := memory.NewMahasiswaRepository(s).UpdateStatus(ctx, fixMahasiswa2, StatusCuti); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Submit(ctx, fixSemester, other); !errors.Is(err, errKRSNotEligible) {
+		t.Errorf("submit while Cuti: error = %v, want %v", err, errKRSNotEligible)
+	}
+	// semester berikutnya sudah boleh disusun walau status masih Cuti
+	if _, err := memory.NewSemesterRepository(s).Create(ctx, &model.Semester{IDSemester: fixSemesterGenap, TahunAjaran: "2024/2025", Term: "Genap"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddKelas(memory.KelasKuliah{IDKelas: "KLS000000003", NamaKelas: "A", IDMK: fixMK, IDSemester: fixSemesterGenap, IDDosenPengampu: fixDosen}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Replace(ctx, fixSemesterGenap, []string{"KLS000000003"}, other); err != nil {
+		t.Errorf("replace next semester while Cuti: %v", err)
+	}
+
+	// cuti yang disetujui pada semester tujuan menolak KRS walau status masih Aktif
+	cuti := memory.NewCutiRepository(s)
+	c, err := cuti.Create(ctx, &model.Cuti{IDMahasiswa: fixMahasiswa, IDSemester: fixSemesterGenap, Alasan: "Sakit", Status: CutiDiajukan}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cuti.SetStatus(ctx, c.IDCuti, CutiDisetujui, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Replace(ctx, fixSemesterGenap, []string{"KLS000000003"}, mahasiswaActor); !errors.Is(err, errKRSOnCuti) {
+		t.Errorf("replace in approved cuti semester: error = %v, want %v", err, errKRSOnCuti)
+	}
+}
+
+// TestBimbinganIPK: IPK hanya dihitung dari KRS yang disetujui, nilai terbaik per mata kuliah
+func TestBimbinganIPK(t *testing.T) {
+	ctx := context.Background()
+	svc, pa, s := newKRSService(t)
+
+	if _, err := svc.Replace(ctx, fixSemester, []string{fixKelas, fixKelas2}, mahasiswaActor); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddNilai(fixMahasiswa, fixKelas, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddNilai(fixMahasiswa, fixKelas2, 2.5); err != nil {
+		t.Fatal(err)
+	}
+	ipk := func() *float64 {
+		t.Helper()
+		list, _, err := pa.ListBimbingan(ctx, "", map[string][]string{"id_mahasiswa": {fixMahasiswa}}, 10, 0, "", dosenActor)
+		if err != nil || len(list) != 1 {
+			t.Fatalf("ListBimbingan = %+v, %v", list, err)
+		}
+		return list[0].IPK
+	}
+	if got := ipk(); got != nil {
+		t.Errorf("ipk before approval = %v, want nil", *got)
+	}
+	k, err := svc.Submit(ctx, fixSemester, mahasiswaActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Review(ctx, k.Pengajuan.IDPengajuan, true, nil, dosenActor); err != nil {
+		t.Fatal(err)
+	}
+	// (4*3 + 2.5*2) / 5 = 3.4
+	if got := ipk(); got == nil || *got != 3.4 {
+		t.Errorf("ipk after approval = %v, want 3.4", got)
+	}
+}
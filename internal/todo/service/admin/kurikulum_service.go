@@ -0,0 +1,389 @@
+package admin
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+	"pencatatan-data-mahasiswa/internal/tracing"
+)
+
+// Jenis mata kuliah pada kurikulum
+const (
+	JenisWajib   = "Wajib"
+	JenisPilihan = "Pilihan"
+)
+
+// Batas jumlah item dalam satu permintaan ganti daftar
+const (
+	maxKurikulumMK = 200
+	maxPrasyarat   = 10
+)
+
+// Kode detail field kurikulum dan prasyarat
+const (
+	codeTahunRange    = "range_1900_2100"
+	codeSemesterRange = "range_1_14"
+	codeSelf          = "self"
+	codeCycle         = "cycle"
+	codePrasyarat     = "prasyarat_unmet"
+	codeOtherProdi    = "other_prodi"
+)
+
+// nilaiHuruf urut dari yang terbaik; bobotHuruf adalah bobot minimal tiap huruf (skala 4, sama
+// dengan kolom nilai.bobot)
+var (
+	nilaiHuruf = []string{"A", "AB", "B", "BC", "C", "D", "E"}
+	bobotHuruf = map[string]float64{"A": 4, "AB": 3.5, "B": 3, "BC": 2.5, "C": 2, "D": 1, "E": 0}
+)
+
+// hurufOf mengembalikan nilai huruf tertinggi yang bobot minimalnya terpenuhi
+func hurufOf(bobot float64) string {
+	for _, h := range nilaiHuruf {
+		if bobot >= bobotHuruf[h] {
+			return h
+		}
+	}
+	return "E"
+}
+
+var errPrasyaratCycle = &apperror.Error{
+	Kind:   ErrUnprocessable,
+	Code:   i18n.MsgPrasyaratCycle,
+	Fields: map[string]string{"prasyarat": codeCycle},
+}
+
+// KurikulumService mengelola versi kurikulum per prodi (mata kuliah, semester anjuran dan jenis)
+// serta prasyarat mata kuliah yang diperiksa saat KRS diajukan
+type KurikulumService struct {
+	repo KurikulumRepository
+	tx   UnitOfWork
+}
+
+func NewKurikulumService(r KurikulumRepository, tx UnitOfWork) *KurikulumService {
+	return &KurikulumService{repo: r, tx: tx}
+}
+
+// List mengembalikan daftar kurikulum; terbuka untuk semua role
+func (s *KurikulumService) List(ctx context.Context, rawFilters map[string][]string, limit, offset int, orderBy string) ([]model.Kurikulum, int64, error) {
+	ctx, span := tracing.Start(ctx, "KurikulumService.List")
+	defer span.End()
+
+	if limit < 0 || offset < 0 {
+		return nil, 0, ErrInvalidInput
+	}
+	filters, err := repo.ParseFilters(rawFilters, repo.KurikulumFilterFields)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.repo.Count(ctx, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := s.repo.List(ctx, filters, limit, offset, orderBy)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, total, nil
+}
+
+// Get mengembalikan kurikulum beserta mata kuliah dan prasyaratnya
+func (s *KurikulumService) Get(ctx context.Context, id int64) (*model.KurikulumDetail, error) {
+	ctx, span := tracing.Start(ctx, "KurikulumService.Get")
+	defer span.End()
+
+	return s.detail(ctx, id)
+}
+
+func (s *KurikulumService) detail(ctx context.Context, id int64) (*model.KurikulumDetail, error) {
+	k, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	mks, err := s.repo.ListMataKuliah(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(mks))
+	for i, m := range mks {
+		ids[i] = m.IDMK
+	}
+	rules, err := s.repo.ListPrasyarat(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range mks {
+		mks[i].Prasyarat = []model.Prasyarat{}
+		for _, p := range rules {
+			if p.IDMK == mks[i].IDMK {
+				mks[i].Prasyarat = append(mks[i].Prasyarat, p)
+			}
+		}
+	}
+	return &model.KurikulumDetail{Kurikulum: *k, MataKuliah: mks}, nil
+}
+
+// normalizeKurikulum merapikan dan memvalidasi field kurikulum yang bisa diubah
+func normalizeKurikulum(fe apperror.Fields, k *model.Kurikulum) {
+	k.Nama = strings.TrimSpace(k.Nama)
+	checkLength(fe, "nama", k.Nama, 3, 120)
+	if k.Tahun < 1900 || k.Tahun > 2100 {
+		fe.Add("tahun", codeTahunRange)
+	}
+}
+
+// save memastikan tahun unik per prodi dan menonaktifkan kurikulum aktif lain bila k aktif;
+// dipanggil di dalam transaksi dan mengunci prodi agar tidak bentrok dengan perubahan lain
+func (s *KurikulumService) save(ctx context.Context, idProdi string, k *model.Kurikulum, excludeID *int64) error {
+	if err := s.tx.LockKey(ctx, lockKurikulumProdi, idProdi); err != nil {
+		return err
+	}
+	taken, err := s.repo.ExistsTahun(ctx, idProdi, k.Tahun, excludeID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return apperror.Field(apperror.ErrConflict, "tahun", apperror.CodeTaken)
+	}
+	if !k.Aktif {
+		return nil
+	}
+	var except int64
+	if excludeID != nil {
+		except = *excludeID
+	}
+	return s.repo.Deactivate(ctx, idProdi, except)
+}
+
+// Create menambah kurikulum prodi. Kurikulum baru yang aktif menonaktifkan kurikulum aktif
+// sebelumnya; operator dengan ref_id hanya untuk prodinya.
+func (s *KurikulumService) Create(ctx context.Context, k *model.Kurikulum, actor Actor) (*model.Kurikulum, error) {
+	ctx, span := tracing.Start(ctx, "KurikulumService.Create")
+	defer span.End()
+
+	fe := apperror.Fields{}
+	k.IDProdi = strings.TrimSpace(k.IDProdi)
+	if k.IDProdi == "" {
+		fe.Add("id_prodi", apperror.CodeRequired)
+	}
+	normalizeKurikulum(fe, k)
+	if err := fe.Err(); err != nil {
+		return nil, err
+	}
+	if !actor.inProdi(k.IDProdi) {
+		return nil, errForbidden
+	}
+	return inTx(ctx, s.tx, func(ctx context.Context) (*model.Kurikulum, error) {
+		ok, err := s.repo.ExistsProdi(ctx, k.IDProdi)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, apperror.Field(ErrUnprocessable, "id_prodi", apperror.CodeNotFound)
+		}
+		if err := s.save(ctx, k.IDProdi, k, nil); err != nil {
+			return nil, err
+		}
+		return s.repo.Create(ctx, k)
+	})
+}
+
+// edit mengunci kurikulum id dan memastikan actor boleh mengubahnya
+func (s *KurikulumService) edit(ctx context.Context, id int64, actor Actor) (*model.Kurikulum, error) {
+	if err := s.repo.LockByID(ctx, id); err != nil {
+		return nil, err
+	}
+	cur, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.inProdi(cur.IDProdi) {
+		return nil, errForbidden
+	}
+	return cur, nil
+}
+
+// Update mengganti tahun, nama dan status aktif kurikulum; prodi tidak bisa dipindah
+func (s *KurikulumService) Update(ctx context.Context, id int64, k *model.Kurikulum, actor Actor) (*model.Kurikulum, error) {
+	ctx, span := tracing.Start(ctx, "KurikulumService.Update")
+	defer span.End()
+
+	fe := apperror.Fields{}
+	normalizeKurikulum(fe, k)
+	if err := fe.Err(); err != nil {
+		return nil, err
+	}
+	return inTx(ctx, s.tx, func(ctx context.Context) (*model.Kurikulum, error) {
+		cur, err := s.edit(ctx, id, actor)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.save(ctx, cur.IDProdi, k, &id); err != nil {
+			return nil, err
+		}
+		return s.repo.Update(ctx, id, k)
+	})
+}
+
+// Delete menghapus kurikulum beserta daftar mata kuliahnya; mata kuliah dan prasyaratnya tetap ada
+func (s *KurikulumService) Delete(ctx context.Context, id int64, actor Actor) error {
+	ctx, span := tracing.Start(ctx, "KurikulumService.Delete")
+	defer span.End()
+
+	return s.tx.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.edit(ctx, id, actor); err != nil {
+			return err
+		}
+		return s.repo.Delete(ctx, id)
+	})
+}
+
+// ReplaceMataKuliah mengganti seluruh daftar mata kuliah kurikulum. Jenis kosong berarti Wajib;
+// mata kuliah harus milik prodi kurikulum.
+func (s *KurikulumService) ReplaceMataKuliah(ctx context.Context, id int64, items []model.KurikulumMK, actor Actor) (*model.KurikulumDetail, error) {
+	ctx, span := tracing.Start(ctx, "KurikulumService.ReplaceMataKuliah")
+	defer span.End()
+
+	if len(items) > maxKurikulumMK {
+		return nil, apperror.Field(ErrInvalidInput, "mata_kuliah", codeMax(maxKurikulumMK))
+	}
+	fe := apperror.Fields{}
+	ids := make([]string, 0, len(items))
+	for i := range items {
+		m := &items[i]
+		m.IDMK = strings.TrimSpace(m.IDMK)
+		switch {
+		case m.IDMK == "":
+			fe.Add("id_mk", apperror.CodeRequired)
+		case slices.Contains(ids, m.IDMK):
+			fe.Add("id_mk", codeDuplicate)
+		}
+		ids = append(ids, m.IDMK)
+		if m.Semester < 1 || m.Semester > 14 {
+			fe.Add("semester", codeSemesterRange)
+		}
+		if m.Jenis == "" {
+			m.Jenis = JenisWajib
+		}
+		if m.Jenis != JenisWajib && m.Jenis != JenisPilihan {
+			fe.Add("jenis", apperror.CodeInvalid)
+		}
+	}
+	if err := fe.Err(); err != nil {
+		return nil, err
+	}
+
+	return inTx(ctx, s.tx, func(ctx context.Context) (*model.KurikulumDetail, error) {
+		cur, err := s.edit(ctx, id, actor)
+		if err != nil {
+			return nil, err
+		}
+		missing, err := s.repo.MataKuliahMissing(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		if len(missing) > 0 {
+			return nil, apperror.Field(ErrUnprocessable, "id_mk", apperror.CodeNotFound)
+		}
+		foreign, err := s.repo.MataKuliahOutsideProdi(ctx, cur.IDProdi, ids)
+		if err != nil {
+			return nil, err
+		}
+		if len(foreign) > 0 {
+			return nil, apperror.Field(ErrUnprocessable, "id_mk", codeOtherProdi)
+		}
+		if err := s.repo.ReplaceMataKuliah(ctx, id, items); err != nil {
+			return nil, err
+		}
+		return s.detail(ctx, id)
+	})
+}
+
+// Prasyarat mengembalikan prasyarat mata kuliah idMK. Prasyarat melekat pada mata kuliah, bukan
+// kurikulum: berlaku sama di semua kurikulum yang memuat idMK.
+func (s *KurikulumService) Prasyarat(ctx context.Context, idMK string) ([]model.Prasyarat, error) {
+	ctx, span := tracing.Start(ctx, "KurikulumService.Prasyarat")
+	defer span.End()
+
+	idMK = strings.TrimSpace(idMK)
+	if _, err := s.repo.MataKuliahProdi(ctx, idMK); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPrasyarat(ctx, []string{idMK})
+}
+
+// ReplacePrasyarat mengganti seluruh prasyarat mata kuliah idMK. Nilai minimal kosong berarti C.
+// Prasyarat yang membuat rantai melingkar (X butuh Y, Y butuh X) ditolak. Perubahan berlaku untuk
+// semua kurikulum yang memuat idMK.
+func (s *KurikulumService) ReplacePrasyarat(ctx context.Context, idMK string, items []model.Prasyarat, actor Actor) ([]model.Prasyarat, error) {
+	ctx, span := tracing.Start(ctx, "KurikulumService.ReplacePrasyarat")
+	defer span.End()
+
+	idMK = strings.TrimSpace(idMK)
+	if len(items) > maxPrasyarat {
+		return nil, apperror.Field(ErrInvalidInput, "prasyarat", codeMax(maxPrasyarat))
+	}
+	fe := apperror.Fields{}
+	ids := make([]string, 0, len(items))
+	for i := range items {
+		p := &items[i]
+		p.IDMKPrasyarat = strings.TrimSpace(p.IDMKPrasyarat)
+		switch {
+		case p.IDMKPrasyarat == "":
+			fe.Add("id_mk_prasyarat", apperror.CodeRequired)
+		case p.IDMKPrasyarat == idMK:
+			fe.Add("id_mk_prasyarat", codeSelf)
+		case slices.Contains(ids, p.IDMKPrasyarat):
+			fe.Add("id_mk_prasyarat", codeDuplicate)
+		}
+		ids = append(ids, p.IDMKPrasyarat)
+		p.NilaiMinimal = strings.ToUpper(strings.TrimSpace(p.NilaiMinimal))
+		if p.NilaiMinimal == "" {
+			p.NilaiMinimal = "C"
+		}
+		if _, ok := bobotHuruf[p.NilaiMinimal]; !ok {
+			fe.Add("nilai_minimal", apperror.CodeInvalid)
+		}
+	}
+	if err := fe.Err(); err != nil {
+		return nil, err
+	}
+
+	return inTx(ctx, s.tx, func(ctx context.Context) ([]model.Prasyarat, error) {
+		// satu kunci untuk seluruh graf prasyarat agar dua perubahan bersamaan tidak membentuk siklus
+		if err := s.tx.LockKey(ctx, lockPrasyarat, ""); err != nil {
+			return nil, err
+		}
+		idProdi, err := s.repo.MataKuliahProdi(ctx, idMK)
+		if err != nil {
+			return nil, err
+		}
+		if !actor.inProdi(idProdi) {
+			return nil, errForbidden
+		}
+		missing, err := s.repo.MataKuliahMissing(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		if len(missing) > 0 {
+			return nil, apperror.Field(ErrUnprocessable, "id_mk_prasyarat", apperror.CodeNotFound)
+		}
+		dependents, err := s.repo.RequiredBy(ctx, idMK)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if slices.Contains(dependents, id) {
+				return nil, errPrasyaratCycle
+			}
+		}
+		if err := s.repo.ReplacePrasyarat(ctx, idMK, items); err != nil {
+			return nil, err
+		}
+		return s.repo.ListPrasyarat(ctx, []string{idMK})
+	})
+}
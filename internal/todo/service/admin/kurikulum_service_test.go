@@ -0,0 +1,244 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	"pencatatan-data-mahasiswa/internal/todo/repository/memory"
+)
+
+const fixMK2 = "MK00000002"
+
+// TestKurikulumSingleActive: tahun unik per prodi dan hanya satu kurikulum aktif per prodi
+func TestKurikulumSingleActive(t *testing.T) {
+	ctx := context.Background()
+	s := seedStore(t)
+	svc := NewKurikulumService(memory.NewKurikulumRepository(s), s)
+
+	k2020, err := svc.Create(ctx, &model.Kurikulum{IDProdi: fixProdi, Tahun: 2020, Nama: "Kurikulum 2020", Aktif: true}, prodiOperator)
+	if err != nil {
+		t.Fatalf("Create 2020: %v", err)
+	}
+	if _, err := svc.Create(ctx, &model.Kurikulum{IDProdi: fixProdi, Tahun: 2020, Nama: "Duplikat"}, adminActor); apperror.FieldsOf(err)["tahun"] != apperror.CodeTaken {
+		t.Errorf("duplicate tahun: error = %v, want tahun %s", err, apperror.CodeTaken)
+	}
+	if _, err := svc.Create(ctx, &model.Kurikulum{IDProdi: fixProdi, Tahun: 2024, Nama: "Kurikulum 2024"}, otherOperator); !errors.Is(err, errForbidden) {
+		t.Errorf("other prodi operator: error = %v, want %v", err, errForbidden)
+	}
+	if _, err := svc.Create(ctx, &model.Kurikulum{IDProdi: "PRD00009", Tahun: 2024, Nama: "Kurikulum 2024"}, adminActor); apperror.FieldsOf(err)["id_prodi"] != apperror.CodeNotFound {
+		t.Errorf("unknown prodi: error = %v, want id_prodi not_found", err)
+	}
+	if _, err := svc.Create(ctx, &model.Kurikulum{IDProdi: fixProdi, Tahun: 1800, Nama: "K"}, adminActor); apperror.FieldsOf(err)["tahun"] != codeTahunRange {
+		t.Errorf("tahun out of range: error = %v", err)
+	}
+
+	k2024, err := svc.Create(ctx, &model.Kurikulum{IDProdi: fixProdi, Tahun: 2024, Nama: "Kurikulum 2024", Aktif: true}, adminActor)
+	if err != nil {
+		t.Fatalf("Create 2024: %v", err)
+	}
+	list, total, err := svc.List(ctx, map[string][]string{"aktif": {"true"}}, 10, 0, "")
+	if err != nil || total != 1 || list[0].IDKurikulum != k2024.IDKurikulum {
+		t.Fatalf("active list = %d %+v, %v", total, list, err)
+	}
+	if _, err := svc.Update(ctx, k2024.IDKurikulum, &model.Kurikulum{Tahun: 2020, Nama: "Kurikulum 2024"}, adminActor); apperror.FieldsOf(err)["tahun"] != apperror.CodeTaken {
+		t.Errorf("update to taken tahun: error = %v", err)
+	}
+	if _, err := svc.Update(ctx, k2020.IDKurikulum, &model.Kurikulum{Tahun: 2020, Nama: "Kurikulum 2020 Revisi", Aktif: true}, adminActor); err != nil {
+		t.Fatalf("reactivate 2020: %v", err)
+	}
+	if got, err := svc.Get(ctx, k2024.IDKurikulum); err != nil || got.Aktif {
+		t.Errorf("2024 after reactivating 2020 = %+v, %v; want inactive", got, err)
+	}
+	if err := svc.Delete(ctx, k2024.IDKurikulum, otherOperator); !errors.Is(err, errForbidden) {
+		t.Errorf("delete by other prodi operator: error = %v", err)
+	}
+	if err := svc.Delete(ctx, k2024.IDKurikulum, prodiOperator); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+}
+
+// TestKurikulumMataKuliah: validasi daftar mata kuliah dan total SKS kurikulum
+func TestKurikulumMataKuliah(t *testing.T) {
+	ctx := context.Background()
+	_, _, s := newKRSService(t)
+	if _, err := memory.NewProdiRepository(s).Create(ctx, &model.Prodi{IDProdi: "PRD00002", IDFakultas: fixFakultas, NamaProdi: "Sistem Informasi", Jenjang: "S1", KodeProdi: "SI"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddMataKuliah(memory.MataKuliah{IDMK: "MK00000009", KodeMK: "SI101", NamaMK: "Pengantar SI", SKS: 2, IDProdi: "PRD00002"}); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewKurikulumService(memory.NewKurikulumRepository(s), s)
+	k, err := svc.Create(ctx, &model.Kurikulum{IDProdi: fixProdi, Tahun: 2024, Nama: "Kurikulum 2024"}, adminActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		items []model.KurikulumMK
+		field string
+		code  string
+	}{
+		{"required", []model.KurikulumMK{{Semester: 1}}, "id_mk", apperror.CodeRequired},
+		{"duplicate", []model.KurikulumMK{{IDMK: fixMK, Semester: 1}, {IDMK: fixMK, Semester: 2}}, "id_mk", codeDuplicate},
+		{"semester", []model.KurikulumMK{{IDMK: fixMK, Semester: 15}}, "semester", codeSemesterRange},
+		{"jenis", []model.KurikulumMK{{IDMK: fixMK, Semester: 1, Jenis: "Bebas"}}, "jenis", apperror.CodeInvalid},
+		{"unknown mk", []model.KurikulumMK{{IDMK: "MK99999999", Semester: 1}}, "id_mk", apperror.CodeNotFound},
+		{"other prodi", []model.KurikulumMK{{IDMK: fixMK, Semester: 1}, {IDMK: "MK00000009", Semester: 1}}, "id_mk", codeOtherProdi},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.ReplaceMataKuliah(ctx, k.IDKurikulum, tc.items, adminActor); apperror.FieldsOf(err)[tc.field] != tc.code {
+				t.Errorf("error = %v, want %s %s", err, tc.field, tc.code)
+			}
+		})
+	}
+
+	d, err := svc.ReplaceMataKuliah(ctx, k.IDKurikulum, []model.KurikulumMK{
+		{IDMK: fixMK2, Semester: 2, Jenis: JenisPilihan},
+		{IDMK: fixMK, Semester: 1},
+	}, prodiOperator)
+	if err != nil {
+		t.Fatalf("ReplaceMataKuliah: %v", err)
+	}
+	if d.JumlahMK != 2 || d.TotalSKS != 5 || d.MataKuliah[0].IDMK != fixMK || d.MataKuliah[0].Jenis != JenisWajib {
+		t.Errorf("detail = %+v", d)
+	}
+	if _, err := svc.ReplaceMataKuliah(ctx, k.IDKurikulum, nil, otherOperator); !errors.Is(err, errForbidden) {
+		t.Errorf("other prodi operator: error = %v", err)
+	}
+}
+
+// TestPrasyaratCycle: prasyarat diri sendiri dan rantai melingkar ditolak
+func TestPrasyaratCycle(t *testing.T) {
+	ctx := context.Background()
+	_, _, s := newKRSService(t)
+	if err := s.AddMataKuliah(memory.MataKuliah{IDMK: "MK00000003", KodeMK: "IF201", NamaMK: "Sistem Basis Data Lanjut", SKS: 3, IDProdi: fixProdi}); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewKurikulumService(memory.NewKurikulumRepository(s), s)
+
+	if _, err := svc.ReplacePrasyarat(ctx, fixMK, []model.Prasyarat{{IDMKPrasyarat: fixMK}}, adminActor); apperror.FieldsOf(err)["id_mk_prasyarat"] != codeSelf {
+		t.Errorf("self: error = %v", err)
+	}
+	if _, err := svc.ReplacePrasyarat(ctx, fixMK, []model.Prasyarat{{IDMKPrasyarat: fixMK2, NilaiMinimal: "F"}}, adminActor); apperror.FieldsOf(err)["nilai_minimal"] != apperror.CodeInvalid {
+		t.Errorf("invalid nilai: error = %v", err)
+	}
+	// MK3 butuh MK2, MK2 butuh MK1
+	got, err := svc.ReplacePrasyarat(ctx, fixMK2, []model.Prasyarat{{IDMKPrasyarat: fixMK}}, prodiOperator)
+	if err != nil || len(got) != 1 || got[0].NilaiMinimal != "C" {
+		t.Fatalf("ReplacePrasyarat MK2 = %+v, %v", got, err)
+	}
+	if _, err := svc.ReplacePrasyarat(ctx, "MK00000003", []model.Prasyarat{{IDMKPrasyarat: fixMK2, NilaiMinimal: "b"}}, adminActor); err != nil {
+		t.Fatalf("ReplacePrasyarat MK3: %v", err)
+	}
+	if _, err := svc.ReplacePrasyarat(ctx, fixMK, []model.Prasyarat{{IDMKPrasyarat: "MK00000003"}}, adminActor); !errors.Is(err, errPrasyaratCycle) {
+		t.Errorf("cycle: error = %v, want %v", err, errPrasyaratCycle)
+	}
+	if _, err := svc.ReplacePrasyarat(ctx, fixMK2, nil, otherOperator); !errors.Is(err, errForbidden) {
+		t.Errorf("other prodi operator: error = %v", err)
+	}
+	if got, err := svc.Prasyarat(ctx, "MK00000003"); err != nil || len(got) != 1 || got[0].NilaiMinimal != "B" {
+		t.Errorf("Prasyarat MK3 = %+v, %v", got, err)
+	}
+}
+
+// TestPrasyaratGlobal: prasyarat melekat pada mata kuliah sehingga tampil sama di setiap
+// kurikulum yang memuatnya, termasuk kurikulum lama yang sudah tidak aktif
+func TestPrasyaratGlobal(t *testing.T) {
+	ctx := context.Background()
+	_, _, s := newKRSService(t)
+	svc := NewKurikulumService(memory.NewKurikulumRepository(s), s)
+	var ids []int64
+	for _, tahun := range []int{2020, 2024} {
+		k, err := svc.Create(ctx, &model.Kurikulum{IDProdi: fixProdi, Tahun: tahun, Nama: "Kurikulum", Aktif: true}, adminActor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := svc.ReplaceMataKuliah(ctx, k.IDKurikulum, []model.KurikulumMK{{IDMK: fixMK, Semester: 1}, {IDMK: fixMK2, Semester: 2}}, adminActor); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, k.IDKurikulum)
+	}
+	if _, err := svc.ReplacePrasyarat(ctx, fixMK2, []model.Prasyarat{{IDMKPrasyarat: fixMK, NilaiMinimal: "B"}}, adminActor); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		d, err := svc.Get(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p := d.MataKuliah[1].Prasyarat; len(p) != 1 || p[0].IDMKPrasyarat != fixMK || p[0].NilaiMinimal != "B" {
+			t.Errorf("kurikulum %d prasyarat %s = %+v", id, fixMK2, p)
+		}
+	}
+}
+
+// TestKRSSubmitPrasyarat: KRS ditolak dengan daftar prasyarat yang belum terpenuhi sampai
+// prasyarat lulus dengan nilai minimalnya pada KRS yang disetujui
+func TestKRSSubmitPrasyarat(t *testing.T) {
+	ctx := context.Background()
+	svc, _, s := newKRSService(t)
+	kur := NewKurikulumService(memory.NewKurikulumRepository(s), s)
+	if _, err := kur.ReplacePrasyarat(ctx, fixMK2, []model.Prasyarat{{IDMKPrasyarat: fixMK, NilaiMinimal: "C"}}, adminActor); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := memory.NewSemesterRepository(s).Create(ctx, &model.Semester{IDSemester: fixSemesterGenap, TahunAjaran: "2024/2025", Term: "Genap"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddKelas(memory.KelasKuliah{IDKelas: "KLS000000003", NamaKelas: "A", IDMK: fixMK2, IDSemester: fixSemesterGenap, IDDosenPengampu: fixDosen}); err != nil {
+		t.Fatal(err)
+	}
+	unmet := func(sem string) []model.PrasyaratBelumTerpenuhi {
+		t.Helper()
+		_, err := svc.Submit(ctx, sem, mahasiswaActor)
+		var ae *apperror.Error
+		if !errors.As(err, &ae) || ae.Code != i18n.MsgKRSPrasyarat || ae.Fields["kelas"] != codePrasyarat {
+			t.Fatalf("Submit %s: error = %v, want %s", sem, err, i18n.MsgKRSPrasyarat)
+		}
+		list, _ := ae.Details.([]model.PrasyaratBelumTerpenuhi)
+		if len(list) != 1 || list[0].KodeMK != "IF102" || list[0].IDMKPrasyarat != fixMK {
+			t.Fatalf("details = %+v", ae.Details)
+		}
+		return list
+	}
+
+	// mengambil MK1 dan MK2 bersamaan tidak memenuhi prasyarat
+	if _, err := svc.Replace(ctx, fixSemester, []string{fixKelas, fixKelas2}, mahasiswaActor); err != nil {
+		t.Fatal(err)
+	}
+	if got := unmet(fixSemester); got[0].NilaiTerbaik != nil {
+		t.Errorf("nilai terbaik = %v, want nil", *got[0].NilaiTerbaik)
+	}
+
+	if _, err := svc.Replace(ctx, fixSemester, []string{fixKelas}, mahasiswaActor); err != nil {
+		t.Fatal(err)
+	}
+	k, err := svc.Submit(ctx, fixSemester, mahasiswaActor)
+	if err != nil {
+		t.Fatalf("Submit without prasyarat: %v", err)
+	}
+	if _, err := svc.Review(ctx, k.Pengajuan.IDPengajuan, true, nil, dosenActor); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddNilai(fixMahasiswa, fixKelas, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Replace(ctx, fixSemesterGenap, []string{"KLS000000003"}, mahasiswaActor); err != nil {
+		t.Fatal(err)
+	}
+	if got := unmet(fixSemesterGenap); got[0].NilaiTerbaik == nil || *got[0].NilaiTerbaik != "D" {
+		t.Errorf("nilai terbaik = %v, want D", got[0].NilaiTerbaik)
+	}
+
+	if err := s.AddNilai(fixMahasiswa, fixKelas, 2.5); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Submit(ctx, fixSemesterGenap, mahasiswaActor); err != nil {
+		t.Errorf("Submit after passing prasyarat: %v", err)
+	}
+}
@@ -2,27 +2,39 @@ package admin
 
 import (
     "context"
+    "encoding/base64"
+    "encoding/json"
     "errors"
     "regexp"
+    "strconv"
     "strings"
     "time"
 
+    "pencatatan-data-mahasiswa/internal/apperror"
+    "pencatatan-data-mahasiswa/internal/i18n"
     model "pencatatan-data-mahasiswa/internal/todo/model/admin"
     repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+    authsvc "pencatatan-data-mahasiswa/internal/todo/service/auth"
+    "pencatatan-data-mahasiswa/internal/tracing"
 )
 
 type MahasiswaService struct {
-    repo *repo.MahasiswaRepository
+    repo     MahasiswaRepository
+    tx       UnitOfWork
+    accounts AccountProvisioner
+    clock    Clock
 }
 
-func NewMahasiswaService(r *repo.MahasiswaRepository) *MahasiswaService {
-    return &MahasiswaService{repo: r}
+func NewMahasiswaService(r MahasiswaRepository, tx UnitOfWork, accounts AccountProvisioner, clock Clock) *MahasiswaService {
+    return &MahasiswaService{repo: r, tx: tx, accounts: accounts, clock: clock}
 }
 
 var (
     // Reuse ErrInvalidInput and ErrConflict from this package (declared in fakultas_service.go)
     // Define unprocessable error for FK not found cases
-    ErrUnprocessable = errors.New("unprocessable")
+    ErrUnprocessable = apperror.ErrUnprocessable
+    // errProdiNotFound: id_prodi valid secara format namun tidak ada di tabel prodi
+    errProdiNotFound = apperror.Field(ErrUnprocessable, "id_prodi", "not_found")
 
     nimPattern      = regexp.MustCompile(`^[A-Za-z0-9]{12}$`)
     jkSet           = map[string]struct{}{"L": {}, "P": {}}
@@ -31,17 +43,27 @@ var (
     nikPattern      = regexp.MustCompile(`^[0-9]{16}$`)
 )
 
-// validateCommon trims and validates common fields (except IDs and tahun_masuk)
-func (s *MahasiswaService) validateCommon(m *model.Mahasiswa, isCreate bool, isPut bool) error {
+// Kode detail field validasi mahasiswa
+const (
+    codeNIM        = "pattern_12_alnum"
+    codeNIK        = "pattern_16_digits"
+    codeTahunMasuk = "range_2000_next_year"
+)
+
+var errInvalidNIM = invalidID("id_mahasiswa", codeNIM)
+
+// validateCommon trims and validates common fields (except IDs and tahun_masuk).
+// Semua kegagalan dicatat ke fe agar client mendapat seluruh field yang salah sekaligus.
+func (s *MahasiswaService) validateCommon(m *model.Mahasiswa, isCreate bool, isPut bool, fe apperror.Fields) {
     m.IDProdi = strings.TrimSpace(m.IDProdi)
     m.NamaLengkap = strings.TrimSpace(m.NamaLengkap)
     m.JenisKelamin = strings.TrimSpace(m.JenisKelamin)
 
-    if m.NamaLengkap == "" || len(m.NamaLengkap) < 3 || len(m.NamaLengkap) > 120 {
-        return ErrInvalidInput
-    }
-    if _, ok := jkSet[m.JenisKelamin]; !ok {
-        return ErrInvalidInput
+    checkLength(fe, "nama_lengkap", m.NamaLengkap, 3, 120)
+    if m.JenisKelamin == "" {
+        fe.Add("jenis_kelamin", apperror.CodeRequired)
+    } else if _, ok := jkSet[m.JenisKelamin]; !ok {
+        fe.Add("jenis_kelamin", codeInvalidChoice)
     }
 
     if m.NIK != nil {
@@ -50,7 +72,7 @@ func (s *MahasiswaService) validateCommon(m *model.Mahasiswa, isCreate bool, isP
             m.NIK = nil
         } else {
             if !nikPattern.MatchString(v) {
-                return ErrInvalidInput
+                fe.Add("nik", codeNIK)
             }
             m.NIK = &v
         }
@@ -60,7 +82,7 @@ func (s *MahasiswaService) validateCommon(m *model.Mahasiswa, isCreate bool, isP
         if v == "" {
             m.TempatLahir = nil
         } else if len(v) > 80 {
-            return ErrInvalidInput
+            fe.Add("tempat_lahir", codeMax(80))
         } else {
             m.TempatLahir = &v
         }
@@ -78,8 +100,10 @@ func (s *MahasiswaService) validateCommon(m *model.Mahasiswa, isCreate bool, isP
         if v == "" {
             m.Email = nil
         } else {
-            if len(v) > 120 || !emailPattern.MatchString(v) { // emailPattern from dosen_service.go
-                return ErrInvalidInput
+            if len(v) > 120 {
+                fe.Add("email", codeMax(120))
+            } else if !emailPattern.MatchString(v) { // emailPattern from dosen_service.go
+                fe.Add("email", codeInvalidEmail)
             }
             m.Email = &v
         }
@@ -90,19 +114,18 @@ func (s *MahasiswaService) validateCommon(m *model.Mahasiswa, isCreate bool, isP
             m.NoHP = nil
         } else {
             if !hpMhsPattern.MatchString(v) {
-                return ErrInvalidInput
+                fe.Add("no_hp", codeInvalidPhone)
             }
             m.NoHP = &v
         }
     }
 
     if isPut {
-        // PUT requires status present (handler sets it when provided). Ensure not empty
-        if m.Status == "" {
-            return ErrInvalidInput
-        }
-        if _, ok := statusSet[m.Status]; !ok {
-            return ErrInvalidInput
+        // PUT: status opsional dan hanya boleh sama dengan status saat ini (lihat Transition)
+        if m.Status != "" {
+            if _, ok := statusSet[m.Status]; !ok {
+                fe.Add("status", codeInvalidChoice)
+            }
         }
     } else if isCreate {
         // Default status if empty on create
@@ -110,147 +133,330 @@ func (s *MahasiswaService) validateCommon(m *model.Mahasiswa, isCreate bool, isP
             m.Status = "Aktif"
         }
         if _, ok := statusSet[m.Status]; !ok {
-            return ErrInvalidInput
+            fe.Add("status", codeInvalidChoice)
         }
     }
+}
+
+// validateTahunMasuk: 2000 s.d. tahun depan (menurut zona waktu kampus)
+func (s *MahasiswaService) validateTahunMasuk(v int, fe apperror.Fields) {
+    if v < 2000 || v > s.clock.Today().Year()+1 {
+        fe.Add("tahun_masuk", codeTahunMasuk)
+    }
+}
 
-    return nil
+// validateIDProdiRef memvalidasi format id_prodi yang dirujuk mahasiswa
+func validateIDProdiRef(v string, fe apperror.Fields) {
+    if v == "" {
+        fe.Add("id_prodi", apperror.CodeRequired)
+    } else if !prodiIDPattern.MatchString(v) {
+        fe.Add("id_prodi", codeAlnum8)
+    }
 }
 
-// List with filters and pagination
-func (s *MahasiswaService) List(ctx context.Context, q string, idProdi *string, angkatan *int, status *string, limit, offset int, orderBy string) ([]model.Mahasiswa, error) {
+// List with filters and pagination; also returns the total number of matching rows.
+// rawFilters memakai grammar filter repository (mis. status=in:Aktif,Cuti, angkatan=gte:2020)
+func (s *MahasiswaService) List(ctx context.Context, q, match string, rawFilters map[string][]string, limit, offset int, orderBy string) ([]model.Mahasiswa, int64, error) {
+    ctx, span := tracing.Start(ctx, "MahasiswaService.List")
+    defer span.End()
+
     if limit < 0 || offset < 0 {
-        return nil, ErrInvalidInput
+        return nil, 0, ErrInvalidInput
     }
-    if idProdi != nil {
-        v := strings.TrimSpace(*idProdi)
-        if v == "" {
-            idProdi = nil
-        } else {
-            if !prodiIDPattern.MatchString(v) { // from prodi_service.go
-                return nil, ErrInvalidInput
-            }
-            idProdi = &v
+    match, err := normalizeMatch(match)
+    if err != nil {
+        return nil, 0, err
+    }
+    filters, err := repo.ParseFilters(rawFilters, repo.MahasiswaFilterFields)
+    if err != nil {
+        return nil, 0, err
+    }
+    q = strings.TrimSpace(q)
+    total, err := s.repo.Count(ctx, q, match, filters)
+    if err != nil {
+        return nil, 0, err
+    }
+    data, err := s.repo.List(ctx, q, match, filters, limit, offset, orderBy)
+    if err != nil {
+        return nil, 0, err
+    }
+    return data, total, nil
+}
+
+// mhsCursor adalah isi cursor keyset: kolom sort, arah, nilai sort terakhir dan id_mahasiswa terakhir
+type mhsCursor struct {
+    SortBy string `json:"s"`
+    Desc   bool   `json:"d"`
+    Value  string `json:"v"`
+    ID     string `json:"id"`
+}
+
+// mhsKeysetCols adalah kolom yang boleh dipakai untuk keyset pagination (semuanya NOT NULL)
+var mhsKeysetCols = map[string]struct{}{"nama_lengkap": {}, "tahun_masuk": {}, "created_at": {}, "updated_at": {}}
+
+func encodeMhsCursor(sortBy string, desc bool, m *model.Mahasiswa) string {
+    c := mhsCursor{SortBy: sortBy, Desc: desc, ID: m.IDMahasiswa}
+    switch sortBy {
+    case "nama_lengkap":
+        c.Value = m.NamaLengkap
+    case "tahun_masuk":
+        c.Value = strconv.Itoa(m.TahunMasuk)
+    case "created_at":
+        c.Value = m.CreatedAt.Format(time.RFC3339Nano)
+    case "updated_at":
+        c.Value = m.UpdatedAt.Format(time.RFC3339Nano)
+    }
+    b, _ := json.Marshal(c)
+    return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// errInvalidCursor: cursor rusak atau dibuat untuk sort_by/sort_dir yang berbeda
+var errInvalidCursor = apperror.Field(ErrInvalidInput, "cursor", "invalid")
+
+// decodeMhsCursor memvalidasi cursor dan mengembalikan nilai sort yang sudah bertipe sesuai kolom
+func decodeMhsCursor(raw, sortBy string, desc bool) (any, string, error) {
+    b, err := base64.RawURLEncoding.DecodeString(raw)
+    if err != nil {
+        return nil, "", errInvalidCursor
+    }
+    var c mhsCursor
+    if err := json.Unmarshal(b, &c); err != nil {
+        return nil, "", errInvalidCursor
+    }
+    // cursor hanya berlaku untuk urutan yang sama dengan saat cursor dibuat
+    if c.SortBy != sortBy || c.Desc != desc || !nimPattern.MatchString(c.ID) {
+        return nil, "", errInvalidCursor
+    }
+    switch sortBy {
+    case "tahun_masuk":
+        v, err := strconv.Atoi(c.Value)
+        if err != nil {
+            return nil, "", errInvalidCursor
         }
+        return v, c.ID, nil
+    case "created_at", "updated_at":
+        v, err := time.Parse(time.RFC3339Nano, c.Value)
+        if err != nil {
+            return nil, "", errInvalidCursor
+        }
+        return v, c.ID, nil
+    default:
+        return c.Value, c.ID, nil
     }
-    if status != nil {
-        v := strings.TrimSpace(*status)
-        if v == "" {
-            status = nil
-        } else {
-            if _, ok := statusSet[v]; !ok {
-                return nil, ErrInvalidInput
-            }
-            status = &v
+}
+
+// ListKeyset mengembalikan satu halaman mahasiswa memakai keyset pagination.
+// cursor kosong berarti halaman pertama; nextCursor kosong berarti tidak ada halaman berikutnya.
+func (s *MahasiswaService) ListKeyset(ctx context.Context, q, match string, rawFilters map[string][]string, limit int, sortBy string, desc bool, cursor string) ([]model.Mahasiswa, string, error) {
+    ctx, span := tracing.Start(ctx, "MahasiswaService.ListKeyset")
+    defer span.End()
+
+    if limit < 1 {
+        return nil, "", ErrInvalidInput
+    }
+    match, err := normalizeMatch(match)
+    if err != nil {
+        return nil, "", err
+    }
+    if _, ok := mhsKeysetCols[sortBy]; !ok {
+        return nil, "", apperror.Field(ErrInvalidInput, "sort_by", codeInvalidChoice)
+    }
+    filters, err := repo.ParseFilters(rawFilters, repo.MahasiswaFilterFields)
+    if err != nil {
+        return nil, "", err
+    }
+
+    var (
+        afterValue any
+        afterID    *string
+    )
+    if cursor = strings.TrimSpace(cursor); cursor != "" {
+        v, id, err := decodeMhsCursor(cursor, sortBy, desc)
+        if err != nil {
+            return nil, "", err
         }
+        afterValue, afterID = v, &id
     }
-    return s.repo.List(ctx, strings.TrimSpace(q), idProdi, angkatan, status, limit, offset, orderBy)
+
+    // ambil satu baris ekstra untuk mengetahui apakah masih ada halaman berikutnya
+    data, err := s.repo.ListKeyset(ctx, strings.TrimSpace(q), match, filters, limit+1, sortBy, desc, afterValue, afterID)
+    if err != nil {
+        return nil, "", err
+    }
+    next := ""
+    if len(data) > limit {
+        data = data[:limit]
+        next = encodeMhsCursor(sortBy, desc, &data[limit-1])
+    }
+    return data, next, nil
 }
 
 func (s *MahasiswaService) Get(ctx context.Context, id string) (*model.Mahasiswa, error) {
+    ctx, span := tracing.Start(ctx, "MahasiswaService.Get")
+    defer span.End()
+
     id = strings.TrimSpace(id)
     if !nimPattern.MatchString(id) {
-        return nil, ErrInvalidInput
+        return nil, errInvalidNIM
     }
     return s.repo.GetByID(ctx, id)
 }
 
-func (s *MahasiswaService) Create(ctx context.Context, m *model.Mahasiswa) (*model.Mahasiswa, error) {
-    // Validate ID (NIM) provided and unique
-    m.IDMahasiswa = strings.TrimSpace(m.IDMahasiswa)
-    if !nimPattern.MatchString(m.IDMahasiswa) {
-        return nil, ErrInvalidInput
-    }
-    if exist, err := s.repo.ExistsID(ctx, m.IDMahasiswa); err != nil {
-        return nil, err
-    } else if exist {
-        return nil, ErrConflict
+// checkUnique mengumpulkan seluruh field unik (email, nik) yang sudah dipakai mahasiswa lain.
+// excludeID diisi saat update agar baris sendiri tidak dihitung. Dipanggil di dalam s.tx.Do
+// karena email hanya unik menurut service dan dikunci lebih dulu.
+func (s *MahasiswaService) checkUnique(ctx context.Context, email, nik, excludeID *string, taken apperror.Fields) error {
+    if email != nil {
+        if err := s.tx.LockKey(ctx, lockMahasiswaEmail, strings.ToLower(*email)); err != nil {
+            return err
+        }
+        if exist, err := s.repo.ExistsEmail(ctx, *email, excludeID); err != nil {
+            return err
+        } else if exist {
+            taken.Add("email", apperror.CodeTaken)
+        }
     }
-
-    // Validate mandatory and optional fields
-    if err := s.validateCommon(m, true, false); err != nil {
-        return nil, err
+    if nik != nil {
+        if exist, err := s.repo.ExistsNIK(ctx, *nik, excludeID); err != nil {
+            return err
+        } else if exist {
+            taken.Add("nik", apperror.CodeTaken)
+        }
     }
+    return nil
+}
 
-    // tahun_masuk validation
-    currentYear := time.Now().Year()
-    if m.TahunMasuk < 2000 || m.TahunMasuk > currentYear+1 {
-        return nil, ErrInvalidInput
-    }
+func (s *MahasiswaService) Create(ctx context.Context, m *model.Mahasiswa) (*model.Mahasiswa, error) {
+    ctx, span := tracing.Start(ctx, "MahasiswaService.Create")
+    defer span.End()
 
-    // id_prodi validation and FK existence
-    if m.IDProdi == "" || !prodiIDPattern.MatchString(m.IDProdi) {
-        return nil, ErrInvalidInput
-    }
-    if ok, err := s.repo.ExistsProdi(ctx, m.IDProdi); err != nil {
+    fe := apperror.Fields{}
+    m.IDMahasiswa = strings.TrimSpace(m.IDMahasiswa)
+    if m.IDMahasiswa == "" {
+        fe.Add("id_mahasiswa", apperror.CodeRequired)
+    } else if !nimPattern.MatchString(m.IDMahasiswa) {
+        fe.Add("id_mahasiswa", codeNIM)
+    }
+    s.validateCommon(m, true, false, fe)
+    s.validateTahunMasuk(m.TahunMasuk, fe)
+    validateIDProdiRef(m.IDProdi, fe)
+    if err := fe.Err(); err != nil {
         return nil, err
-    } else if !ok {
-        return nil, ErrUnprocessable
     }
 
-    // Uniqueness checks for optional unique fields
-    if m.Email != nil {
-        if exist, err := s.repo.ExistsEmail(ctx, *m.Email, nil); err != nil {
+    return inTx(ctx, s.tx, func(ctx context.Context) (*model.Mahasiswa, error) {
+        // id_prodi FK existence
+        if ok, err := s.repo.ExistsProdi(ctx, m.IDProdi); err != nil {
             return nil, err
-        } else if exist {
-            return nil, ErrConflict
+        } else if !ok {
+            return nil, errProdiNotFound
         }
-    }
-    if m.NIK != nil {
-        if exist, err := s.repo.ExistsNIK(ctx, *m.NIK, nil); err != nil {
+
+        // Uniqueness checks: NIM and optional unique fields
+        taken := apperror.Fields{}
+        if exist, err := s.repo.ExistsID(ctx, m.IDMahasiswa); err != nil {
             return nil, err
         } else if exist {
-            return nil, ErrConflict
+            taken.Add("id_mahasiswa", apperror.CodeTaken)
+        }
+        if err := s.checkUnique(ctx, m.Email, m.NIK, nil, taken); err != nil {
+            return nil, err
         }
+        if err := taken.As(ErrConflict); err != nil {
+            return nil, err
+        }
+
+        return s.repo.Create(ctx, m)
+    })
+}
+
+// CreateWithAccount membuat mahasiswa sekaligus akun login-nya (username = NIM) dalam satu transaksi;
+// bila akun gagal dibuat (mis. username sudah dipakai) mahasiswa ikut batal. mode kosong sama dengan Create.
+func (s *MahasiswaService) CreateWithAccount(ctx context.Context, m *model.Mahasiswa, mode authsvc.AccountMode) (*model.Mahasiswa, *authsvc.Account, error) {
+    if mode == "" {
+        out, err := s.Create(ctx, m)
+        return out, nil, err
+    }
+    var (
+        out *model.Mahasiswa
+        acc *authsvc.Account
+    )
+    err := s.tx.Do(ctx, func(ctx context.Context) error {
+        var err error
+        if out, err = s.Create(ctx, m); err != nil {
+            return err
+        }
+        acc, err = s.accounts.Provision(ctx, "mahasiswa", out.IDMahasiswa, mode)
+        return err
+    })
+    if err != nil {
+        return nil, nil, err
     }
+    return out, acc, nil
+}
+
+// errDryRun membatalkan transaksi ValidateCreate setelah seluruh pengecekan lolos
+var errDryRun = errors.New("dry run")
 
-    return s.repo.Create(ctx, m)
+// ValidateCreate menjalankan CreateWithAccount lalu selalu me-rollback, sehingga dry run import
+// memakai aturan yang persis sama dengan import sebenarnya. Jangan dipanggil di dalam transaksi lain.
+func (s *MahasiswaService) ValidateCreate(ctx context.Context, m *model.Mahasiswa, mode authsvc.AccountMode) error {
+    ctx, span := tracing.Start(ctx, "MahasiswaService.ValidateCreate")
+    defer span.End()
+
+    err := s.tx.Do(ctx, func(ctx context.Context) error {
+        if _, _, err := s.CreateWithAccount(ctx, m, mode); err != nil {
+            return err
+        }
+        return errDryRun
+    })
+    if errors.Is(err, errDryRun) {
+        return nil
+    }
+    return err
 }
 
 func (s *MahasiswaService) UpdatePut(ctx context.Context, id string, m *model.Mahasiswa) (*model.Mahasiswa, error) {
+    ctx, span := tracing.Start(ctx, "MahasiswaService.UpdatePut")
+    defer span.End()
+
     id = strings.TrimSpace(id)
     if !nimPattern.MatchString(id) {
-        return nil, ErrInvalidInput
+        return nil, errInvalidNIM
     }
 
-    // Validate fields (PUT requires full data including status)
-    if err := s.validateCommon(m, false, true); err != nil {
+    // Validate fields (PUT requires full data; status diubah lewat Transition)
+    fe := apperror.Fields{}
+    s.validateCommon(m, false, true, fe)
+    s.validateTahunMasuk(m.TahunMasuk, fe)
+    validateIDProdiRef(m.IDProdi, fe)
+    if err := fe.Err(); err != nil {
         return nil, err
     }
 
-    // tahun_masuk validation
-    currentYear := time.Now().Year()
-    if m.TahunMasuk < 2000 || m.TahunMasuk > currentYear+1 {
-        return nil, ErrInvalidInput
-    }
-
-    // id_prodi validation and existence
-    if m.IDProdi == "" || !prodiIDPattern.MatchString(m.IDProdi) {
-        return nil, ErrInvalidInput
-    }
-    if ok, err := s.repo.ExistsProdi(ctx, m.IDProdi); err != nil {
-        return nil, err
-    } else if !ok {
-        return nil, ErrUnprocessable
-    }
+    return inTx(ctx, s.tx, func(ctx context.Context) (*model.Mahasiswa, error) {
+        if ok, err := s.repo.ExistsProdi(ctx, m.IDProdi); err != nil {
+            return nil, err
+        } else if !ok {
+            return nil, errProdiNotFound
+        }
 
-    // Uniqueness checks with exclude id
-    if m.Email != nil {
-        if exist, err := s.repo.ExistsEmail(ctx, *m.Email, &id); err != nil {
+        // Uniqueness checks with exclude id
+        taken := apperror.Fields{}
+        if err := s.checkUnique(ctx, m.Email, m.NIK, &id, taken); err != nil {
             return nil, err
-        } else if exist {
-            return nil, ErrConflict
         }
-    }
-    if m.NIK != nil {
-        if exist, err := s.repo.ExistsNIK(ctx, *m.NIK, &id); err != nil {
+        if err := taken.As(ErrConflict); err != nil {
             return nil, err
-        } else if exist {
-            return nil, ErrConflict
         }
-    }
+        if m.Status != "" {
+            if err := s.checkStatusUnchanged(ctx, id, &m.Status); err != nil {
+                return nil, err
+            }
+        }
 
-    return s.repo.UpdatePut(ctx, id, m)
+        return s.repo.UpdatePut(ctx, id, m)
+    })
 }
 
 func (s *MahasiswaService) UpdatePatch(
@@ -260,25 +466,19 @@ func (s *MahasiswaService) UpdatePatch(
     tanggalLahir *time.Time,
     tahunMasuk *int,
 ) (*model.Mahasiswa, error) {
+    ctx, span := tracing.Start(ctx, "MahasiswaService.UpdatePatch")
+    defer span.End()
+
     id = strings.TrimSpace(id)
     if !nimPattern.MatchString(id) {
-        return nil, ErrInvalidInput
+        return nil, errInvalidNIM
     }
 
     // Validate and normalize each provided field
+    fe := apperror.Fields{}
     if idProdi != nil {
         v := strings.TrimSpace(*idProdi)
-        if v == "" {
-            return nil, ErrInvalidInput
-        }
-        if !prodiIDPattern.MatchString(v) {
-            return nil, ErrInvalidInput
-        }
-        if ok, err := s.repo.ExistsProdi(ctx, v); err != nil {
-            return nil, err
-        } else if !ok {
-            return nil, ErrUnprocessable
-        }
+        validateIDProdiRef(v, fe)
         idProdi = &v
     }
     if nik != nil {
@@ -287,27 +487,20 @@ func (s *MahasiswaService) UpdatePatch(
             nik = nil
         } else {
             if !nikPattern.MatchString(v) {
-                return nil, ErrInvalidInput
-            }
-            if exist, err := s.repo.ExistsNIK(ctx, v, &id); err != nil {
-                return nil, err
-            } else if exist {
-                return nil, ErrConflict
+                fe.Add("nik", codeNIK)
             }
             nik = &v
         }
     }
     if namaLengkap != nil {
         v := strings.TrimSpace(*namaLengkap)
-        if len(v) < 3 || len(v) > 120 {
-            return nil, ErrInvalidInput
-        }
+        checkLength(fe, "nama_lengkap", v, 3, 120)
         namaLengkap = &v
     }
     if jenisKelamin != nil {
         v := strings.TrimSpace(*jenisKelamin)
         if _, ok := jkSet[v]; !ok {
-            return nil, ErrInvalidInput
+            fe.Add("jenis_kelamin", codeInvalidChoice)
         }
         jenisKelamin = &v
     }
@@ -316,7 +509,7 @@ func (s *MahasiswaService) UpdatePatch(
         if v == "" {
             tempatLahir = nil
         } else if len(v) > 80 {
-            return nil, ErrInvalidInput
+            fe.Add("tempat_lahir", codeMax(80))
         } else {
             tempatLahir = &v
         }
@@ -334,13 +527,10 @@ func (s *MahasiswaService) UpdatePatch(
         if v == "" {
             email = nil
         } else {
-            if len(v) > 120 || !emailPattern.MatchString(v) {
-                return nil, ErrInvalidInput
-            }
-            if exist, err := s.repo.ExistsEmail(ctx, v, &id); err != nil {
-                return nil, err
-            } else if exist {
-                return nil, ErrConflict
+            if len(v) > 120 {
+                fe.Add("email", codeMax(120))
+            } else if !emailPattern.MatchString(v) {
+                fe.Add("email", codeInvalidEmail)
             }
             email = &v
         }
@@ -351,7 +541,7 @@ func (s *MahasiswaService) UpdatePatch(
             noHP = nil
         } else {
             if !hpMhsPattern.MatchString(v) {
-                return nil, ErrInvalidInput
+                fe.Add("no_hp", codeInvalidPhone)
             }
             noHP = &v
         }
@@ -362,32 +552,60 @@ func (s *MahasiswaService) UpdatePatch(
             status = nil
         } else {
             if _, ok := statusSet[v]; !ok {
-                return nil, ErrInvalidInput
+                fe.Add("status", codeInvalidChoice)
             }
             status = &v
         }
     }
     if tahunMasuk != nil {
-        v := *tahunMasuk
-        currentYear := time.Now().Year()
-        if v < 2000 || v > currentYear+1 {
-            return nil, ErrInvalidInput
-        }
-        tahunMasuk = &v
+        s.validateTahunMasuk(*tahunMasuk, fe)
+    }
+    if err := fe.Err(); err != nil {
+        return nil, err
     }
 
-    return s.repo.UpdatePatch(ctx, id, idProdi, nik, namaLengkap, jenisKelamin, tempatLahir, alamat, email, noHP, status, tanggalLahir, tahunMasuk)
+    return inTx(ctx, s.tx, func(ctx context.Context) (*model.Mahasiswa, error) {
+        if idProdi != nil {
+            if ok, err := s.repo.ExistsProdi(ctx, *idProdi); err != nil {
+                return nil, err
+            } else if !ok {
+                return nil, errProdiNotFound
+            }
+        }
+        taken := apperror.Fields{}
+        if err := s.checkUnique(ctx, email, nik, &id, taken); err != nil {
+            return nil, err
+        }
+        if err := taken.As(ErrConflict); err != nil {
+            return nil, err
+        }
+        if err := s.checkStatusUnchanged(ctx, id, status); err != nil {
+            return nil, err
+        }
+
+        return s.repo.UpdatePatch(ctx, id, idProdi, nik, namaLengkap, jenisKelamin, tempatLahir, alamat, email, noHP, tanggalLahir, tahunMasuk)
+    })
 }
 
 func (s *MahasiswaService) Delete(ctx context.Context, id string) error {
+    ctx, span := tracing.Start(ctx, "MahasiswaService.Delete")
+    defer span.End()
+
     id = strings.TrimSpace(id)
     if !nimPattern.MatchString(id) {
-        return ErrInvalidInput
-    }
-    if has, err := s.repo.HasKRSRelated(ctx, id); err != nil {
-        return err
-    } else if has {
-        return ErrConflict
+        return errInvalidNIM
     }
-    return s.repo.Delete(ctx, id)
-}
\ No newline at end of file
+    return s.tx.Do(ctx, func(ctx context.Context) error {
+        // FOR UPDATE bentrok dengan FOR KEY SHARE dari insert krs (FK), jadi krs baru
+        // menunggu sampai delete selesai lalu gagal, bukan lolos di antara cek dan delete
+        if err := s.repo.LockByID(ctx, id); err != nil {
+            return err
+        }
+        if has, err := s.repo.HasKRSRelated(ctx, id); err != nil {
+            return err
+        } else if has {
+            return apperror.New(ErrConflict, i18n.MsgMahasiswaInUse)
+        }
+        return s.repo.Delete(ctx, id)
+    })
+}
@@ -0,0 +1,218 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	authmodel "pencatatan-data-mahasiswa/internal/todo/model/auth"
+	"pencatatan-data-mahasiswa/internal/todo/repository/memory"
+	authsvc "pencatatan-data-mahasiswa/internal/todo/service/auth"
+)
+
+func TestMahasiswaValidateCommon(t *testing.T) {
+	valid := func() model.Mahasiswa {
+		return model.Mahasiswa{IDProdi: fixProdi, NamaLengkap: "Siti Aminah", JenisKelamin: "P"}
+	}
+	tests := []struct {
+		name     string
+		edit     func(m *model.Mahasiswa)
+		isCreate bool
+		isPut    bool
+		want     map[string]string // field -> kode; kosong berarti valid
+		check    func(t *testing.T, m *model.Mahasiswa)
+	}{
+		{name: "valid create defaults status", isCreate: true, check: func(t *testing.T, m *model.Mahasiswa) {
+			if m.Status != "Aktif" {
+				t.Errorf("status = %q, want Aktif", m.Status)
+			}
+		}},
+		{name: "trims text fields", edit: func(m *model.Mahasiswa) {
+			m.NamaLengkap, m.JenisKelamin, m.IDProdi = "  Siti  ", " L ", " "+fixProdi+" "
+		}, check: func(t *testing.T, m *model.Mahasiswa) {
+			if m.NamaLengkap != "Siti" || m.JenisKelamin != "L" || m.IDProdi != fixProdi {
+				t.Errorf("not trimmed: %+v", m)
+			}
+		}},
+		{name: "missing nama and jenis kelamin", edit: func(m *model.Mahasiswa) { m.NamaLengkap, m.JenisKelamin = " ", "" },
+			want: map[string]string{"nama_lengkap": apperror.CodeRequired, "jenis_kelamin": apperror.CodeRequired}},
+		{name: "nama too short", edit: func(m *model.Mahasiswa) { m.NamaLengkap = "Al" },
+			want: map[string]string{"nama_lengkap": codeLength(3, 120)}},
+		{name: "nama too long", edit: func(m *model.Mahasiswa) { m.NamaLengkap = strings.Repeat("a", 121) },
+			want: map[string]string{"nama_lengkap": codeLength(3, 120)}},
+		{name: "invalid jenis kelamin", edit: func(m *model.Mahasiswa) { m.JenisKelamin = "X" },
+			want: map[string]string{"jenis_kelamin": codeInvalidChoice}},
+		{name: "nik must be 16 digits", edit: func(m *model.Mahasiswa) { m.NIK = strPtr("12345") },
+			want: map[string]string{"nik": codeNIK}},
+		{name: "blank optionals become null", edit: func(m *model.Mahasiswa) {
+			m.NIK, m.TempatLahir, m.Alamat, m.Email, m.NoHP = strPtr(" "), strPtr(""), strPtr(" "), strPtr(""), strPtr(" ")
+		}, check: func(t *testing.T, m *model.Mahasiswa) {
+			if m.NIK != nil || m.TempatLahir != nil || m.Alamat != nil || m.Email != nil || m.NoHP != nil {
+				t.Errorf("blank optional fields should be nil: %+v", m)
+			}
+		}},
+		{name: "tempat lahir too long", edit: func(m *model.Mahasiswa) { m.TempatLahir = strPtr(strings.Repeat("b", 81)) },
+			want: map[string]string{"tempat_lahir": codeMax(80)}},
+		{name: "invalid email", edit: func(m *model.Mahasiswa) { m.Email = strPtr("siti@") },
+			want: map[string]string{"email": codeInvalidEmail}},
+		{name: "email too long", edit: func(m *model.Mahasiswa) { m.Email = strPtr(strings.Repeat("c", 115) + "@x.com") },
+			want: map[string]string{"email": codeMax(120)}},
+		{name: "invalid phone", edit: func(m *model.Mahasiswa) { m.NoHP = strPtr("08-abc") },
+			want: map[string]string{"no_hp": codeInvalidPhone}},
+		{name: "put status optional", isPut: true},
+		{name: "put invalid status", isPut: true, edit: func(m *model.Mahasiswa) { m.Status = "Wisuda" },
+			want: map[string]string{"status": codeInvalidChoice}},
+		{name: "create invalid status", isCreate: true, edit: func(m *model.Mahasiswa) { m.Status = "aktif" },
+			want: map[string]string{"status": codeInvalidChoice}},
+		{name: "collects every failing field", edit: func(m *model.Mahasiswa) {
+			m.NamaLengkap, m.JenisKelamin, m.NIK, m.Email = "", "Z", strPtr("x"), strPtr("bad")
+		}, want: map[string]string{
+			"nama_lengkap": apperror.CodeRequired, "jenis_kelamin": codeInvalidChoice, "nik": codeNIK, "email": codeInvalidEmail,
+		}},
+	}
+
+	s := memory.NewStore()
+	svc := NewMahasiswaService(memory.NewMahasiswaRepository(s), s, nil, Clock{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := valid()
+			if tt.edit != nil {
+				tt.edit(&m)
+			}
+			fe := apperror.Fields{}
+			svc.validateCommon(&m, tt.isCreate, tt.isPut, fe)
+			want := tt.want
+			if want == nil {
+				want = map[string]string{}
+			}
+			if !reflect.DeepEqual(map[string]string(fe), want) {
+				t.Errorf("fields = %v, want %v", fe, want)
+			}
+			if tt.check != nil {
+				tt.check(t, &m)
+			}
+		})
+	}
+}
+
+// TestMahasiswaCreateRules memeriksa aturan FK dan keunikan Create terhadap repository in-memory
+func TestMahasiswaCreateRules(t *testing.T) {
+	newMhs := func(edit func(m *model.Mahasiswa)) *model.Mahasiswa {
+		m := &model.Mahasiswa{IDMahasiswa: "202400000002", IDProdi: fixProdi, NamaLengkap: "Budi Santoso", JenisKelamin: "L", TahunMasuk: 2024}
+		if edit != nil {
+			edit(m)
+		}
+		return m
+	}
+	tests := []struct {
+		name       string
+		m          *model.Mahasiswa
+		wantKind   error
+		wantFields map[string]string
+	}{
+		{name: "ok", m: newMhs(nil)},
+		{name: "unknown prodi", m: newMhs(func(m *model.Mahasiswa) { m.IDProdi = "PRD99999" }),
+			wantKind: apperror.ErrUnprocessable, wantFields: map[string]string{"id_prodi": "not_found"}},
+		{name: "nim taken", m: newMhs(func(m *model.Mahasiswa) { m.IDMahasiswa = fixMahasiswa }),
+			wantKind: apperror.ErrConflict, wantFields: map[string]string{"id_mahasiswa": apperror.CodeTaken}},
+		{name: "nik taken", m: newMhs(func(m *model.Mahasiswa) { m.NIK = strPtr("3201010101010001") }),
+			wantKind: apperror.ErrConflict, wantFields: map[string]string{"nik": apperror.CodeTaken}},
+		{name: "invalid nim and tahun masuk", m: newMhs(func(m *model.Mahasiswa) { m.IDMahasiswa, m.TahunMasuk = "ABC", 1999 }),
+			wantKind: apperror.ErrInvalidInput, wantFields: map[string]string{"id_mahasiswa": codeNIM, "tahun_masuk": codeTahunMasuk}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := seedStore(t)
+			svc := NewMahasiswaService(memory.NewMahasiswaRepository(s), s, nil, Clock{})
+			out, err := svc.Create(context.Background(), tt.m)
+			if tt.wantKind == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if out.Angkatan != out.TahunMasuk || out.Status != "Aktif" {
+					t.Errorf("created = %+v, want angkatan = tahun_masuk and status Aktif", out)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("error = %v, want kind %v", err, tt.wantKind)
+			}
+			if got := apperror.FieldsOf(err); !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+// TestMahasiswaTahunMasukClock: batas "tahun depan" dihitung dari tanggal kampus, bukan jam server
+func TestMahasiswaTahunMasukClock(t *testing.T) {
+	now := time.Date(2024, 12, 31, 20, 0, 0, 0, time.UTC) // 1 Januari 2025 03:00 WIB
+	for _, tc := range []struct {
+		name string
+		loc  *time.Location
+		ok   bool
+	}{
+		{"WIB sudah 2025", nil, true},
+		{"UTC masih 2024", time.UTC, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := seedStore(t)
+			svc := NewMahasiswaService(memory.NewMahasiswaRepository(s), s, nil, Clock{Now: func() time.Time { return now }, Location: tc.loc})
+			m := &model.Mahasiswa{IDMahasiswa: "202600000002", IDProdi: fixProdi, NamaLengkap: "Budi Santoso", JenisKelamin: "L", TahunMasuk: 2026}
+			_, err := svc.Create(context.Background(), m)
+			if got := apperror.FieldsOf(err)["tahun_masuk"]; (got == "") != tc.ok {
+				t.Errorf("tahun_masuk 2026: error = %v, want ok=%v", err, tc.ok)
+			}
+		})
+	}
+}
+
+// TestMahasiswaCreateWithAccount: mahasiswa dan akun tersimpan bersama, dan mahasiswa ikut batal
+// bila akun gagal dibuat
+func TestMahasiswaCreateWithAccount(t *testing.T) {
+	ctx := context.Background()
+	s := seedStore(t)
+	users := memory.NewUserRepository(s)
+	accounts := authsvc.NewService(users, s, authsvc.Options{JWTSecret: "secret", TokenTTL: time.Hour, BcryptCost: bcrypt.MinCost, ActivationTTL: time.Hour})
+	svc := NewMahasiswaService(memory.NewMahasiswaRepository(s), s, accounts, Clock{})
+	newMhs := func(nim string) *model.Mahasiswa {
+		return &model.Mahasiswa{IDMahasiswa: nim, IDProdi: fixProdi, NamaLengkap: "Budi Santoso", JenisKelamin: "L", TahunMasuk: 2024}
+	}
+
+	out, acc, err := svc.CreateWithAccount(ctx, newMhs("202400000002"), authsvc.AccountPassword)
+	if err != nil {
+		t.Fatalf("CreateWithAccount: %v", err)
+	}
+	if acc.Username != out.IDMahasiswa || acc.Role != "mahasiswa" || acc.InitialPassword == "" {
+		t.Errorf("account = %+v, want username = NIM with an initial password", acc)
+	}
+	if u, err := users.GetByUsername(ctx, out.IDMahasiswa); err != nil || !u.MustChangePassword {
+		t.Errorf("stored user = %+v, %v; want must_change_password", u, err)
+	}
+
+	if err := svc.ValidateCreate(ctx, newMhs("202400000003"), authsvc.AccountActivation); err != nil {
+		t.Fatalf("ValidateCreate: %v", err)
+	}
+	if _, err := svc.Get(ctx, "202400000003"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("dry run left the mahasiswa behind: %v", err)
+	}
+
+	// username sudah dipakai akun lain: mahasiswa tidak boleh tersimpan tanpa akun
+	if _, err := users.Create(ctx, &authmodel.User{Username: "202400000004", PasswordHash: "x", Role: "admin"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := svc.CreateWithAccount(ctx, newMhs("202400000004"), authsvc.AccountPassword); !errors.Is(err, apperror.ErrConflict) {
+		t.Fatalf("error = %v, want conflict", err)
+	}
+	if _, err := svc.Get(ctx, "202400000004"); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("mahasiswa must be rolled back with its account: %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+package admin
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	"pencatatan-data-mahasiswa/internal/tracing"
+)
+
+// Status mahasiswa
+const (
+	StatusAktif    = "Aktif"
+	StatusCuti     = "Cuti"
+	StatusLulus    = "Lulus"
+	StatusDropOut  = "Drop Out"
+	StatusNonAktif = "Non-Aktif"
+)
+
+// statusTransitions adalah transisi yang diizinkan tanpa override. Lulus dan Drop Out adalah
+// status akhir: keluar dari keduanya hanya bisa lewat override admin.
+var statusTransitions = map[string][]string{
+	StatusAktif:    {StatusCuti, StatusLulus, StatusDropOut, StatusNonAktif},
+	StatusCuti:     {StatusAktif, StatusDropOut, StatusNonAktif},
+	StatusNonAktif: {StatusAktif, StatusDropOut},
+}
+
+// StatusTransitions mengembalikan salinan aturan transisi, dipakai dokumentasi API
+func StatusTransitions() map[string][]string {
+	out := make(map[string][]string, len(statusTransitions))
+	for from, to := range statusTransitions {
+		out[from] = append([]string(nil), to...)
+	}
+	return out
+}
+
+func transitionAllowed(from, to string) bool {
+	for _, s := range statusTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusTransition adalah permintaan perubahan status. TanggalEfektif nol berarti hari ini.
+type StatusTransition struct {
+	Status         string
+	TanggalEfektif time.Time
+	NomorSK        *string
+	Alasan         string
+	Override       bool
+}
+
+// Actor adalah user yang melakukan perubahan; dicatat pada riwayat. RefID dari token login:
+// NIM untuk mahasiswa, id_prodi untuk operator yang dibatasi satu prodi.
+type Actor struct {
+	IDUser   int64
+	Username string
+	Role     string
+	RefID    *string
+}
+
+// managesProdi: admin mengelola semua prodi, operator hanya prodi ref_id-nya (operator tanpa
+// ref_id tidak mengelola prodi mana pun); role lain tidak mengelola prodi
+func (a Actor) managesProdi(idProdi string) bool {
+	switch a.Role {
+	case "admin":
+		return true
+	case "operator":
+		return a.RefID != nil && *a.RefID == idProdi
+	}
+	return false
+}
+
+// Kode detail field transisi status
+const (
+	codeSameStatus        = "same_as_current"
+	codeTransitionDenied  = "transition_not_allowed"
+	codeUseTransition     = "use_transition_endpoint"
+	codeBeforeLastChange  = "before_last_transition"
+	codeDateInFuture      = "after_today"
+	codeOverrideAdminOnly = "admin_only"
+)
+
+var (
+	errStatusOverride = &apperror.Error{
+		Kind:   apperror.ErrForbidden,
+		Code:   i18n.MsgStatusOverrideForbidden,
+		Fields: map[string]string{"override": codeOverrideAdminOnly},
+	}
+	errStatusReadOnly = apperror.Field(ErrInvalidInput, "status", codeUseTransition)
+	// errForbidden: actor tidak berhak atas resource (role, prodi, atau pemilik tidak cocok)
+	errForbidden = apperror.New(apperror.ErrForbidden, i18n.MsgForbidden)
+)
+
+// requiresSK: status akhir dan override wajib berdasarkan surat keputusan
+func requiresSK(in StatusTransition) bool {
+	return in.Override || in.Status == StatusLulus || in.Status == StatusDropOut
+}
+
+// Transition mengubah status mahasiswa sesuai statusTransitions dan mencatatnya ke riwayat dalam
+// satu transaksi. Override (hanya admin) melewati aturan transisi, tetapi tetap wajib nomor SK dan alasan.
+// Operator hanya untuk mahasiswa prodi ref_id-nya.
+func (s *MahasiswaService) Transition(ctx context.Context, id string, in StatusTransition, actor Actor) (*model.MahasiswaStatusChange, error) {
+	ctx, span := tracing.Start(ctx, "MahasiswaService.Transition")
+	defer span.End()
+
+	id = strings.TrimSpace(id)
+	if !nimPattern.MatchString(id) {
+		return nil, errInvalidNIM
+	}
+	if in.Override && actor.Role != "admin" {
+		return nil, errStatusOverride
+	}
+
+	today := s.clock.Today()
+	if in.TanggalEfektif.IsZero() {
+		in.TanggalEfektif = today
+	}
+	in.Status = strings.TrimSpace(in.Status)
+	in.Alasan = strings.TrimSpace(in.Alasan)
+	fe := apperror.Fields{}
+	if in.Status == "" {
+		fe.Add("status", apperror.CodeRequired)
+	} else if _, ok := statusSet[in.Status]; !ok {
+		fe.Add("status", codeInvalidChoice)
+	}
+	if in.TanggalEfektif.After(today) {
+		fe.Add("tanggal_efektif", codeDateInFuture)
+	}
+	if in.NomorSK != nil {
+		v := strings.TrimSpace(*in.NomorSK)
+		if v == "" {
+			in.NomorSK = nil
+		} else if len(v) > 100 {
+			fe.Add("nomor_sk", codeMax(100))
+		} else {
+			in.NomorSK = &v
+		}
+	}
+	if in.NomorSK == nil && requiresSK(in) {
+		fe.Add("nomor_sk", apperror.CodeRequired)
+	}
+	checkLength(fe, "alasan", in.Alasan, 3, 1000)
+	if err := fe.Err(); err != nil {
+		return nil, err
+	}
+
+	return inTx(ctx, s.tx, func(ctx context.Context) (*model.MahasiswaStatusChange, error) {
+		// lock baris agar dua transisi bersamaan tidak sama-sama membaca status lama
+		if err := s.repo.LockByID(ctx, id); err != nil {
+			return nil, err
+		}
+		cur, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if actor.Role == "operator" && !actor.managesProdi(cur.IDProdi) {
+			return nil, errForbidden
+		}
+		if cur.Status == in.Status {
+			return nil, apperror.Field(ErrUnprocessable, "status", codeSameStatus)
+		}
+		if !in.Override && !transitionAllowed(cur.Status, in.Status) {
+			return nil, &apperror.Error{
+				Kind:   ErrUnprocessable,
+				Code:   i18n.MsgStatusTransitionNotAllowed,
+				Fields: map[string]string{"status": codeTransitionDenied},
+			}
+		}
+		// riwayat harus kronologis: tanggal efektif tidak boleh mendahului transisi terakhir
+		if last, err := s.repo.LatestStatusDate(ctx, id); err != nil {
+			return nil, err
+		} else if last != nil && in.TanggalEfektif.Before(*last) {
+			return nil, apperror.Field(ErrUnprocessable, "tanggal_efektif", codeBeforeLastChange)
+		}
+
+		out, err := s.repo.UpdateStatus(ctx, id, in.Status)
+		if err != nil {
+			return nil, err
+		}
+		h := &model.MahasiswaStatusHistory{
+			IDMahasiswa:    id,
+			StatusLama:     cur.Status,
+			StatusBaru:     in.Status,
+			TanggalEfektif: in.TanggalEfektif,
+			NomorSK:        in.NomorSK,
+			Alasan:         in.Alasan,
+			Override:       in.Override,
+			Username:       actor.Username,
+			Role:           actor.Role,
+		}
+		if actor.IDUser != 0 {
+			h.IDUser = &actor.IDUser
+		}
+		h, err = s.repo.CreateStatusHistory(ctx, h)
+		if err != nil {
+			return nil, err
+		}
+		return &model.MahasiswaStatusChange{Mahasiswa: *out, History: *h}, nil
+	})
+}
+
+// StatusHistory mengembalikan riwayat status mahasiswa, terbaru lebih dulu
+func (s *MahasiswaService) StatusHistory(ctx context.Context, id string) ([]model.MahasiswaStatusHistory, error) {
+	ctx, span := tracing.Start(ctx, "MahasiswaService.StatusHistory")
+	defer span.End()
+
+	id = strings.TrimSpace(id)
+	if !nimPattern.MatchString(id) {
+		return nil, errInvalidNIM
+	}
+	if ok, err := s.repo.ExistsID(ctx, id); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return s.repo.ListStatusHistory(ctx, id)
+}
+
+// checkStatusUnchanged menolak perubahan status lewat PUT/PATCH; status yang dikirim boleh ada
+// selama sama dengan status saat ini (client yang mengirim ulang seluruh objek tetap berhasil)
+func (s *MahasiswaService) checkStatusUnchanged(ctx context.Context, id string, status *string) error {
+	if status == nil {
+		return nil
+	}
+	cur, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if cur.Status != *status {
+		return errStatusReadOnly
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	"pencatatan-data-mahasiswa/internal/todo/repository/memory"
+)
+
+var (
+	operatorActor = Actor{IDUser: 2, Username: "operator1", Role: "operator"}
+	adminActor    = Actor{IDUser: 1, Username: "admin", Role: "admin"}
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// TestMahasiswaTransition menjalankan alur Aktif -> Cuti -> Aktif -> Lulus beserta penolakan
+// transisi dari status akhir tanpa override
+func TestMahasiswaTransition(t *testing.T) {
+	ctx := context.Background()
+	s := seedStore(t)
+	svc := NewMahasiswaService(memory.NewMahasiswaRepository(s), s, nil, Clock{})
+
+	steps := []StatusTransition{
+		{Status: StatusCuti, TanggalEfektif: date("2024-09-01"), Alasan: "Cuti sakit"},
+		{Status: StatusAktif, TanggalEfektif: date("2025-02-01"), Alasan: "Kembali kuliah"},
+		{Status: StatusLulus, TanggalEfektif: date("2025-02-01"), NomorSK: strPtr("SK/001/2025"), Alasan: "Yudisium"},
+	}
+	// operator hanya untuk prodi ref_id-nya; tanpa ref_id tidak untuk prodi mana pun
+	for _, a := range []Actor{operatorActor, otherOperator} {
+		if _, err := svc.Transition(ctx, fixMahasiswa, steps[0], a); !errors.Is(err, errForbidden) {
+			t.Errorf("transition by %s: error = %v, want %v", a.Username, err, errForbidden)
+		}
+	}
+	for _, in := range steps {
+		out, err := svc.Transition(ctx, fixMahasiswa, in, prodiOperator)
+		if err != nil {
+			t.Fatalf("Transition to %s: %v", in.Status, err)
+		}
+		if out.Mahasiswa.Status != in.Status || out.History.StatusBaru != in.Status || out.History.Username != prodiOperator.Username {
+			t.Fatalf("Transition to %s = %+v", in.Status, out)
+		}
+	}
+
+	hist, err := svc.StatusHistory(ctx, fixMahasiswa)
+	if err != nil {
+		t.Fatalf("StatusHistory: %v", err)
+	}
+	got := []string{}
+	for _, h := range hist {
+		got = append(got, h.StatusLama+">"+h.StatusBaru)
+	}
+	want := []string{"Aktif>Lulus", "Cuti>Aktif", "Aktif>Cuti"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("history = %v, want %v (newest first)", got, want)
+	}
+
+	// Lulus adalah status akhir
+	back := StatusTransition{Status: StatusAktif, TanggalEfektif: date("2025-03-01"), NomorSK: strPtr("SK/002/2025"), Alasan: "Salah input"}
+	if _, err := svc.Transition(ctx, fixMahasiswa, back, prodiOperator); apperror.FieldsOf(err)["status"] != codeTransitionDenied {
+		t.Errorf("leave Lulus without override: error = %v, want %s", err, codeTransitionDenied)
+	}
+	back.Override = true
+	if _, err := svc.Transition(ctx, fixMahasiswa, back, prodiOperator); !errors.Is(err, apperror.ErrForbidden) {
+		t.Errorf("override by operator: error = %v, want forbidden", err)
+	}
+	out, err := svc.Transition(ctx, fixMahasiswa, back, adminActor)
+	if err != nil || out.Mahasiswa.Status != StatusAktif || !out.History.Override {
+		t.Errorf("override by admin = (%+v, %v)", out, err)
+	}
+}
+
+func TestMahasiswaTransitionRules(t *testing.T) {
+	ctx := context.Background()
+	s := seedStore(t)
+	svc := NewMahasiswaService(memory.NewMahasiswaRepository(s), s, nil, Clock{})
+	if _, err := svc.Transition(ctx, fixMahasiswa, StatusTransition{Status: StatusCuti, TanggalEfektif: date("2024-09-01"), Alasan: "Cuti"}, prodiOperator); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		in    StatusTransition
+		field string
+		want  string
+	}{
+		{"same status", StatusTransition{Status: StatusCuti, Alasan: "Cuti lagi"}, "status", codeSameStatus},
+		{"unknown status", StatusTransition{Status: "Wisuda", Alasan: "Wisuda"}, "status", codeInvalidChoice},
+		{"reason required", StatusTransition{Status: StatusAktif}, "alasan", apperror.CodeRequired},
+		{"sk required for drop out", StatusTransition{Status: StatusDropOut, Alasan: "Tidak aktif 4 semester"}, "nomor_sk", apperror.CodeRequired},
+		{"future date", StatusTransition{Status: StatusAktif, TanggalEfektif: time.Now().AddDate(0, 0, 2), Alasan: "Kembali"}, "tanggal_efektif", codeDateInFuture},
+		{"before last transition", StatusTransition{Status: StatusAktif, TanggalEfektif: date("2024-08-31"), Alasan: "Kembali"}, "tanggal_efektif", codeBeforeLastChange},
+		{"cuti cannot graduate", StatusTransition{Status: StatusLulus, NomorSK: strPtr("SK/1"), Alasan: "Yudisium"}, "status", codeTransitionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Transition(ctx, fixMahasiswa, tt.in, prodiOperator)
+			if got := apperror.FieldsOf(err)[tt.field]; got != tt.want {
+				t.Errorf("error = %v, want %s=%s", err, tt.field, tt.want)
+			}
+		})
+	}
+
+	if hist, _ := svc.StatusHistory(ctx, fixMahasiswa); len(hist) != 1 {
+		t.Errorf("rejected transitions must not be recorded: %d entries", len(hist))
+	}
+}
+
+// TestMahasiswaUpdateKeepsStatus: PUT/PATCH tidak boleh dipakai untuk mengubah status
+func TestMahasiswaUpdateKeepsStatus(t *testing.T) {
+	ctx := context.Background()
+	s := seedStore(t)
+	svc := NewMahasiswaService(memory.NewMahasiswaRepository(s), s, nil, Clock{})
+	put := func(status string) error {
+		m := &model.Mahasiswa{IDProdi: fixProdi, NamaLengkap: "Siti Aminah", JenisKelamin: "P", TahunMasuk: 2024, Status: status}
+		_, err := svc.UpdatePut(ctx, fixMahasiswa, m)
+		return err
+	}
+
+	if err := put(""); err != nil {
+		t.Errorf("PUT without status: %v", err)
+	}
+	if err := put(StatusAktif); err != nil {
+		t.Errorf("PUT with the current status: %v", err)
+	}
+	if err := put(StatusLulus); apperror.FieldsOf(err)["status"] != codeUseTransition {
+		t.Errorf("PUT status change: error = %v, want %s", err, codeUseTransition)
+	}
+	if _, err := svc.UpdatePatch(ctx, fixMahasiswa, nil, nil, nil, nil, nil, nil, nil, nil, strPtr(StatusCuti), nil, nil); apperror.FieldsOf(err)["status"] != codeUseTransition {
+		t.Errorf("PATCH status change: error = %v, want %s", err, codeUseTransition)
+	}
+	if m, _ := svc.Get(ctx, fixMahasiswa); m.Status != StatusAktif {
+		t.Errorf("status = %s, want unchanged", m.Status)
+	}
+}
@@ -0,0 +1,268 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+	"pencatatan-data-mahasiswa/internal/tracing"
+)
+
+// PembimbingAssignment adalah permintaan penetapan dosen pembimbing akademik (PA). Untuk penetapan
+// massal IDProdi dan Angkatan memilih mahasiswanya; HanyaTanpaPembimbing melewati mahasiswa yang
+// sudah punya PA sehingga pembagian sebelumnya tidak tertimpa.
+type PembimbingAssignment struct {
+	IDDosen              string
+	IDProdi              string
+	Angkatan             int
+	HanyaTanpaPembimbing bool
+	Alasan               *string
+}
+
+// PembimbingService mengelola penetapan PA beserta riwayatnya dan daftar mahasiswa bimbingan
+type PembimbingService struct {
+	repo      PembimbingRepository
+	mahasiswa MahasiswaRepository
+	tx        UnitOfWork
+	clock     Clock
+}
+
+func NewPembimbingService(r PembimbingRepository, mahasiswa MahasiswaRepository, tx UnitOfWork, clock Clock) *PembimbingService {
+	return &PembimbingService{repo: r, mahasiswa: mahasiswa, tx: tx, clock: clock}
+}
+
+// inProdi: operator dengan ref_id hanya boleh mengelola mahasiswa prodinya
+func (a Actor) inProdi(idProdi string) bool {
+	return a.Role != "operator" || a.RefID == nil || *a.RefID == idProdi
+}
+
+// normalizeAlasan merapikan alasan opsional; kosong berarti nil
+func normalizeAlasan(fe apperror.Fields, alasan *string) *string {
+	if alasan == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*alasan)
+	if v == "" {
+		return nil
+	}
+	checkLength(fe, "alasan", v, 3, 1000)
+	return &v
+}
+
+// lockDosen mengunci dosen tujuan; dosen yang tidak ada dilaporkan sebagai field id_dosen
+func (s *PembimbingService) lockDosen(ctx context.Context, id string) error {
+	err := s.repo.LockDosen(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return apperror.Field(ErrUnprocessable, "id_dosen", "not_found")
+	}
+	return err
+}
+
+// assign mengganti PA mahasiswa nim menjadi in.IDDosen: masa bimbingan lama ditutup hari ini dan
+// KRS yang masih menunggu persetujuan dipindahkan ke PA baru. changed false bila dosennya sama.
+func (s *PembimbingService) assign(ctx context.Context, nim string, in PembimbingAssignment, actor Actor) (p *model.PembimbingAkademik, changed bool, err error) {
+	cur, err := s.repo.Current(ctx, nim)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		cur = nil
+	case err != nil:
+		return nil, false, err
+	case cur.IDDosen == in.IDDosen:
+		return cur, false, nil
+	}
+	today := s.clock.Today()
+	if cur != nil {
+		if err := s.repo.End(ctx, cur.IDPembimbing, today); err != nil {
+			return nil, false, err
+		}
+	}
+	row := &model.PembimbingAkademik{
+		IDMahasiswa:  nim,
+		IDDosen:      in.IDDosen,
+		TanggalMulai: today,
+		Alasan:       in.Alasan,
+		Username:     actor.Username,
+	}
+	if actor.IDUser != 0 {
+		row.IDUser = &actor.IDUser
+	}
+	p, err = s.repo.Create(ctx, row)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := s.repo.ReassignPending(ctx, nim, in.IDDosen); err != nil {
+		return nil, false, err
+	}
+	return p, true, nil
+}
+
+// Assign menetapkan PA satu mahasiswa. Mahasiswa Lulus/Drop Out tidak bisa diberi PA.
+func (s *PembimbingService) Assign(ctx context.Context, nim string, in PembimbingAssignment, actor Actor) (*model.PembimbingAkademik, error) {
+	ctx, span := tracing.Start(ctx, "PembimbingService.Assign")
+	defer span.End()
+
+	nim = strings.TrimSpace(nim)
+	if !nimPattern.MatchString(nim) {
+		return nil, errInvalidNIM
+	}
+	in.IDDosen = strings.TrimSpace(in.IDDosen)
+	fe := apperror.Fields{}
+	if in.IDDosen == "" {
+		fe.Add("id_dosen", apperror.CodeRequired)
+	}
+	in.Alasan = normalizeAlasan(fe, in.Alasan)
+	if err := fe.Err(); err != nil {
+		return nil, err
+	}
+
+	return inTx(ctx, s.tx, func(ctx context.Context) (*model.PembimbingAkademik, error) {
+		if err := s.mahasiswa.LockByID(ctx, nim); err != nil {
+			return nil, err
+		}
+		m, err := s.mahasiswa.GetByID(ctx, nim)
+		if err != nil {
+			return nil, err
+		}
+		if !actor.inProdi(m.IDProdi) {
+			return nil, pgx.ErrNoRows
+		}
+		if m.Status == StatusLulus || m.Status == StatusDropOut {
+			return nil, apperror.Field(ErrUnprocessable, "status", codeNotEligible)
+		}
+		if err := s.lockDosen(ctx, in.IDDosen); err != nil {
+			return nil, err
+		}
+		p, changed, err := s.assign(ctx, nim, in, actor)
+		if err != nil {
+			return nil, err
+		}
+		if !changed {
+			return nil, apperror.Field(ErrUnprocessable, "id_dosen", codeSameStatus)
+		}
+		return p, nil
+	})
+}
+
+// AssignBulk menetapkan dosen sebagai PA seluruh mahasiswa prodi/angkatan yang belum Lulus atau
+// Drop Out, dalam satu transaksi
+func (s *PembimbingService) AssignBulk(ctx context.Context, in PembimbingAssignment, actor Actor) (*model.BimbinganAssignment, error) {
+	ctx, span := tracing.Start(ctx, "PembimbingService.AssignBulk")
+	defer span.End()
+
+	in.IDDosen = strings.TrimSpace(in.IDDosen)
+	in.IDProdi = strings.TrimSpace(in.IDProdi)
+	fe := apperror.Fields{}
+	if in.IDProdi == "" {
+		fe.Add("id_prodi", apperror.CodeRequired)
+	}
+	if in.Angkatan < 1900 || in.Angkatan > 2100 {
+		fe.Add("angkatan", "range_1900_2100")
+	}
+	in.Alasan = normalizeAlasan(fe, in.Alasan)
+	if err := fe.Err(); err != nil {
+		return nil, err
+	}
+	if !actor.inProdi(in.IDProdi) {
+		return nil, errForbidden
+	}
+
+	return inTx(ctx, s.tx, func(ctx context.Context) (*model.BimbinganAssignment, error) {
+		if err := s.repo.LockDosen(ctx, in.IDDosen); err != nil {
+			return nil, err
+		}
+		if ok, err := s.mahasiswa.ExistsProdi(ctx, in.IDProdi); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, apperror.Field(ErrUnprocessable, "id_prodi", "not_found")
+		}
+		nims, err := s.repo.LockMahasiswaAngkatan(ctx, in.IDProdi, in.Angkatan)
+		if err != nil {
+			return nil, err
+		}
+		out := &model.BimbinganAssignment{IDDosen: in.IDDosen, Assigned: []string{}, Skipped: []string{}}
+		for _, nim := range nims {
+			if in.HanyaTanpaPembimbing {
+				if _, err := s.repo.Current(ctx, nim); err == nil {
+					out.Skipped = append(out.Skipped, nim)
+					continue
+				} else if !errors.Is(err, pgx.ErrNoRows) {
+					return nil, err
+				}
+			}
+			_, changed, err := s.assign(ctx, nim, in, actor)
+			if err != nil {
+				return nil, err
+			}
+			if changed {
+				out.Assigned = append(out.Assigned, nim)
+			} else {
+				out.Skipped = append(out.Skipped, nim)
+			}
+		}
+		return out, nil
+	})
+}
+
+// History mengembalikan riwayat PA mahasiswa, terbaru lebih dulu
+func (s *PembimbingService) History(ctx context.Context, nim string, actor Actor) ([]model.PembimbingAkademik, error) {
+	ctx, span := tracing.Start(ctx, "PembimbingService.History")
+	defer span.End()
+
+	nim = strings.TrimSpace(nim)
+	if !nimPattern.MatchString(nim) {
+		return nil, errInvalidNIM
+	}
+	m, err := s.mahasiswa.GetByID(ctx, nim)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.inProdi(m.IDProdi) {
+		return nil, pgx.ErrNoRows
+	}
+	return s.repo.History(ctx, nim)
+}
+
+// ListBimbingan mengembalikan mahasiswa bimbingan aktif dosen beserta IPK. Dosen hanya bisa
+// melihat bimbingannya sendiri (idDosen diambil dari ref_id); admin/operator untuk dosen mana pun.
+func (s *PembimbingService) ListBimbingan(ctx context.Context, idDosen string, rawFilters map[string][]string, limit, offset int, orderBy string, actor Actor) ([]model.Bimbingan, int64, error) {
+	ctx, span := tracing.Start(ctx, "PembimbingService.ListBimbingan")
+	defer span.End()
+
+	if actor.Role == "dosen" {
+		if actor.RefID == nil {
+			return nil, 0, errForbidden
+		}
+		idDosen = *actor.RefID
+	}
+	idDosen = strings.TrimSpace(idDosen)
+	if limit < 0 || offset < 0 {
+		return nil, 0, ErrInvalidInput
+	}
+	filters, err := repo.ParseFilters(rawFilters, repo.BimbinganFilterFields)
+	if err != nil {
+		return nil, 0, err
+	}
+	if actor.Role == "operator" && actor.RefID != nil {
+		filters = append(filters, repo.Filter{Column: repo.BimbinganFilterFields["id_prodi"].Column, Op: "eq", Values: []any{*actor.RefID}})
+	}
+	if actor.Role != "dosen" {
+		if err := s.repo.LockDosen(ctx, idDosen); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	total, err := s.repo.CountBimbingan(ctx, idDosen, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := s.repo.ListBimbingan(ctx, idDosen, filters, limit, offset, orderBy)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, total, nil
+}
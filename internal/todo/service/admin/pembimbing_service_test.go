@@ -0,0 +1,107 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	"pencatatan-data-mahasiswa/internal/todo/repository/memory"
+)
+
+const (
+	fixDosen2     = "DSN0000002"
+	fixMahasiswa2 = "202400000002"
+)
+
+var dosenActor = Actor{IDUser: 6, Username: fixDosen, Role: "dosen", RefID: strPtr(fixDosen)}
+
+// newPembimbingService menambah dosen kedua dan mahasiswa kedua (angkatan 2024) pada seedStore
+func newPembimbingService(t *testing.T) (*PembimbingService, *memory.Store) {
+	t.Helper()
+	ctx := context.Background()
+	s := seedStore(t)
+	if _, err := memory.NewDosenRepository(s).Create(ctx, &model.Dosen{IDDosen: fixDosen2, NamaDosen: "Dr. Andi"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := memory.NewMahasiswaRepository(s).Create(ctx, &model.Mahasiswa{IDMahasiswa: fixMahasiswa2, IDProdi: fixProdi, NamaLengkap: "Budi Santoso", JenisKelamin: "L", TahunMasuk: 2024, Status: StatusAktif}); err != nil {
+		t.Fatal(err)
+	}
+	return NewPembimbingService(memory.NewPembimbingRepository(s), memory.NewMahasiswaRepository(s), s, Clock{}), s
+}
+
+func TestPembimbingAssignKeepsHistory(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newPembimbingService(t)
+
+	if _, err := svc.Assign(ctx, fixMahasiswa, PembimbingAssignment{IDDosen: fixDosen}, prodiOperator); err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+	if _, err := svc.Assign(ctx, fixMahasiswa, PembimbingAssignment{IDDosen: fixDosen}, adminActor); apperror.FieldsOf(err)["id_dosen"] != codeSameStatus {
+		t.Errorf("same dosen: error = %v, want id_dosen %s", err, codeSameStatus)
+	}
+	if _, err := svc.Assign(ctx, fixMahasiswa, PembimbingAssignment{IDDosen: "DSN9999999"}, adminActor); apperror.FieldsOf(err)["id_dosen"] != "not_found" {
+		t.Errorf("unknown dosen: error = %v, want id_dosen not_found", err)
+	}
+	if _, err := svc.Assign(ctx, fixMahasiswa, PembimbingAssignment{IDDosen: fixDosen2}, otherOperator); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("operator of another prodi: error = %v, want not found", err)
+	}
+	p, err := svc.Assign(ctx, fixMahasiswa, PembimbingAssignment{IDDosen: fixDosen2, Alasan: strPtr("Dosen lama tugas belajar")}, adminActor)
+	if err != nil {
+		t.Fatalf("reassign: %v", err)
+	}
+	if p.NamaDosen != "Dr. Andi" || p.Username != adminActor.Username || p.TanggalSelesai != nil {
+		t.Errorf("new pembimbing = %+v", p)
+	}
+
+	hist, err := svc.History(ctx, fixMahasiswa, adminActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hist) != 2 || hist[0].IDDosen != fixDosen2 || hist[1].IDDosen != fixDosen || hist[1].TanggalSelesai == nil {
+		t.Errorf("history = %+v, want current %s and ended %s", hist, fixDosen2, fixDosen)
+	}
+}
+
+func TestPembimbingAssignBulk(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newPembimbingService(t)
+
+	in := PembimbingAssignment{IDDosen: fixDosen, IDProdi: fixProdi, Angkatan: 2024}
+	res, err := svc.AssignBulk(ctx, in, adminActor)
+	if err != nil {
+		t.Fatalf("AssignBulk: %v", err)
+	}
+	if !slices.Equal(res.Assigned, []string{fixMahasiswa, fixMahasiswa2}) || len(res.Skipped) != 0 {
+		t.Errorf("first bulk = %+v", res)
+	}
+	// mahasiswa yang sudah dibimbing dosen yang sama dilewati, bukan dicatat ulang
+	if res, _ := svc.AssignBulk(ctx, in, adminActor); len(res.Assigned) != 0 || len(res.Skipped) != 2 {
+		t.Errorf("repeat bulk = %+v, want all skipped", res)
+	}
+	in.IDDosen, in.HanyaTanpaPembimbing = fixDosen2, true
+	if res, _ := svc.AssignBulk(ctx, in, adminActor); len(res.Assigned) != 0 || len(res.Skipped) != 2 {
+		t.Errorf("bulk hanya_tanpa_pembimbing = %+v, want all skipped", res)
+	}
+	if _, err := svc.AssignBulk(ctx, in, otherOperator); !errors.Is(err, apperror.ErrForbidden) {
+		t.Errorf("operator of another prodi: error = %v, want forbidden", err)
+	}
+	if _, err := svc.AssignBulk(ctx, PembimbingAssignment{IDDosen: fixDosen, IDProdi: fixProdi}, adminActor); apperror.FieldsOf(err)["angkatan"] == "" {
+		t.Errorf("missing angkatan: error = %v", err)
+	}
+
+	list, total, err := svc.ListBimbingan(ctx, "", nil, 10, 0, "m.nama_lengkap ASC", dosenActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || list[0].IDMahasiswa != fixMahasiswa2 || list[0].IPK != nil {
+		t.Errorf("bimbingan = %d %+v", total, list)
+	}
+	if _, total, _ := svc.ListBimbingan(ctx, fixDosen2, nil, 10, 0, "", adminActor); total != 0 {
+		t.Errorf("bimbingan %s = %d, want 0", fixDosen2, total)
+	}
+}
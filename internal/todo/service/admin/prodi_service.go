@@ -8,16 +8,20 @@ import (
     "regexp"
     "strings"
 
+    "pencatatan-data-mahasiswa/internal/apperror"
+    "pencatatan-data-mahasiswa/internal/i18n"
     model "pencatatan-data-mahasiswa/internal/todo/model/admin"
     repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+    "pencatatan-data-mahasiswa/internal/tracing"
 )
 
 type ProdiService struct {
-    repo *repo.ProdiRepository
+    repo ProdiRepository
+    tx   UnitOfWork
 }
 
-func NewProdiService(r *repo.ProdiRepository) *ProdiService {
-    return &ProdiService{repo: r}
+func NewProdiService(r ProdiRepository, tx UnitOfWork) *ProdiService {
+    return &ProdiService{repo: r, tx: tx}
 }
 
 var (
@@ -26,8 +30,15 @@ var (
     kodePattern    = regexp.MustCompile(`^[A-Za-z0-9_-]{1,16}$`)
     jenjangSet     = map[string]struct{}{"D3":{}, "D4":{}, "S1":{}, "S2":{}, "S3":{}}
     akreditasiSet  = map[string]struct{}{"A":{}, "B":{}, "C":{}, "Baik":{}, "Baik Sekali":{}, "Unggul":{}}
+    errProdiInUse  = apperror.New(ErrConflict, i18n.MsgProdiInUse)
+
+    errInvalidProdiID   = invalidID("id_prodi", codeAlnum8)
+    errFakultasNotFound = apperror.Field(ErrUnprocessable, "id_fakultas", apperror.CodeNotFound)
 )
 
+// codeKodeProdi: 1-16 karakter alfanumerik, '_' atau '-'
+const codeKodeProdi = "pattern_16_alnum"
+
 // util: autogenerate ID untuk Prodi dengan prefix PRD + 5 digit
 func (s *ProdiService) generateUniqueID(ctx context.Context) (string, error) {
     for i := 0; i < 10; i++ {
@@ -47,7 +58,8 @@ func (s *ProdiService) generateUniqueID(ctx context.Context) (string, error) {
     return "", ErrConflict
 }
 
-func (s *ProdiService) validateCommon(p *model.Prodi) error {
+// validateCommon menormalisasi dan memvalidasi field umum prodi, mencatat semua kegagalan ke fe
+func (s *ProdiService) validateCommon(p *model.Prodi, fe apperror.Fields) {
     p.IDProdi = strings.TrimSpace(p.IDProdi)
     p.IDFakultas = strings.TrimSpace(p.IDFakultas)
     p.NamaProdi = strings.TrimSpace(p.NamaProdi)
@@ -62,166 +74,212 @@ func (s *ProdiService) validateCommon(p *model.Prodi) error {
         }
     }
 
-    if p.NamaProdi == "" || len(p.NamaProdi) < 3 || len(p.NamaProdi) > 120 {
-        return ErrInvalidInput
+    checkLength(fe, "nama_prodi", p.NamaProdi, 3, 120)
+    if p.Jenjang == "" {
+        fe.Add("jenjang", apperror.CodeRequired)
+    } else if _, ok := jenjangSet[p.Jenjang]; !ok {
+        fe.Add("jenjang", codeInvalidChoice)
     }
-    if _, ok := jenjangSet[p.Jenjang]; !ok {
-        return ErrInvalidInput
-    }
-    if !kodePattern.MatchString(p.KodeProdi) {
-        return ErrInvalidInput
+    if p.KodeProdi == "" {
+        fe.Add("kode_prodi", apperror.CodeRequired)
+    } else if !kodePattern.MatchString(p.KodeProdi) {
+        fe.Add("kode_prodi", codeKodeProdi)
     }
     if p.Akreditasi != nil {
         if _, ok := akreditasiSet[*p.Akreditasi]; !ok {
-            return ErrInvalidInput
+            fe.Add("akreditasi", codeInvalidChoice)
         }
     }
+    if p.IDFakultas == "" {
+        fe.Add("id_fakultas", apperror.CodeRequired)
+    }
+}
+
+// checkFakultas memastikan fakultas yang dirujuk ada (422 bila tidak)
+func (s *ProdiService) checkFakultas(ctx context.Context, idFakultas string) error {
+    if ok, err := s.repo.ExistsFakultas(ctx, idFakultas); err != nil {
+        return err
+    } else if !ok {
+        return errFakultasNotFound
+    }
     return nil
 }
 
-// List Prodi dengan filter dan pagination
-func (s *ProdiService) List(ctx context.Context, q string, idFakultas, jenjang, akreditasi *string, limit, offset int, orderBy string) ([]model.Prodi, error) {
+// List Prodi dengan filter (grammar filter repository) dan pagination, beserta total baris yang cocok
+func (s *ProdiService) List(ctx context.Context, q string, rawFilters map[string][]string, limit, offset int, orderBy string) ([]model.Prodi, int64, error) {
+    ctx, span := tracing.Start(ctx, "ProdiService.List")
+    defer span.End()
+
     if limit < 0 || offset < 0 {
-        return nil, ErrInvalidInput
+        return nil, 0, ErrInvalidInput
+    }
+    filters, err := repo.ParseFilters(rawFilters, repo.ProdiFilterFields)
+    if err != nil {
+        return nil, 0, err
+    }
+    total, err := s.repo.Count(ctx, q, filters)
+    if err != nil {
+        return nil, 0, err
     }
-    return s.repo.List(ctx, q, idFakultas, jenjang, akreditasi, limit, offset, orderBy)
+    data, err := s.repo.List(ctx, q, filters, limit, offset, orderBy)
+    if err != nil {
+        return nil, 0, err
+    }
+    return data, total, nil
 }
 
 // Get detail prodi by id_prodi
 func (s *ProdiService) Get(ctx context.Context, id string) (*model.Prodi, error) {
+    ctx, span := tracing.Start(ctx, "ProdiService.Get")
+    defer span.End()
+
     id = strings.TrimSpace(id)
     if !prodiIDPattern.MatchString(id) {
-        return nil, ErrInvalidInput
+        return nil, errInvalidProdiID
     }
     return s.repo.GetByID(ctx, id)
 }
 
+// lockNama mengunci kombinasi fakultas+jenjang+nama yang keunikannya hanya dijaga service
+func (s *ProdiService) lockNama(ctx context.Context, idFakultas, jenjang, nama string) error {
+    return s.tx.LockKey(ctx, lockProdiNama, idFakultas+"|"+jenjang+"|"+strings.ToLower(nama))
+}
+
 // Create Prodi: ID auto-generate jika kosong; validasi unik kode dan nama per fakultas+jenjang
 func (s *ProdiService) Create(ctx context.Context, p *model.Prodi) (*model.Prodi, error) {
-    if err := s.validateCommon(p); err != nil {
-        return nil, err
-    }
-    // id_fakultas harus ada
-    if p.IDFakultas == "" {
-        return nil, ErrInvalidInput
-    }
-    if ok, err := s.repo.ExistsFakultas(ctx, p.IDFakultas); err != nil {
-        return nil, err
-    } else if !ok {
-        return nil, ErrInvalidInput
-    }
+    ctx, span := tracing.Start(ctx, "ProdiService.Create")
+    defer span.End()
 
-    // kode unik global
-    if exist, err := s.repo.ExistsKode(ctx, p.KodeProdi, nil); err != nil {
-        return nil, err
-    } else if exist {
-        return nil, ErrConflict
+    fe := apperror.Fields{}
+    s.validateCommon(p, fe)
+    if p.IDProdi != "" && !prodiIDPattern.MatchString(p.IDProdi) {
+        fe.Add("id_prodi", codeAlnum8)
     }
-
-    // nama unik per fakultas + jenjang (case-insensitive)
-    if exist, err := s.repo.ExistsNamaPerFakultasJenjangCI(ctx, p.IDFakultas, p.Jenjang, p.NamaProdi, nil); err != nil {
+    if err := fe.Err(); err != nil {
         return nil, err
-    } else if exist {
-        return nil, ErrConflict
     }
-
-    // handle ID
-    if p.IDProdi == "" {
-        id, err := s.generateUniqueID(ctx)
-        if err != nil {
+    return inTx(ctx, s.tx, func(ctx context.Context) (*model.Prodi, error) {
+        if err := s.lockNama(ctx, p.IDFakultas, p.Jenjang, p.NamaProdi); err != nil {
             return nil, err
         }
-        p.IDProdi = id
-    } else {
-        if !prodiIDPattern.MatchString(p.IDProdi) {
-            return nil, ErrInvalidInput
+        // id_fakultas harus ada
+        if err := s.checkFakultas(ctx, p.IDFakultas); err != nil {
+            return nil, err
         }
-        if exist, err := s.repo.ExistsID(ctx, p.IDProdi); err != nil {
+
+        taken := apperror.Fields{}
+        // kode unik global
+        if exist, err := s.repo.ExistsKode(ctx, p.KodeProdi, nil); err != nil {
             return nil, err
         } else if exist {
-            return nil, ErrConflict
+            taken.Add("kode_prodi", apperror.CodeTaken)
+        }
+        // nama unik per fakultas + jenjang (case-insensitive)
+        if exist, err := s.repo.ExistsNamaPerFakultasJenjangCI(ctx, p.IDFakultas, p.Jenjang, p.NamaProdi, nil); err != nil {
+            return nil, err
+        } else if exist {
+            taken.Add("nama_prodi", apperror.CodeTaken)
+        }
+        if p.IDProdi != "" {
+            if exist, err := s.repo.ExistsID(ctx, p.IDProdi); err != nil {
+                return nil, err
+            } else if exist {
+                taken.Add("id_prodi", apperror.CodeTaken)
+            }
+        }
+        if err := taken.As(ErrConflict); err != nil {
+            return nil, err
         }
-    }
 
-    return s.repo.Create(ctx, p)
+        // ID kosong: generate otomatis
+        if p.IDProdi == "" {
+            id, err := s.generateUniqueID(ctx)
+            if err != nil {
+                return nil, err
+            }
+            p.IDProdi = id
+        }
+
+        return s.repo.Create(ctx, p)
+    })
 }
 
 // UpdatePut: full update kecuali id_prodi
 func (s *ProdiService) UpdatePut(ctx context.Context, id string, p *model.Prodi) (*model.Prodi, error) {
+    ctx, span := tracing.Start(ctx, "ProdiService.UpdatePut")
+    defer span.End()
+
     id = strings.TrimSpace(id)
     if !prodiIDPattern.MatchString(id) {
-        return nil, ErrInvalidInput
+        return nil, errInvalidProdiID
     }
     // Validasi field umum
-    if err := s.validateCommon(p); err != nil {
-        return nil, err
-    }
-    // id_fakultas harus valid
-    if p.IDFakultas == "" {
-        return nil, ErrInvalidInput
-    }
-    if ok, err := s.repo.ExistsFakultas(ctx, p.IDFakultas); err != nil {
-        return nil, err
-    } else if !ok {
-        return nil, ErrInvalidInput
-    }
-    // Kode unik exclude id
-    if exist, err := s.repo.ExistsKode(ctx, p.KodeProdi, &id); err != nil {
+    fe := apperror.Fields{}
+    s.validateCommon(p, fe)
+    if err := fe.Err(); err != nil {
         return nil, err
-    } else if exist {
-        return nil, ErrConflict
-    }
-    // Nama unik per fakultas+jenjang exclude id
-    if exist, err := s.repo.ExistsNamaPerFakultasJenjangCI(ctx, p.IDFakultas, p.Jenjang, p.NamaProdi, &id); err != nil {
-        return nil, err
-    } else if exist {
-        return nil, ErrConflict
     }
+    return inTx(ctx, s.tx, func(ctx context.Context) (*model.Prodi, error) {
+        if err := s.lockNama(ctx, p.IDFakultas, p.Jenjang, p.NamaProdi); err != nil {
+            return nil, err
+        }
+        // id_fakultas harus valid
+        if err := s.checkFakultas(ctx, p.IDFakultas); err != nil {
+            return nil, err
+        }
+        taken := apperror.Fields{}
+        // Kode unik exclude id
+        if exist, err := s.repo.ExistsKode(ctx, p.KodeProdi, &id); err != nil {
+            return nil, err
+        } else if exist {
+            taken.Add("kode_prodi", apperror.CodeTaken)
+        }
+        // Nama unik per fakultas+jenjang exclude id
+        if exist, err := s.repo.ExistsNamaPerFakultasJenjangCI(ctx, p.IDFakultas, p.Jenjang, p.NamaProdi, &id); err != nil {
+            return nil, err
+        } else if exist {
+            taken.Add("nama_prodi", apperror.CodeTaken)
+        }
+        if err := taken.As(ErrConflict); err != nil {
+            return nil, err
+        }
 
-    return s.repo.UpdatePut(ctx, id, p)
+        return s.repo.UpdatePut(ctx, id, p)
+    })
 }
 
 // UpdatePatch: partial update
 func (s *ProdiService) UpdatePatch(ctx context.Context, id string, idFakultas, nama, jenjang, kode, akreditasi *string) (*model.Prodi, error) {
+    ctx, span := tracing.Start(ctx, "ProdiService.UpdatePatch")
+    defer span.End()
+
     id = strings.TrimSpace(id)
     if !prodiIDPattern.MatchString(id) {
-        return nil, ErrInvalidInput
+        return nil, errInvalidProdiID
     }
 
     // Validasi field jika disediakan
+    fe := apperror.Fields{}
     if idFakultas != nil {
         *idFakultas = strings.TrimSpace(*idFakultas)
         if *idFakultas == "" {
-            return nil, ErrInvalidInput
-        }
-        if ok, err := s.repo.ExistsFakultas(ctx, *idFakultas); err != nil {
-            return nil, err
-        } else if !ok {
-            return nil, ErrInvalidInput
+            fe.Add("id_fakultas", apperror.CodeRequired)
         }
     }
     if nama != nil {
         *nama = strings.TrimSpace(*nama)
-        if len(*nama) < 3 || len(*nama) > 120 {
-            return nil, ErrInvalidInput
-        }
+        checkLength(fe, "nama_prodi", *nama, 3, 120)
     }
     if jenjang != nil {
         *jenjang = strings.TrimSpace(*jenjang)
         if _, ok := jenjangSet[*jenjang]; !ok {
-            return nil, ErrInvalidInput
+            fe.Add("jenjang", codeInvalidChoice)
         }
     }
     if kode != nil {
         *kode = strings.TrimSpace(*kode)
         if !kodePattern.MatchString(*kode) {
-            return nil, ErrInvalidInput
-        }
-        // Kode unik exclude id
-        if exist, err := s.repo.ExistsKode(ctx, *kode, &id); err != nil {
-            return nil, err
-        } else if exist {
-            return nil, ErrConflict
+            fe.Add("kode_prodi", codeKodeProdi)
         }
     }
     if akreditasi != nil {
@@ -230,54 +288,91 @@ func (s *ProdiService) UpdatePatch(ctx context.Context, id string, idFakultas, n
             akreditasi = nil
         } else {
             if _, ok := akreditasiSet[*akreditasi]; !ok {
-                return nil, ErrInvalidInput
+                fe.Add("akreditasi", codeInvalidChoice)
             }
         }
     }
-
-    // Jika ada kombinasi (id_fakultas || jenjang || nama) berubah, cek unik kombinasi tersebut
-    if idFakultas != nil || jenjang != nil || nama != nil {
-        // Ambil existing untuk mendapatkan nilai default saat pointer nil
-        cur, err := s.repo.GetByID(ctx, id)
-        if err != nil {
-            return nil, err
-        }
-        finalIDF := cur.IDFakultas
-        finalJen := cur.Jenjang
-        finalNama := cur.NamaProdi
+    if err := fe.Err(); err != nil {
+        return nil, err
+    }
+    return inTx(ctx, s.tx, func(ctx context.Context) (*model.Prodi, error) {
         if idFakultas != nil {
-            finalIDF = *idFakultas
+            if err := s.checkFakultas(ctx, *idFakultas); err != nil {
+                return nil, err
+            }
         }
-        if jenjang != nil {
-            finalJen = *jenjang
+
+        taken := apperror.Fields{}
+        if kode != nil {
+            // Kode unik exclude id
+            if exist, err := s.repo.ExistsKode(ctx, *kode, &id); err != nil {
+                return nil, err
+            } else if exist {
+                taken.Add("kode_prodi", apperror.CodeTaken)
+            }
         }
-        if nama != nil {
-            finalNama = *nama
+        // Jika ada kombinasi (id_fakultas || jenjang || nama) berubah, cek unik kombinasi tersebut
+        if idFakultas != nil || jenjang != nil || nama != nil {
+            // Ambil existing untuk mendapatkan nilai default saat pointer nil; baris dikunci agar
+            // PATCH lain pada prodi yang sama tidak mengubah kombinasinya di tengah pengecekan
+            if err := s.repo.LockByID(ctx, id); err != nil {
+                return nil, err
+            }
+            cur, err := s.repo.GetByID(ctx, id)
+            if err != nil {
+                return nil, err
+            }
+            finalIDF := cur.IDFakultas
+            finalJen := cur.Jenjang
+            finalNama := cur.NamaProdi
+            if idFakultas != nil {
+                finalIDF = *idFakultas
+            }
+            if jenjang != nil {
+                finalJen = *jenjang
+            }
+            if nama != nil {
+                finalNama = *nama
+            }
+            if err := s.lockNama(ctx, finalIDF, finalJen, finalNama); err != nil {
+                return nil, err
+            }
+            if exist, err := s.repo.ExistsNamaPerFakultasJenjangCI(ctx, finalIDF, finalJen, finalNama, &id); err != nil {
+                return nil, err
+            } else if exist {
+                taken.Add("nama_prodi", apperror.CodeTaken)
+            }
         }
-        if exist, err := s.repo.ExistsNamaPerFakultasJenjangCI(ctx, finalIDF, finalJen, finalNama, &id); err != nil {
+        if err := taken.As(ErrConflict); err != nil {
             return nil, err
-        } else if exist {
-            return nil, ErrConflict
         }
-    }
 
-    return s.repo.UpdatePatch(ctx, id, idFakultas, nama, jenjang, kode, akreditasi)
+        return s.repo.UpdatePatch(ctx, id, idFakultas, nama, jenjang, kode, akreditasi)
+    })
 }
 
 func (s *ProdiService) Delete(ctx context.Context, id string) error {
+    ctx, span := tracing.Start(ctx, "ProdiService.Delete")
+    defer span.End()
+
     id = strings.TrimSpace(id)
     if !prodiIDPattern.MatchString(id) {
-        return ErrInvalidInput
-    }
-    if has, err := s.repo.HasMahasiswaRelated(ctx, id); err != nil {
-        return err
-    } else if has {
-        return ErrConflict
+        return errInvalidProdiID
     }
-    if has, err := s.repo.HasMataKuliahRelated(ctx, id); err != nil {
-        return err
-    } else if has {
-        return ErrConflict
-    }
-    return s.repo.Delete(ctx, id)
-}
\ No newline at end of file
+    return s.tx.Do(ctx, func(ctx context.Context) error {
+        if err := s.repo.LockByID(ctx, id); err != nil {
+            return err
+        }
+        if has, err := s.repo.HasMahasiswaRelated(ctx, id); err != nil {
+            return err
+        } else if has {
+            return errProdiInUse
+        }
+        if has, err := s.repo.HasMataKuliahRelated(ctx, id); err != nil {
+            return err
+        } else if has {
+            return errProdiInUse
+        }
+        return s.repo.Delete(ctx, id)
+    })
+}
@@ -0,0 +1,230 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"pencatatan-data-mahasiswa/internal/db"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+	authsvc "pencatatan-data-mahasiswa/internal/todo/service/auth"
+)
+
+// Interface repository yang dipakai service admin. Implementasi PostgreSQL ada di
+// internal/todo/repository/admin, implementasi in-memory untuk unit test ada di
+// internal/todo/repository/memory. Kontrak keduanya sama: data tidak ditemukan -> pgx.ErrNoRows,
+// pelanggaran unique/FK -> *pgconn.PgError yang diterjemahkan apperror.FromDB.
+
+// FakultasRepository dipakai oleh Service (fakultas)
+type FakultasRepository interface {
+	List(ctx context.Context, search string, filters []repo.Filter, limit, offset int) ([]model.Fakultas, error)
+	Count(ctx context.Context, search string, filters []repo.Filter) (int64, error)
+	GetByID(ctx context.Context, id string) (*model.Fakultas, error)
+	ExistsID(ctx context.Context, id string) (bool, error)
+	ExistsNamaCI(ctx context.Context, nama string) (bool, error)
+	Create(ctx context.Context, f *model.Fakultas) (*model.Fakultas, error)
+	Update(ctx context.Context, id string, nama *string, singkatan *string) (*model.Fakultas, error)
+	HasProdiRelated(ctx context.Context, id string) (bool, error)
+	LockByID(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// ProdiRepository dipakai oleh ProdiService
+type ProdiRepository interface {
+	List(ctx context.Context, q string, filters []repo.Filter, limit, offset int, orderBy string) ([]model.Prodi, error)
+	Count(ctx context.Context, q string, filters []repo.Filter) (int64, error)
+	GetByID(ctx context.Context, id string) (*model.Prodi, error)
+	ExistsID(ctx context.Context, id string) (bool, error)
+	ExistsFakultas(ctx context.Context, idFak string) (bool, error)
+	ExistsKode(ctx context.Context, kode string, excludeID *string) (bool, error)
+	ExistsNamaPerFakultasJenjangCI(ctx context.Context, idFakultas, jenjang, nama string, excludeID *string) (bool, error)
+	Create(ctx context.Context, p *model.Prodi) (*model.Prodi, error)
+	UpdatePut(ctx context.Context, id string, p *model.Prodi) (*model.Prodi, error)
+	UpdatePatch(ctx context.Context, id string, idFakultas, nama, jenjang, kode *string, akreditasi *string) (*model.Prodi, error)
+	HasMahasiswaRelated(ctx context.Context, id string) (bool, error)
+	HasMataKuliahRelated(ctx context.Context, id string) (bool, error)
+	LockByID(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// DosenRepository dipakai oleh DosenService
+type DosenRepository interface {
+	List(ctx context.Context, q, match string, filters []repo.Filter, limit, offset int, orderBy string) ([]model.Dosen, error)
+	Count(ctx context.Context, q, match string, filters []repo.Filter) (int64, error)
+	GetByID(ctx context.Context, id string) (*model.Dosen, error)
+	ExistsID(ctx context.Context, id string) (bool, error)
+	ExistsNIDN(ctx context.Context, nidn string, excludeID *string) (bool, error)
+	ExistsEmail(ctx context.Context, email string, excludeID *string) (bool, error)
+	Create(ctx context.Context, d *model.Dosen) (*model.Dosen, error)
+	UpdatePut(ctx context.Context, id string, d *model.Dosen) (*model.Dosen, error)
+	UpdatePatch(ctx context.Context, id string, nidn, nama, email, nohp, jabatan *string) (*model.Dosen, error)
+	HasMataKuliahPenanggungJawab(ctx context.Context, id string) (bool, error)
+	HasKelasKuliahPengampu(ctx context.Context, id string) (bool, error)
+	HasPembimbingRelated(ctx context.Context, id string) (bool, error)
+	LockByID(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// MahasiswaRepository dipakai oleh MahasiswaService
+type MahasiswaRepository interface {
+	List(ctx context.Context, q, match string, filters []repo.Filter, limit, offset int, orderBy string) ([]model.Mahasiswa, error)
+	ListKeyset(ctx context.Context, q, match string, filters []repo.Filter, limit int, sortCol string, desc bool, afterValue any, afterID *string) ([]model.Mahasiswa, error)
+	Count(ctx context.Context, q, match string, filters []repo.Filter) (int64, error)
+	GetByID(ctx context.Context, id string) (*model.Mahasiswa, error)
+	ExistsID(ctx context.Context, id string) (bool, error)
+	ExistsEmail(ctx context.Context, email string, excludeID *string) (bool, error)
+	ExistsNIK(ctx context.Context, nik string, excludeID *string) (bool, error)
+	ExistsProdi(ctx context.Context, idProdi string) (bool, error)
+	Create(ctx context.Context, m *model.Mahasiswa) (*model.Mahasiswa, error)
+	UpdatePut(ctx context.Context, id string, m *model.Mahasiswa) (*model.Mahasiswa, error)
+	UpdatePatch(ctx context.Context, id string, idProdi, nik, nama, jk, tempat, alamat, email, nohp *string, tgl *time.Time, tahunMasuk *int) (*model.Mahasiswa, error)
+	UpdateStatus(ctx context.Context, id, status string) (*model.Mahasiswa, error)
+	CreateStatusHistory(ctx context.Context, h *model.MahasiswaStatusHistory) (*model.MahasiswaStatusHistory, error)
+	ListStatusHistory(ctx context.Context, id string) ([]model.MahasiswaStatusHistory, error)
+	LatestStatusDate(ctx context.Context, id string) (*time.Time, error)
+	HasKRSRelated(ctx context.Context, id string) (bool, error)
+	LockByID(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// SemesterRepository dipakai oleh SemesterService
+type SemesterRepository interface {
+	List(ctx context.Context, q string, filters []repo.Filter, limit, offset int, orderBy string) ([]model.Semester, error)
+	Count(ctx context.Context, q string, filters []repo.Filter) (int64, error)
+	GetByID(ctx context.Context, id string) (*model.Semester, error)
+	ExistsID(ctx context.Context, id string) (bool, error)
+	Create(ctx context.Context, s *model.Semester) (*model.Semester, error)
+	UpdatePut(ctx context.Context, id string, s *model.Semester) (*model.Semester, error)
+	UpdatePatch(ctx context.Context, id string, tahunAjaran, term *string, tglMulai, tglSelesai *time.Time) (*model.Semester, error)
+	HasKelasRelated(ctx context.Context, id string) (bool, error)
+	HasKRSRelated(ctx context.Context, id string) (bool, error)
+	LockByID(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// CutiRepository dipakai oleh CutiService
+type CutiRepository interface {
+	List(ctx context.Context, filters []repo.Filter, limit, offset int, orderBy string) ([]model.Cuti, error)
+	Count(ctx context.Context, filters []repo.Filter) (int64, error)
+	GetByID(ctx context.Context, id int64) (*model.Cuti, error)
+	LockByID(ctx context.Context, id int64) error
+	GetLampiran(ctx context.Context, id int64) (*model.CutiLampiran, error)
+	Create(ctx context.Context, c *model.Cuti, lampiran []byte) (*model.Cuti, error)
+	SetStatus(ctx context.Context, id int64, status string, catatan *string, reviewer *int64) (*model.Cuti, error)
+	MarkApplied(ctx context.Context, id int64) error
+	MarkFinished(ctx context.Context, id int64) error
+	CountActive(ctx context.Context, idMahasiswa string, excludeID int64) (int, error)
+	OpenStatus(ctx context.Context, idMahasiswa, idSemester string) (string, error)
+	HasKRS(ctx context.Context, idMahasiswa, idSemester string) (bool, error)
+	ListToApply(ctx context.Context, idSemester string) ([]model.Cuti, error)
+	ListToFinish(ctx context.Context, idSemester string) ([]model.Cuti, error)
+	GetSemester(ctx context.Context, id string) (*model.Semester, error)
+	CurrentSemester(ctx context.Context, today time.Time) (*model.Semester, error)
+	GetPolicy(ctx context.Context, idProdi string) (*model.CutiPolicy, error)
+	UpsertPolicy(ctx context.Context, idProdi string, maks int) (*model.CutiPolicy, error)
+}
+
+// PembimbingRepository dipakai oleh PembimbingService
+type PembimbingRepository interface {
+	Current(ctx context.Context, idMahasiswa string) (*model.PembimbingAkademik, error)
+	History(ctx context.Context, idMahasiswa string) ([]model.PembimbingAkademik, error)
+	End(ctx context.Context, id int64, tanggal time.Time) error
+	Create(ctx context.Context, p *model.PembimbingAkademik) (*model.PembimbingAkademik, error)
+	ReassignPending(ctx context.Context, idMahasiswa, idDosen string) error
+	LockDosen(ctx context.Context, id string) error
+	LockMahasiswaAngkatan(ctx context.Context, idProdi string, angkatan int) ([]string, error)
+	ListBimbingan(ctx context.Context, idDosen string, filters []repo.Filter, limit, offset int, orderBy string) ([]model.Bimbingan, error)
+	CountBimbingan(ctx context.Context, idDosen string, filters []repo.Filter) (int64, error)
+	IsAdvisee(ctx context.Context, idDosen, idMahasiswa string) (bool, error)
+}
+
+// KRSRepository dipakai oleh KRSService
+type KRSRepository interface {
+	ListKelas(ctx context.Context, idMahasiswa, idSemester string) ([]model.KRSKelas, error)
+	KelasOutside(ctx context.Context, idSemester string, ids []string) ([]string, error)
+	ReplaceKelas(ctx context.Context, idMahasiswa, idSemester string, ids []string) error
+	GetPengajuan(ctx context.Context, idMahasiswa, idSemester string) (*model.KRSPengajuan, error)
+	GetPengajuanByID(ctx context.Context, id int64) (*model.KRSPengajuan, error)
+	LockPengajuan(ctx context.Context, id int64) error
+	Submit(ctx context.Context, idMahasiswa, idSemester, idDosen string) (*model.KRSPengajuan, error)
+	Review(ctx context.Context, id int64, status string, catatan *string) (*model.KRSPengajuan, error)
+	ListPengajuan(ctx context.Context, idDosen string, filters []repo.Filter, limit, offset int, orderBy string) ([]model.KRSPengajuan, error)
+	CountPengajuan(ctx context.Context, idDosen string, filters []repo.Filter) (int64, error)
+	ExistsSemester(ctx context.Context, id string) (bool, error)
+	BestBobot(ctx context.Context, idMahasiswa string, ids []string) (map[string]float64, error)
+}
+
+// KurikulumRepository dipakai oleh KurikulumService dan KRSService (cek prasyarat)
+type KurikulumRepository interface {
+	List(ctx context.Context, filters []repo.Filter, limit, offset int, orderBy string) ([]model.Kurikulum, error)
+	Count(ctx context.Context, filters []repo.Filter) (int64, error)
+	GetByID(ctx context.Context, id int64) (*model.Kurikulum, error)
+	LockByID(ctx context.Context, id int64) error
+	ExistsProdi(ctx context.Context, idProdi string) (bool, error)
+	ExistsTahun(ctx context.Context, idProdi string, tahun int, excludeID *int64) (bool, error)
+	Create(ctx context.Context, k *model.Kurikulum) (*model.Kurikulum, error)
+	Update(ctx context.Context, id int64, k *model.Kurikulum) (*model.Kurikulum, error)
+	Deactivate(ctx context.Context, idProdi string, exceptID int64) error
+	Delete(ctx context.Context, id int64) error
+	ListMataKuliah(ctx context.Context, id int64) ([]model.KurikulumMK, error)
+	ReplaceMataKuliah(ctx context.Context, id int64, items []model.KurikulumMK) error
+	MataKuliahMissing(ctx context.Context, ids []string) ([]string, error)
+	MataKuliahOutsideProdi(ctx context.Context, idProdi string, ids []string) ([]string, error)
+	MataKuliahProdi(ctx context.Context, idMK string) (string, error)
+	ListPrasyarat(ctx context.Context, ids []string) ([]model.Prasyarat, error)
+	ReplacePrasyarat(ctx context.Context, idMK string, items []model.Prasyarat) error
+	RequiredBy(ctx context.Context, idMK string) ([]string, error)
+}
+
+// UnitOfWork menjalankan beberapa panggilan repository dalam satu transaksi: repository
+// memakai transaksi yang dibawa ctx milik fn. Implementasinya db.TxManager dan memory.Store.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+	// LockKey mengunci (scope, key) sampai transaksi selesai; hanya sah di dalam Do
+	LockKey(ctx context.Context, scope, key string) error
+}
+
+// AccountProvisioner membuat akun login mahasiswa/dosen (service auth). Provision ikut transaksi
+// yang dibawa ctx sehingga data master dan akunnya tersimpan atau dibatalkan bersama.
+type AccountProvisioner interface {
+	Provision(ctx context.Context, role, refID string, mode authsvc.AccountMode) (*authsvc.Account, error)
+}
+
+// Scope LockKey untuk aturan unik yang hanya dijaga service (tidak ada constraint di database)
+const (
+	lockFakultasNama   = "fakultas.nama"
+	lockProdiNama      = "prodi.nama"
+	lockDosenEmail     = "dosen.email"
+	lockMahasiswaEmail = "mahasiswa.email"
+	lockKurikulumProdi = "kurikulum.prodi"
+	lockPrasyarat      = "prasyarat_mk"
+)
+
+// inTx menjalankan fn di dalam tx.Do dan meneruskan hasilnya
+func inTx[T any](ctx context.Context, tx UnitOfWork, fn func(ctx context.Context) (T, error)) (T, error) {
+	var out T
+	err := tx.Do(ctx, func(ctx context.Context) error {
+		var err error
+		out, err = fn(ctx)
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return out, nil
+}
+
+var (
+	_ FakultasRepository   = (*repo.FakultasRepository)(nil)
+	_ ProdiRepository      = (*repo.ProdiRepository)(nil)
+	_ DosenRepository      = (*repo.DosenRepository)(nil)
+	_ MahasiswaRepository  = (*repo.MahasiswaRepository)(nil)
+	_ SemesterRepository   = (*repo.SemesterRepository)(nil)
+	_ CutiRepository       = (*repo.CutiRepository)(nil)
+	_ PembimbingRepository = (*repo.PembimbingRepository)(nil)
+	_ KRSRepository        = (*repo.KRSRepository)(nil)
+	_ KurikulumRepository  = (*repo.KurikulumRepository)(nil)
+	_ UnitOfWork           = (*db.TxManager)(nil)
+	_ AccountProvisioner   = (*authsvc.Service)(nil)
+)
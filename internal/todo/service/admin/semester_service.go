@@ -2,30 +2,45 @@ package admin
 
 import (
 	"context"
-	"errors"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/i18n"
 	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
 	repo "pencatatan-data-mahasiswa/internal/todo/repository/admin"
+	"pencatatan-data-mahasiswa/internal/tracing"
 )
 
 // Reuse ErrInvalidInput and ErrConflict from this package (declared in fakultas_service.go)
 
 type SemesterService struct {
-	repo *repo.SemesterRepository
+	repo SemesterRepository
+	tx   UnitOfWork
 }
 
-func NewSemesterService(r *repo.SemesterRepository) *SemesterService {
-	return &SemesterService{repo: r}
+func NewSemesterService(r SemesterRepository, tx UnitOfWork) *SemesterService {
+	return &SemesterService{repo: r, tx: tx}
 }
 
 var (
 	semIDPattern       = regexp.MustCompile(`^\d{4}[123]$`)
 	tahunAjaranPattern = regexp.MustCompile(`^\d{4}/\d{4}$`)
 	termSet            = map[string]struct{}{"Ganjil": {}, "Genap": {}, "Antara": {}}
+	errSemesterInUse   = apperror.New(ErrConflict, i18n.MsgSemesterInUse)
+	errSemesterTaken   = apperror.Field(ErrConflict, "id_semester", apperror.CodeTaken)
+
+	errInvalidSemesterID = invalidID("id_semester", codeSemesterID)
+)
+
+// Kode detail field validasi semester
+const (
+	codeSemesterID       = "pattern_yyyy_term_digit" // YYYY + 1/2/3
+	codeTahunAjaran      = "pattern_yyyy_yyyy"
+	codeConsecutiveYears = "consecutive_years"
+	codeMismatchID       = "mismatch_id_semester"
 )
 
 func parseYearFromID(id string) (int, int, error) {
@@ -55,39 +70,42 @@ func termOfDigit(d int) (string, error) {
 }
 
 func validateTahunAjaranConsistent(tahunAjaran string, id string) error {
+	if tahunAjaran == "" {
+		return apperror.Field(ErrInvalidInput, "tahun_ajaran", apperror.CodeRequired)
+	}
 	if !tahunAjaranPattern.MatchString(tahunAjaran) {
-		return ErrInvalidInput
+		return apperror.Field(ErrInvalidInput, "tahun_ajaran", codeTahunAjaran)
 	}
 	part := strings.Split(tahunAjaran, "/")
 	y1, err1 := strconv.Atoi(part[0])
 	y2, err2 := strconv.Atoi(part[1])
 	if err1 != nil || err2 != nil || y2 != y1+1 {
-		return ErrInvalidInput
+		return apperror.Field(ErrInvalidInput, "tahun_ajaran", codeConsecutiveYears)
 	}
 	if id != "" {
+		// id_semester yang tidak valid dilaporkan terpisah oleh pemanggil
 		yFromID, _, err := parseYearFromID(id)
-		if err != nil {
-			return ErrInvalidInput
-		}
-		if yFromID != y1 {
-			return ErrInvalidInput
+		if err == nil && yFromID != y1 {
+			return apperror.Field(ErrInvalidInput, "tahun_ajaran", codeMismatchID)
 		}
 	}
 	return nil
 }
 
 func validateTermConsistent(term string, id string) error {
+	if term == "" {
+		return apperror.Field(ErrInvalidInput, "term", apperror.CodeRequired)
+	}
 	if _, ok := termSet[term]; !ok {
-		return ErrInvalidInput
+		return apperror.Field(ErrInvalidInput, "term", codeInvalidChoice)
 	}
 	if id != "" {
 		_, d, err := parseYearFromID(id)
-		if err != nil {
-			return ErrInvalidInput
-		}
-		t, _ := termOfDigit(d)
-		if t != term {
-			return ErrInvalidInput
+		if err == nil {
+			t, _ := termOfDigit(d)
+			if t != term {
+				return apperror.Field(ErrInvalidInput, "term", codeMismatchID)
+			}
 		}
 	}
 	return nil
@@ -96,17 +114,39 @@ func validateTermConsistent(term string, id string) error {
 func validateDates(tglMulai, tglSelesai *time.Time) error {
 	if tglMulai != nil && tglSelesai != nil {
 		if !tglMulai.Before(*tglSelesai) {
-			return ErrInvalidInput
+			return apperror.Field(ErrInvalidInput, "tanggal_selesai", "after_tanggal_mulai")
 		}
 	}
 	return nil
 }
 
-// List with filters and pagination
-func (s *SemesterService) List(ctx context.Context, q string, tahunAjaran, term *string, limit, offset int, orderBy string) ([]model.Semester, error) {
+// validateSemester memvalidasi seluruh field semester terhadap id dan mengumpulkan semua kegagalan
+func validateSemester(id string, sem *model.Semester) error {
+	fe := apperror.Fields{}
+	if !semIDPattern.MatchString(id) {
+		fe.Add("id_semester", codeSemesterID)
+	}
+	for _, err := range []error{
+		validateTahunAjaranConsistent(sem.TahunAjaran, id),
+		validateTermConsistent(sem.Term, id),
+		validateDates(sem.TanggalMulai, sem.TanggalSelesai),
+	} {
+		for f, code := range apperror.FieldsOf(err) {
+			fe.Add(f, code)
+		}
+	}
+	return fe.Err()
+}
+
+// List with filters and pagination; also returns the total number of matching rows.
+// rawFilters memakai grammar filter repository (mis. term=in:Ganjil,Genap, tanggal_mulai=gte:2024-01-01)
+func (s *SemesterService) List(ctx context.Context, q string, rawFilters map[string][]string, limit, offset int, orderBy string) ([]model.Semester, int64, error) {
+	ctx, span := tracing.Start(ctx, "SemesterService.List")
+	defer span.End()
+
 	// sanitize
 	if limit < 0 || offset < 0 {
-		return nil, ErrInvalidInput
+		return nil, 0, ErrInvalidInput
 	}
 	// allowed order by columns
 	allowed := map[string]bool{
@@ -127,24 +167,30 @@ func (s *SemesterService) List(ctx context.Context, q string, tahunAjaran, term
 	}
 
 	// optional filter validation
-	if tahunAjaran != nil {
-		if err := validateTahunAjaranConsistent(*tahunAjaran, ""); err != nil {
-			return nil, ErrInvalidInput
-		}
-	}
-	if term != nil {
-		if err := validateTermConsistent(*term, ""); err != nil {
-			return nil, ErrInvalidInput
-		}
+	filters, err := repo.ParseFilters(rawFilters, repo.SemesterFilterFields)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return s.repo.List(ctx, strings.TrimSpace(q), tahunAjaran, term, limit, offset, key)
+	q = strings.TrimSpace(q)
+	total, err := s.repo.Count(ctx, q, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := s.repo.List(ctx, q, filters, limit, offset, key)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, total, nil
 }
 
 func (s *SemesterService) Get(ctx context.Context, id string) (*model.Semester, error) {
+	ctx, span := tracing.Start(ctx, "SemesterService.Get")
+	defer span.End()
+
 	id = strings.TrimSpace(id)
 	if !semIDPattern.MatchString(id) {
-		return nil, ErrInvalidInput
+		return nil, errInvalidSemesterID
 	}
 	out, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -154,6 +200,9 @@ func (s *SemesterService) Get(ctx context.Context, id string) (*model.Semester,
 }
 
 func (s *SemesterService) Create(ctx context.Context, sem *model.Semester) (*model.Semester, error) {
+	ctx, span := tracing.Start(ctx, "SemesterService.Create")
+	defer span.End()
+
 	if sem == nil {
 		return nil, ErrInvalidInput
 	}
@@ -161,38 +210,29 @@ func (s *SemesterService) Create(ctx context.Context, sem *model.Semester) (*mod
 	sem.TahunAjaran = strings.TrimSpace(sem.TahunAjaran)
 	sem.Term = strings.TrimSpace(sem.Term)
 
-	if !semIDPattern.MatchString(sem.IDSemester) {
-		return nil, ErrInvalidInput
-	}
-	if err := validateTahunAjaranConsistent(sem.TahunAjaran, sem.IDSemester); err != nil {
-		return nil, ErrInvalidInput
-	}
-	if err := validateTermConsistent(sem.Term, sem.IDSemester); err != nil {
-		return nil, ErrInvalidInput
-	}
-	if err := validateDates(sem.TanggalMulai, sem.TanggalSelesai); err != nil {
-		return nil, ErrInvalidInput
-	}
-
-	exists, err := s.repo.ExistsID(ctx, sem.IDSemester)
-	if err != nil {
+	if err := validateSemester(sem.IDSemester, sem); err != nil {
 		return nil, err
 	}
-	if exists {
-		return nil, ErrConflict
-	}
 
-	out, err := s.repo.Create(ctx, sem)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+	return inTx(ctx, s.tx, func(ctx context.Context) (*model.Semester, error) {
+		exists, err := s.repo.ExistsID(ctx, sem.IDSemester)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, errSemesterTaken
+		}
+		return s.repo.Create(ctx, sem)
+	})
 }
 
 // ValidateForCreate melakukan validasi lengkap yang sama dengan Create,
 // namun tidak melakukan operasi insert. Jika checkConflict=true, akan
 // melakukan pengecekan bahwa id_semester belum ada.
 func (s *SemesterService) ValidateForCreate(ctx context.Context, sem *model.Semester, checkConflict bool) error {
+	ctx, span := tracing.Start(ctx, "SemesterService.ValidateForCreate")
+	defer span.End()
+
 	if sem == nil {
 		return ErrInvalidInput
 	}
@@ -200,17 +240,8 @@ func (s *SemesterService) ValidateForCreate(ctx context.Context, sem *model.Seme
 	sem.TahunAjaran = strings.TrimSpace(sem.TahunAjaran)
 	sem.Term = strings.TrimSpace(sem.Term)
 
-	if !semIDPattern.MatchString(sem.IDSemester) {
-		return ErrInvalidInput
-	}
-	if err := validateTahunAjaranConsistent(sem.TahunAjaran, sem.IDSemester); err != nil {
-		return ErrInvalidInput
-	}
-	if err := validateTermConsistent(sem.Term, sem.IDSemester); err != nil {
-		return ErrInvalidInput
-	}
-	if err := validateDates(sem.TanggalMulai, sem.TanggalSelesai); err != nil {
-		return ErrInvalidInput
+	if err := validateSemester(sem.IDSemester, sem); err != nil {
+		return err
 	}
 	if checkConflict {
 		exists, err := s.repo.ExistsID(ctx, sem.IDSemester)
@@ -218,29 +249,29 @@ func (s *SemesterService) ValidateForCreate(ctx context.Context, sem *model.Seme
 			return err
 		}
 		if exists {
-			return ErrConflict
+			return errSemesterTaken
 		}
 	}
 	return nil
 }
 
 func (s *SemesterService) UpdatePut(ctx context.Context, id string, sem *model.Semester) (*model.Semester, error) {
+	ctx, span := tracing.Start(ctx, "SemesterService.UpdatePut")
+	defer span.End()
+
 	id = strings.TrimSpace(id)
-	if !semIDPattern.MatchString(id) || sem == nil {
+	if !semIDPattern.MatchString(id) {
+		return nil, errInvalidSemesterID
+	}
+	if sem == nil {
 		return nil, ErrInvalidInput
 	}
 
 	sem.TahunAjaran = strings.TrimSpace(sem.TahunAjaran)
 	sem.Term = strings.TrimSpace(sem.Term)
 
-	if err := validateTahunAjaranConsistent(sem.TahunAjaran, id); err != nil {
-		return nil, ErrInvalidInput
-	}
-	if err := validateTermConsistent(sem.Term, id); err != nil {
-		return nil, ErrInvalidInput
-	}
-	if err := validateDates(sem.TanggalMulai, sem.TanggalSelesai); err != nil {
-		return nil, ErrInvalidInput
+	if err := validateSemester(id, sem); err != nil {
+		return nil, err
 	}
 
 	// ensure exists
@@ -256,27 +287,34 @@ func (s *SemesterService) UpdatePut(ctx context.Context, id string, sem *model.S
 }
 
 func (s *SemesterService) UpdatePatch(ctx context.Context, id string, tahunAjaran, term *string, tglMulai, tglSelesai *time.Time) (*model.Semester, error) {
+	ctx, span := tracing.Start(ctx, "SemesterService.UpdatePatch")
+	defer span.End()
+
 	id = strings.TrimSpace(id)
 	if !semIDPattern.MatchString(id) {
-		return nil, ErrInvalidInput
+		return nil, errInvalidSemesterID
 	}
 
+	fe := apperror.Fields{}
+	var errs []error
 	if tahunAjaran != nil {
 		v := strings.TrimSpace(*tahunAjaran)
-		if err := validateTahunAjaranConsistent(v, id); err != nil {
-			return nil, ErrInvalidInput
-		}
+		errs = append(errs, validateTahunAjaranConsistent(v, id))
 		*tahunAjaran = v
 	}
 	if term != nil {
 		v := strings.TrimSpace(*term)
-		if err := validateTermConsistent(v, id); err != nil {
-			return nil, ErrInvalidInput
-		}
+		errs = append(errs, validateTermConsistent(v, id))
 		*term = v
 	}
-	if err := validateDates(tglMulai, tglSelesai); err != nil {
-		return nil, ErrInvalidInput
+	errs = append(errs, validateDates(tglMulai, tglSelesai))
+	for _, err := range errs {
+		for f, code := range apperror.FieldsOf(err) {
+			fe.Add(f, code)
+		}
+	}
+	if err := fe.Err(); err != nil {
+		return nil, err
 	}
 
 	// ensure exists
@@ -292,37 +330,37 @@ func (s *SemesterService) UpdatePatch(ctx context.Context, id string, tahunAjara
 }
 
 func (s *SemesterService) Delete(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "SemesterService.Delete")
+	defer span.End()
+
 	id = strings.TrimSpace(id)
 	if !semIDPattern.MatchString(id) {
-		return ErrInvalidInput
+		return errInvalidSemesterID
 	}
 
-	// ensure exists
-	if _, err := s.repo.GetByID(ctx, id); err != nil {
-		return err
-	}
-
-	// check relations
-	hasKelas, err := s.repo.HasKelasRelated(ctx, id)
-	if err != nil {
-		return err
-	}
-	if hasKelas {
-		return ErrConflict
-	}
-	hasKRS, err := s.repo.HasKRSRelated(ctx, id)
-	if err != nil {
-		return err
-	}
-	if hasKRS {
-		return ErrConflict
-	}
+	return s.tx.Do(ctx, func(ctx context.Context) error {
+		// ensure exists; baris dikunci sampai commit sehingga insert kelas/krs yang merujuknya menunggu
+		if err := s.repo.LockByID(ctx, id); err != nil {
+			return err
+		}
 
-	if err := s.repo.Delete(ctx, id); err != nil {
-		if errors.Is(err, ErrConflict) {
-			return ErrConflict
+		// check relations
+		hasKelas, err := s.repo.HasKelasRelated(ctx, id)
+		if err != nil {
+			return err
 		}
-		return err
-	}
-	return nil
+		if hasKelas {
+			return errSemesterInUse
+		}
+		hasKRS, err := s.repo.HasKRSRelated(ctx, id)
+		if err != nil {
+			return err
+		}
+		if hasKRS {
+			return errSemesterInUse
+		}
+
+		// FK RESTRICT tetap menjadi pengaman terakhir dan dipetakan oleh apperror.FromDB
+		return s.repo.Delete(ctx, id)
+	})
 }
@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	model "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	"pencatatan-data-mahasiswa/internal/todo/repository/memory"
+)
+
+func TestValidateSemester(t *testing.T) {
+	date := func(s string) *time.Time {
+		v, _ := time.Parse("2006-01-02", s)
+		return &v
+	}
+	tests := []struct {
+		name string
+		id   string
+		sem  model.Semester
+		want map[string]string // kosong berarti valid
+	}{
+		{name: "ganjil", id: "20241", sem: model.Semester{TahunAjaran: "2024/2025", Term: "Ganjil"}},
+		{name: "genap", id: "20242", sem: model.Semester{TahunAjaran: "2024/2025", Term: "Genap"}},
+		{name: "antara with dates", id: "20243", sem: model.Semester{TahunAjaran: "2024/2025", Term: "Antara", TanggalMulai: date("2025-07-01"), TanggalSelesai: date("2025-08-15")}},
+		{name: "invalid id", id: "2024", sem: model.Semester{TahunAjaran: "2024/2025", Term: "Ganjil"},
+			want: map[string]string{"id_semester": codeSemesterID}},
+		{name: "invalid term digit", id: "20244", sem: model.Semester{TahunAjaran: "2024/2025", Term: "Ganjil"},
+			want: map[string]string{"id_semester": codeSemesterID}},
+		{name: "term mismatch id", id: "20241", sem: model.Semester{TahunAjaran: "2024/2025", Term: "Genap"},
+			want: map[string]string{"term": codeMismatchID}},
+		{name: "tahun ajaran mismatch id", id: "20241", sem: model.Semester{TahunAjaran: "2023/2024", Term: "Ganjil"},
+			want: map[string]string{"tahun_ajaran": codeMismatchID}},
+		{name: "tahun ajaran not consecutive", id: "20241", sem: model.Semester{TahunAjaran: "2024/2026", Term: "Ganjil"},
+			want: map[string]string{"tahun_ajaran": codeConsecutiveYears}},
+		{name: "tahun ajaran bad format", id: "20241", sem: model.Semester{TahunAjaran: "2024-2025", Term: "Ganjil"},
+			want: map[string]string{"tahun_ajaran": codeTahunAjaran}},
+		{name: "unknown term", id: "20241", sem: model.Semester{TahunAjaran: "2024/2025", Term: "Pendek"},
+			want: map[string]string{"term": codeInvalidChoice}},
+		{name: "required fields", id: "20241",
+			want: map[string]string{"tahun_ajaran": apperror.CodeRequired, "term": apperror.CodeRequired}},
+		{name: "dates reversed", id: "20241", sem: model.Semester{TahunAjaran: "2024/2025", Term: "Ganjil", TanggalMulai: date("2025-01-31"), TanggalSelesai: date("2024-09-01")},
+			want: map[string]string{"tanggal_selesai": "after_tanggal_mulai"}},
+		{name: "invalid id reported once", id: "ABCDE", sem: model.Semester{TahunAjaran: "2024/2025", Term: "Genap"},
+			want: map[string]string{"id_semester": codeSemesterID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSemester(tt.id, &tt.sem)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, apperror.ErrInvalidInput) {
+				t.Fatalf("error = %v, want invalid input", err)
+			}
+			if got := apperror.FieldsOf(err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSemesterUpdatePatchConsistency: PATCH hanya memvalidasi field yang dikirim, tetap terhadap id di URL
+func TestSemesterUpdatePatchConsistency(t *testing.T) {
+	tests := []struct {
+		name        string
+		tahunAjaran *string
+		term        *string
+		wantKind    error
+		wantFields  map[string]string
+	}{
+		{name: "no fields", wantKind: nil},
+		{name: "matching term", term: strPtr(" Ganjil ")},
+		{name: "term of other digit", term: strPtr("Antara"), wantKind: apperror.ErrInvalidInput, wantFields: map[string]string{"term": codeMismatchID}},
+		{name: "tahun ajaran of other year", tahunAjaran: strPtr("2025/2026"), wantKind: apperror.ErrInvalidInput, wantFields: map[string]string{"tahun_ajaran": codeMismatchID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := seedStore(t)
+			svc := NewSemesterService(memory.NewSemesterRepository(s), s)
+			out, err := svc.UpdatePatch(context.Background(), fixSemester, tt.tahunAjaran, tt.term, nil, nil)
+			if tt.wantKind == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if out.Term != "Ganjil" || out.TahunAjaran != "2024/2025" {
+					t.Errorf("semester = %+v", out)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("error = %v, want kind %v", err, tt.wantKind)
+			}
+			if got := apperror.FieldsOf(err); !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
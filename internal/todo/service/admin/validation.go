@@ -0,0 +1,63 @@
+package admin
+
+import (
+    "fmt"
+    "sort"
+
+    "pencatatan-data-mahasiswa/internal/apperror"
+)
+
+// Kode detail field yang dipakai bersama validator service admin. Kode lain yang spesifik
+// per field (mis. pattern_16_digits) ditulis langsung di validator masing-masing.
+const (
+    codeInvalidChoice = "invalid_choice"
+    codeInvalidEmail  = "invalid_email"
+    codeInvalidPhone  = "invalid_phone"
+    codeAlnum8        = "pattern_8_alnum" // id_fakultas, id_prodi
+)
+
+// codeLength menghasilkan kode panjang teks, mis. length_3_120
+func codeLength(min, max int) string {
+    return fmt.Sprintf("length_%d_%d", min, max)
+}
+
+// codeMax menghasilkan kode panjang maksimum, mis. max_80
+func codeMax(max int) string {
+    return fmt.Sprintf("max_%d", max)
+}
+
+// checkLength mencatat required/length_min_max untuk field teks wajib
+func checkLength(fe apperror.Fields, field, v string, min, max int) {
+    if v == "" {
+        fe.Add(field, apperror.CodeRequired)
+    } else if len(v) < min || len(v) > max {
+        fe.Add(field, codeLength(min, max))
+    }
+}
+
+// invalidID membuat error 400 untuk parameter id yang formatnya salah
+func invalidID(field, code string) error {
+    return apperror.Field(ErrInvalidInput, field, code)
+}
+
+// Choices mengembalikan nilai yang diizinkan untuk field pilihan (nama field JSON -> nilai),
+// dipakai dokumen OpenAPI agar enum selalu sama dengan validasi
+func Choices() map[string][]string {
+    return map[string][]string{
+        "jenis_kelamin": sortedKeys(jkSet),
+        "status":        sortedKeys(statusSet),
+        "jenjang":       sortedKeys(jenjangSet),
+        "akreditasi":    sortedKeys(akreditasiSet),
+        "term":          sortedKeys(termSet),
+        "match":         sortedKeys(matchSet),
+    }
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+    out := make([]string, 0, len(set))
+    for k := range set {
+        out = append(out, k)
+    }
+    sort.Strings(out)
+    return out
+}
@@ -0,0 +1,252 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	model "pencatatan-data-mahasiswa/internal/todo/model/auth"
+	"pencatatan-data-mahasiswa/internal/tracing"
+)
+
+// AccountMode menentukan kredensial awal akun yang dibuat Provision
+type AccountMode string
+
+const (
+	// AccountPassword: password awal acak yang wajib diganti setelah login pertama
+	AccountPassword AccountMode = "password"
+	// AccountActivation: tanpa password; user memilih password sendiri lewat token aktivasi
+	AccountActivation AccountMode = "activation"
+)
+
+// AccountModes mengembalikan mode yang valid, dipakai dokumentasi API
+func AccountModes() []string {
+	return []string{string(AccountActivation), string(AccountPassword)}
+}
+
+// ParseAccountMode membaca nilai create_account; string kosong berarti akun tidak dibuat
+func ParseAccountMode(raw string) (AccountMode, error) {
+	switch m := AccountMode(strings.ToLower(strings.TrimSpace(raw))); m {
+	case "", AccountPassword, AccountActivation:
+		return m, nil
+	}
+	return "", apperror.Field(apperror.ErrInvalidInput, "create_account", "invalid_choice")
+}
+
+// Account adalah akun login hasil Provision. Password awal dan token aktivasi hanya muncul di sini;
+// database hanya menyimpan hash-nya, jadi keduanya tidak bisa diambil ulang.
+type Account struct {
+	IDUser              int64       `json:"id_user"`
+	Username            string      `json:"username"`
+	Role                string      `json:"role"`
+	Mode                AccountMode `json:"mode"`
+	InitialPassword     string      `json:"initial_password,omitempty"`
+	ActivationToken     string      `json:"activation_token,omitempty"`
+	ActivationURL       string      `json:"activation_url,omitempty"`
+	ActivationExpiresAt *time.Time  `json:"activation_expires_at,omitempty"`
+}
+
+// Kode detail field password
+const (
+	codePasswordMin  = "min_8"
+	codePasswordMax  = "max_72" // batas input bcrypt
+	codePasswordSame = "same_as_old"
+	codeMismatch     = "mismatch"
+	codeExpired      = "expired"
+)
+
+var (
+	ErrInvalidActivation = &apperror.Error{
+		Kind:   apperror.ErrInvalidInput,
+		Code:   i18n.MsgActivationTokenInvalid,
+		Fields: map[string]string{"token": apperror.CodeInvalid},
+	}
+	errActivationExpired = &apperror.Error{
+		Kind:   apperror.ErrInvalidInput,
+		Code:   i18n.MsgActivationTokenInvalid,
+		Fields: map[string]string{"token": codeExpired},
+	}
+	errWrongPassword = apperror.Field(apperror.ErrInvalidInput, "old_password", codeMismatch)
+)
+
+// validatePassword mengembalikan kode detail field, atau "" bila password dapat dipakai
+func validatePassword(p string) string {
+	switch {
+	case len(p) < 8:
+		return codePasswordMin
+	case len(p) > 72:
+		return codePasswordMax
+	}
+	return ""
+}
+
+// passwordAlphabet tanpa karakter yang mudah tertukar saat dibaca (0/O, 1/l/I)
+const passwordAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789"
+
+func generatePassword() (string, error) {
+	b := make([]byte, 12)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passwordAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		b[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// newActivationToken mengembalikan token acak untuk user dan hash sha256 yang disimpan
+func newActivationToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Provision membuat akun login untuk mahasiswa atau dosen dengan username = refID (NIM atau id_dosen).
+// Dijalankan di dalam transaksi pemanggil bila ada, sehingga pembuatan data master dan akunnya
+// berhasil atau gagal bersama.
+func (s *Service) Provision(ctx context.Context, role, refID string, mode AccountMode) (*Account, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Provision")
+	defer span.End()
+
+	refID = strings.TrimSpace(refID)
+	if (role != "mahasiswa" && role != "dosen") || refID == "" || len(refID) > 20 {
+		return nil, ErrInvalidInput
+	}
+	u := &model.User{Username: refID, Role: role, RefID: &refID, MustChangePassword: true}
+	acc := &Account{Username: refID, Role: role, Mode: mode}
+	switch mode {
+	case AccountPassword:
+		pw, err := generatePassword()
+		if err != nil {
+			return nil, err
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(pw), s.opt.BcryptCost)
+		if err != nil {
+			return nil, err
+		}
+		u.PasswordHash = string(hash)
+		acc.InitialPassword = pw
+	case AccountActivation:
+		token, hash, err := newActivationToken()
+		if err != nil {
+			return nil, err
+		}
+		exp := time.Now().UTC().Add(s.opt.ActivationTTL).Truncate(time.Second)
+		u.ActivationTokenHash, u.ActivationExpiresAt = &hash, &exp
+		acc.ActivationToken, acc.ActivationExpiresAt = token, &exp
+		if s.opt.ActivationURL != "" {
+			acc.ActivationURL = strings.ReplaceAll(s.opt.ActivationURL, "{token}", token)
+		}
+	default:
+		return nil, apperror.Field(apperror.ErrInvalidInput, "create_account", "invalid_choice")
+	}
+
+	err := s.tx.Do(ctx, func(ctx context.Context) error {
+		created, err := s.create(ctx, u)
+		if err != nil {
+			return err
+		}
+		acc.IDUser = created.IDUser
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
+
+// Activate memasang password pilihan user pada akun mode aktivasi; token hanya berlaku sekali
+func (s *Service) Activate(ctx context.Context, token, password string) error {
+	ctx, span := tracing.Start(ctx, "AuthService.Activate")
+	defer span.End()
+
+	fe := apperror.Fields{}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		fe.Add("token", apperror.CodeRequired)
+	}
+	if code := validatePassword(password); code != "" {
+		fe.Add("password", code)
+	}
+	if err := fe.Err(); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.opt.BcryptCost)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.Do(ctx, func(ctx context.Context) error {
+		u, err := s.repo.GetByActivationHash(ctx, hashToken(token))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvalidActivation
+		}
+		if err != nil {
+			return err
+		}
+		if u.ActivationExpiresAt == nil || !time.Now().Before(*u.ActivationExpiresAt) {
+			return errActivationExpired
+		}
+		return s.repo.SetPassword(ctx, u.IDUser, string(hash), false)
+	})
+}
+
+// ChangePassword mengganti password user yang sedang login dan menghapus kewajiban ganti password.
+// Token baru dikembalikan karena token lama masih membawa claim pwd_change.
+func (s *Service) ChangePassword(ctx context.Context, userID int64, oldPassword, newPassword string) (token string, expiresIn int64, user *model.User, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ChangePassword")
+	defer span.End()
+
+	if code := validatePassword(newPassword); code != "" {
+		return "", 0, nil, apperror.Field(apperror.ErrInvalidInput, "new_password", code)
+	}
+	if newPassword == oldPassword {
+		return "", 0, nil, apperror.Field(apperror.ErrInvalidInput, "new_password", codePasswordSame)
+	}
+
+	u, err := s.repo.GetByID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", 0, nil, ErrInvalidCredential
+	}
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(oldPassword)) != nil {
+		return "", 0, nil, errWrongPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), s.opt.BcryptCost)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if err := s.repo.SetPassword(ctx, u.IDUser, string(hash), false); err != nil {
+		return "", 0, nil, err
+	}
+
+	u.MustChangePassword = false
+	token, expiresIn, err = s.issueToken(u)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	u.PasswordHash = ""
+	return token, expiresIn, u, nil
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	adminmodel "pencatatan-data-mahasiswa/internal/todo/model/admin"
+	"pencatatan-data-mahasiswa/internal/todo/repository/memory"
+)
+
+const fixDosen = "DSN0000001"
+
+func newAccountService(t *testing.T, opt Options) *Service {
+	t.Helper()
+	s := memory.NewStore()
+	if _, err := memory.NewDosenRepository(s).Create(context.Background(), &adminmodel.Dosen{IDDosen: fixDosen, NamaDosen: "Dr. Budi"}); err != nil {
+		t.Fatal(err)
+	}
+	opt.JWTSecret, opt.TokenTTL, opt.BcryptCost = "secret", time.Hour, bcrypt.MinCost
+	return NewService(memory.NewUserRepository(s), s, opt)
+}
+
+// pendingChange membaca claim pwd_change dari token
+func pendingChange(t *testing.T, token string) bool {
+	t.Helper()
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) { return []byte("secret"), nil }); err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	v, _ := claims[ClaimPasswordChange].(bool)
+	return v
+}
+
+func TestProvisionPasswordForcesChange(t *testing.T) {
+	ctx := context.Background()
+	svc := newAccountService(t, Options{})
+
+	if _, err := svc.Provision(ctx, "mahasiswa", "202400000099", AccountPassword); !errors.Is(err, apperror.ErrInvalidInput) {
+		t.Errorf("provision for unknown ref: error = %v, want invalid input", err)
+	}
+	acc, err := svc.Provision(ctx, "dosen", fixDosen, AccountPassword)
+	if err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+	if acc.Username != fixDosen || len(acc.InitialPassword) != 12 || acc.ActivationToken != "" {
+		t.Fatalf("account = %+v", acc)
+	}
+	if _, err := svc.Provision(ctx, "dosen", fixDosen, AccountPassword); !errors.Is(err, apperror.ErrConflict) {
+		t.Errorf("second provision: error = %v, want conflict", err)
+	}
+
+	token, _, u, err := svc.Login(ctx, fixDosen, acc.InitialPassword)
+	if err != nil || !u.MustChangePassword || !pendingChange(t, token) {
+		t.Fatalf("first login = (%+v, %v), want must_change_password and pwd_change claim", u, err)
+	}
+
+	if _, _, _, err := svc.ChangePassword(ctx, u.IDUser, "salah-password", "passwordbaru1"); !errors.Is(err, apperror.ErrInvalidInput) {
+		t.Errorf("wrong old password: error = %v, want invalid input", err)
+	}
+	if _, _, _, err := svc.ChangePassword(ctx, u.IDUser, acc.InitialPassword, acc.InitialPassword); !errors.Is(err, apperror.ErrInvalidInput) {
+		t.Errorf("same password: error = %v, want invalid input", err)
+	}
+	token, _, u, err = svc.ChangePassword(ctx, u.IDUser, acc.InitialPassword, "passwordbaru1")
+	if err != nil || u.MustChangePassword || pendingChange(t, token) {
+		t.Fatalf("change password = (%+v, %v), want a token without pwd_change", u, err)
+	}
+	if _, _, _, err := svc.Login(ctx, fixDosen, "passwordbaru1"); err != nil {
+		t.Errorf("login with new password: %v", err)
+	}
+}
+
+func TestProvisionActivation(t *testing.T) {
+	ctx := context.Background()
+	svc := newAccountService(t, Options{ActivationTTL: time.Hour, ActivationURL: "https://siakad.example/aktivasi?token={token}"})
+
+	acc, err := svc.Provision(ctx, "dosen", fixDosen, AccountActivation)
+	if err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+	if acc.ActivationToken == "" || acc.InitialPassword != "" || acc.ActivationExpiresAt == nil ||
+		!strings.HasSuffix(acc.ActivationURL, "token="+acc.ActivationToken) {
+		t.Fatalf("account = %+v", acc)
+	}
+	if _, _, _, err := svc.Login(ctx, fixDosen, ""); !errors.Is(err, apperror.ErrUnauthorized) {
+		t.Errorf("login before activation: error = %v, want unauthorized", err)
+	}
+
+	if err := svc.Activate(ctx, "token-salah", "passwordbaru1"); !errors.Is(err, ErrInvalidActivation) {
+		t.Errorf("unknown token: error = %v, want ErrInvalidActivation", err)
+	}
+	if err := svc.Activate(ctx, acc.ActivationToken, "pendek"); apperror.FieldsOf(err)["password"] != codePasswordMin {
+		t.Errorf("short password: error = %v, want password %s", err, codePasswordMin)
+	}
+	if err := svc.Activate(ctx, acc.ActivationToken, "passwordbaru1"); err != nil {
+		t.Fatalf("Activate: %v", err)
+	}
+	if err := svc.Activate(ctx, acc.ActivationToken, "passwordbaru2"); !errors.Is(err, ErrInvalidActivation) {
+		t.Errorf("token reused: error = %v, want ErrInvalidActivation", err)
+	}
+	token, _, u, err := svc.Login(ctx, fixDosen, "passwordbaru1")
+	if err != nil || u.MustChangePassword || pendingChange(t, token) {
+		t.Errorf("login after activation = (%+v, %v), want no pending change", u, err)
+	}
+}
+
+func TestActivationExpired(t *testing.T) {
+	ctx := context.Background()
+	svc := newAccountService(t, Options{ActivationTTL: -time.Minute})
+	acc, err := svc.Provision(ctx, "dosen", fixDosen, AccountActivation)
+	if err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+	if err := svc.Activate(ctx, acc.ActivationToken, "passwordbaru1"); apperror.FieldsOf(err)["token"] != codeExpired {
+		t.Errorf("expired token: error = %v, want token %s", err, codeExpired)
+	}
+}
@@ -3,31 +3,50 @@ package auth
 import (
 	"context"
 	"errors"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/i18n"
+	"pencatatan-data-mahasiswa/internal/metrics"
 	model "pencatatan-data-mahasiswa/internal/todo/model/auth"
-	repo "pencatatan-data-mahasiswa/internal/todo/repository/auth"
+	"pencatatan-data-mahasiswa/internal/tracing"
 
-	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5"
 )
 
 type Service struct {
-	repo      *repo.Repository
-	jwtSecret string
+	repo Repository
+	tx   UnitOfWork
+	opt  Options
 }
 
-func NewService(r *repo.Repository, jwtSecret string) *Service {
-	return &Service{repo: r, jwtSecret: jwtSecret}
+// Options adalah pengaturan Service, diisi dari config.Config oleh handler
+type Options struct {
+	JWTSecret     string
+	TokenTTL      time.Duration
+	BcryptCost    int
+	ActivationTTL time.Duration
+	// ActivationURL berisi placeholder {token}; kosong berarti tidak ada link aktivasi
+	ActivationURL string
+}
+
+func NewService(r Repository, tx UnitOfWork, opt Options) *Service {
+	return &Service{repo: r, tx: tx, opt: opt}
 }
 
 var (
-	errInvalidCredential = errors.New("invalid username or password")
-	ErrInvalidInput      = errors.New("invalid input")
-	ErrUsernameTaken     = errors.New("username already taken")
+	ErrInvalidCredential = apperror.New(apperror.ErrUnauthorized, i18n.MsgInvalidCredentials)
+	ErrInvalidInput      = apperror.ErrInvalidInput
+	ErrUsernameTaken     = &apperror.Error{
+		Kind:   apperror.ErrConflict,
+		Code:   i18n.MsgUsernameTaken,
+		Fields: map[string]string{"username": apperror.CodeTaken},
+	}
 )
 
 var allowedRoles = map[string]struct{}{
@@ -37,10 +56,23 @@ var allowedRoles = map[string]struct{}{
 	"operator":  {},
 }
 
+// Roles mengembalikan daftar role yang valid (terurut), dipakai dokumentasi API
+func Roles() []string {
+	out := make([]string, 0, len(allowedRoles))
+	for r := range allowedRoles {
+		out = append(out, r)
+	}
+	sort.Strings(out)
+	return out
+}
+
 // Register membuat user baru setelah validasi
 func (s *Service) Register(ctx context.Context, username, password, role string, refID *string) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Register")
+	defer span.End()
+
 	username = strings.TrimSpace(username)
-	if username == "" || len(password) < 8 {
+	if username == "" || validatePassword(password) != "" {
 		return nil, ErrInvalidInput
 	}
 	if len(username) > 50 {
@@ -59,72 +91,109 @@ func (s *Service) Register(ctx context.Context, username, password, role string,
 			return nil, ErrInvalidInput
 		}
 	}
+	// admin dan operator opsional (operator: id_prodi yang dikelola); mahasiswa/dosen wajib
+	if (role == "mahasiswa" || role == "dosen") && refID == nil {
+		return nil, ErrInvalidInput
+	}
+
+	// hash password di luar transaksi: bcrypt sengaja lambat dan tidak perlu memegang koneksi
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.opt.BcryptCost)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &model.User{Username: username, PasswordHash: string(hash), Role: role, RefID: refID}
+	var created *model.User
+	err = s.tx.Do(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = s.create(ctx, u)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// create menyimpan u setelah memastikan username belum dipakai dan ref_id merujuk baris yang ada.
+// Harus dipanggil di dalam s.tx.Do.
+func (s *Service) create(ctx context.Context, u *model.User) (*model.User, error) {
 	// cek username unik
-	exists, err := s.repo.UsernameExists(ctx, username)
+	exists, err := s.repo.UsernameExists(ctx, u.Username)
 	if err != nil {
 		return nil, err
 	}
 	if exists {
 		return nil, ErrUsernameTaken
 	}
-	// validasi ref_id wajib dan harus valid bila role mahasiswa/dosen
-	if role == "mahasiswa" || role == "dosen" {
-		if refID == nil || *refID == "" {
-			return nil, ErrInvalidInput
-		}
-		if role == "mahasiswa" {
-			ok, err1 := s.repo.ExistsMahasiswaByID(ctx, *refID)
-			if err1 != nil {
-				return nil, err
-			}
-			if !ok {
-				return nil, ErrInvalidInput
-			}
-		}
-		if role == "dosen" {
-			ok, err2 := s.repo.ExistsDosenByID(ctx, *refID)
-			if err2 != nil {
-				return nil, err
-			}
-			if !ok {
-				return nil, ErrInvalidInput
-			}
+	// ref_id harus merujuk baris yang ada; baris dikunci sampai user tersimpan (users.ref_id tanpa FK)
+	var ok bool
+	switch u.Role {
+	case "mahasiswa":
+		ok, err = s.repo.ExistsMahasiswaByID(ctx, *u.RefID)
+	case "dosen":
+		ok, err = s.repo.ExistsDosenByID(ctx, *u.RefID)
+	case "operator":
+		ok = true
+		if u.RefID != nil {
+			ok, err = s.repo.ExistsProdiByID(ctx, *u.RefID)
 		}
-	} else if role == "operator" && refID != nil && *refID != "" { // operator tidak boleh punya ref_id; admin boleh opsional
-		return nil, ErrInvalidInput
+	default:
+		ok = true
 	}
-
-	// hash password
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
 	}
-	u := &model.User{Username: username, PasswordHash: string(hash), Role: role, RefID: refID}
+	if !ok {
+		return nil, ErrInvalidInput
+	}
+
 	created, err := s.repo.Create(ctx, u)
-	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			if pgErr.Code == "23505" { // unique_violation
-				return nil, ErrUsernameTaken
-			}
-		}
-		return nil, err
+	if err != nil && errors.Is(apperror.FromDB(err), apperror.ErrConflict) { // unique_violation
+		return nil, ErrUsernameTaken
 	}
-	return created, nil
+	return created, err
 }
 
 // Login memvalidasi kredensial, dan mengembalikan token JWT HS256 dan masa berlaku
 func (s *Service) Login(ctx context.Context, username, password string) (token string, expiresIn int64, user *model.User, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Login")
+	defer span.End()
+
 	u, err := s.repo.GetByUsername(ctx, username)
 	if err != nil {
-		return "", 0, nil, errInvalidCredential
+		// hanya user yang tidak ditemukan yang dianggap kredensial salah; error DB lain tetap 500
+		if errors.Is(err, pgx.ErrNoRows) {
+			metrics.Login(false)
+			return "", 0, nil, ErrInvalidCredential
+		}
+		return "", 0, nil, err
 	}
+	// akun mode aktivasi yang belum diaktifkan punya password_hash kosong sehingga selalu gagal di sini
 	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
-		return "", 0, nil, errInvalidCredential
+		metrics.Login(false)
+		return "", 0, nil, ErrInvalidCredential
 	}
 
-	// generate JWT 24 jam
-	expiresIn = 24 * 60 * 60
+	signed, expiresIn, err := s.issueToken(u)
+	if err != nil {
+		return "", 0, nil, err
+	}
+
+	// nolkan hash sebelum dikembalikan
+	u.PasswordHash = ""
+	u.ActivationTokenHash = nil
+	metrics.Login(true)
+	return signed, expiresIn, u, nil
+}
+
+// ClaimPasswordChange adalah claim JWT untuk user yang masih wajib mengganti password awal;
+// RequireAuth menolak token ini kecuali pada route ganti password
+const ClaimPasswordChange = "pwd_change"
+
+// issueToken membuat JWT HS256 dengan masa berlaku sesuai konfigurasi (default 24 jam)
+func (s *Service) issueToken(u *model.User) (string, int64, error) {
+	expiresIn := int64(s.opt.TokenTTL / time.Second)
 	claims := jwt.MapClaims{
 		"user_id":  u.IDUser,
 		"username": u.Username,
@@ -133,13 +202,13 @@ func (s *Service) Login(ctx context.Context, username, password string) (token s
 		"iat":      time.Now().Unix(),
 		"exp":      time.Now().Add(time.Second * time.Duration(expiresIn)).Unix(),
 	}
+	if u.MustChangePassword {
+		claims[ClaimPasswordChange] = true
+	}
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := t.SignedString([]byte(s.jwtSecret))
+	signed, err := t.SignedString([]byte(s.opt.JWTSecret))
 	if err != nil {
-		return "", 0, nil, err
+		return "", 0, err
 	}
-
-	// nolkan hash sebelum dikembalikan
-	u.PasswordHash = ""
-	return signed, expiresIn, u, nil
+	return signed, expiresIn, nil
 }
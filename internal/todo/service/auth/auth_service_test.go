@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"pencatatan-data-mahasiswa/internal/apperror"
+	"pencatatan-data-mahasiswa/internal/todo/repository/memory"
+)
+
+var _ Repository = (*memory.UserRepository)(nil)
+
+func TestRegisterAndLogin(t *testing.T) {
+	ctx := context.Background()
+	s := memory.NewStore()
+	svc := NewService(memory.NewUserRepository(s), s, Options{JWTSecret: "secret", TokenTTL: time.Hour, BcryptCost: bcrypt.MinCost})
+	ref := "202400000001"
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		role     string
+		refID    *string
+		wantKind error
+	}{
+		{name: "admin", username: "admin", password: "rahasia123", role: "admin"},
+		{name: "duplicate username", username: "admin", password: "rahasia123", role: "admin", wantKind: apperror.ErrConflict},
+		{name: "short password", username: "op", password: "pendek", role: "operator", wantKind: apperror.ErrInvalidInput},
+		{name: "unknown role", username: "x", password: "rahasia123", role: "root", wantKind: apperror.ErrInvalidInput},
+		{name: "mahasiswa ref not found", username: "mhs", password: "rahasia123", role: "mahasiswa", refID: &ref, wantKind: apperror.ErrInvalidInput},
+		{name: "operator ref not a prodi", username: "op", password: "rahasia123", role: "operator", refID: &ref, wantKind: apperror.ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Register(ctx, tt.username, tt.password, tt.role, tt.refID)
+			if tt.wantKind == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantKind != nil && !errors.Is(err, tt.wantKind) {
+				t.Fatalf("error = %v, want kind %v", err, tt.wantKind)
+			}
+		})
+	}
+
+	if _, _, _, err := svc.Login(ctx, "admin", "salah-password"); !errors.Is(err, apperror.ErrUnauthorized) {
+		t.Errorf("login wrong password: error = %v, want unauthorized", err)
+	}
+	if _, _, _, err := svc.Login(ctx, "tidak-ada", "rahasia123"); !errors.Is(err, apperror.ErrUnauthorized) {
+		t.Errorf("login unknown user: error = %v, want unauthorized", err)
+	}
+	token, exp, u, err := svc.Login(ctx, "admin", "rahasia123")
+	if err != nil || token == "" || exp != 3600 || u.PasswordHash != "" {
+		t.Errorf("login = (%q, %d, %+v, %v)", token, exp, u, err)
+	}
+}
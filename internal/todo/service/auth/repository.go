@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"context"
+
+	"pencatatan-data-mahasiswa/internal/db"
+	model "pencatatan-data-mahasiswa/internal/todo/model/auth"
+	repo "pencatatan-data-mahasiswa/internal/todo/repository/auth"
+)
+
+// Repository adalah kebutuhan Service terhadap penyimpanan user. Implementasi PostgreSQL ada di
+// internal/todo/repository/auth, implementasi in-memory untuk unit test di internal/todo/repository/memory.
+type Repository interface {
+	GetByUsername(ctx context.Context, username string) (*model.User, error)
+	GetByID(ctx context.Context, id int64) (*model.User, error)
+	GetByActivationHash(ctx context.Context, hash string) (*model.User, error)
+	UsernameExists(ctx context.Context, username string) (bool, error)
+	ExistsMahasiswaByID(ctx context.Context, id string) (bool, error)
+	ExistsDosenByID(ctx context.Context, id string) (bool, error)
+	ExistsProdiByID(ctx context.Context, id string) (bool, error)
+	Create(ctx context.Context, u *model.User) (*model.User, error)
+	SetPassword(ctx context.Context, id int64, hash string, mustChange bool) error
+}
+
+// UnitOfWork menjalankan fn dalam satu transaksi (db.TxManager); lihat service/admin.UnitOfWork
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+var (
+	_ Repository = (*repo.Repository)(nil)
+	_ UnitOfWork = (*db.TxManager)(nil)
+)
@@ -0,0 +1,66 @@
+// Package tracing menyiapkan OpenTelemetry: tracer provider dengan exporter OTLP/stdout/none,
+// propagasi W3C trace context, serta helper span untuk method service.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName adalah nama instrumentation scope untuk span yang dibuat aplikasi ini
+const ScopeName = "pencatatan-data-mahasiswa"
+
+// Exporter yang didukung (OTEL_TRACES_EXPORTER)
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Setup memasang tracer provider global sesuai exporter dan propagator W3C (traceparent + baggage).
+// Endpoint, header dan sampler OTLP dibaca SDK dari env standar OTEL_EXPORTER_OTLP_* dan OTEL_TRACES_SAMPLER.
+// Fungsi shutdown yang dikembalikan mem-flush span yang tersisa.
+func Setup(ctx context.Context, exporter, serviceName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exp sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case "", ExporterNone:
+		// tanpa exporter: tracer global tetap no-op, namun trace context tetap diteruskan
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		exp, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q (use none, stdout or otlp)", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s trace exporter: %w", exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", serviceName)))
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exp), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// Start membuka span anak untuk method service, mis. Start(ctx, "MahasiswaService.List").
+// Pemanggil wajib menutup span dengan defer span.End().
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(ScopeName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
@@ -0,0 +1,7 @@
+-- Rollback migration: Drop name search indexes, function and extensions
+
+DROP INDEX IF EXISTS idx_dosen_nama_trgm;
+DROP INDEX IF EXISTS idx_mahasiswa_nama_trgm;
+DROP FUNCTION IF EXISTS immutable_unaccent(text);
+DROP EXTENSION IF EXISTS unaccent;
+DROP EXTENSION IF EXISTS pg_trgm;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- Migration: Enable fuzzy name search for mahasiswa and dosen
+-- NOTE: pg_trgm untuk similarity/word_similarity, unaccent untuk mengabaikan diakritik
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE EXTENSION IF NOT EXISTS unaccent SCHEMA public;
+
+-- unaccent() tidak IMMUTABLE sehingga tidak bisa dipakai di index; bungkus dengan dictionary eksplisit.
+-- Fungsi dan dictionary ditulis lengkap dengan schema dan search_path dikunci, karena index juga
+-- dievaluasi dengan search_path kosong (pg_restore, autovacuum/analyze).
+CREATE OR REPLACE FUNCTION immutable_unaccent(text)
+RETURNS text AS $$
+  SELECT public.unaccent('public.unaccent'::regdictionary, $1);
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+SET search_path = pg_catalog, public;
+
+-- Trigram index untuk pencarian fuzzy dan prefix pada nama
+CREATE INDEX IF NOT EXISTS idx_mahasiswa_nama_trgm ON mahasiswa USING gin (immutable_unaccent(nama_lengkap) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_dosen_nama_trgm ON dosen USING gin (immutable_unaccent(nama_dosen) gin_trgm_ops);
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- Rollback migration: Drop rate limit buckets
+
+DROP TABLE IF EXISTS rate_limit_buckets;
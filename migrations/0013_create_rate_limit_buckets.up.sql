@@ -0,0 +1,11 @@
+-- Migration: Token bucket bersama untuk rate limiting lintas instance
+-- NOTE: UNLOGGED karena isinya sementara; kehilangan data saat crash hanya mereset batas
+
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+    key        TEXT PRIMARY KEY,
+    tokens     DOUBLE PRECISION NOT NULL,
+    allowed    BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets (updated_at);
@@ -0,0 +1,8 @@
+-- Rollback migration: Hapus kolom aktivasi akun
+
+DROP INDEX IF EXISTS idx_users_activation_token_hash;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS activation_expires_at,
+    DROP COLUMN IF EXISTS activation_token_hash,
+    DROP COLUMN IF EXISTS must_change_password;
@@ -0,0 +1,11 @@
+-- Migration: Akun yang dibuat otomatis bersama mahasiswa/dosen
+-- NOTE: must_change_password memaksa ganti password setelah login pertama; akun mode aktivasi
+-- belum punya password (password_hash kosong) sampai token aktivasi dipakai
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS must_change_password BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS activation_token_hash CHAR(64),
+    ADD COLUMN IF NOT EXISTS activation_expires_at TIMESTAMP;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_activation_token_hash
+    ON users (activation_token_hash) WHERE activation_token_hash IS NOT NULL;
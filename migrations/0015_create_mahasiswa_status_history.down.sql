@@ -0,0 +1,3 @@
+-- Rollback migration: Hapus tabel riwayat status mahasiswa
+
+DROP TABLE IF EXISTS mahasiswa_status_history;
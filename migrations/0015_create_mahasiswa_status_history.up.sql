@@ -0,0 +1,26 @@
+-- Migration: Riwayat perubahan status mahasiswa
+-- NOTE: status hanya berubah lewat endpoint transisi; setiap transisi dicatat di sini beserta
+-- tanggal efektif, nomor SK, alasan dan user pelakunya. username/role disalin agar riwayat tetap
+-- terbaca walaupun akun pelaku sudah dihapus.
+
+CREATE TABLE IF NOT EXISTS mahasiswa_status_history (
+  id_history BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  id_mahasiswa CHAR(12) NOT NULL,
+  status_lama TEXT NOT NULL CHECK (status_lama IN ('Aktif','Cuti','Lulus','Drop Out','Non-Aktif')),
+  status_baru TEXT NOT NULL CHECK (status_baru IN ('Aktif','Cuti','Lulus','Drop Out','Non-Aktif')),
+  tanggal_efektif DATE NOT NULL,
+  nomor_sk VARCHAR(100),
+  alasan TEXT NOT NULL,
+  override BOOLEAN NOT NULL DEFAULT FALSE,
+  id_user BIGINT,
+  username VARCHAR(50) NOT NULL,
+  role VARCHAR(20) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT fk_status_history_mhs FOREIGN KEY (id_mahasiswa) REFERENCES mahasiswa(id_mahasiswa)
+    ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT fk_status_history_user FOREIGN KEY (id_user) REFERENCES users(id_user)
+    ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_history_mhs
+    ON mahasiswa_status_history (id_mahasiswa, tanggal_efektif DESC, id_history DESC);
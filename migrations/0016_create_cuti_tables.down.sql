@@ -0,0 +1,7 @@
+-- Rollback migration: Hapus pengajuan cuti, batas cuti prodi dan trigger blokir KRS
+
+DROP TRIGGER IF EXISTS block_cuti ON krs;
+DROP FUNCTION IF EXISTS trigger_krs_block_cuti();
+DROP TABLE IF EXISTS cuti;
+DROP FUNCTION IF EXISTS trigger_set_timestamp_cuti();
+DROP TABLE IF EXISTS prodi_cuti_policy;
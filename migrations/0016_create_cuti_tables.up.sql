@@ -0,0 +1,75 @@
+-- Migration: Pengajuan cuti akademik dan batas cuti per prodi
+-- NOTE: lampiran disimpan sebagai BYTEA (ukurannya dibatasi aplikasi) agar tidak bergantung pada
+-- object storage. Pengajuan yang masih Diajukan/Disetujui unik per (mahasiswa, semester).
+-- diterapkan = status mahasiswa sudah diubah ke Cuti untuk semester tersebut;
+-- selesai = semester berikutnya sudah dimulai dan status mahasiswa sudah dikembalikan.
+
+CREATE TABLE IF NOT EXISTS prodi_cuti_policy (
+  id_prodi CHAR(8) PRIMARY KEY,
+  maks_semester SMALLINT NOT NULL CHECK (maks_semester >= 0),
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT fk_cuti_policy_prodi FOREIGN KEY (id_prodi) REFERENCES prodi(id_prodi)
+    ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS cuti (
+  id_cuti BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  id_mahasiswa CHAR(12) NOT NULL,
+  id_semester CHAR(6) NOT NULL,
+  alasan TEXT NOT NULL,
+  lampiran_nama VARCHAR(255) NOT NULL,
+  lampiran_tipe VARCHAR(100) NOT NULL,
+  lampiran_ukuran INTEGER NOT NULL,
+  lampiran BYTEA NOT NULL,
+  status TEXT NOT NULL DEFAULT 'Diajukan' CHECK (status IN ('Diajukan','Disetujui','Ditolak','Dibatalkan')),
+  catatan TEXT,
+  id_reviewer BIGINT,
+  reviewed_at TIMESTAMP,
+  diterapkan BOOLEAN NOT NULL DEFAULT FALSE,
+  selesai BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT fk_cuti_mhs FOREIGN KEY (id_mahasiswa) REFERENCES mahasiswa(id_mahasiswa)
+    ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT fk_cuti_semester FOREIGN KEY (id_semester) REFERENCES semester(id_semester)
+    ON UPDATE CASCADE ON DELETE RESTRICT,
+  CONSTRAINT fk_cuti_reviewer FOREIGN KEY (id_reviewer) REFERENCES users(id_user)
+    ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cuti_open
+    ON cuti (id_mahasiswa, id_semester) WHERE status IN ('Diajukan','Disetujui');
+CREATE INDEX IF NOT EXISTS idx_cuti_semester_status ON cuti (id_semester, status);
+
+CREATE OR REPLACE FUNCTION trigger_set_timestamp_cuti()
+RETURNS TRIGGER AS $$
+BEGIN
+  NEW.updated_at = CURRENT_TIMESTAMP;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS set_timestamp ON cuti;
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON cuti
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp_cuti();
+
+-- Mahasiswa dengan cuti yang disetujui tidak boleh mengisi KRS pada semester tersebut
+CREATE OR REPLACE FUNCTION trigger_krs_block_cuti()
+RETURNS TRIGGER AS $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM cuti
+             WHERE id_mahasiswa = NEW.id_mahasiswa AND id_semester = NEW.id_semester AND status = 'Disetujui') THEN
+    RAISE EXCEPTION 'mahasiswa % sedang cuti pada semester %', NEW.id_mahasiswa, NEW.id_semester
+      USING ERRCODE = 'check_violation', TABLE = 'krs', COLUMN = 'id_semester', CONSTRAINT = 'krs_cuti';
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS block_cuti ON krs;
+CREATE TRIGGER block_cuti
+BEFORE INSERT OR UPDATE OF id_mahasiswa, id_semester ON krs
+FOR EACH ROW
+EXECUTE FUNCTION trigger_krs_block_cuti();
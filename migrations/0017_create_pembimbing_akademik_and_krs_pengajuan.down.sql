@@ -0,0 +1,7 @@
+-- Rollback migration: Hapus pembimbing akademik, pengajuan KRS dan trigger penguncian KRS
+
+DROP TRIGGER IF EXISTS lock_final ON krs;
+DROP FUNCTION IF EXISTS trigger_krs_lock_final();
+DROP TABLE IF EXISTS krs_pengajuan;
+DROP FUNCTION IF EXISTS trigger_set_timestamp_krs_pengajuan();
+DROP TABLE IF EXISTS pembimbing_akademik;
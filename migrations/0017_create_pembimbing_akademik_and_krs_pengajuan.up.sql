@@ -0,0 +1,104 @@
+-- Migration: Dosen pembimbing akademik (PA) dan persetujuan KRS
+-- NOTE: pembimbing_akademik menyimpan riwayat; baris dengan tanggal_selesai NULL adalah pembimbing
+-- saat ini (paling banyak satu per mahasiswa). krs_pengajuan adalah status KRS satu mahasiswa pada
+-- satu semester: tidak ada baris = masih disusun, Diajukan = menunggu PA, Dikembalikan = perlu
+-- diperbaiki, Disetujui = final. Hanya KRS yang disetujui dihitung pada IPK; KRS lama di-backfill
+-- sebagai Disetujui.
+
+CREATE TABLE IF NOT EXISTS pembimbing_akademik (
+  id_pembimbing BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  id_mahasiswa CHAR(12) NOT NULL,
+  id_dosen CHAR(10) NOT NULL,
+  tanggal_mulai DATE NOT NULL,
+  tanggal_selesai DATE,
+  alasan TEXT,
+  id_user BIGINT,
+  username VARCHAR(50) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT ck_pembimbing_tanggal CHECK (tanggal_selesai IS NULL OR tanggal_selesai >= tanggal_mulai),
+  CONSTRAINT fk_pembimbing_mhs FOREIGN KEY (id_mahasiswa) REFERENCES mahasiswa(id_mahasiswa)
+    ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT fk_pembimbing_dosen FOREIGN KEY (id_dosen) REFERENCES dosen(id_dosen)
+    ON UPDATE CASCADE ON DELETE RESTRICT,
+  CONSTRAINT fk_pembimbing_user FOREIGN KEY (id_user) REFERENCES users(id_user)
+    ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_pembimbing_aktif
+    ON pembimbing_akademik (id_mahasiswa) WHERE tanggal_selesai IS NULL;
+CREATE INDEX IF NOT EXISTS idx_pembimbing_dosen_aktif
+    ON pembimbing_akademik (id_dosen) WHERE tanggal_selesai IS NULL;
+
+CREATE TABLE IF NOT EXISTS krs_pengajuan (
+  id_pengajuan BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  id_mahasiswa CHAR(12) NOT NULL,
+  id_semester CHAR(6) NOT NULL,
+  status TEXT NOT NULL DEFAULT 'Diajukan' CHECK (status IN ('Diajukan','Dikembalikan','Disetujui')),
+  id_dosen CHAR(10),
+  catatan TEXT,
+  diajukan_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  reviewed_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uq_krs_pengajuan UNIQUE (id_mahasiswa, id_semester),
+  CONSTRAINT fk_krs_pengajuan_mhs FOREIGN KEY (id_mahasiswa) REFERENCES mahasiswa(id_mahasiswa)
+    ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT fk_krs_pengajuan_sem FOREIGN KEY (id_semester) REFERENCES semester(id_semester)
+    ON UPDATE CASCADE ON DELETE RESTRICT,
+  CONSTRAINT fk_krs_pengajuan_dosen FOREIGN KEY (id_dosen) REFERENCES dosen(id_dosen)
+    ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_krs_pengajuan_dosen ON krs_pengajuan (id_dosen, status);
+
+-- KRS yang sudah ada sebelum alur persetujuan dianggap final (Disetujui) agar tetap dihitung pada
+-- IPK dan pemeriksaan prasyarat
+INSERT INTO krs_pengajuan (id_mahasiswa, id_semester, status, diajukan_at, reviewed_at)
+SELECT id_mahasiswa, id_semester, 'Disetujui', MIN(tanggal_daftar), MIN(tanggal_daftar)
+FROM krs
+GROUP BY id_mahasiswa, id_semester
+ON CONFLICT (id_mahasiswa, id_semester) DO NOTHING;
+
+CREATE OR REPLACE FUNCTION trigger_set_timestamp_krs_pengajuan()
+RETURNS TRIGGER AS $$
+BEGIN
+  NEW.updated_at = CURRENT_TIMESTAMP;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS set_timestamp ON krs_pengajuan;
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON krs_pengajuan
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp_krs_pengajuan();
+
+-- Kelas pada KRS yang sedang diajukan atau sudah disetujui tidak boleh ditambah, dihapus atau dipindah
+CREATE OR REPLACE FUNCTION trigger_krs_lock_final()
+RETURNS TRIGGER AS $$
+DECLARE
+  r krs%ROWTYPE;
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    r := OLD;
+  ELSE
+    r := NEW;
+  END IF;
+  IF EXISTS (SELECT 1 FROM krs_pengajuan
+             WHERE id_mahasiswa = r.id_mahasiswa AND id_semester = r.id_semester
+               AND status IN ('Diajukan','Disetujui')) THEN
+    RAISE EXCEPTION 'KRS mahasiswa % semester % sudah diajukan', r.id_mahasiswa, r.id_semester
+      USING ERRCODE = 'check_violation', TABLE = 'krs', COLUMN = 'id_kelas', CONSTRAINT = 'krs_final';
+  END IF;
+  IF TG_OP = 'DELETE' THEN
+    RETURN OLD;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS lock_final ON krs;
+CREATE TRIGGER lock_final
+BEFORE INSERT OR DELETE OR UPDATE OF id_mahasiswa, id_semester, id_kelas ON krs
+FOR EACH ROW
+EXECUTE FUNCTION trigger_krs_lock_final();
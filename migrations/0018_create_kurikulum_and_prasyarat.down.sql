@@ -0,0 +1,6 @@
+-- Rollback migration: Hapus prasyarat mata kuliah dan kurikulum
+
+DROP TABLE IF EXISTS prasyarat_mk;
+DROP TABLE IF EXISTS kurikulum_mk;
+DROP TABLE IF EXISTS kurikulum;
+DROP FUNCTION IF EXISTS trigger_set_timestamp_kurikulum();
@@ -0,0 +1,63 @@
+-- Migration: Kurikulum per prodi dan prasyarat mata kuliah
+-- NOTE: kurikulum adalah versi kurikulum prodi per tahun; paling banyak satu yang aktif per prodi.
+-- kurikulum_mk menempatkan mata kuliah pada semester anjuran dengan jenis Wajib/Pilihan.
+-- prasyarat_mk berlaku untuk mata kuliah di semua kurikulum: id_mk hanya boleh diambil bila
+-- id_mk_prasyarat sudah lulus dengan nilai minimal nilai_minimal pada KRS yang disetujui.
+
+CREATE TABLE IF NOT EXISTS kurikulum (
+  id_kurikulum BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+  id_prodi CHAR(8) NOT NULL,
+  tahun SMALLINT NOT NULL CHECK (tahun BETWEEN 1900 AND 2100),
+  nama VARCHAR(120) NOT NULL,
+  aktif BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT uq_kurikulum_tahun UNIQUE (id_prodi, tahun),
+  CONSTRAINT fk_kurikulum_prodi FOREIGN KEY (id_prodi) REFERENCES prodi(id_prodi)
+    ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_kurikulum_aktif ON kurikulum (id_prodi) WHERE aktif;
+
+CREATE OR REPLACE FUNCTION trigger_set_timestamp_kurikulum()
+RETURNS TRIGGER AS $$
+BEGIN
+  NEW.updated_at = CURRENT_TIMESTAMP;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS set_timestamp ON kurikulum;
+CREATE TRIGGER set_timestamp
+BEFORE UPDATE ON kurikulum
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp_kurikulum();
+
+CREATE TABLE IF NOT EXISTS kurikulum_mk (
+  id_kurikulum BIGINT NOT NULL,
+  id_mk CHAR(10) NOT NULL,
+  semester SMALLINT NOT NULL CHECK (semester BETWEEN 1 AND 14),
+  jenis TEXT NOT NULL DEFAULT 'Wajib' CHECK (jenis IN ('Wajib','Pilihan')),
+  PRIMARY KEY (id_kurikulum, id_mk),
+  CONSTRAINT fk_kurikulum_mk_kurikulum FOREIGN KEY (id_kurikulum) REFERENCES kurikulum(id_kurikulum)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_kurikulum_mk_mk FOREIGN KEY (id_mk) REFERENCES mata_kuliah(id_mk)
+    ON UPDATE CASCADE ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_kurikulum_mk_mk ON kurikulum_mk (id_mk);
+
+CREATE TABLE IF NOT EXISTS prasyarat_mk (
+  id_mk CHAR(10) NOT NULL,
+  id_mk_prasyarat CHAR(10) NOT NULL,
+  nilai_minimal TEXT NOT NULL DEFAULT 'C' CHECK (nilai_minimal IN ('A','AB','B','BC','C','D','E')),
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id_mk, id_mk_prasyarat),
+  CONSTRAINT ck_prasyarat_diri CHECK (id_mk <> id_mk_prasyarat),
+  CONSTRAINT fk_prasyarat_mk FOREIGN KEY (id_mk) REFERENCES mata_kuliah(id_mk)
+    ON UPDATE CASCADE ON DELETE CASCADE,
+  CONSTRAINT fk_prasyarat_mk_prasyarat FOREIGN KEY (id_mk_prasyarat) REFERENCES mata_kuliah(id_mk)
+    ON UPDATE CASCADE ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_prasyarat_mk_prasyarat ON prasyarat_mk (id_mk_prasyarat);
@@ -0,0 +1,38 @@
+// Package migrations menyematkan file SQL migrasi ke dalam binary sehingga versi
+// terbaru bisa diketahui tanpa bergantung pada working directory.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+	"regexp"
+	"strconv"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+var upFilePattern = regexp.MustCompile(`^(\d+)_.+\.up\.sql$`)
+
+// Latest mengembalikan nomor versi migrasi tertinggi yang tersemat
+func Latest() (uint, error) {
+	entries, err := fs.ReadDir(FS, ".")
+	if err != nil {
+		return 0, err
+	}
+	var latest uint
+	for _, e := range entries {
+		m := upFilePattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		v, err := strconv.ParseUint(m[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		if uint(v) > latest {
+			latest = uint(v)
+		}
+	}
+	return latest, nil
+}